@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/pkg/config"
+	"suxin/internal/pkg/database"
+	"suxin/internal/service"
+)
+
+// 从行情Tick归档重建K线
+//
+// 用法：
+//   go run ./cmd/backfill_candles -from 2025-11-01 -to 2025-11-20
+//   go run ./cmd/backfill_candles -symbol AU9999 -from 2025-11-18
+//
+// 时间范围按日期对齐（-to 不含当天，默认为明天，即包含今天）
+
+func main() {
+	symbol := flag.String("symbol", "", "品种代码（为空表示全部品种）")
+	fromStr := flag.String("from", "", "开始日期（YYYY-MM-DD，必填）")
+	toStr := flag.String("to", "", "结束日期（YYYY-MM-DD，不含，默认明天）")
+	flag.Parse()
+
+	from, err := time.ParseInLocation("2006-01-02", *fromStr, time.Local)
+	if err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	to := time.Now().AddDate(0, 0, 1)
+	if *toStr != "" {
+		if to, err = time.ParseInLocation("2006-01-02", *toStr, time.Local); err != nil {
+			log.Fatalf("invalid -to: %v", err)
+		}
+	}
+
+	// 加载配置，与主服务保持一致
+	env := config.AppEnv()
+	cfg, err := config.Load(env)
+	if err != nil {
+		log.Fatalf("load config failed: %v", err)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		log.Fatalf("connect db failed: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		log.Fatalf("auto migrate failed: %v", err)
+	}
+
+	history := service.NewQuoteHistoryService(appctx.New(db, cfg))
+	ticks, candles, err := history.RebuildCandles(strings.ToUpper(*symbol), from, to)
+	if err != nil {
+		log.Fatalf("rebuild candles failed: %v", err)
+	}
+
+	log.Printf("[Backfill] ✅ K线重建完成：Tick %d 条，K线 %d 根", ticks, candles)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"suxin/internal/appctx"
+	"suxin/internal/pkg/config"
+	"suxin/internal/pkg/database"
+	"suxin/internal/pkg/storage"
+	"suxin/internal/service"
+)
+
+// 将数据库中的 Base64 凭证、身份证照片转存到文件存储
+//
+// 用法：
+//   go run ./cmd/migrate_files -dry-run     只统计待迁移记录
+//   go run ./cmd/migrate_files -batch 200
+//
+// 迁移后字段改为文件引用（file:<hash>），可重复执行；
+// MySQL 迁移完成后可执行 OPTIMIZE TABLE 回收空间
+
+func main() {
+	batch := flag.Int("batch", 100, "每批记录数")
+	dryRun := flag.Bool("dry-run", false, "只统计不转存")
+	flag.Parse()
+
+	// 加载配置，与主服务保持一致
+	env := config.AppEnv()
+	cfg, err := config.Load(env)
+	if err != nil {
+		log.Fatalf("load config failed: %v", err)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		log.Fatalf("connect db failed: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		log.Fatalf("auto migrate failed: %v", err)
+	}
+
+	fileStorage, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("init file storage failed: %v", err)
+	}
+	service.SetDefaultFileStorage(fileStorage)
+
+	result, err := service.NewFileService(appctx.New(db, cfg)).MigrateInlineFiles(*batch, *dryRun)
+	if err != nil {
+		log.Fatalf("migrate files failed: %v", err)
+	}
+
+	if *dryRun {
+		log.Printf("[MigrateFiles] 扫描 %d 条，待迁移 %d 条（%.2f MB）", result.Scanned, result.Migrated, float64(result.BytesBefore)/(1<<20))
+		return
+	}
+	log.Printf("[MigrateFiles] ✅ 扫描 %d 条，迁移 %d 条，失败 %d 条，字段大小 %.2f MB → %.2f KB",
+		result.Scanned, result.Migrated, result.Failed,
+		float64(result.BytesBefore)/(1<<20), float64(result.BytesAfter)/(1<<10))
+}
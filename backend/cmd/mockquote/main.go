@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"suxin/internal/pkg/config"
+	ws "suxin/internal/websocket"
+)
+
+// 模拟上游行情推送服务（与真实上游协议一致），用于本地联调行情代理和风控
+//
+// 用法：
+//   go run ./cmd/mockquote -addr :9001
+//   go run ./cmd/mockquote -replay ./data/quotes.jsonl -speed 5
+//
+// 主服务配置中增加 jtd 类型行情源指向 ws://127.0.0.1:9001/ws 即可
+
+var (
+	addr       = flag.String("addr", ":9001", "监听地址")
+	path       = flag.String("path", "/ws", "WebSocket路径")
+	symbol     = flag.String("symbol", "AU9999", "品种代码")
+	startPrice = flag.Float64("start", 500, "初始价格（元/克）")
+	intervalMs = flag.Int("interval", 1000, "推送间隔（毫秒）")
+	volatility = flag.Float64("volatility", 0.3, "随机游走单跳最大波动（元）")
+	jumpProb   = flag.Float64("jump-prob", 0, "每跳发生跳空的概率（0-1）")
+	jumpSize   = flag.Float64("jump-size", 5, "跳空幅度（元）")
+	gapProb    = flag.Float64("gap-prob", 0, "每跳发生断流的概率（0-1）")
+	gapSeconds = flag.Int("gap-seconds", 30, "断流时长（秒）")
+	replayPath = flag.String("replay", "", "回放录制文件（JSON lines），为空则生成模拟行情")
+	speed      = flag.Float64("speed", 1, "回放倍速")
+)
+
+const heartbeatPeriod = 10 * time.Second
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func main() {
+	flag.Parse()
+
+	http.HandleFunc(*path, serveQuote)
+	log.Printf("[MockQuote] ✅ 模拟行情服务已启动: ws://127.0.0.1%s%s", *addr, *path)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatalf("listen failed: %v", err)
+	}
+}
+
+// serveQuote 处理一个下游连接：等待订阅消息后持续推送行情和心跳
+func serveQuote(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[MockQuote] 升级连接失败: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// 与上游一致：客户端先发送订阅消息
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		log.Printf("[MockQuote] 读取订阅消息失败: %v", err)
+		return
+	}
+	var sub map[string]interface{}
+	if err := json.Unmarshal(msg, &sub); err != nil || sub["dempCode"] == nil || sub["secret"] == nil {
+		log.Printf("[MockQuote] ⚠️ 订阅消息缺少 dempCode/secret: %s", string(msg))
+	} else {
+		log.Printf("[MockQuote] 新连接订阅成功: %s", r.RemoteAddr)
+	}
+
+	// 丢弃后续客户端消息，连接关闭时结束推送
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	next, stop, err := newFeed()
+	if err != nil {
+		log.Printf("[MockQuote] %v", err)
+		return
+	}
+	defer stop()
+
+	heartbeat := time.NewTicker(heartbeatPeriod)
+	defer heartbeat.Stop()
+
+	for {
+		frame, wait, err := next()
+		if err == io.EOF {
+			log.Println("[MockQuote] 回放结束")
+			<-closed
+			return
+		}
+		if err != nil {
+			log.Printf("[MockQuote] 生成行情失败: %v", err)
+			return
+		}
+
+		timer := time.NewTimer(wait)
+	waitLoop:
+		for {
+			select {
+			case <-closed:
+				timer.Stop()
+				log.Printf("[MockQuote] 连接断开: %s", r.RemoteAddr)
+				return
+			case <-heartbeat.C:
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"p"}`)); err != nil {
+					return
+				}
+			case <-timer.C:
+				break waitLoop
+			}
+		}
+
+		if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+			return
+		}
+	}
+}
+
+// newFeed 按参数创建行情来源：回放文件或模拟生成器
+func newFeed() (func() ([]byte, time.Duration, error), func(), error) {
+	if *replayPath != "" {
+		replayer, err := ws.NewQuoteReplayer(*replayPath, *speed, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		return replayer.Next, replayer.Close, nil
+	}
+
+	generator := ws.NewQuoteGenerator(config.QuoteSourceConfig{
+		Symbol:          *symbol,
+		StartPrice:      *startPrice,
+		IntervalMs:      *intervalMs,
+		Volatility:      *volatility,
+		JumpProbability: *jumpProb,
+		JumpSize:        *jumpSize,
+		GapProbability:  *gapProb,
+		GapSeconds:      *gapSeconds,
+	})
+	return func() ([]byte, time.Duration, error) {
+		frame, pause := generator.Next()
+		return frame, generator.Interval() + pause, nil
+	}, func() {}, nil
+}
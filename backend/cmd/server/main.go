@@ -12,6 +12,8 @@ import (
 
 	"suxin/internal/pkg/config"
 	"suxin/internal/pkg/database"
+	"suxin/internal/pkg/payment"
+	"suxin/internal/pkg/storage"
 	"suxin/internal/appctx"
 	"suxin/internal/api/v1"
 	"suxin/internal/middleware"
@@ -38,13 +40,36 @@ func main() {
 	if err := database.AutoMigrate(db); err != nil {
 		log.Fatalf("auto migrate failed: %v", err)
 	}
+	if err := database.SeedDefaultConfigs(db); err != nil {
+		log.Fatalf("seed default configs failed: %v", err)
+	}
 
 	app := appctx.New(db, cfg)
 
+	// 初始化总账平台科目（客户资金变更时同步记账）
+	if err := service.NewLedgerService(app).EnsureHouseAccounts(); err != nil {
+		log.Fatalf("init ledger accounts failed: %v", err)
+	}
+
 	// 启动WebSocket行情代理（上海黄金交易所）
-	quoteHub := ws.NewQuoteProxyHub()
+	quoteHub, err := ws.NewQuoteProxyHub(cfg)
+	if err != nil {
+		log.Fatalf("init quote hub failed: %v", err)
+	}
+	quoteHub.SetSpreadProvider(service.NewQuoteSpreadService(app).GetSpread)
 	go quoteHub.Run()
-	log.Println("[Main] ✅ WebSocket行情代理已启动（数据源: 上海黄金交易所）")
+	service.SetDefaultQuoteService(service.NewQuoteService(quoteHub))
+	log.Printf("[Main] ✅ WebSocket行情代理已启动（数据源: 上海黄金交易所，行情源 %d 个）", len(quoteHub.GetSourceStatuses()))
+	
+	// 启动行情历史（K线聚合 + Tick归档 + 异常Tick隔离日志，每5秒落库）
+	quoteHistory := service.NewQuoteHistoryService(app)
+	service.SetDefaultQuoteHistory(quoteHistory)
+	quoteHub.OnTicks(quoteHistory.OnTicks)
+	quoteQuarantine := service.NewQuoteQuarantineService(app)
+	service.SetDefaultQuoteQuarantine(quoteQuarantine)
+	quoteHub.OnQuarantine(quoteQuarantine.OnQuarantine)
+	quoteHistoryScheduler := scheduler.NewQuoteHistoryScheduler(quoteHistory, quoteQuarantine, 5)
+	quoteHistoryScheduler.Start()
 	
 	// 启动WebSocket通知推送中心
 	notificationHub := ws.NewNotificationHub()
@@ -52,11 +77,60 @@ func main() {
 	log.Println("[Main] ✅ WebSocket通知推送中心已启动")
 	service.SetDefaultNotificationHub(notificationHub)
 
+	// 启动价格提醒（在K线聚合之后判断，保证当日开盘价已更新）
+	priceAlert := service.NewPriceAlertService(app)
+	service.SetDefaultPriceAlert(priceAlert)
+	quoteHub.OnTicks(priceAlert.OnTicks)
+	go priceAlert.Run()
+
+	// 启动行情保护（行情过期时进入降级模式，暂停下单/结算/强平）
+	quoteFailsafe := service.NewQuoteFailsafeService(app, quoteHub, quoteHub)
+	service.SetDefaultQuoteFailsafe(quoteFailsafe)
+	quoteFailsafeScheduler := scheduler.NewQuoteFailsafeScheduler(quoteFailsafe, 5)
+	quoteFailsafeScheduler.Start()
+	log.Println("[Main] ✅ 行情保护已启动（检查间隔: 5秒）")
+
+	// 启动定时任务选主（多实例部署时只有主实例执行风控等定时任务）
+	leaderElection := service.NewLeaderElectionService(app)
+	service.SetDefaultLeaderElection(leaderElection)
+	leaderElection.Start()
+
 	// 启动风控调度器（15秒间隔，使用WebSocket价格）
 	riskScheduler := scheduler.NewRiskScheduler(app, 15, quoteHub)
+	riskScheduler.SetLeaderElection(leaderElection)
 	riskScheduler.Start()
 	log.Println("[Main] ✅ 风控调度器已启动（间隔: 15秒，价格来源: WebSocket实时数据）")
 
+	// 启动每日资金对账（每分钟检查是否到达对账时间，仅主实例执行）
+	reconciliationScheduler := scheduler.NewReconciliationScheduler(service.NewReconciliationService(app), 60)
+	reconciliationScheduler.Start()
+
+	// 启动月度对账单（每分钟检查是否进入新的月份，生成上月对账单，仅主实例执行）
+	statementScheduler := scheduler.NewAccountStatementScheduler(service.NewAccountStatementService(app), 60)
+	statementScheduler.Start()
+
+	// 初始化在线支付通道，并启动支付查单（每分钟对未收到回调的支付单查单补单，仅主实例执行）
+	paymentProviders, err := payment.NewProviders(cfg)
+	if err != nil {
+		log.Fatalf("init payment providers failed: %v", err)
+	}
+	service.SetDefaultPaymentProviders(paymentProviders)
+	paymentQueryScheduler := scheduler.NewPaymentQueryScheduler(service.NewPaymentService(app), 60)
+	paymentQueryScheduler.Start()
+	log.Printf("[Main] ✅ 在线支付已启动（支付通道 %d 个）", len(paymentProviders))
+
+	// 初始化文件存储（付款凭证、打款凭证、身份证照片）
+	fileStorage, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("init file storage failed: %v", err)
+	}
+	service.SetDefaultFileStorage(fileStorage)
+	log.Printf("[Main] ✅ 文件存储已启动（%s）", fileStorage.Driver())
+
+	// 启动数据导出（每10秒执行排队中的异步导出任务并清理过期文件，仅主实例执行）
+	exportScheduler := scheduler.NewExportScheduler(service.NewExportService(app), 10)
+	exportScheduler.Start()
+
 	// WebSocket升级器
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
@@ -95,6 +169,9 @@ func main() {
 	// 公开路由（无需认证）
 	api := r.Group("/api/v1")
 	v1.RegisterAuthRoutes(api, app)
+	v1.RegisterFileDownloadRoutes(api, app) // 文件下载（以链接签名认证）
+	v1.RegisterQuoteFeedRoutes(api, app) // 最新报价/SSE，是否需要认证由 quote.public_feed 决定
+	v1.RegisterPaymentCallbackRoutes(api, app) // 支付通道异步回调（以签名认证）
 
 	// 受保护路由（需要JWT认证）
 	protected := api.Group("", middleware.AuthRequired(app))
@@ -104,12 +181,26 @@ func main() {
 	v1.RegisterBankCardRoutes(protected, app)
 	v1.RegisterSalesRoutes(protected, app)
 	v1.RegisterDepositRoutes(protected, app)
+	v1.RegisterPaymentRoutes(protected, app)
+	v1.RegisterBankStatementRoutes(protected, app)
+	v1.RegisterFileRoutes(protected, app)
 	v1.RegisterWithdrawRoutes(protected, app)
+	v1.RegisterWithdrawPolicyRoutes(protected, app)
 	v1.RegisterFundLogRoutes(protected, app)
+	v1.RegisterLedgerRoutes(protected, app)
+	v1.RegisterReconciliationRoutes(protected, app)
+	v1.RegisterAccountStatementRoutes(protected, app)
+	v1.RegisterExportRoutes(protected, app)
+	v1.RegisterAdjustmentRoutes(protected, app)
 	v1.RegisterUserManageRoutes(protected, app)
 	v1.RegisterConfigRoutes(protected, app)
 	v1.RegisterSupplementRoutes(protected, app)
+	v1.RegisterMarginCallRoutes(protected, app)
 	v1.RegisterInvitationRoutes(protected, app)
+	v1.RegisterMarketRoutes(protected, app)
+	v1.RegisterQuoteRoutes(protected, app)
+	v1.RegisterPriceAlertRoutes(protected, app)
+	v1.RegisterSystemRoutes(protected, app)
 
 	// WebSocket行情代理接口
 	r.GET("/ws/quote", func(c *gin.Context) {
@@ -165,5 +256,12 @@ func main() {
 
 	log.Println("[Main] 🛑 收到退出信号，正在关闭服务...")
 	riskScheduler.Stop()
+	reconciliationScheduler.Stop()
+	statementScheduler.Stop()
+	exportScheduler.Stop()
+	paymentQueryScheduler.Stop()
+	quoteFailsafeScheduler.Stop()
+	quoteHistoryScheduler.Stop()
+	leaderElection.Stop()
 	log.Println("[Main] ✅ 服务已关闭")
 }
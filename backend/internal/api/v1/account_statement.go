@@ -0,0 +1,190 @@
+/**
+ * 账户对账单API处理器
+ *
+ * 用途：
+ * - 客户查看、生成、下载自己的日/月对账单
+ * - 管理员（审计）查看、生成、下载任意客户的对账单
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterAccountStatementRoutes 注册账户对账单路由
+ *
+ * 路由列表：
+ * - GET  /statements                       查询我的对账单（需JWT）
+ * - POST /statements                       生成我的对账单（需JWT）
+ * - GET  /statements/:id/download          下载我的对账单（需JWT）
+ * - GET  /statements/admin                 查询客户对账单（需JWT+管理员）
+ * - POST /statements/admin                 为客户生成对账单（需JWT+管理员）
+ * - GET  /statements/admin/:id/download    下载客户对账单（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterAccountStatementRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	statementSvc := service.NewAccountStatementService(ctx)
+	statements := rg.Group("/statements")
+	admin := statements.Group("/admin", middleware.RequireAdmin(ctx))
+
+	type generateRequest struct {
+		UserID     uint   `json:"user_id"`
+		PeriodType string `json:"period_type" binding:"required"`
+		Period     string `json:"period" binding:"required"`
+	}
+
+	generate := func(c *gin.Context, userID uint, req *generateRequest) {
+		periodStart, err := statementSvc.ParsePeriod(req.PeriodType, req.Period)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		statement, err := statementSvc.Generate(userID, req.PeriodType, periodStart,
+			model.StatementTriggerManual, c.GetUint("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, statement)
+	}
+
+	list := func(c *gin.Context, userID uint) {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		result, total, err := statementSvc.GetStatements(userID, c.Query("period_type"), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"statements": result, "total": total})
+	}
+
+	// ownerID 为0时不校验归属（管理员）
+	download := func(c *gin.Context, ownerID uint) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的对账单ID"})
+			return
+		}
+		statement, err := statementSvc.GetStatement(uint(id))
+		if err != nil || (ownerID > 0 && statement.UserID != ownerID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "对账单不存在"})
+			return
+		}
+
+		file, err := statementSvc.Render(statement, c.DefaultQuery("format", service.StatementFormatPDF))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s",
+			file.FileName, url.PathEscape(file.FileName)))
+		c.Data(http.StatusOK, file.ContentType, file.Data)
+	}
+
+	/**
+	 * GET /statements - 查询我的对账单
+	 *
+	 * 查询参数：
+	 * - period_type: 周期类型（可选，daily/monthly）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	statements.GET("", func(c *gin.Context) {
+		list(c, c.GetUint("user_id"))
+	})
+
+	/**
+	 * POST /statements - 生成我的对账单（已生成过的周期返回已有对账单）
+	 *
+	 * 请求体：
+	 * {
+	 *   "period_type": "monthly",   // daily/monthly
+	 *   "period": "2025-10"         // 日对账单为日期 2025-10-31，月对账单为月份 2025-10
+	 * }
+	 */
+	statements.POST("", func(c *gin.Context) {
+		var req generateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		generate(c, c.GetUint("user_id"), &req)
+	})
+
+	/**
+	 * GET /statements/:id/download - 下载我的对账单
+	 *
+	 * 查询参数：
+	 * - format: 文件格式（pdf/csv，默认pdf）
+	 */
+	statements.GET("/:id/download", func(c *gin.Context) {
+		download(c, c.GetUint("user_id"))
+	})
+
+	/**
+	 * GET /statements/admin - 管理员查询客户对账单
+	 *
+	 * 查询参数：
+	 * - user_id: 客户ID（可选）
+	 * - period_type: 周期类型（可选，daily/monthly）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("", func(c *gin.Context) {
+		userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+		list(c, uint(userID))
+	})
+
+	/**
+	 * POST /statements/admin - 管理员为客户生成对账单
+	 *
+	 * 请求体：
+	 * {
+	 *   "user_id": 12,
+	 *   "period_type": "daily",
+	 *   "period": "2025-10-31"
+	 * }
+	 */
+	admin.POST("", func(c *gin.Context) {
+		var req generateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.UserID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请指定客户"})
+			return
+		}
+		generate(c, req.UserID, &req)
+	})
+
+	/**
+	 * GET /statements/admin/:id/download - 管理员下载客户对账单
+	 *
+	 * 查询参数：
+	 * - format: 文件格式（pdf/csv，默认pdf）
+	 */
+	admin.GET("/:id/download", func(c *gin.Context) {
+		download(c, 0)
+	})
+}
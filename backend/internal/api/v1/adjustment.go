@@ -0,0 +1,195 @@
+/**
+ * 手工余额调整API处理器
+ *
+ * 用途：
+ * - 管理员按原因调增/调减客户可用定金（须填写说明并上传附件）
+ * - 超过审批阈值的调整由超级管理员审批后入账
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+	"suxin/internal/service"
+)
+
+// createAdjustmentReq 发起余额调整请求
+type createAdjustmentReq struct {
+	UserID     uint    `json:"user_id" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required"`
+	ReasonCode string  `json:"reason_code" binding:"required"`
+	Attachment string  `json:"attachment" binding:"required"`
+	Note       string  `json:"note" binding:"required"`
+}
+
+// reviewAdjustmentReq 审批余额调整请求
+type reviewAdjustmentReq struct {
+	Action string `json:"action" binding:"required,oneof=approve reject"`
+	Note   string `json:"note"`
+}
+
+/**
+ * RegisterAdjustmentRoutes 注册手工余额调整路由
+ *
+ * 路由列表：
+ * - GET  /admin/adjustments/reasons          查询调整原因（需JWT+管理员）
+ * - GET  /admin/adjustments                  查询余额调整（需JWT+管理员）
+ * - POST /admin/adjustments                  发起余额调整（需JWT+管理员，超过阈值需超级管理员审批）
+ * - POST /admin/adjustments/:id/review       审批余额调整（需JWT+管理员，超过阈值需超级管理员）
+ * - GET  /admin/adjustments/:id/approvals    查询余额调整审批记录（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterAdjustmentRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	adjustmentSvc := service.NewAdjustmentService(ctx)
+	adjustments := rg.Group("/admin/adjustments", middleware.RequireAdmin(ctx))
+
+	// GET /admin/adjustments/reasons - 查询调整原因
+	adjustments.GET("/reasons", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"reasons": model.AdjustmentReasonText})
+	})
+
+	/**
+	 * GET /admin/adjustments - 查询余额调整
+	 *
+	 * 查询参数：
+	 * - user_id: 客户ID（可选）
+	 * - status: 状态（pending/applied/rejected，可选）
+	 * - reason_code: 调整原因（可选）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	adjustments.GET("", func(c *gin.Context) {
+		userID, _ := strconv.ParseUint(c.Query("user_id"), 10, 32)
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		list, total, err := adjustmentSvc.GetAdjustments(repository.AdjustmentFilter{
+			UserID:     uint(userID),
+			Status:     c.Query("status"),
+			ReasonCode: c.Query("reason_code"),
+		}, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"adjustments": list, "total": total})
+	})
+
+	/**
+	 * POST /admin/adjustments - 发起余额调整
+	 *
+	 * 请求body：
+	 * {
+	 *   "user_id": 12,
+	 *   "amount": -50.00,               // 正数调增，负数调减
+	 *   "reason_code": "fee_refund",    // compensation/correction/bonus/fee_refund
+	 *   "attachment": "file:xxxx",      // 说明附件（先通过 /files 上传）
+	 *   "note": "退还重复收取的提现手续费"
+	 * }
+	 *
+	 * 响应：
+	 * {
+	 *   "message": "调整已入账",        // 超过阈值时为 "已提交，等待超级管理员审批"
+	 *   "adjustment": {...}
+	 * }
+	 */
+	adjustments.POST("", func(c *gin.Context) {
+		var req createAdjustmentReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误：客户、金额、原因、附件和说明均为必填"})
+			return
+		}
+
+		adjustment, err := adjustmentSvc.CreateAdjustment(c.GetUint("user_id"), req.UserID, req.Amount, req.ReasonCode, req.Attachment, req.Note)
+		if err != nil {
+			var insufficient *service.InsufficientBalanceError
+			if errors.As(err, &insufficient) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "调减金额超过客户可用定金：" + err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		message := "调整已入账"
+		if adjustment.IsPending() {
+			message = "已提交，等待超级管理员审批"
+		}
+		c.JSON(http.StatusOK, gin.H{"message": message, "adjustment": adjustment})
+	})
+
+	/**
+	 * POST /admin/adjustments/:id/review - 审批余额调整
+	 *
+	 * 请求body：
+	 * {
+	 *   "action": "approve",  // approve 或 reject
+	 *   "note": "审批备注"
+	 * }
+	 *
+	 * 响应：
+	 * {
+	 *   "message": "审批成功",
+	 *   "status": "applied"   // applied/rejected
+	 * }
+	 */
+	adjustments.POST("/:id/review", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的调整ID"})
+			return
+		}
+		var req reviewAdjustmentReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+			return
+		}
+
+		reviewerID := c.GetUint("user_id")
+		if req.Action == "reject" {
+			if err := adjustmentSvc.RejectAdjustment(uint(id), reviewerID, req.Note); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "审批成功", "status": model.AdjustmentStatusRejected})
+			return
+		}
+
+		adjustment, err := adjustmentSvc.ApproveAdjustment(uint(id), reviewerID, req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "审批成功", "status": adjustment.Status})
+	})
+
+	// GET /admin/adjustments/:id/approvals - 查询余额调整审批记录（含发起步骤）
+	adjustments.GET("/:id/approvals", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的调整ID"})
+			return
+		}
+		steps, err := adjustmentSvc.GetAdjustmentApprovals(uint(id))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"approvals": steps})
+	})
+}
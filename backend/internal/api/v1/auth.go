@@ -64,6 +64,7 @@ func RegisterAuthRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	userRepo := repository.NewUserRepository(ctx.DB)
 	notiSvc := service.NewNotificationService(ctx)
 	verificationRepo := repository.NewUserVerificationRepository(ctx.DB)
+	fileSvc := service.NewFileService(ctx)
 
 	rg.POST("/auth/register", func(c *gin.Context) {
 		var req registerReq
@@ -72,6 +73,19 @@ func RegisterAuthRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			return
 		}
 		
+		// 身份证照片转存到文件存储（注册时尚无用户ID，上传人记为0）
+		if req.Verification != nil {
+			var err error
+			if req.Verification.IDFrontURL, err = fileSvc.Ingest(req.Verification.IDFrontURL, model.FileCategoryIDCard, 0); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "身份证照片保存失败: " + err.Error()})
+				return
+			}
+			if req.Verification.IDBackURL, err = fileSvc.Ingest(req.Verification.IDBackURL, model.FileCategoryIDCard, 0); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "身份证照片保存失败: " + err.Error()})
+				return
+			}
+		}
+		
 		// 1. 验证邀请码
 		invitationSvc := service.NewInvitationService(ctx)
 		inviteCode, err := invitationSvc.ValidateInvitationCode(req.InviteCode)
@@ -245,6 +259,7 @@ func RegisterAuthRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			"sales_id":                user.SalesID,
 			"available_deposit":       user.AvailableDeposit,
 			"used_deposit":            user.UsedDeposit,
+			"withdraw_frozen":         user.WithdrawFrozen,
 			"has_pay_password":        user.HasPayPassword,
 			"auto_supplement_enabled": user.AutoSupplementEnabled,
 			"created_at":              user.CreatedAt,
@@ -269,8 +284,8 @@ func RegisterAuthRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 				"user_id":        v.UserID,
 				"real_name":      v.RealName,
 				"id_number":      v.IDNumber,
-				"id_front_url":   v.IDFrontURL,
-				"id_back_url":    v.IDBackURL,
+				"id_front_url":   fileSvc.ResolveRefs(v.IDFrontURL),
+				"id_back_url":    fileSvc.ResolveRefs(v.IDBackURL),
 				"bank_card_id":   v.BankCardID,
 				"receiver_name":  v.ReceiverName,
 				"receiver_phone": v.ReceiverPhone,
@@ -302,8 +317,14 @@ func RegisterAuthRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		}
 		v.RealName = req.RealName
 		v.IDNumber = req.IDNumber
-		v.IDFrontURL = req.IDFrontURL
-		v.IDBackURL = req.IDBackURL
+		if v.IDFrontURL, err = fileSvc.Ingest(req.IDFrontURL, model.FileCategoryIDCard, userID); err != nil {
+			response.BadRequest(c, "身份证照片保存失败: "+err.Error())
+			return
+		}
+		if v.IDBackURL, err = fileSvc.Ingest(req.IDBackURL, model.FileCategoryIDCard, userID); err != nil {
+			response.BadRequest(c, "身份证照片保存失败: "+err.Error())
+			return
+		}
 		v.BankCardID = req.BankCardID
 		v.ReceiverName = req.ReceiverName
 		v.ReceiverPhone = req.ReceiverPhone
@@ -0,0 +1,322 @@
+/**
+ * 银行流水API处理器
+ *
+ * 用途：
+ * - 管理员维护各银行流水文件的列映射
+ * - 上传流水文件（CSV/XLSX）导入并自动匹配充值申请
+ * - 处理建议匹配和异常流水（确认、驳回、指定充值申请、忽略）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/service"
+)
+
+const maxStatementFileSize = 10 << 20 // 流水文件大小上限（10MB）
+
+/**
+ * RegisterBankStatementRoutes 注册银行流水路由（均需JWT+管理员）
+ *
+ * 路由列表：
+ * - GET    /bank-statements/mappings              查询列映射
+ * - POST   /bank-statements/mappings              新增列映射
+ * - PUT    /bank-statements/mappings/:id          修改列映射
+ * - DELETE /bank-statements/mappings/:id          删除列映射
+ * - POST   /bank-statements/imports               上传流水文件导入（multipart：file、mapping_id）
+ * - GET    /bank-statements/imports               查询导入批次
+ * - GET    /bank-statements/lines                 查询流水（按状态、批次筛选）
+ * - GET    /bank-statements/lines/:id/candidates  查询流水的候选充值申请
+ * - POST   /bank-statements/lines/:id/confirm     确认匹配（异常流水需指定充值申请）
+ * - POST   /bank-statements/lines/:id/reject      驳回建议匹配
+ * - POST   /bank-statements/lines/:id/ignore      忽略异常流水
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterBankStatementRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	statementSvc := service.NewBankStatementService(ctx)
+	admin := rg.Group("/bank-statements", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /bank-statements/mappings - 查询列映射
+	 */
+	admin.GET("/mappings", func(c *gin.Context) {
+		mappings, err := statementSvc.ListMappings()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"mappings": mappings})
+	})
+
+	/**
+	 * POST /bank-statements/mappings - 新增列映射
+	 *
+	 * 请求体：
+	 * {
+	 *   "bank_name": "工商银行",
+	 *   "encoding": "gbk",
+	 *   "header_row": 1,
+	 *   "date_column": "交易时间",
+	 *   "amount_column": "收入金额",
+	 *   "payer_name_column": "对方户名",
+	 *   "reference_column": "摘要"
+	 * }
+	 */
+	admin.POST("/mappings", func(c *gin.Context) {
+		var mapping model.BankStatementMapping
+		if err := c.ShouldBindJSON(&mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+		mapping.ID = 0
+
+		if err := statementSvc.SaveMapping(&mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, mapping)
+	})
+
+	/**
+	 * PUT /bank-statements/mappings/:id - 修改列映射
+	 */
+	admin.PUT("/mappings/:id", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的列映射ID"})
+			return
+		}
+		var mapping model.BankStatementMapping
+		if err := c.ShouldBindJSON(&mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+		mapping.ID = uint(id)
+
+		if err := statementSvc.SaveMapping(&mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, mapping)
+	})
+
+	/**
+	 * DELETE /bank-statements/mappings/:id - 删除列映射
+	 */
+	admin.DELETE("/mappings/:id", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的列映射ID"})
+			return
+		}
+		if err := statementSvc.DeleteMapping(uint(id)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+	})
+
+	/**
+	 * POST /bank-statements/imports - 上传流水文件导入
+	 *
+	 * 表单字段：
+	 * - mapping_id: 列映射ID
+	 * - file: 流水文件（.csv/.xlsx，不超过10MB）
+	 *
+	 * 响应：导入批次（含入账、重复、自动入账、待确认、异常笔数）
+	 */
+	admin.POST("/imports", func(c *gin.Context) {
+		mappingID, err := strconv.ParseUint(c.PostForm("mapping_id"), 10, 32)
+		if err != nil || mappingID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请选择列映射"})
+			return
+		}
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请上传流水文件"})
+			return
+		}
+		if fileHeader.Size > maxStatementFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "流水文件不能超过10MB"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败"})
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxStatementFileSize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败"})
+			return
+		}
+
+		imp, err := statementSvc.Import(uint(mappingID), c.GetUint("user_id"), fileHeader.Filename, data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, imp)
+	})
+
+	/**
+	 * GET /bank-statements/imports - 查询导入批次
+	 *
+	 * 查询参数：
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("/imports", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		imports, total, err := statementSvc.GetImports(limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"imports": imports, "total": total})
+	})
+
+	/**
+	 * GET /bank-statements/lines - 查询流水
+	 *
+	 * 查询参数：
+	 * - status: 匹配状态（可选，auto_matched/proposed/exception/confirmed/ignored）
+	 * - import_id: 导入批次ID（可选）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("/lines", func(c *gin.Context) {
+		importID, _ := strconv.ParseUint(c.Query("import_id"), 10, 32)
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		lines, total, err := statementSvc.GetLines(c.Query("status"), uint(importID), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"lines": lines, "total": total})
+	})
+
+	/**
+	 * GET /bank-statements/lines/:id/candidates - 查询候选充值申请（金额一致的待审核申请，按置信度排序）
+	 */
+	admin.GET("/lines/:id/candidates", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的流水ID"})
+			return
+		}
+		candidates, err := statementSvc.GetCandidates(uint(id))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"candidates": candidates})
+	})
+
+	/**
+	 * POST /bank-statements/lines/:id/confirm - 确认匹配
+	 *
+	 * 请求体：
+	 * {
+	 *   "deposit_id": 12,   // 异常流水必填；建议匹配可不传（使用建议的充值申请）
+	 *   "note": "已核对凭证"
+	 * }
+	 */
+	admin.POST("/lines/:id/confirm", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的流水ID"})
+			return
+		}
+		var req struct {
+			DepositID uint   `json:"deposit_id"`
+			Note      string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+
+		line, err := statementSvc.ConfirmLine(uint(id), req.DepositID, c.GetUint("user_id"), req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, line)
+	})
+
+	/**
+	 * POST /bank-statements/lines/:id/reject - 驳回建议匹配（流水转入异常队列）
+	 *
+	 * 请求体：{"note": "付款人不符"}
+	 */
+	admin.POST("/lines/:id/reject", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的流水ID"})
+			return
+		}
+		var req struct {
+			Note string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+
+		line, err := statementSvc.RejectProposal(uint(id), c.GetUint("user_id"), req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, line)
+	})
+
+	/**
+	 * POST /bank-statements/lines/:id/ignore - 忽略异常流水
+	 *
+	 * 请求体：{"note": "银行结息"}（必填）
+	 */
+	admin.POST("/lines/:id/ignore", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的流水ID"})
+			return
+		}
+		var req struct {
+			Note string `json:"note" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请填写忽略原因"})
+			return
+		}
+
+		line, err := statementSvc.IgnoreLine(uint(id), c.GetUint("user_id"), req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, line)
+	})
+}
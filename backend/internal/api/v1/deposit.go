@@ -20,6 +20,7 @@ import (
 
 	"suxin/internal/appctx"
 	"suxin/internal/middleware"
+	"suxin/internal/model"
 	"suxin/internal/service"
 )
 
@@ -42,8 +43,10 @@ type reviewDepositReq struct {
  * 路由列表：
  * - POST /deposits              提交充值申请（需JWT）
  * - GET  /deposits              查询充值记录（需JWT）
+ * - GET  /deposits/reference-code 查询转账附言识别码（需JWT）
  * - GET  /deposits/pending      查询待审核列表（需JWT+管理员）
- * - POST /deposits/:id/review   审核充值（需JWT+管理员）
+ * - POST /deposits/:id/review   审核充值（需JWT+管理员，大额需超级管理员复核）
+ * - GET  /deposits/:id/approvals 查询充值审批记录（需JWT+管理员）
  * 
  * @param rg *gin.RouterGroup - 路由组
  * @param ctx *appctx.AppContext - 应用上下文
@@ -52,6 +55,7 @@ type reviewDepositReq struct {
 func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	depositSvc := service.NewDepositService(ctx)
 	withdrawSvc := service.NewWithdrawService(ctx)
+	fileSvc := service.NewFileService(ctx)
 	
 	/**
 	 * POST /deposits - 提交充值申请
@@ -118,6 +122,7 @@ func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		fileSvc.ResolveDeposits(deposits)
 		
 		c.JSON(http.StatusOK, gin.H{
 			"deposits": deposits,
@@ -125,6 +130,22 @@ func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		})
 	})
 	
+	/**
+	 * GET /deposits/reference-code - 查询转账附言识别码
+	 * 
+	 * 银行转账时在附言中填写识别码，流水导入后可自动匹配入账
+	 * 
+	 * 响应：
+	 * {
+	 *   "reference_code": "SJ000001"
+	 * }
+	 */
+	rg.GET("/deposits/reference-code", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"reference_code": model.DepositReferenceCode(c.GetUint("user_id")),
+		})
+	})
+	
 	/**
 	 * GET /deposits/pending - 查询待审核列表（管理员）
 	 * 
@@ -140,7 +161,7 @@ func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 		status := c.Query("status")
 		
-		var deposits interface{}
+		var deposits []*model.DepositRequest
 		var err error
 		
 		if status != "" {
@@ -155,6 +176,7 @@ func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		fileSvc.ResolveDeposits(deposits)
 		
 		c.JSON(http.StatusOK, gin.H{
 			"deposits": deposits,
@@ -172,8 +194,8 @@ func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	 * 
 	 * 响应：
 	 * {
-	 *   "message": "审核成功",
-	 *   "deposit": {...}
+	 *   "message": "审核成功",          // 初审通过待复核时为 "初审通过，等待超级管理员复核"
+	 *   "status": "approved"            // approved/reviewing/rejected
 	 * }
 	 */
 	admin.POST("/deposits/:id/review", func(c *gin.Context) {
@@ -191,21 +213,47 @@ func RegisterDepositRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		
 		reviewerID := c.GetUint("user_id")
 		
-		if req.Action == "approve" {
-			err = depositSvc.ApproveDeposit(uint(depositID), reviewerID, req.Note, req.ReceiptVoucher)
-		} else {
-			err = depositSvc.RejectDeposit(uint(depositID), reviewerID, req.Note)
+		if req.Action == "reject" {
+			if err := depositSvc.RejectDeposit(uint(depositID), reviewerID, req.Note); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "审核成功", "status": model.DepositStatusRejected})
+			return
 		}
 		
+		deposit, err := depositSvc.ApproveDeposit(uint(depositID), reviewerID, req.Note, req.ReceiptVoucher)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 		
+		message := "审核成功"
+		if deposit.Status == model.DepositStatusReviewing {
+			message = "初审通过，等待超级管理员复核"
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"message": "审核成功",
+			"message": message,
+			"status":  deposit.Status,
 		})
 	})
+	
+	// GET /deposits/:id/approvals - 查询充值审批记录（管理员）
+	admin.GET("/deposits/:id/approvals", func(c *gin.Context) {
+		depositID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的充值ID"})
+			return
+		}
+		
+		steps, err := depositSvc.GetDepositApprovals(uint(depositID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{"approvals": steps})
+	})
 
 	// POST /withdraws/:id/pay - 标记提现已打款并上传打款凭证（管理员）
 	admin.POST("/withdraws/:id/pay", func(c *gin.Context) {
@@ -249,8 +297,10 @@ type reviewWithdrawReq struct {
  * 路由列表：
  * - POST /withdraws              提交提现申请（需JWT）
  * - GET  /withdraws              查询提现记录（需JWT）
+ * - POST /withdraws/:id/cancel   取消待审核的提现申请（需JWT）
  * - GET  /withdraws/pending      查询待审核列表（需JWT+管理员）
- * - POST /withdraws/:id/review   审核提现（需JWT+管理员）
+ * - POST /withdraws/:id/review   审核提现（需JWT+管理员，大额需超级管理员复核）
+ * - GET  /withdraws/:id/approvals 查询提现审批记录（需JWT+管理员）
  * 
  * @param rg *gin.RouterGroup - 路由组
  * @param ctx *appctx.AppContext - 应用上下文
@@ -258,6 +308,7 @@ type reviewWithdrawReq struct {
  */
 func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	withdrawSvc := service.NewWithdrawService(ctx)
+	fileSvc := service.NewFileService(ctx)
 	
 	// POST /withdraws - 提交提现申请
 	rg.POST("/withdraws", func(c *gin.Context) {
@@ -279,6 +330,7 @@ func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			"id":            withdraw.ID,
 			"amount":        withdraw.Amount,
 			"fee":           withdraw.Fee,
+			"fee_detail":    withdraw.FeeDetail,
 			"actual_amount": withdraw.ActualAmount,
 			"status":        withdraw.Status,
 			"created_at":    withdraw.CreatedAt,
@@ -296,6 +348,7 @@ func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		fileSvc.ResolveWithdraws(withdraws)
 		
 		c.JSON(http.StatusOK, gin.H{
 			"withdraws": withdraws,
@@ -303,6 +356,24 @@ func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		})
 	})
 	
+	// POST /withdraws/:id/cancel - 取消待审核的提现申请（退回冻结金额）
+	rg.POST("/withdraws/:id/cancel", func(c *gin.Context) {
+		withdrawID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提现ID"})
+			return
+		}
+		
+		if err := withdrawSvc.CancelWithdraw(uint(withdrawID), c.GetUint("user_id")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"message": "提现申请已取消",
+		})
+	})
+	
 	// 管理员权限路由组
 	admin := rg.Group("", middleware.RequireAdmin(ctx))
 	
@@ -311,7 +382,7 @@ func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 		status := c.Query("status")
 		
-		var withdraws interface{}
+		var withdraws []*model.WithdrawRequest
 		var err error
 		
 		if status != "" {
@@ -324,6 +395,7 @@ func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		fileSvc.ResolveWithdraws(withdraws)
 		
 		c.JSON(http.StatusOK, gin.H{
 			"withdraws": withdraws,
@@ -346,19 +418,45 @@ func RegisterWithdrawRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 		
 		reviewerID := c.GetUint("user_id")
 		
-		if req.Action == "approve" {
-			err = withdrawSvc.ApproveWithdraw(uint(withdrawID), reviewerID, req.Note)
-		} else {
-			err = withdrawSvc.RejectWithdraw(uint(withdrawID), reviewerID, req.Note)
+		if req.Action == "reject" {
+			if err := withdrawSvc.RejectWithdraw(uint(withdrawID), reviewerID, req.Note); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "审核成功", "status": model.WithdrawStatusRejected})
+			return
 		}
 		
+		withdraw, err := withdrawSvc.ApproveWithdraw(uint(withdrawID), reviewerID, req.Note)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 		
+		message := "审核成功"
+		if withdraw.Status == model.WithdrawStatusReviewing {
+			message = "初审通过，等待超级管理员复核"
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"message": "审核成功",
+			"message": message,
+			"status":  withdraw.Status,
 		})
 	})
+	
+	// GET /withdraws/:id/approvals - 查询提现审批记录（管理员）
+	admin.GET("/withdraws/:id/approvals", func(c *gin.Context) {
+		withdrawID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提现ID"})
+			return
+		}
+		
+		steps, err := withdrawSvc.GetWithdrawApprovals(uint(withdrawID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{"approvals": steps})
+	})
 }
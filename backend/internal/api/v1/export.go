@@ -0,0 +1,261 @@
+/**
+ * 数据导出API处理器
+ *
+ * 用途：
+ * - 管理员按筛选条件导出订单、资金流水、付定金申请、退定金申请、销售提成记录（CSV/XLSX）
+ * - 数据量不超过上限时直接流式下载，超过时创建异步导出任务，完成后下载
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+	"suxin/internal/service"
+)
+
+// exportParams 导出参数（直接下载取查询参数，创建任务取请求体）
+type exportParams struct {
+	Dataset       string `form:"-" json:"dataset"`
+	Format        string `form:"format" json:"format"`
+	StartDate     string `form:"start_date" json:"start_date"`
+	EndDate       string `form:"end_date" json:"end_date"`
+	Status        string `form:"status" json:"status"`
+	UserID        uint   `form:"user_id" json:"user_id"`
+	SalespersonID uint   `form:"salesperson_id" json:"salesperson_id"`
+}
+
+// filter 转换为导出筛选条件（日期为本地日期，包含结束日期整天）
+func (p *exportParams) filter() (repository.ExportFilter, error) {
+	filter := repository.ExportFilter{
+		Status:        p.Status,
+		UserID:        p.UserID,
+		SalespersonID: p.SalespersonID,
+	}
+	if p.StartDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", p.StartDate, time.Local)
+		if err != nil {
+			return filter, errors.New("开始日期格式错误，应为 YYYY-MM-DD")
+		}
+		filter.StartDate = &t
+	}
+	if p.EndDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", p.EndDate, time.Local)
+		if err != nil {
+			return filter, errors.New("结束日期格式错误，应为 YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		filter.EndDate = &t
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.StartDate.Before(*filter.EndDate) {
+		return filter, errors.New("开始日期不能晚于结束日期")
+	}
+	return filter, nil
+}
+
+func setExportDownloadHeaders(c *gin.Context, fileName, format string) {
+	c.Header("Content-Type", service.ExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s",
+		fileName, url.PathEscape(fileName)))
+}
+
+/**
+ * RegisterExportRoutes 注册数据导出路由
+ *
+ * 路由列表：
+ * - GET  /admin/exports/:dataset               直接下载导出文件（需JWT+管理员）
+ * - GET  /admin/exports/:dataset/count         统计导出条数（需JWT+管理员）
+ * - GET  /admin/exports/jobs                   查询导出任务（需JWT+管理员）
+ * - POST /admin/exports/jobs                   创建异步导出任务（需JWT+管理员）
+ * - GET  /admin/exports/jobs/:id/download      下载异步导出文件（需JWT+管理员）
+ *
+ * 数据集（dataset）：orders / fund_logs / deposits / withdraws / commissions
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterExportRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	exportSvc := service.NewExportService(ctx)
+	exports := rg.Group("/admin/exports", middleware.RequireAdmin(ctx))
+
+	// bindQuery 解析直接下载/统计的查询参数
+	bindQuery := func(c *gin.Context) (*exportParams, repository.ExportFilter, bool) {
+		var params exportParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, repository.ExportFilter{}, false
+		}
+		params.Dataset = c.Param("dataset")
+		if params.Format == "" {
+			params.Format = model.ExportFormatXLSX
+		}
+		if err := exportSvc.Validate(params.Dataset, params.Format); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, repository.ExportFilter{}, false
+		}
+		filter, err := params.filter()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, repository.ExportFilter{}, false
+		}
+		return &params, filter, true
+	}
+
+	/**
+	 * GET /admin/exports/:dataset - 直接下载导出文件
+	 *
+	 * 查询参数（与列表接口一致，均可选）：
+	 * - format: 文件格式（csv/xlsx，默认xlsx）
+	 * - start_date / end_date: 日期范围（YYYY-MM-DD，包含结束日期整天；提成记录按结算时间）
+	 * - status: 状态（资金流水为流水类型）
+	 * - user_id: 客户ID
+	 * - salesperson_id: 销售ID
+	 *
+	 * 超过直接下载上限时返回 400：
+	 * {
+	 *   "error": "数据量较大，请创建异步导出任务",
+	 *   "total": 25000,
+	 *   "async_required": true
+	 * }
+	 */
+	exports.GET("/:dataset", func(c *gin.Context) {
+		params, filter, ok := bindQuery(c)
+		if !ok {
+			return
+		}
+
+		total, err := exportSvc.CheckSyncExport(params.Dataset, filter)
+		if errors.Is(err, service.ErrExportTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "total": total, "async_required": true})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 开始输出后无法再返回JSON错误，失败只记录日志（客户端收到的文件不完整）
+		setExportDownloadHeaders(c, service.ExportFileName(params.Dataset, params.Format, time.Now()), params.Format)
+		c.Status(http.StatusOK)
+		if _, err := exportSvc.Write(c.Writer, params.Dataset, params.Format, filter); err != nil {
+			log.Printf("[Export] ❌ 导出 %s 失败: %v", params.Dataset, err)
+		}
+	})
+
+	/**
+	 * GET /admin/exports/:dataset/count - 统计导出条数（查询参数同直接下载）
+	 *
+	 * 响应：
+	 * {
+	 *   "total": 25000,
+	 *   "sync_max_rows": 10000   // 超过时需创建异步导出任务
+	 * }
+	 */
+	exports.GET("/:dataset/count", func(c *gin.Context) {
+		params, filter, ok := bindQuery(c)
+		if !ok {
+			return
+		}
+		total, err := exportSvc.Count(params.Dataset, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"total": total, "sync_max_rows": exportSvc.SyncMaxRows()})
+	})
+
+	/**
+	 * GET /admin/exports/jobs - 查询导出任务
+	 *
+	 * 查询参数：
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	exports.GET("/jobs", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		jobs, total, err := exportSvc.GetJobs(limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"jobs": jobs, "total": total})
+	})
+
+	/**
+	 * POST /admin/exports/jobs - 创建异步导出任务
+	 *
+	 * 请求体（筛选条件同直接下载）：
+	 * {
+	 *   "dataset": "fund_logs",
+	 *   "format": "xlsx",
+	 *   "start_date": "2025-01-01",
+	 *   "end_date": "2025-10-31",
+	 *   "status": "",
+	 *   "user_id": 0,
+	 *   "salesperson_id": 0
+	 * }
+	 */
+	exports.POST("/jobs", func(c *gin.Context) {
+		var params exportParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if params.Format == "" {
+			params.Format = model.ExportFormatXLSX
+		}
+		filter, err := params.filter()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		job, err := exportSvc.CreateJob(c.GetUint("user_id"), params.Dataset, params.Format, filter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	})
+
+	/**
+	 * GET /admin/exports/jobs/:id/download - 下载异步导出文件
+	 */
+	exports.GET("/jobs/:id/download", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务ID"})
+			return
+		}
+		job, body, err := exportSvc.OpenJobFile(uint(id))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		defer body.Close()
+
+		setExportDownloadHeaders(c, job.FileName, job.Format)
+		c.Header("Content-Length", strconv.FormatInt(job.FileSize, 10))
+		c.Status(http.StatusOK)
+		io.Copy(c.Writer, body)
+	})
+}
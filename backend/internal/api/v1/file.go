@@ -0,0 +1,154 @@
+/**
+ * 文件API处理器
+ *
+ * 用途：
+ * - 上传凭证、证件照片（返回文件引用和下载链接）
+ * - 按签名限时链接下载文件（不走JWT，图片标签可直接引用）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/pkg/storage"
+	"suxin/internal/service"
+)
+
+// fileUploadCategories 允许客户端指定的文件用途
+var fileUploadCategories = map[string]bool{
+	model.FileCategoryDepositVoucher:  true,
+	model.FileCategoryDepositReceipt:  true,
+	model.FileCategoryWithdrawVoucher: true,
+	model.FileCategoryIDCard:          true,
+	model.FileCategoryAdjustment:      true,
+	model.FileCategoryOther:           true,
+}
+
+/**
+ * RegisterFileDownloadRoutes 注册文件下载路由（公开路由，以链接签名认证）
+ *
+ * 路由列表：
+ * - GET /files/:hash?e=&s=[&thumb=1]   下载文件（thumb=1 下载缩略图）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterFileDownloadRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	fileSvc := service.NewFileService(ctx)
+
+	rg.GET("/files/:hash", func(c *gin.Context) {
+		download, err := fileSvc.Open(c.Param("hash"), c.Query("e"), c.Query("s"), c.Query("thumb") == "1")
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 链接有效期内允许浏览器缓存
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(time.Until(download.ExpireAt).Seconds())))
+
+		if download.RedirectURL != "" {
+			c.Redirect(http.StatusFound, download.RedirectURL)
+			return
+		}
+		defer download.Body.Close()
+
+		c.Header("ETag", `"`+download.File.Hash+`"`)
+		c.Header("X-Content-Type-Options", "nosniff")
+		if download.File.FileName != "" && !strings.HasPrefix(download.ContentType, "image/") {
+			c.Header("Content-Disposition", fmt.Sprintf("inline; filename*=UTF-8''%s", url.PathEscape(download.File.FileName)))
+		}
+		c.Header("Content-Type", download.ContentType)
+		c.Status(http.StatusOK)
+		io.Copy(c.Writer, download.Body)
+	})
+}
+
+/**
+ * RegisterFileRoutes 注册文件上传路由
+ *
+ * 路由列表：
+ * - POST /files   上传文件（需JWT；multipart：file、category）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterFileRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	fileSvc := service.NewFileService(ctx)
+
+	/**
+	 * POST /files - 上传文件
+	 *
+	 * 表单字段：
+	 * - file: 文件（图片或PDF，图片会压缩并生成缩略图）
+	 * - category: 用途（deposit_voucher/deposit_receipt/withdraw_voucher/id_card/other）
+	 *
+	 * 响应：
+	 * {
+	 *   "ref": "file:3a7b...",            // 文件引用，提交业务数据时使用
+	 *   "url": "/api/v1/files/3a7b...?e=...&s=...",
+	 *   "thumb_url": "/api/v1/files/3a7b...?e=...&s=...&thumb=1",
+	 *   "file": {...}
+	 * }
+	 */
+	rg.POST("/files", func(c *gin.Context) {
+		category := c.DefaultPostForm("category", model.FileCategoryOther)
+		if !fileUploadCategories[category] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文件用途"})
+			return
+		}
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请选择文件"})
+			return
+		}
+		if fileHeader.Size > fileSvc.MaxUploadBytes() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("文件不能超过%dMB", fileSvc.MaxUploadBytes()>>20)})
+			return
+		}
+
+		f, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败"})
+			return
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, fileSvc.MaxUploadBytes()+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败"})
+			return
+		}
+
+		stored, err := fileSvc.Upload(c.GetUint("user_id"), category, fileHeader.Filename, data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		link := fileSvc.SignedURL(stored.Hash)
+		c.JSON(http.StatusOK, gin.H{
+			"ref":       model.FileRef(stored.Hash),
+			"url":       link,
+			"thumb_url": link + "&thumb=1",
+			"file":      stored,
+		})
+	})
+}
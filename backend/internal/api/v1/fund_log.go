@@ -13,6 +13,7 @@
 package v1
 
 import (
+	"math"
 	"net/http"
 	"strconv"
 	"time"
@@ -39,7 +40,7 @@ type FundLogResponse struct {
 	Note            string    `json:"Note"`
 	CreatedAt       time.Time `json:"CreatedAt"`
 
-	// 额外补充的字段：用于前端“补定金”Tab 的料单筛选与详情
+	// 额外补充的字段：订单相关流水关联的料单（用于前端料单筛选与详情）
 	OrderID   string `json:"OrderID,omitempty"`
 	OrderType string `json:"OrderType,omitempty"`
 }
@@ -127,8 +128,8 @@ func RegisterFundLogRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 				CreatedAt:       log.CreatedAt,
 			}
 			
-			// 对补定金流水增加 OrderID / OrderType
-			if log.Type == "supplement" && log.RelatedType == "order" && log.RelatedID > 0 {
+			// 订单相关流水（冻结、结算、强平、部分平仓、补定金等）增加 OrderID / OrderType
+			if log.RelatedType == "order" && log.RelatedID > 0 {
 				// 复用缓存，避免重复查库
 				order, ok := orderCache[log.RelatedID]
 				if !ok {
@@ -159,31 +160,83 @@ func RegisterFundLogRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	 * 
 	 * 查询参数：
 	 * - start_date: 开始日期（可选）
-	 * - end_date: 结束日期（可选）
+	 * - end_date: 结束日期（可选，包含当天）
 	 * 
-	 * 响应：
+	 * 响应（金额为流水金额合计，冻结/补定金为负数；各项合计等于 net_change 即可用定金变动）：
+	 * - total_withdraw/total_withdraw_fee: 已扣款的提现金额与提现手续费（含从提现冻结中扣除的部分）
+	 * - total_withdraw_frozen: 提现冻结净额（冻结 - 解冻 - 已从冻结中扣款），即尚在审核中的提现
 	 * {
 	 *   "total_deposit": 50000.00,
-	 *   "total_withdraw": 10000.00,
+	 *   "total_withdraw": -10000.00,
+	 *   "total_withdraw_fee": -20.00,
+	 *   "total_withdraw_frozen": -2000.00,
+	 *   "total_order_freeze": -8000.00,
+	 *   "total_order_release": 0,
 	 *   "total_settle": 5000.00,
-	 *   "net_change": 45000.00
+	 *   "total_force_close": 1200.00,
+	 *   "total_partial_close": 300.00,
+	 *   "total_supplement": -500.00,
+	 *   "total_adjustment": 100.00,
+	 *   "net_change": 36080.00
 	 * }
 	 */
 	rg.GET("/fund-logs/summary", func(c *gin.Context) {
 		userID := c.GetUint("user_id")
 		
+		var startDate, endDate *time.Time
+		if t, err := time.ParseInLocation("2006-01-02", c.Query("start_date"), time.Local); err == nil {
+			startDate = &t
+		}
+		if t, err := time.ParseInLocation("2006-01-02", c.Query("end_date"), time.Local); err == nil {
+			t = t.Add(24 * time.Hour) // 包含结束日期整天
+			endDate = &t
+		}
+		
 		// 统计各类型总额
-		depositSum, _ := fundLogRepo.GetSumByType(userID, "deposit")
-		withdrawSum, _ := fundLogRepo.GetSumByType(userID, "withdraw")
-		settleSum, _ := fundLogRepo.GetSumByType(userID, "settle")
+		sums, err := fundLogRepo.GetSumsGroupByType(userID, startDate, endDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		
+		netChange, err := fundLogRepo.GetAvailableChange(userID, startDate, endDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		netChange = math.Round(netChange*100) / 100
 		
-		netChange := depositSum + withdrawSum + settleSum
+		totals := gin.H{
+			"total_deposit":       roundSum(sums, model.FundLogTypeDeposit),
+			"total_withdraw":      roundSum(sums, model.FundLogTypeWithdraw),
+			"total_withdraw_fee":  roundSum(sums, model.FundLogTypeWithdrawFee),
+			"total_order_freeze":  roundSum(sums, model.FundLogTypeOrderFreeze),
+			"total_order_release": roundSum(sums, model.FundLogTypeOrderRelease),
+			"total_settle":        roundSum(sums, model.FundLogTypeSettle),
+			"total_force_close":   roundSum(sums, model.FundLogTypeForceClose),
+			"total_partial_close": roundSum(sums, model.FundLogTypePartialClose),
+			"total_supplement":    roundSum(sums, model.FundLogTypeSupplement),
+			"total_adjustment":    roundSum(sums, model.FundLogTypeAdjustment),
+		}
+		// 提现冻结/解冻改变可用定金，从冻结中扣款不改变可用定金，其净额取可用定金变动与其余各项之差
+		listed := 0.0
+		for _, total := range totals {
+			listed += total.(float64)
+		}
+		totals["total_withdraw_frozen"] = math.Round((netChange-listed)*100) / 100
+		totals["net_change"] = netChange
 		
-		c.JSON(http.StatusOK, gin.H{
-			"total_deposit":  depositSum,
-			"total_withdraw": withdrawSum,
-			"total_settle":   settleSum,
-			"net_change":     netChange,
-		})
+		c.JSON(http.StatusOK, totals)
 	})
 }
+
+/**
+ * roundSum 合计多个流水类型的金额（保留两位小数）
+ */
+func roundSum(sums map[string]float64, types ...string) float64 {
+	var total float64
+	for _, t := range types {
+		total += sums[t]
+	}
+	return math.Round(total*100) / 100
+}
@@ -0,0 +1,117 @@
+/**
+ * 总账API处理器
+ *
+ * 用途：
+ * - 管理员查看总账科目余额、记账凭证
+ * - 核对总账（试算平衡、科目余额、客户余额与总账一致性）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterLedgerRoutes 注册总账路由
+ *
+ * 路由列表：
+ * - GET /ledger/accounts  查询科目余额（需JWT+管理员）
+ * - GET /ledger/entries   查询记账凭证（需JWT+管理员）
+ * - GET /ledger/check     核对总账（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterLedgerRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	ledgerSvc := service.NewLedgerService(ctx)
+	admin := rg.Group("/ledger", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /ledger/accounts - 查询科目余额
+	 *
+	 * 查询参数：
+	 * - user_id: 客户ID（可选，不传返回平台科目）
+	 */
+	admin.GET("/accounts", func(c *gin.Context) {
+		userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+
+		accounts, err := ledgerSvc.GetUserAccounts(uint(userID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"accounts": accounts})
+	})
+
+	/**
+	 * GET /ledger/entries - 查询记账凭证（含分录）
+	 *
+	 * 查询参数：
+	 * - user_id: 客户ID（可选）
+	 * - type: 凭证类型（可选，与资金流水类型一致，或 opening）
+	 * - limit: 每页数量（默认50）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("/entries", func(c *gin.Context) {
+		userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		entries, total, err := ledgerSvc.GetEntries(uint(userID), c.Query("type"), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"entries": entries,
+			"total":   total,
+		})
+	})
+
+	/**
+	 * GET /ledger/check - 核对总账
+	 *
+	 * 响应：
+	 * {
+	 *   "ok": true,
+	 *   "total_debit": 100000, "total_credit": 100000, "balanced": true,
+	 *   "account_mismatches": [...],
+	 *   "user_mismatches": [...],
+	 *   "checked_users": 10, "unopened_users": 2,
+	 *   "house_accounts": [...]
+	 * }
+	 */
+	admin.GET("/check", func(c *gin.Context) {
+		result, err := ledgerSvc.Check()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"ok":                 result.OK(),
+			"total_debit":        result.TotalDebit,
+			"total_credit":       result.TotalCredit,
+			"balanced":           result.Balanced,
+			"account_mismatches": result.AccountMismatches,
+			"user_mismatches":    result.UserMismatches,
+			"checked_users":      result.CheckedUsers,
+			"unopened_users":     result.UnopenedUsers,
+			"house_accounts":     result.HouseAccounts,
+		})
+	})
+}
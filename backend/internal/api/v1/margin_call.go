@@ -0,0 +1,177 @@
+/**
+ * 追保API处理器
+ *
+ * 用途：
+ * - 客户查看自己订单的追保记录
+ * - 管理员查看追保列表（进行中/已解除/已逾期）及当前追保阈值
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/service"
+)
+
+/**
+ * marginCallResponse 追保记录响应（附带客户和订单摘要）
+ */
+func marginCallResponse(call *model.MarginCall) gin.H {
+	resp := gin.H{
+		"id":              call.ID,
+		"user_id":         call.UserID,
+		"order_id":        call.OrderID,
+		"order_no":        call.OrderNo,
+		"sales_id":        call.SalesID,
+		"status":          call.Status,
+		"call_rate":       call.CallRate,
+		"trigger_rate":    call.TriggerRate,
+		"trigger_price":   call.TriggerPrice,
+		"required_amount": call.RequiredAmount,
+		"deadline":        call.Deadline,
+		"resolved_at":     call.ResolvedAt,
+		"resolved_rate":   call.ResolvedRate,
+		"supplement_id":   call.SupplementID,
+		"resolve_note":    call.ResolveNote,
+		"created_at":      call.CreatedAt,
+	}
+	if call.User != nil {
+		resp["user"] = gin.H{
+			"id":       call.User.ID,
+			"phone":    call.User.Phone,
+			"realname": call.User.RealName,
+		}
+	}
+	if call.Order != nil {
+		resp["order"] = gin.H{
+			"type":          call.Order.Type,
+			"status":        call.Order.Status,
+			"weight_g":      call.Order.WeightG,
+			"deposit":       call.Order.Deposit,
+			"locked_price":  call.Order.LockedPrice,
+			"current_price": call.Order.CurrentPrice,
+			"margin_rate":   call.Order.MarginRate,
+		}
+	}
+	return resp
+}
+
+/**
+ * RegisterMarginCallRoutes 注册追保路由
+ *
+ * 路由列表：
+ * - GET /margin-calls/my   查询我的追保记录（需JWT）
+ * - GET /margin-calls      查询追保列表（需JWT+管理员）
+ * - GET /margin-calls/:id  查询追保详情（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterMarginCallRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	marginCallSvc := service.NewMarginCallService(ctx)
+
+	/**
+	 * GET /margin-calls/my - 查询我的追保记录
+	 *
+	 * 查询参数：
+	 * - status: open/met/expired（可选）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 *
+	 * 响应：
+	 * {
+	 *   "margin_calls": [...]
+	 * }
+	 */
+	rg.GET("/margin-calls/my", func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+		status := c.Query("status")
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		calls, err := marginCallSvc.GetUserMarginCalls(userID, status, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		list := make([]gin.H, 0, len(calls))
+		for _, call := range calls {
+			list = append(list, marginCallResponse(call))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"margin_calls": list,
+		})
+	})
+
+	admin := rg.Group("", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /margin-calls - 查询追保列表（管理员）
+	 *
+	 * 查询参数：
+	 * - status: open/met/expired（可选，默认全部）
+	 * - limit: 每页数量（默认50）
+	 * - offset: 偏移量（默认0）
+	 *
+	 * 响应：
+	 * {
+	 *   "margin_calls": [...],
+	 *   "total": 10,
+	 *   "policy": {"call_rate": 20, "floor_rate": 10, "grace_minutes": 120}
+	 * }
+	 */
+	admin.GET("/margin-calls", func(c *gin.Context) {
+		status := c.Query("status")
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		calls, total, err := marginCallSvc.GetMarginCalls(status, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		list := make([]gin.H, 0, len(calls))
+		for _, call := range calls {
+			list = append(list, marginCallResponse(call))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"margin_calls": list,
+			"total":        total,
+			"policy":       marginCallSvc.GetPolicy(),
+		})
+	})
+
+	/**
+	 * GET /margin-calls/:id - 查询追保详情（管理员）
+	 */
+	admin.GET("/margin-calls/:id", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的追保ID"})
+			return
+		}
+
+		call, err := marginCallSvc.GetMarginCall(uint(id))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, marginCallResponse(call))
+	})
+}
@@ -0,0 +1,90 @@
+/**
+ * 平台行情状态API处理器
+ *
+ * 用途：
+ * - 查询平台是否处于行情降级模式（前端横幅）
+ * - 管理员查看历史降级记录
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterMarketRoutes 注册平台行情状态路由
+ *
+ * 路由列表：
+ * - GET /market/status    查询平台行情状态（需JWT）
+ * - GET /market/outages   查询历史降级记录（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterMarketRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	/**
+	 * GET /market/status - 查询平台行情状态
+	 *
+	 * 响应：
+	 * {
+	 *   "type": "market_status",
+	 *   "status": "degraded",
+	 *   "degraded": true,
+	 *   "message": "行情数据中断，系统已进入保护模式...",
+	 *   "since": "2025-11-18T01:00:00Z",
+	 *   "last_quote_at": "2025-11-18T00:59:20Z",
+	 *   "quote_age_seconds": 45,
+	 *   "threshold_seconds": 30
+	 * }
+	 */
+	rg.GET("/market/status", func(c *gin.Context) {
+		failsafe := service.DefaultQuoteFailsafe()
+		if failsafe == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "行情保护服务未启动"})
+			return
+		}
+
+		c.JSON(http.StatusOK, failsafe.GetStatus())
+	})
+
+	/**
+	 * GET /market/outages - 查询历史降级记录（管理员）
+	 *
+	 * 查询参数：
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	rg.GET("/market/outages", middleware.RequireAdmin(ctx), func(c *gin.Context) {
+		failsafe := service.DefaultQuoteFailsafe()
+		if failsafe == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "行情保护服务未启动"})
+			return
+		}
+
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		outages, total, err := failsafe.GetOutages(limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"outages": outages,
+			"total":   total,
+		})
+	})
+}
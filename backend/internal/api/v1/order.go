@@ -197,7 +197,7 @@ func RegisterOrderRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	 * {
 	 *   "order_id": "202511180001",
 	 *   "settled_price": 510.00,
-	 *   "settled_pnl": 1000.00,
+	 *   "settled_pnl": 1000.00,   // 订单总盈亏（含部分平仓已实现盈亏）
 	 *   "status": "settled",
 	 *   "settled_at": "2025-11-18T01:00:00Z"
 	 * }
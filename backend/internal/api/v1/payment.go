@@ -0,0 +1,303 @@
+/**
+ * 在线支付API处理器
+ *
+ * 用途：
+ * - 客户查询支付通道、发起在线充值、查询支付单
+ * - 接收支付通道异步回调（不走JWT，以通道签名认证）
+ * - 管理员查询支付单、手动查单补单、核查金额异常的支付单
+ * - 模拟通道的模拟支付（本地联调/测试）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterPaymentCallbackRoutes 注册支付通道回调路由（公开路由）
+ *
+ * 路由列表：
+ * - POST /payments/callback/:provider   支付通道异步回调（表单或JSON，以签名认证）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterPaymentCallbackRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	paymentSvc := service.NewPaymentService(ctx)
+
+	/**
+	 * POST /payments/callback/:provider - 支付通道异步回调
+	 *
+	 * 处理成功返回通道约定的应答（如 success），失败返回 400 fail，通道会重试推送
+	 */
+	rg.POST("/payments/callback/:provider", func(c *gin.Context) {
+		params, err := callbackParams(c)
+		if err != nil {
+			c.String(http.StatusBadRequest, "fail")
+			return
+		}
+
+		name := c.Param("provider")
+		if err := paymentSvc.HandleCallback(name, params); err != nil {
+			c.String(http.StatusBadRequest, "fail")
+			return
+		}
+		c.String(http.StatusOK, paymentSvc.AckBody(name))
+	})
+}
+
+/**
+ * RegisterPaymentRoutes 注册在线支付路由
+ *
+ * 路由列表：
+ * - GET  /payments/providers                      查询可用支付通道（需JWT）
+ * - POST /payments                                发起在线充值（需JWT）
+ * - GET  /payments                                查询我的支付单（需JWT）
+ * - GET  /payments/:order_no                      查询我的支付单详情（需JWT）
+ * - POST /payments/:order_no/query                我已支付，主动查单（需JWT）
+ * - POST /payments/:order_no/mock-pay             模拟支付，仅模拟通道（需JWT）
+ * - GET  /payments/admin/orders                   查询支付单（需JWT+管理员）
+ * - POST /payments/admin/orders/:order_no/query   手动查单补单（需JWT+管理员）
+ * - POST /payments/admin/orders/:order_no/resolve 核查金额异常的支付单（需JWT+管理员）
+ * - POST /payments/admin/orders/:order_no/mock-pay 模拟支付，可指定金额或不推送回调（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterPaymentRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	paymentSvc := service.NewPaymentService(ctx)
+	payments := rg.Group("/payments")
+	admin := payments.Group("/admin", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /payments/providers - 查询可用支付通道
+	 */
+	payments.GET("/providers", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"providers": paymentSvc.ListProviders()})
+	})
+
+	/**
+	 * POST /payments - 发起在线充值
+	 *
+	 * 请求体：
+	 * {
+	 *   "provider": "mock",
+	 *   "amount": 10000.00
+	 * }
+	 */
+	payments.POST("", func(c *gin.Context) {
+		var req struct {
+			Provider string  `json:"provider" binding:"required"`
+			Amount   float64 `json:"amount" binding:"required,gt=0"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+
+		order, err := paymentSvc.CreateOrder(c.GetUint("user_id"), req.Provider, req.Amount)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+
+	/**
+	 * GET /payments - 查询我的支付单
+	 *
+	 * 查询参数：
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	payments.GET("", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		orders, total, err := paymentSvc.GetUserOrders(c.GetUint("user_id"), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"orders": orders, "total": total})
+	})
+
+	/**
+	 * GET /payments/:order_no - 查询我的支付单详情
+	 */
+	payments.GET("/:order_no", func(c *gin.Context) {
+		order, err := paymentSvc.GetUserOrder(c.Param("order_no"), c.GetUint("user_id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+
+	/**
+	 * POST /payments/:order_no/query - 我已支付，主动向通道查单
+	 */
+	payments.POST("/:order_no/query", func(c *gin.Context) {
+		if _, err := paymentSvc.GetUserOrder(c.Param("order_no"), c.GetUint("user_id")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		order, err := paymentSvc.QueryOrder(c.Param("order_no"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+
+	/**
+	 * POST /payments/:order_no/mock-pay - 模拟支付（按下单金额支付并推送回调）
+	 */
+	payments.POST("/:order_no/mock-pay", func(c *gin.Context) {
+		order, err := paymentSvc.MockPay(c.Param("order_no"), c.GetUint("user_id"), false, 0, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+
+	/**
+	 * GET /payments/admin/orders - 管理员查询支付单
+	 *
+	 * 查询参数：
+	 * - status: 状态（可选，pending/paid/failed/expired/abnormal/closed）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("/orders", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		orders, total, err := paymentSvc.GetOrders(c.Query("status"), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"orders": orders, "total": total})
+	})
+
+	/**
+	 * POST /payments/admin/orders/:order_no/query - 手动查单补单
+	 */
+	admin.POST("/orders/:order_no/query", func(c *gin.Context) {
+		order, err := paymentSvc.QueryOrder(c.Param("order_no"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+
+	/**
+	 * POST /payments/admin/orders/:order_no/resolve - 核查金额异常的支付单
+	 *
+	 * 请求体：
+	 * {
+	 *   "action": "credit",              // credit 按实付金额入账 / close 不入账关闭
+	 *   "note": "已与客户核实，按实付入账"  // 必填，核查说明
+	 * }
+	 */
+	admin.POST("/orders/:order_no/resolve", func(c *gin.Context) {
+		var req struct {
+			Action string `json:"action" binding:"required,oneof=credit close"`
+			Note   string `json:"note" binding:"required,max=200"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误：处理方式和核查说明为必填"})
+			return
+		}
+
+		order, err := paymentSvc.ResolveAbnormal(c.Param("order_no"), c.GetUint("user_id"), req.Action, req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+
+	/**
+	 * POST /payments/admin/orders/:order_no/mock-pay - 管理员模拟支付
+	 *
+	 * 请求体：
+	 * {
+	 *   "amount": 0,       // 可选，实付金额（不传按下单金额，传入不同金额可模拟金额不符）
+	 *   "notify": false    // 可选，是否推送回调（默认true，false 模拟回调丢失）
+	 * }
+	 */
+	admin.POST("/orders/:order_no/mock-pay", func(c *gin.Context) {
+		req := struct {
+			Amount float64 `json:"amount"`
+			Notify *bool   `json:"notify"`
+		}{}
+		if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+		notify := req.Notify == nil || *req.Notify
+
+		order, err := paymentSvc.MockPay(c.Param("order_no"), c.GetUint("user_id"), true, req.Amount, notify)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+}
+
+/**
+ * callbackParams 读取回调参数（支持表单和扁平JSON，JSON中的数字按原文转为字符串以保证验签一致）
+ */
+func callbackParams(c *gin.Context) (map[string]string, error) {
+	params := make(map[string]string)
+
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(strings.NewReader(string(body)))
+		decoder.UseNumber()
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		for k, v := range raw {
+			if v != nil {
+				params[k] = fmt.Sprint(v)
+			}
+		}
+		return params, nil
+	}
+
+	if err := c.Request.ParseForm(); err != nil {
+		return nil, err
+	}
+	for k := range c.Request.PostForm {
+		params[k] = c.Request.PostForm.Get(k)
+	}
+	return params, nil
+}
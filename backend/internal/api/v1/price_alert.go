@@ -0,0 +1,172 @@
+/**
+ * 价格提醒API处理器
+ *
+ * 用途：
+ * - 客户设置价格提醒（上涨到/下跌到目标价、当日涨跌幅）
+ * - 提醒触发后通过站内通知和通知WebSocket推送（type=price_alert）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/service"
+)
+
+/**
+ * parsePriceAlertID 解析路径中的提醒ID
+ */
+func parsePriceAlertID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提醒ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+/**
+ * RegisterPriceAlertRoutes 注册价格提醒路由
+ *
+ * 路由列表：
+ * - GET    /price-alerts              查询我的价格提醒
+ * - POST   /price-alerts              创建价格提醒
+ * - PUT    /price-alerts/:id          修改价格提醒
+ * - POST   /price-alerts/:id/enable   启用价格提醒
+ * - POST   /price-alerts/:id/disable  停用价格提醒
+ * - DELETE /price-alerts/:id          删除价格提醒
+ *
+ * @param rg *gin.RouterGroup - 路由组（需JWT）
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterPriceAlertRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	alertSvc := service.NewPriceAlertService(ctx)
+
+	/**
+	 * GET /price-alerts - 查询我的价格提醒
+	 *
+	 * 响应：
+	 * {
+	 *   "alerts": [...],
+	 *   "limit": 20   // 最多可启用数量
+	 * }
+	 */
+	rg.GET("/price-alerts", func(c *gin.Context) {
+		alerts, err := alertSvc.List(c.GetUint("user_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"alerts": alerts,
+			"limit":  alertSvc.GetLimit(),
+		})
+	})
+
+	/**
+	 * POST /price-alerts - 创建价格提醒
+	 *
+	 * 请求体：
+	 * {
+	 *   "symbol": "AU9999",          // 可选，默认AU9999
+	 *   "type": "above",             // above/below/percent_move
+	 *   "target_price": 620.5,       // above/below 必填
+	 *   "percent": 1.5,              // percent_move 必填（%）
+	 *   "recurring": false,          // 是否重复提醒
+	 *   "note": "备注"
+	 * }
+	 */
+	rg.POST("/price-alerts", func(c *gin.Context) {
+		var req service.PriceAlertInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+			return
+		}
+
+		alert, err := alertSvc.Create(c.GetUint("user_id"), req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, alert)
+	})
+
+	// PUT /price-alerts/:id - 修改价格提醒
+	rg.PUT("/price-alerts/:id", func(c *gin.Context) {
+		id, ok := parsePriceAlertID(c)
+		if !ok {
+			return
+		}
+		var req service.PriceAlertInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+			return
+		}
+
+		alert, err := alertSvc.Update(c.GetUint("user_id"), id, req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, alert)
+	})
+
+	// POST /price-alerts/:id/enable - 启用价格提醒
+	rg.POST("/price-alerts/:id/enable", func(c *gin.Context) {
+		id, ok := parsePriceAlertID(c)
+		if !ok {
+			return
+		}
+
+		alert, err := alertSvc.SetEnabled(c.GetUint("user_id"), id, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, alert)
+	})
+
+	// POST /price-alerts/:id/disable - 停用价格提醒
+	rg.POST("/price-alerts/:id/disable", func(c *gin.Context) {
+		id, ok := parsePriceAlertID(c)
+		if !ok {
+			return
+		}
+
+		alert, err := alertSvc.SetEnabled(c.GetUint("user_id"), id, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, alert)
+	})
+
+	// DELETE /price-alerts/:id - 删除价格提醒
+	rg.DELETE("/price-alerts/:id", func(c *gin.Context) {
+		id, ok := parsePriceAlertID(c)
+		if !ok {
+			return
+		}
+
+		if err := alertSvc.Delete(c.GetUint("user_id"), id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "已删除"})
+	})
+}
@@ -0,0 +1,348 @@
+/**
+ * 行情历史API处理器
+ *
+ * 用途：
+ * - 查询历史K线（前端走势图，刷新页面后不丢失历史）
+ * - 管理员查看、复核异常Tick隔离日志
+ * - 最新报价（REST）和报价推送（SSE），供后端对接和简单看板使用
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/service"
+)
+
+/**
+ * parseQuoteTime 解析时间参数
+ *
+ * 支持 RFC3339、"2006-01-02 15:04:05"、"2006-01-02" 和秒级时间戳，空值返回零值
+ */
+func parseQuoteTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+/**
+ * RegisterQuoteRoutes 注册行情历史路由
+ *
+ * 路由列表：
+ * - GET  /quotes/candles                  查询历史K线（需JWT）
+ * - GET  /quotes/sources                  查询上游行情源状态（需JWT+管理员）
+ * - GET  /quotes/quarantine               查询异常Tick隔离记录（需JWT+管理员）
+ * - POST /quotes/quarantine/:id/review    复核隔离记录（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterQuoteRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	/**
+	 * GET /quotes/candles - 查询历史K线
+	 *
+	 * 查询参数：
+	 * - symbol: 品种代码（如 AU9999，必填）
+	 * - interval: 周期 1m/5m/15m/1h/1d（默认1m）
+	 * - from: 开始时间（可选，RFC3339/日期/秒级时间戳）
+	 * - to: 结束时间（可选，默认当前）
+	 * - limit: 最多返回数量（默认500，最大2000，取最近的K线）
+	 *
+	 * 响应：
+	 * {
+	 *   "symbol": "AU9999",
+	 *   "interval": "1m",
+	 *   "candles": [{"time": "...", "open": 500.1, "high": 500.5, "low": 499.8, "close": 500.2, "ticks": 36}]
+	 * }
+	 */
+	rg.GET("/quotes/candles", func(c *gin.Context) {
+		history := service.DefaultQuoteHistory()
+		if history == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "行情历史服务未启动"})
+			return
+		}
+
+		symbol := strings.ToUpper(c.Query("symbol"))
+		interval := c.DefaultQuery("interval", "1m")
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "500"))
+
+		from, err := parseQuoteTime(c.Query("from"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "开始时间格式错误"})
+			return
+		}
+		to, err := parseQuoteTime(c.Query("to"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "结束时间格式错误"})
+			return
+		}
+
+		candles, err := history.GetCandles(symbol, interval, from, to, limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"symbol":   symbol,
+			"interval": interval,
+			"candles":  candles,
+		})
+	})
+
+	/**
+	 * GET /quotes/sources - 查询上游行情源状态（管理员）
+	 *
+	 * 响应：
+	 * {
+	 *   "active": "jtd-primary",
+	 *   "sources": [{"name": "jtd-primary", "state": "connected", "score": 98, "reconnects": 2,
+	 *                "message_count": 36012, "messages_per_second": 1.2, "last_message_at": "...", ...}]
+	 * }
+	 */
+	rg.GET("/quotes/sources", middleware.RequireAdmin(ctx), func(c *gin.Context) {
+		quoteSvc := service.DefaultQuoteService()
+		if quoteSvc == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "行情服务未启动"})
+			return
+		}
+
+		sources := quoteSvc.GetSourceStatuses()
+		active := ""
+		for _, source := range sources {
+			if source.Active {
+				active = source.Name
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"active":  active,
+			"sources": sources,
+		})
+	})
+
+	/**
+	 * GET /quotes/quarantine - 查询异常Tick隔离记录（管理员）
+	 *
+	 * 查询参数：
+	 * - symbol: 品种代码（可选）
+	 * - source: 行情源名称（可选）
+	 * - reason: 隔离原因 crossed/too_frequent/deviation/source_disagree（可选）
+	 * - reviewed: 是否已复核 true/false（可选）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	rg.GET("/quotes/quarantine", middleware.RequireAdmin(ctx), func(c *gin.Context) {
+		quarantine := service.DefaultQuoteQuarantine()
+		if quarantine == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "隔离日志服务未启动"})
+			return
+		}
+
+		var reviewed *bool
+		if v := c.Query("reviewed"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "reviewed 参数错误"})
+				return
+			}
+			reviewed = &b
+		}
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		items, total, err := quarantine.GetList(strings.ToUpper(c.Query("symbol")), c.Query("source"), c.Query("reason"), reviewed, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"items": items,
+			"total": total,
+		})
+	})
+
+	/**
+	 * POST /quotes/quarantine/:id/review - 复核隔离记录（管理员）
+	 *
+	 * 请求体：
+	 * {
+	 *   "note": "上游源数据错误，已联系供应商"
+	 * }
+	 */
+	rg.POST("/quotes/quarantine/:id/review", middleware.RequireAdmin(ctx), func(c *gin.Context) {
+		quarantine := service.DefaultQuoteQuarantine()
+		if quarantine == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "隔离日志服务未启动"})
+			return
+		}
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+			return
+		}
+
+		var req struct {
+			Note string `json:"note" binding:"max=255"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误"})
+			return
+		}
+
+		item, err := quarantine.Review(uint(id), c.GetUint("user_id"), req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "复核成功",
+			"item":    item,
+		})
+	})
+}
+
+/**
+ * parseSymbols 解析逗号分隔的品种参数（转大写，空值返回nil）
+ */
+func parseSymbols(value string) []string {
+	var symbols []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	return symbols
+}
+
+/**
+ * RegisterQuoteFeedRoutes 注册最新报价和SSE推送路由
+ *
+ * 路由列表：
+ * - GET /quotes/latest  各品种最新报价
+ * - GET /quotes/stream  报价推送（Server-Sent Events）
+ *
+ * 说明：
+ * - 配置 quote.public_feed 为 true 时免登录，否则需要JWT（EventSource无法设置请求头，可用 ?token= 传递）
+ *
+ * @param rg *gin.RouterGroup - 路由组（公开路由组）
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterQuoteFeedRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	group := rg
+	if !ctx.Config.Quote.PublicFeed {
+		group = rg.Group("", middleware.AuthRequired(ctx))
+	}
+
+	/**
+	 * GET /quotes/latest - 各品种最新报价
+	 *
+	 * 查询参数：
+	 * - symbols: 品种代码，逗号分隔（可选，默认全部）
+	 *
+	 * 响应（同 QuoteService.GetPriceInfo）：
+	 * {
+	 *   "price": 500.1,            // 风控使用的Au9999价格
+	 *   "last_update": "2025-11-18 10:00:00",
+	 *   "age_seconds": 1,
+	 *   "source": "jtd-primary",
+	 *   "valid": true,
+	 *   "quotes": [{"symbol": "AU", "bid": 499.6, "ask": 500.3, "last": 500.1, "source": "jtd-primary",
+	 *               "ts": 1732000000000, "updated_at": "2025-11-18 10:00:00", "age_seconds": 1, "stale": false}]
+	 * }
+	 */
+	group.GET("/quotes/latest", func(c *gin.Context) {
+		quoteSvc := service.DefaultQuoteService()
+		if quoteSvc == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "行情服务未启动"})
+			return
+		}
+
+		info := quoteSvc.GetPriceInfo()
+		if symbols := parseSymbols(c.Query("symbols")); len(symbols) > 0 {
+			info["quotes"] = quoteSvc.GetLatestQuotes(symbols)
+		}
+
+		c.JSON(http.StatusOK, info)
+	})
+
+	/**
+	 * GET /quotes/stream - 报价推送（Server-Sent Events）
+	 *
+	 * 查询参数：
+	 * - symbols: 品种代码，逗号分隔（可选，默认全部）
+	 *
+	 * 每条事件的 data 与 WebSocket 推送消息一致（type 为 quote / market_status），
+	 * 每15秒发送一次注释行保活
+	 */
+	group.GET("/quotes/stream", func(c *gin.Context) {
+		quoteSvc := service.DefaultQuoteService()
+		if quoteSvc == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "行情服务未启动"})
+			return
+		}
+
+		symbols := parseSymbols(c.Query("symbols"))
+		if len(symbols) == 0 {
+			symbols = []string{"*"}
+		}
+		messages, cancel, err := quoteSvc.Subscribe(symbols)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		defer cancel()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // 关闭nginx缓冲
+
+		keepalive := time.NewTicker(15 * time.Second)
+		defer keepalive.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return false
+				}
+				_, err := fmt.Fprintf(w, "data: %s\n\n", message)
+				return err == nil
+			case <-keepalive.C:
+				_, err := io.WriteString(w, ": keepalive\n\n")
+				return err == nil
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	})
+}
@@ -0,0 +1,170 @@
+/**
+ * 资金对账API处理器
+ *
+ * 用途：
+ * - 管理员查看对账任务、对账报告（不一致记录）
+ * - 管理员手动对单个客户（或全部客户）发起对账
+ * - 管理员将不一致记录标记为已处理
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterReconciliationRoutes 注册资金对账路由
+ *
+ * 路由列表：
+ * - GET  /reconciliation/runs                     查询对账任务（需JWT+管理员）
+ * - GET  /reconciliation/mismatches               查询对账报告（需JWT+管理员）
+ * - POST /reconciliation/run                      手动发起对账（需JWT+管理员）
+ * - POST /reconciliation/mismatches/:id/resolve   标记不一致为已处理（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterReconciliationRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	reconcileSvc := service.NewReconciliationService(ctx)
+	admin := rg.Group("/reconciliation", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /reconciliation/runs - 查询对账任务
+	 *
+	 * 查询参数：
+	 * - user_id: 客户ID（可选）
+	 * - limit: 每页数量（默认20）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("/runs", func(c *gin.Context) {
+		userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		runs, total, err := reconcileSvc.GetRuns(uint(userID), limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"runs":          runs,
+			"total":         total,
+			"schedule_hour": reconcileSvc.GetScheduleHour(),
+		})
+	})
+
+	/**
+	 * GET /reconciliation/mismatches - 查询对账报告
+	 *
+	 * 查询参数：
+	 * - run_id: 对账任务ID（可选）
+	 * - user_id: 客户ID（可选）
+	 * - resolved: 是否已处理（可选，true/false）
+	 * - limit: 每页数量（默认50）
+	 * - offset: 偏移量（默认0）
+	 */
+	admin.GET("/mismatches", func(c *gin.Context) {
+		runID, _ := strconv.ParseUint(c.DefaultQuery("run_id", "0"), 10, 32)
+		userID, _ := strconv.ParseUint(c.DefaultQuery("user_id", "0"), 10, 32)
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		var resolved *bool
+		if v, err := strconv.ParseBool(c.Query("resolved")); err == nil {
+			resolved = &v
+		}
+
+		mismatches, total, err := reconcileSvc.GetMismatches(uint(runID), uint(userID), resolved, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"mismatches": mismatches,
+			"total":      total,
+		})
+	})
+
+	/**
+	 * POST /reconciliation/run - 手动发起对账
+	 *
+	 * 请求体：
+	 * {
+	 *   "user_id": 10001  // 可选，不传或为0时对全部客户对账
+	 * }
+	 */
+	admin.POST("/run", func(c *gin.Context) {
+		var req struct {
+			UserID uint `json:"user_id"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+
+		run, mismatches, err := reconcileSvc.Run(model.ReconciliationTriggerManual, req.UserID, c.GetUint("user_id"))
+		if err != nil {
+			if run == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "run": run})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"run":        run,
+			"mismatches": mismatches,
+		})
+	})
+
+	/**
+	 * POST /reconciliation/mismatches/:id/resolve - 标记不一致为已处理
+	 *
+	 * 请求体：
+	 * {
+	 *   "note": "已人工核实，补录流水"
+	 * }
+	 */
+	admin.POST("/mismatches/:id/resolve", func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+			return
+		}
+
+		var req struct {
+			Note string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+
+		mismatch, err := reconcileSvc.ResolveMismatch(uint(id), c.GetUint("user_id"), req.Note)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "已标记为已处理",
+			"mismatch": mismatch,
+		})
+	})
+}
@@ -45,6 +45,7 @@ func RegisterRiskRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	 * {
 	 *   "total_orders": 10,
 	 *   "force_close_count": 2,
+	 *   "margin_call_count": 1,
 	 *   "high_risk_count": 3,
 	 *   "warning_count": 4,
 	 *   "safe_count": 1,
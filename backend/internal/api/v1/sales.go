@@ -169,6 +169,7 @@ func RegisterSalesRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 				"created_at":        customer.CreatedAt,
 				"available_deposit": customer.AvailableDeposit,
 				"used_deposit":      customer.UsedDeposit,
+				"withdraw_frozen":   customer.WithdrawFrozen,
 			})
 		}
 		
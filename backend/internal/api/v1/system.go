@@ -0,0 +1,63 @@
+/**
+ * 系统运行状态API处理器
+ *
+ * 用途：
+ * - 查看当前实例及定时任务主实例（多实例部署）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterSystemRoutes 注册系统状态路由（需管理员权限）
+ *
+ * 路由列表：
+ * - GET /system/leader   查询定时任务主实例
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterSystemRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	admin := rg.Group("", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /system/leader - 查询定时任务主实例
+	 *
+	 * 响应：
+	 * {
+	 *   "instance_id": "app-01-1234",      // 处理本次请求的实例
+	 *   "is_leader": false,
+	 *   "lease_seconds": 30,
+	 *   "heartbeat_seconds": 10,
+	 *   "leader": {
+	 *     "instance_id": "app-02-5678",
+	 *     "acquired_at": "...",
+	 *     "renewed_at": "...",
+	 *     "expires_at": "...",
+	 *     "expired": false
+	 *   }
+	 * }
+	 */
+	admin.GET("/system/leader", func(c *gin.Context) {
+		leader := service.DefaultLeaderElection()
+		if leader == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "选主服务未启动"})
+			return
+		}
+
+		c.JSON(http.StatusOK, leader.GetStatus())
+	})
+}
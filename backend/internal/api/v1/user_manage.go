@@ -64,6 +64,7 @@ type verifyAuditReq struct {
 func RegisterUserManageRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 	userRepo := repository.NewUserRepository(ctx.DB)
 	orderRepo := repository.NewOrderRepository(ctx.DB)
+	fileSvc := service.NewFileService(ctx)
 	
 	// 所有用户管理接口需要管理员权限
 	admin := rg.Group("", middleware.RequireAdmin(ctx))
@@ -222,6 +223,7 @@ func RegisterUserManageRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 				"sales_name":              salesNameMap[user.SalesID],
 				"available_deposit":       user.AvailableDeposit,
 				"used_deposit":            user.UsedDeposit,
+				"withdraw_frozen":         user.WithdrawFrozen,
 				"has_pay_password":        user.HasPayPassword,
 				"auto_supplement_enabled": user.AutoSupplementEnabled,
 				"pending_weight_g":        pendingWeightMap[user.ID],
@@ -259,6 +261,7 @@ func RegisterUserManageRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 			"sales_id":          user.SalesID,
 			"available_deposit": user.AvailableDeposit,
 			"used_deposit":      user.UsedDeposit,
+			"withdraw_frozen":   user.WithdrawFrozen,
 			"has_pay_password":  user.HasPayPassword,
 			"created_at":        user.CreatedAt,
 		})
@@ -576,8 +579,8 @@ func RegisterUserManageRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
 				"user_id":        v.UserID,
 				"real_name":      v.RealName,
 				"id_number":      v.IDNumber,
-				"id_front_url":   v.IDFrontURL,
-				"id_back_url":    v.IDBackURL,
+				"id_front_url":   fileSvc.ResolveRefs(v.IDFrontURL),
+				"id_back_url":    fileSvc.ResolveRefs(v.IDBackURL),
 				"bank_card_id":   v.BankCardID,
 				"receiver_name":  v.ReceiverName,
 				"receiver_phone": v.ReceiverPhone,
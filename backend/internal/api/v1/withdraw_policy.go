@@ -0,0 +1,180 @@
+/**
+ * 提现策略API处理器
+ *
+ * 用途：
+ * - 客户提交提现前试算手续费明细、查看适用的提现策略
+ * - 管理员为个别客户（VIP等）设置专属提现策略
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"suxin/internal/appctx"
+	"suxin/internal/middleware"
+	"suxin/internal/model"
+	"suxin/internal/service"
+)
+
+/**
+ * RegisterWithdrawPolicyRoutes 注册提现策略路由
+ *
+ * 路由列表：
+ * - GET    /withdraws/quote                         提现试算（需JWT）
+ * - GET    /withdraw-policy                         查询当前用户适用的提现策略（需JWT）
+ * - GET    /withdraw-policy/overrides               查询客户专属策略列表（需JWT+管理员）
+ * - GET    /withdraw-policy/overrides/:user_id      查询客户专属策略及生效策略（需JWT+管理员）
+ * - PUT    /withdraw-policy/overrides/:user_id      设置客户专属策略（需JWT+管理员）
+ * - DELETE /withdraw-policy/overrides/:user_id      删除客户专属策略（需JWT+管理员）
+ *
+ * @param rg *gin.RouterGroup - 路由组
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return void
+ */
+func RegisterWithdrawPolicyRoutes(rg *gin.RouterGroup, ctx *appctx.AppContext) {
+	policySvc := service.NewWithdrawPolicyService(ctx)
+
+	/**
+	 * GET /withdraws/quote - 提现试算
+	 *
+	 * 查询参数：
+	 * - amount: 提现金额
+	 *
+	 * 响应：
+	 * {
+	 *   "quote": { "amount": 1000, "fee_fixed": 2, "fee_rate_amount": 5, "fee": 7, "actual_amount": 993, ... },
+	 *   "allowed": true,
+	 *   "error": "超过每日提现金额上限..."  // 不允许提交时返回原因
+	 * }
+	 */
+	rg.GET("/withdraws/quote", func(c *gin.Context) {
+		amount, err := strconv.ParseFloat(c.Query("amount"), 64)
+		if err != nil || amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "提现金额必须大于0"})
+			return
+		}
+
+		quote, err := policySvc.Quote(ctx.DB, c.GetUint("user_id"), amount)
+		if quote == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp := gin.H{
+			"quote":   quote,
+			"allowed": err == nil,
+		}
+		if err != nil {
+			resp["error"] = err.Error()
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	/**
+	 * GET /withdraw-policy - 查询当前用户适用的提现策略
+	 */
+	rg.GET("/withdraw-policy", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"policy": policySvc.GetPolicy(c.GetUint("user_id")),
+		})
+	})
+
+	admin := rg.Group("/withdraw-policy/overrides", middleware.RequireAdmin(ctx))
+
+	/**
+	 * GET /withdraw-policy/overrides - 查询客户专属策略列表（附全局策略）
+	 */
+	admin.GET("", func(c *gin.Context) {
+		overrides, err := policySvc.ListOverrides()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"global":    policySvc.GetGlobalPolicy(),
+			"overrides": overrides,
+		})
+	})
+
+	/**
+	 * GET /withdraw-policy/overrides/:user_id - 查询客户专属策略及生效策略
+	 */
+	admin.GET("/:user_id", func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+			return
+		}
+
+		override, _ := policySvc.GetOverride(uint(userID))
+		c.JSON(http.StatusOK, gin.H{
+			"override": override,
+			"policy":   policySvc.GetPolicy(uint(userID)),
+		})
+	})
+
+	/**
+	 * PUT /withdraw-policy/overrides/:user_id - 设置客户专属策略
+	 *
+	 * 请求体（字段可选，不传或为null表示沿用全局配置；限额类填0表示不限制）：
+	 * {
+	 *   "fee_fixed": 0, "fee_rate": 0.1, "fee_min": 0, "fee_max": 50,
+	 *   "min_amount": 100,
+	 *   "daily_count": 5, "daily_amount": 500000,
+	 *   "monthly_count": 0, "monthly_amount": 0,
+	 *   "free_per_month": 3,
+	 *   "note": "VIP2"
+	 * }
+	 */
+	admin.PUT("/:user_id", func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+			return
+		}
+
+		var req model.WithdrawPolicyOverride
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误"})
+			return
+		}
+
+		override, err := policySvc.SaveOverride(uint(userID), req, c.GetUint("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "保存成功",
+			"override": override,
+			"policy":   policySvc.GetPolicy(uint(userID)),
+		})
+	})
+
+	/**
+	 * DELETE /withdraw-policy/overrides/:user_id - 删除客户专属策略（恢复全局配置）
+	 */
+	admin.DELETE("/:user_id", func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+			return
+		}
+
+		if err := policySvc.DeleteOverride(uint(userID)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "已恢复使用全局提现策略"})
+	})
+}
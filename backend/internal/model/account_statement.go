@@ -0,0 +1,96 @@
+/**
+ * 账户对账单模型
+ *
+ * 用途：
+ * - 记录客户每日/每月对账单的期初期末余额和本期汇总（正式对账单快照）
+ * - 明细（资金流水、订单）在下载时按账单周期从资金流水、订单表生成 PDF/CSV
+ *
+ * 说明：
+ * - 同一客户同一周期只生成一份，生成后不再变化
+ * - 只能为已结束的周期生成对账单；月度对账单在月末后自动生成
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 对账单周期常量
+ */
+const (
+	StatementPeriodDaily   = "daily"   // 日对账单
+	StatementPeriodMonthly = "monthly" // 月对账单
+)
+
+/**
+ * 对账单生成方式常量
+ */
+const (
+	StatementTriggerScheduled = "scheduled" // 月末自动生成
+	StatementTriggerManual    = "manual"    // 客户/管理员手动生成
+)
+
+/**
+ * AccountStatement 账户对账单实体
+ *
+ * 字段说明：
+ * - PeriodStart/PeriodEnd: 账单周期 [开始, 结束)
+ * - Opening/Closing: 期初/期末可用定金、已用定金、提现冻结（取周期前/周期内最后一条资金流水的变动后余额）
+ * - Deposits: 本期入账充值
+ * - Withdrawals: 本期提现到账金额（正数）
+ * - Fees: 本期手续费（正数）
+ * - RealizedPnL: 本期已实现盈亏（结算、强平、部分平仓）
+ * - Supplements: 本期补定金（可用定金转入订单定金，不影响总权益）
+ * - OtherAmount: 本期其他影响总权益的变动
+ * - OrdersOpened/OrdersClosed: 本期开仓/平仓订单数
+ * - EntryCount: 本期资金流水条数
+ * - OperatorID: 手动生成人（客户本人或管理员，自动生成为0）
+ */
+type AccountStatement struct {
+	ID               uint      `gorm:"primarykey" json:"id"`
+	UserID           uint      `gorm:"uniqueIndex:idx_statement_period;not null" json:"user_id"`                      // 客户ID
+	PeriodType       string    `gorm:"type:varchar(10);uniqueIndex:idx_statement_period;not null" json:"period_type"` // 周期类型
+	PeriodStart      time.Time `gorm:"uniqueIndex:idx_statement_period;not null" json:"period_start"`                 // 周期开始
+	PeriodEnd        time.Time `gorm:"not null" json:"period_end"`                                                    // 周期结束（不含）
+	OpeningAvailable float64   `gorm:"type:decimal(15,2);default:0" json:"opening_available"`                         // 期初可用定金
+	OpeningUsed      float64   `gorm:"type:decimal(15,2);default:0" json:"opening_used"`                              // 期初已用定金
+	OpeningFrozen    float64   `gorm:"type:decimal(15,2);default:0" json:"opening_frozen"`                            // 期初提现冻结
+	ClosingAvailable float64   `gorm:"type:decimal(15,2);default:0" json:"closing_available"`                         // 期末可用定金
+	ClosingUsed      float64   `gorm:"type:decimal(15,2);default:0" json:"closing_used"`                              // 期末已用定金
+	ClosingFrozen    float64   `gorm:"type:decimal(15,2);default:0" json:"closing_frozen"`                            // 期末提现冻结
+	Deposits         float64   `gorm:"type:decimal(15,2);default:0" json:"deposits"`                                  // 入账充值
+	Withdrawals      float64   `gorm:"type:decimal(15,2);default:0" json:"withdrawals"`                               // 提现
+	Fees             float64   `gorm:"type:decimal(15,2);default:0" json:"fees"`                                      // 手续费
+	RealizedPnL      float64   `gorm:"column:realized_pnl;type:decimal(15,2);default:0" json:"realized_pnl"`          // 已实现盈亏
+	Supplements      float64   `gorm:"type:decimal(15,2);default:0" json:"supplements"`                               // 补定金
+	OtherAmount      float64   `gorm:"type:decimal(15,2);default:0" json:"other_amount"`                              // 其他变动
+	OrdersOpened     int       `gorm:"default:0" json:"orders_opened"`                                                // 开仓订单数
+	OrdersClosed     int       `gorm:"default:0" json:"orders_closed"`                                                // 平仓订单数
+	EntryCount       int       `gorm:"default:0" json:"entry_count"`                                                  // 资金流水条数
+	TriggerType      string    `gorm:"type:varchar(20);not null" json:"trigger_type"`                                 // 生成方式
+	OperatorID       uint      `gorm:"default:0" json:"operator_id"`                                                  // 手动生成人
+	CreatedAt        time.Time `gorm:"index" json:"created_at"`
+}
+
+/**
+ * OpeningTotal 期初总权益（可用 + 已用 + 提现冻结）
+ *
+ * @return float64
+ */
+func (s *AccountStatement) OpeningTotal() float64 {
+	return s.OpeningAvailable + s.OpeningUsed + s.OpeningFrozen
+}
+
+/**
+ * ClosingTotal 期末总权益（可用 + 已用 + 提现冻结）
+ *
+ * @return float64
+ */
+func (s *AccountStatement) ClosingTotal() float64 {
+	return s.ClosingAvailable + s.ClosingUsed + s.ClosingFrozen
+}
@@ -0,0 +1,109 @@
+/**
+ * 审批链领域模型
+ *
+ * 用途：
+ * - 为充值、提现等资金类申请提供通用的多级审批（maker-checker）
+ * - 记录每一步审批的审批人、角色、结论和备注
+ *
+ * 规则：
+ * - 发起人不能审批自己的申请，同一审批人不能重复审批
+ * - 超过金额阈值增加的一级审批须由超级管理员审批（余额调整的发起步骤计为第一级）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 审批对象类型常量
+ */
+const (
+	ApprovalTargetWithdraw = "withdraw" // 提现申请
+	ApprovalTargetDeposit  = "deposit"  // 充值申请
+
+	ApprovalTargetAdjustment = "adjustment" // 手工余额调整
+)
+
+/**
+ * 审批结论常量
+ */
+const (
+	ApprovalDecisionApprove = "approve" // 通过
+	ApprovalDecisionReject  = "reject"  // 驳回
+	ApprovalDecisionSubmit  = "submit"  // 发起（由管理员直接发起的操作，发起即计为第一级）
+)
+
+/**
+ * ApprovalRoleSystem 系统自动审批的审批人角色（如支付通道回调已验签入账）
+ */
+const ApprovalRoleSystem = "system"
+
+/**
+ * ApprovalChain 审批链（嵌入到需要审批的申请中）
+ *
+ * 字段说明：
+ * - RequiredApprovals: 需要的审批级数（提交时按金额确定，1为单人审批，2为双人审批）
+ * - ApprovalCount: 已通过的审批级数
+ */
+type ApprovalChain struct {
+	RequiredApprovals int `gorm:"default:1" json:"required_approvals"` // 需要的审批级数
+	ApprovalCount     int `gorm:"default:0" json:"approval_count"`     // 已通过的审批级数
+}
+
+/**
+ * Required 需要的审批级数（早期申请未设置时按单人审批）
+ *
+ * @return int
+ */
+func (a *ApprovalChain) Required() int {
+	if a.RequiredApprovals < 1 {
+		return 1
+	}
+	return a.RequiredApprovals
+}
+
+/**
+ * NextStep 下一步审批的序号（从1开始）
+ *
+ * @return int
+ */
+func (a *ApprovalChain) NextStep() int {
+	return a.ApprovalCount + 1
+}
+
+/**
+ * IsFinalStep 下一步审批是否为最后一级
+ *
+ * @return bool
+ */
+func (a *ApprovalChain) IsFinalStep() bool {
+	return a.NextStep() >= a.Required()
+}
+
+/**
+ * ApprovalStep 审批步骤记录
+ *
+ * 字段说明：
+ * - TargetType/TargetID: 审批对象（如 withdraw/12）
+ * - Step: 审批序号（第几级）
+ * - ApproverID/ApproverName/ApproverRole: 审批人及其审批时的姓名、角色
+ * - Decision: 审批结论（approve/reject/submit）
+ * - Note: 审批备注
+ */
+type ApprovalStep struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	TargetType   string    `gorm:"type:varchar(30);index:idx_approval_target;not null" json:"target_type"` // 审批对象类型
+	TargetID     uint      `gorm:"index:idx_approval_target;not null" json:"target_id"`                    // 审批对象ID
+	Step         int       `gorm:"not null" json:"step"`                                                   // 审批序号
+	ApproverID   uint      `gorm:"index;not null" json:"approver_id"`                                      // 审批人ID
+	ApproverName string    `gorm:"type:varchar(50)" json:"approver_name"`                                  // 审批人姓名
+	ApproverRole string    `gorm:"type:varchar(20)" json:"approver_role"`                                  // 审批人角色
+	Decision     string    `gorm:"type:varchar(20);not null" json:"decision"`                              // 审批结论
+	Note         string    `gorm:"type:varchar(500)" json:"note"`                                          // 审批备注
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,149 @@
+/**
+ * 手工余额调整模型
+ *
+ * 用途：
+ * - 记录管理员按原因（补偿、差错更正、赠送、手续费退还）调增/调减客户可用定金
+ * - 每笔调整须附说明附件和备注，入账时生成独立类型的资金流水
+ *
+ * 规则：
+ * - 每笔调整须由发起人以外的管理员审批后才入账，金额（绝对值）超过审批阈值时还需超级管理员审批
+ * - 发起和审批均记入审批记录，与资金流水、总账分录共同构成审计轨迹
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 余额调整原因常量
+ */
+const (
+	AdjustmentReasonCompensation = "compensation" // 补偿
+	AdjustmentReasonCorrection   = "correction"   // 差错更正
+	AdjustmentReasonBonus        = "bonus"        // 赠送
+	AdjustmentReasonFeeRefund    = "fee_refund"   // 手续费退还
+)
+
+// AdjustmentReasonText 余额调整原因名称
+var AdjustmentReasonText = map[string]string{
+	AdjustmentReasonCompensation: "补偿",
+	AdjustmentReasonCorrection:   "差错更正",
+	AdjustmentReasonBonus:        "赠送",
+	AdjustmentReasonFeeRefund:    "手续费退还",
+}
+
+/**
+ * 余额调整状态常量
+ */
+const (
+	AdjustmentStatusPending  = "pending"  // 待审批
+	AdjustmentStatusApplied  = "applied"  // 已入账
+	AdjustmentStatusRejected = "rejected" // 已驳回
+)
+
+/**
+ * BalanceAdjustment 手工余额调整实体
+ *
+ * 字段说明：
+ * - Amount: 调整金额（正数调增，负数调减可用定金）
+ * - ReasonCode: 调整原因（AdjustmentReason*）
+ * - AttachmentURL: 说明附件（文件引用，支持多个，逗号分隔）
+ * - ApprovalChain: 审批链（发起计为第一级，超过阈值需超级管理员审批第二级）
+ * - OperatorID: 发起人ID
+ * - ReviewerID/ReviewNote/ReviewedAt: 审批人、审批备注、审批时间（无需审批时为发起人）
+ * - FundLogID: 入账生成的资金流水ID
+ */
+type BalanceAdjustment struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`                          // 客户ID
+	Amount        float64    `gorm:"type:decimal(15,2);not null" json:"amount"`              // 调整金额
+	ReasonCode    string     `gorm:"type:varchar(20);index;not null" json:"reason_code"`     // 调整原因
+	AttachmentURL string     `gorm:"type:text" json:"attachment_url"`                        // 说明附件
+	Note          string     `gorm:"type:varchar(500);not null" json:"note"`                 // 调整说明
+	Status        string     `gorm:"type:varchar(20);index;default:'pending'" json:"status"` // 状态
+	ApprovalChain            // 审批链
+	OperatorID    uint       `gorm:"index;not null" json:"operator_id"`       // 发起人
+	ReviewerID    uint       `gorm:"default:0" json:"reviewer_id"`            // 审批人
+	ReviewNote    string     `gorm:"type:varchar(500)" json:"review_note"`    // 审批备注
+	ReviewedAt    *time.Time `json:"reviewed_at"`                             // 审批时间
+	FundLogID     uint       `gorm:"default:0" json:"fund_log_id"`            // 资金流水ID
+	User          *User      `gorm:"foreignKey:UserID" json:"user,omitempty"` // 关联客户
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+/**
+ * ReasonText 调整原因名称
+ *
+ * @return string
+ */
+func (a *BalanceAdjustment) ReasonText() string {
+	if text, ok := AdjustmentReasonText[a.ReasonCode]; ok {
+		return text
+	}
+	return a.ReasonCode
+}
+
+/**
+ * Apply 标记为已入账
+ *
+ * @param reviewerID uint - 审批人ID（无需审批时为发起人）
+ * @param note string - 审批备注
+ * @param fundLogID uint - 资金流水ID
+ * @return void
+ */
+func (a *BalanceAdjustment) Apply(reviewerID uint, note string, fundLogID uint) {
+	now := time.Now()
+	a.Status = AdjustmentStatusApplied
+	a.ReviewerID = reviewerID
+	a.ReviewNote = note
+	a.ReviewedAt = &now
+	a.FundLogID = fundLogID
+}
+
+/**
+ * PassStep 通过一级审批（发起或审批通过）
+ *
+ * @return void
+ */
+func (a *BalanceAdjustment) PassStep() {
+	a.ApprovalCount++
+}
+
+/**
+ * IsFullyApproved 判断是否已完成全部审批
+ *
+ * @return bool
+ */
+func (a *BalanceAdjustment) IsFullyApproved() bool {
+	return a.ApprovalCount >= a.Required()
+}
+
+/**
+ * Reject 驳回调整
+ *
+ * @param reviewerID uint - 审批人ID
+ * @param note string - 驳回原因
+ * @return void
+ */
+func (a *BalanceAdjustment) Reject(reviewerID uint, note string) {
+	now := time.Now()
+	a.Status = AdjustmentStatusRejected
+	a.ReviewerID = reviewerID
+	a.ReviewNote = note
+	a.ReviewedAt = &now
+}
+
+/**
+ * IsPending 判断是否待审批
+ *
+ * @return bool
+ */
+func (a *BalanceAdjustment) IsPending() bool {
+	return a.Status == AdjustmentStatusPending
+}
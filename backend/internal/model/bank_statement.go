@@ -0,0 +1,133 @@
+/**
+ * 银行流水导入模型
+ *
+ * 用途：
+ * - 按银行配置流水文件的列映射（CSV/XLSX）
+ * - 记录每次导入和导入的每一笔入账流水
+ * - 流水与待审核充值申请自动匹配：高置信度自动入账，中等置信度待人工确认，其余进入异常队列
+ *
+ * 流水状态流转：
+ * - auto_matched：自动匹配并已审核通过对应充值申请
+ * - proposed → confirmed：人工确认建议匹配
+ * - proposed → exception：人工驳回建议匹配
+ * - exception → confirmed：人工指定充值申请匹配
+ * - exception → ignored：确认非客户充值（如利息、退款），不处理
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+ * 流水匹配状态常量
+ */
+const (
+	StatementLineAutoMatched = "auto_matched" // 自动匹配并入账
+	StatementLineProposed    = "proposed"     // 建议匹配，待人工确认
+	StatementLineException   = "exception"    // 未匹配，异常队列
+	StatementLineConfirmed   = "confirmed"    // 人工确认匹配
+	StatementLineIgnored     = "ignored"      // 已忽略
+)
+
+/**
+ * 流水文件编码常量
+ */
+const (
+	StatementEncodingUTF8 = "utf-8" // UTF-8（默认，兼容BOM）
+	StatementEncodingGBK  = "gbk"   // GBK（国内网银导出常见）
+)
+
+/**
+ * BankStatementMapping 银行流水列映射
+ *
+ * 字段说明：
+ * - BankName: 银行名称（唯一）
+ * - Encoding: CSV 文件编码（XLSX 忽略）
+ * - HeaderRow: 表头所在行（从1开始，表头之前的行忽略）
+ * - *Column: 各字段对应的表头名称，为空表示文件中没有该列
+ * - DateFormat: 时间格式（Go 时间格式，如 2006-01-02 15:04:05；为空时自动识别常见格式）
+ * - DebitColumn: 支出金额列（该列有金额而收入列为空的行视为支出，不导入）
+ */
+type BankStatementMapping struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	BankName           string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"bank_name"` // 银行名称
+	Encoding           string    `gorm:"type:varchar(20);default:'utf-8'" json:"encoding"`        // CSV编码
+	HeaderRow          int       `gorm:"default:1" json:"header_row"`                             // 表头行号
+	DateColumn         string    `gorm:"type:varchar(100);not null" json:"date_column"`           // 交易日期列
+	TimeColumn         string    `gorm:"type:varchar(100)" json:"time_column"`                    // 交易时间列（日期列不含时间时）
+	DateFormat         string    `gorm:"type:varchar(50)" json:"date_format"`                     // 时间格式
+	AmountColumn       string    `gorm:"type:varchar(100);not null" json:"amount_column"`         // 收入金额列
+	DebitColumn        string    `gorm:"type:varchar(100)" json:"debit_column"`                   // 支出金额列
+	PayerNameColumn    string    `gorm:"type:varchar(100)" json:"payer_name_column"`              // 付款人户名列
+	PayerAccountColumn string    `gorm:"type:varchar(100)" json:"payer_account_column"`           // 付款人账号列
+	ReferenceColumn    string    `gorm:"type:varchar(100)" json:"reference_column"`               // 摘要/附言列
+	SerialColumn       string    `gorm:"type:varchar(100)" json:"serial_column"`                  // 交易流水号列
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+/**
+ * BankStatementImport 流水导入批次
+ */
+type BankStatementImport struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	MappingID      uint      `gorm:"index;not null" json:"mapping_id"`            // 列映射ID
+	BankName       string    `gorm:"type:varchar(100);not null" json:"bank_name"` // 银行名称
+	FileName       string    `gorm:"type:varchar(255)" json:"file_name"`          // 文件名
+	OperatorID     uint      `gorm:"not null" json:"operator_id"`                 // 导入人
+	TotalLines     int       `gorm:"default:0" json:"total_lines"`                // 数据行数
+	CreditLines    int       `gorm:"default:0" json:"credit_lines"`               // 入账行数（已导入）
+	DuplicateLines int       `gorm:"default:0" json:"duplicate_lines"`            // 重复行数（已导入过，跳过）
+	SkippedLines   int       `gorm:"default:0" json:"skipped_lines"`              // 支出/无法解析行数
+	AutoMatched    int       `gorm:"default:0" json:"auto_matched"`               // 自动入账数
+	Proposed       int       `gorm:"default:0" json:"proposed"`                   // 建议匹配数
+	Exceptions     int       `gorm:"default:0" json:"exceptions"`                 // 异常数
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+/**
+ * BankStatementLine 银行流水（仅入账）
+ *
+ * 字段说明：
+ * - Fingerprint: 去重指纹（银行+流水号，无流水号时为银行+时间+金额+户名+附言）
+ * - DepositID: 匹配的充值申请（建议匹配、已匹配时有值）
+ * - Confidence: 匹配置信度（0-100）
+ * - MatchReason: 匹配依据说明
+ */
+type BankStatementLine struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	ImportID     uint       `gorm:"index;not null" json:"import_id"`                // 导入批次ID
+	BankName     string     `gorm:"type:varchar(100);not null" json:"bank_name"`    // 银行名称
+	Fingerprint  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"` // 去重指纹
+	SerialNo     string     `gorm:"type:varchar(100)" json:"serial_no"`             // 交易流水号
+	TxnTime      time.Time  `gorm:"index" json:"txn_time"`                          // 交易时间
+	Amount       float64    `gorm:"type:decimal(15,2);not null" json:"amount"`      // 入账金额
+	PayerName    string     `gorm:"type:varchar(100)" json:"payer_name"`            // 付款人户名
+	PayerAccount string     `gorm:"type:varchar(100)" json:"payer_account"`         // 付款人账号
+	Reference    string     `gorm:"type:varchar(500)" json:"reference"`             // 摘要/附言
+	Status       string     `gorm:"type:varchar(20);index;not null" json:"status"`  // 匹配状态
+	DepositID    uint       `gorm:"index;default:0" json:"deposit_id"`              // 匹配的充值申请
+	Confidence   int        `gorm:"default:0" json:"confidence"`                    // 匹配置信度
+	MatchReason  string     `gorm:"type:varchar(255)" json:"match_reason"`          // 匹配依据
+	HandledBy    uint       `gorm:"default:0" json:"handled_by"`                    // 处理人
+	HandleNote   string     `gorm:"type:varchar(500)" json:"handle_note"`           // 处理备注
+	HandledAt    *time.Time `json:"handled_at,omitempty"`                           // 处理时间
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+/**
+ * DepositReferenceCode 客户转账附言识别码（客户转账时填写，用于流水匹配）
+ *
+ * @param userID uint - 用户ID
+ * @return string
+ */
+func DepositReferenceCode(userID uint) string {
+	return fmt.Sprintf("SJ%06d", userID)
+}
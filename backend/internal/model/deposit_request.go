@@ -22,9 +22,10 @@ import (
  * 充值状态常量
  */
 const (
-	DepositStatusPending  = "pending"  // 待审核
-	DepositStatusApproved = "approved" // 已通过
-	DepositStatusRejected = "rejected" // 已驳回
+	DepositStatusPending   = "pending"   // 待审核
+	DepositStatusReviewing = "reviewing" // 初审通过，待复核
+	DepositStatusApproved  = "approved"  // 已通过
+	DepositStatusRejected  = "rejected"  // 已驳回
 )
 
 /**
@@ -34,6 +35,7 @@ const (
 	DepositMethodBank   = "bank"   // 银行转账
 	DepositMethodWechat = "wechat" // 微信支付
 	DepositMethodAlipay = "alipay" // 支付宝
+	DepositMethodOnline = "online" // 在线支付（支付通道回调自动入账）
 )
 
 /**
@@ -45,6 +47,7 @@ const (
  * - Method: 充值方式
  * - VoucherURL: 凭证图片URL
  * - Status: 审核状态
+ * - ApprovalChain: 审批链（大额充值需超级管理员复核）
  * - ReviewerID: 审核人ID
  * - ReviewNote: 审核备注
  * - ReviewedAt: 审核时间
@@ -58,6 +61,7 @@ type DepositRequest struct {
 	VoucherURL  string         `gorm:"type:longtext" json:"voucher_url"`                        // 用户付款凭证（支持多张，逗号分隔）
 	UserNote    string         `gorm:"type:varchar(500)" json:"user_note"`                     // 用户备注
 	Status      string         `gorm:"type:varchar(20);index;default:'pending'" json:"status"` // 状态
+	ApprovalChain                                                                               // 审批链
 	ReviewerID  uint           `gorm:"default:0" json:"reviewer_id"`                                // 审核人ID
 	ReviewNote  string         `gorm:"type:varchar(500)" json:"review_note"`                        // 审核备注
 	ReceiptVoucherURL string   `gorm:"type:longtext" json:"receipt_voucher"`                   // 管理员收款凭证
@@ -77,12 +81,23 @@ type DepositRequest struct {
  */
 func (d *DepositRequest) Approve(reviewerID uint, note string) {
 	now := time.Now()
+	d.ApprovalCount++
 	d.Status = DepositStatusApproved
 	d.ReviewerID = reviewerID
 	d.ReviewNote = note
 	d.ReviewedAt = &now
 }
 
+/**
+ * PassStep 通过一级审批（未到最后一级时进入待复核）
+ * 
+ * @return void
+ */
+func (d *DepositRequest) PassStep() {
+	d.ApprovalCount++
+	d.Status = DepositStatusReviewing
+}
+
 /**
  * Reject 驳回审核
  * 
@@ -107,6 +122,15 @@ func (d *DepositRequest) IsPending() bool {
 	return d.Status == DepositStatusPending
 }
 
+/**
+ * IsAwaitingApproval 判断是否仍在审批中（待审核或待复核）
+ * 
+ * @return bool
+ */
+func (d *DepositRequest) IsAwaitingApproval() bool {
+	return d.Status == DepositStatusPending || d.Status == DepositStatusReviewing
+}
+
 /**
  * IsApproved 判断是否已通过
  * 
@@ -0,0 +1,77 @@
+/**
+ * 数据导出任务模型
+ *
+ * 用途：
+ * - 记录管理员发起的异步导出任务（订单、资金流水、付定金、退定金、提成记录）
+ * - 导出文件保存在文件存储中，完成后可在有效期内下载
+ *
+ * 说明：
+ * - 筛选条件与列表接口一致（日期范围、状态、客户、销售），以JSON保存
+ * - 任务由主实例后台依次执行，服务重启时执行中的任务标记为失败
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 导出数据集常量
+ */
+const (
+	ExportDatasetOrders      = "orders"      // 订单
+	ExportDatasetFundLogs    = "fund_logs"   // 资金流水
+	ExportDatasetDeposits    = "deposits"    // 付定金申请
+	ExportDatasetWithdraws   = "withdraws"   // 退定金申请
+	ExportDatasetCommissions = "commissions" // 销售提成记录
+)
+
+/**
+ * 导出格式常量
+ */
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+/**
+ * 导出任务状态常量
+ */
+const (
+	ExportStatusPending = "pending" // 排队中
+	ExportStatusRunning = "running" // 执行中
+	ExportStatusDone    = "done"    // 已完成
+	ExportStatusFailed  = "failed"  // 失败
+	ExportStatusExpired = "expired" // 文件已过期删除
+)
+
+/**
+ * ExportJob 导出任务实体
+ *
+ * 字段说明：
+ * - Filter: 筛选条件JSON（start_date/end_date/status/user_id/salesperson_id）
+ * - StorageKey: 导出文件在文件存储中的键（完成后才有）
+ * - RowCount/FileSize: 导出行数（不含表头）/文件大小（字节）
+ * - ExpiresAt: 文件过期时间，过期后删除文件
+ */
+type ExportJob struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	Dataset    string     `gorm:"type:varchar(20);not null" json:"dataset"`               // 数据集
+	Format     string     `gorm:"type:varchar(10);not null" json:"format"`                // 文件格式
+	Filter     string     `gorm:"type:text" json:"filter"`                                // 筛选条件JSON
+	Status     string     `gorm:"type:varchar(20);index;default:'pending'" json:"status"` // 状态
+	FileName   string     `gorm:"type:varchar(255)" json:"file_name"`                     // 下载文件名
+	StorageKey string     `gorm:"type:varchar(255)" json:"-"`                             // 存储键
+	RowCount   int64      `json:"row_count"`                                              // 导出行数
+	FileSize   int64      `json:"file_size"`                                              // 文件大小
+	Error      string     `gorm:"type:varchar(500)" json:"error"`                         // 失败原因
+	OperatorID uint       `gorm:"index" json:"operator_id"`                               // 发起人
+	StartedAt  *time.Time `json:"started_at"`                                             // 开始执行时间
+	FinishedAt *time.Time `json:"finished_at"`                                            // 完成时间
+	ExpiresAt  *time.Time `json:"expires_at"`                                             // 文件过期时间
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+}
@@ -28,6 +28,14 @@ const (
 	FundLogTypeOrderRelease = "order_release" // 订单释放
 	FundLogTypeSettle       = "settle"        // 结算
 	FundLogTypeForceClose   = "force_close"   // 强平
+	FundLogTypeSupplement   = "supplement"    // 补定金
+	FundLogTypePartialClose = "partial_close" // 部分平仓
+
+	FundLogTypeWithdrawFreeze  = "withdraw_freeze"  // 提现冻结（提交提现申请）
+	FundLogTypeWithdrawRelease = "withdraw_release" // 提现解冻（驳回/取消提现申请）
+	FundLogTypeWithdrawFee     = "withdraw_fee"     // 提现手续费
+
+	FundLogTypeAdjustment = "adjustment" // 手工余额调整（管理员按原因调增/调减可用定金）
 )
 
 /**
@@ -36,11 +44,13 @@ const (
  * 字段说明：
  * - UserID: 用户ID
  * - Type: 流水类型
- * - Amount: 变动金额（正数为增加，负数为减少）
+ * - Amount: 可用定金变动金额（正数为增加，负数为减少；提现、提现手续费为可用与提现冻结合计扣减金额）
  * - AvailableBefore: 变动前可用定金
  * - AvailableAfter: 变动后可用定金
  * - UsedBefore: 变动前已用定金
  * - UsedAfter: 变动后已用定金
+ * - FrozenBefore: 变动前提现冻结
+ * - FrozenAfter: 变动后提现冻结
  * - RelatedID: 关联业务ID
  * - RelatedType: 关联业务类型
  * - Note: 备注
@@ -54,6 +64,8 @@ type FundLog struct {
 	AvailableAfter  float64        `gorm:"type:decimal(15,2);not null"`       // 变动后可用
 	UsedBefore      float64        `gorm:"type:decimal(15,2);not null"`       // 变动前已用
 	UsedAfter       float64        `gorm:"type:decimal(15,2);not null"`       // 变动后已用
+	FrozenBefore    float64        `gorm:"type:decimal(15,2);default:0"`      // 变动前提现冻结
+	FrozenAfter     float64        `gorm:"type:decimal(15,2);default:0"`      // 变动后提现冻结
 	RelatedID       uint           `gorm:"default:0"`                         // 关联业务ID
 	RelatedType     string         `gorm:"type:varchar(50)"`                  // 关联业务类型
 	Note            string         `gorm:"type:varchar(500)"`                 // 备注
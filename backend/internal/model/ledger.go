@@ -0,0 +1,133 @@
+/**
+ * 复式记账总账模型
+ *
+ * 用途：
+ * - 科目：客户可用定金、客户已用定金（按客户分户）、平台盈亏、手续费收入、银行清算
+ * - 凭证：每次资金变更生成一张凭证，凭证分录借贷必须相等
+ * - 客户余额（User.AvailableDeposit/UsedDeposit）与对应科目余额核对
+ *
+ * 记账方向：
+ * - 资产类（银行清算）：借增贷减
+ * - 负债类（客户定金）、权益类（平台盈亏）、收入类（手续费）：贷增借减
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 科目代码常量
+ */
+const (
+	LedgerAccountCustomerAvailable = "customer_available" // 客户可用定金（按客户分户）
+	LedgerAccountCustomerMargin    = "customer_margin"    // 客户已用定金（按客户分户）
+	LedgerAccountCustomerFrozen    = "customer_frozen"    // 客户提现冻结（按客户分户）
+	LedgerAccountHousePnL          = "house_pnl"          // 平台盈亏（客户盈利即平台亏损）
+	LedgerAccountFeeIncome         = "fee_income"         // 手续费收入
+	LedgerAccountBankClearing      = "bank_clearing"      // 银行清算（客户入金/出金）
+)
+
+/**
+ * 科目类别常量
+ */
+const (
+	LedgerCategoryAsset     = "asset"     // 资产
+	LedgerCategoryLiability = "liability" // 负债
+	LedgerCategoryEquity    = "equity"    // 权益
+	LedgerCategoryIncome    = "income"    // 收入
+)
+
+/**
+ * JournalTypeOpening 期初余额凭证类型（启用总账前已有的客户余额）
+ *
+ * 其他凭证类型与资金流水类型一致（model.FundLogType*）
+ */
+const JournalTypeOpening = "opening"
+
+/**
+ * LedgerAccount 总账科目实体
+ *
+ * 字段说明：
+ * - Code: 科目代码
+ * - UserID: 客户分户的用户ID（平台科目为0）
+ * - Category: 科目类别（决定余额方向）
+ * - Name: 科目名称
+ * - Balance: 科目余额（按余额方向计算，随分录实时更新）
+ */
+type LedgerAccount struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Code      string    `gorm:"type:varchar(30);uniqueIndex:idx_ledger_account;not null" json:"code"` // 科目代码
+	UserID    uint      `gorm:"uniqueIndex:idx_ledger_account;default:0" json:"user_id"`              // 客户ID（平台科目为0）
+	Category  string    `gorm:"type:varchar(20);not null" json:"category"`                            // 科目类别
+	Name      string    `gorm:"type:varchar(50)" json:"name"`                                         // 科目名称
+	Balance   float64   `gorm:"type:decimal(15,2);default:0" json:"balance"`                          // 余额
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+/**
+ * DebitNormal 是否借方余额科目（资产类）
+ *
+ * @return bool
+ */
+func (a *LedgerAccount) DebitNormal() bool {
+	return a.Category == LedgerCategoryAsset
+}
+
+/**
+ * SignedAmount 分录对科目余额的影响
+ *
+ * @param debit float64 - 借方金额
+ * @param credit float64 - 贷方金额
+ * @return float64
+ */
+func (a *LedgerAccount) SignedAmount(debit, credit float64) float64 {
+	if a.DebitNormal() {
+		return debit - credit
+	}
+	return credit - debit
+}
+
+/**
+ * JournalEntry 记账凭证实体
+ *
+ * 字段说明：
+ * - Type: 凭证类型（与资金流水类型一致，或 opening）
+ * - UserID: 相关客户
+ * - FundLogID: 对应的资金流水
+ * - RelatedID/RelatedType: 关联业务
+ * - Amount: 凭证金额（借方合计，等于贷方合计）
+ * - Lines: 分录
+ */
+type JournalEntry struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Type        string         `gorm:"type:varchar(20);index;not null" json:"type"` // 凭证类型
+	UserID      uint           `gorm:"index;default:0" json:"user_id"`              // 相关客户
+	FundLogID   uint           `gorm:"index;default:0" json:"fund_log_id"`          // 资金流水ID
+	RelatedID   uint           `gorm:"default:0" json:"related_id"`                 // 关联业务ID
+	RelatedType string         `gorm:"type:varchar(50)" json:"related_type"`        // 关联业务类型
+	Amount      float64        `gorm:"type:decimal(15,2)" json:"amount"`            // 凭证金额
+	Note        string         `gorm:"type:varchar(500)" json:"note"`               // 摘要
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`
+	Lines       []*JournalLine `gorm:"foreignKey:EntryID" json:"lines,omitempty"`
+}
+
+/**
+ * JournalLine 凭证分录实体
+ *
+ * 每条分录只有借方或贷方一侧有金额
+ */
+type JournalLine struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	EntryID     uint      `gorm:"index;not null" json:"entry_id"`             // 凭证ID
+	AccountID   uint      `gorm:"index;not null" json:"account_id"`           // 科目ID
+	AccountCode string    `gorm:"type:varchar(30)" json:"account_code"`       // 科目代码（冗余，便于查询）
+	Debit       float64   `gorm:"type:decimal(15,2);default:0" json:"debit"`  // 借方金额
+	Credit      float64   `gorm:"type:decimal(15,2);default:0" json:"credit"` // 贷方金额
+	CreatedAt   time.Time `json:"created_at"`
+}
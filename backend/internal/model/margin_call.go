@@ -0,0 +1,137 @@
+/**
+ * 追加保证金（追保）通知模型
+ *
+ * 用途：
+ * - 订单定金率跌破追保线时生成追保记录，给客户一个补足期限
+ * - 期限内补足定金则追保解除，逾期未补足再执行强平
+ * - 供管理后台查看追保进度
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * 追保状态常量
+ */
+const (
+	MarginCallStatusOpen    = "open"    // 追保中（等待客户补足）
+	MarginCallStatusMet     = "met"     // 已解除（已补足/行情回升/客户自行结算）
+	MarginCallStatusExpired = "expired" // 已逾期（已执行强平）
+)
+
+/**
+ * MarginCall 追保记录实体
+ *
+ * 字段说明：
+ * - OrderID/OrderNo: 关联订单ID和订单号
+ * - SalesID: 客户归属销售ID（触发时快照）
+ * - CallRate: 追保线（%）
+ * - TriggerRate: 触发时的定金率（%）
+ * - TriggerPrice: 触发时的价格（元/克）
+ * - RequiredAmount: 恢复到追保线以上需要补充的金额（元，随补定金更新）
+ * - Deadline: 补足期限
+ * - ResolvedAt: 解除或逾期时间
+ * - ResolvedRate: 解除或逾期时的定金率（%）
+ * - SupplementID: 解除追保的补定金记录ID
+ * - ResolveNote: 解除或逾期说明
+ */
+type MarginCall struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	UserID         uint           `gorm:"index;not null" json:"user_id"`                       // 用户ID
+	OrderID        uint           `gorm:"index;not null" json:"order_id"`                      // 订单ID
+	OrderNo        string         `gorm:"type:varchar(50)" json:"order_no"`                    // 订单号
+	SalesID        uint           `gorm:"index" json:"sales_id"`                               // 归属销售ID
+	Status         string         `gorm:"type:varchar(20);index;default:'open'" json:"status"` // 状态
+	CallRate       float64        `gorm:"type:decimal(10,2)" json:"call_rate"`                 // 追保线（%）
+	TriggerRate    float64        `gorm:"type:decimal(10,2)" json:"trigger_rate"`              // 触发定金率（%）
+	TriggerPrice   float64        `gorm:"type:decimal(10,4)" json:"trigger_price"`             // 触发价格
+	RequiredAmount float64        `gorm:"type:decimal(15,2)" json:"required_amount"`           // 需补充金额
+	Deadline       time.Time      `gorm:"index;not null" json:"deadline"`                      // 补足期限
+	ResolvedAt     *time.Time     `json:"resolved_at,omitempty"`                               // 解除/逾期时间
+	ResolvedRate   float64        `gorm:"type:decimal(10,2)" json:"resolved_rate"`             // 解除/逾期时定金率（%）
+	SupplementID   uint           `json:"supplement_id,omitempty"`                             // 补定金记录ID
+	ResolveNote    string         `gorm:"type:varchar(500)" json:"resolve_note"`               // 说明
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 关联数据（不存储）
+	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+/**
+ * IsOpen 判断追保是否仍在进行中
+ *
+ * @return bool
+ */
+func (m *MarginCall) IsOpen() bool {
+	return m.Status == MarginCallStatusOpen
+}
+
+/**
+ * IsOverdue 判断追保是否已过期限
+ *
+ * @param now time.Time - 当前时间
+ * @return bool
+ */
+func (m *MarginCall) IsOverdue(now time.Time) bool {
+	return m.IsOpen() && !now.Before(m.Deadline)
+}
+
+/**
+ * Meet 解除追保
+ *
+ * @param rate float64 - 解除时的定金率
+ * @param note string - 说明
+ * @return void
+ */
+func (m *MarginCall) Meet(rate float64, note string) {
+	now := time.Now()
+	m.Status = MarginCallStatusMet
+	m.ResolvedAt = &now
+	m.ResolvedRate = rate
+	m.RequiredAmount = 0
+	m.ResolveNote = note
+}
+
+/**
+ * Expire 追保逾期（执行强平）
+ *
+ * @param rate float64 - 强平时的定金率
+ * @param note string - 说明
+ * @return void
+ */
+func (m *MarginCall) Expire(rate float64, note string) {
+	now := time.Now()
+	m.Status = MarginCallStatusExpired
+	m.ResolvedAt = &now
+	m.ResolvedRate = rate
+	m.ResolveNote = note
+}
+
+/**
+ * RequiredSupplement 计算订单恢复到指定定金率需要补充的金额
+ *
+ * 定金率 = (定金 + 浮动盈亏) / 基础定金 × 100%，基础定金 = 克重 × 10 元/克
+ *
+ * @param order *Order - 订单（需已按当前价格计算盈亏）
+ * @param rate float64 - 目标定金率（%）
+ * @return float64 - 需补充金额（元，不小于0）
+ */
+func RequiredSupplement(order *Order, rate float64) float64 {
+	baseDeposit := order.WeightG * 10
+	need := baseDeposit*rate/100.0 - (order.Deposit + order.PnLFloat)
+	if need < 0 {
+		return 0
+	}
+	return need
+}
@@ -27,6 +27,7 @@ const (
 	NotifyTypeRisk      = "risk"       // 风控通知
 	NotifyTypeFund      = "fund"       // 资金通知
 	NotifyTypeAnnounce  = "announce"   // 系统公告
+	NotifyTypePriceAlert = "price_alert" // 价格提醒
 )
 
 /**
@@ -13,6 +13,7 @@
 package model
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -49,8 +50,10 @@ const (
  * - MarginRate: 定金率（%，实时计算）
  * - Status: 订单状态
  * - SettledPrice: 结算价格（结算时记录）
- * - SettledPnL: 结算盈亏（结算时记录）
+ * - SettledPnL: 订单总盈亏（结算时记录，含部分平仓已实现盈亏）
  * - SettledAt: 结算时间
+ * - ClosedWeightG: 风控部分平仓累计克重
+ * - RealizedPnL: 风控部分平仓累计已实现盈亏（已从定金中扣除/计入）
  */
 type Order struct {
 	ID           uint           `gorm:"primarykey"`
@@ -65,8 +68,10 @@ type Order struct {
 	MarginRate   float64        `gorm:"type:decimal(10,2);default:100"`        // 定金率（%）
 	Status       string         `gorm:"type:varchar(20);index;default:'holding'"` // 状态
 	SettledPrice float64        `gorm:"type:decimal(10,4)"`                    // 结算价格
-	SettledPnL   float64        `gorm:"type:decimal(15,2)"`                    // 结算盈亏
+	SettledPnL   float64        `gorm:"type:decimal(15,2)"`                    // 结算盈亏（订单总盈亏）
 	SettledAt    *time.Time     // 结算时间
+	ClosedWeightG float64       `gorm:"type:decimal(10,3);default:0"`                    // 部分平仓累计克重
+	RealizedPnL   float64       `gorm:"column:realized_pnl;type:decimal(15,2);default:0"` // 部分平仓累计已实现盈亏
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
@@ -198,7 +203,7 @@ func (o *Order) CanSettle() bool {
  * Settle 执行订单结算
  * 
  * 结算逻辑：
- * 1. 记录结算价格和结算盈亏
+ * 1. 记录结算价格和订单总盈亏（剩余持仓盈亏 + 部分平仓已实现盈亏）
  * 2. 更新订单状态为已结算
  * 3. 记录结算时间
  * 
@@ -207,7 +212,7 @@ func (o *Order) CanSettle() bool {
  */
 func (o *Order) Settle(settlePrice float64) {
 	o.SettledPrice = settlePrice
-	o.SettledPnL = o.CalculatePnL(settlePrice)
+	o.SettledPnL = o.totalPnL(settlePrice)
 	o.Status = OrderStatusSettled
 	now := time.Now()
 	o.SettledAt = &now
@@ -217,7 +222,7 @@ func (o *Order) Settle(settlePrice float64) {
  * ForceClose 执行强制平仓
  * 
  * 平仓逻辑：
- * 1. 记录平仓价格和最终盈亏
+ * 1. 记录平仓价格和订单总盈亏（剩余持仓盈亏 + 部分平仓已实现盈亏）
  * 2. 更新订单状态为已平仓
  * 3. 记录平仓时间
  * 
@@ -226,8 +231,51 @@ func (o *Order) Settle(settlePrice float64) {
  */
 func (o *Order) ForceClose(closePrice float64) {
 	o.SettledPrice = closePrice
-	o.SettledPnL = o.CalculatePnL(closePrice)
+	o.SettledPnL = o.totalPnL(closePrice)
 	o.Status = OrderStatusClosed
 	now := time.Now()
 	o.SettledAt = &now
 }
+
+/**
+ * totalPnL 按平仓价计算订单总盈亏（剩余持仓盈亏 + 部分平仓已实现盈亏）
+ * 
+ * 部分平仓的已实现盈亏已计入定金，结算入账只按剩余持仓盈亏计算，此处仅用于记录订单总盈亏
+ * 
+ * @param price float64 - 结算/平仓价格
+ * @return float64
+ */
+func (o *Order) totalPnL(price float64) float64 {
+	return math.Round((o.CalculatePnL(price)+o.RealizedPnL)*100) / 100
+}
+
+/**
+ * PartialClose 执行部分平仓
+ * 
+ * 平仓逻辑：
+ * 1. 按平仓价计算被平掉克重的盈亏（已实现盈亏）
+ * 2. 已实现盈亏计入订单定金（亏损则从定金中扣除），定金整体保留在剩余持仓上
+ * 3. 减少持仓克重，累计部分平仓克重和已实现盈亏
+ * 4. 按平仓价重新计算剩余持仓的盈亏和定金率
+ * 
+ * 由于定金不随平仓克重释放，而基础定金（克重 × 10）随克重减少，剩余持仓的定金率会回升
+ * 
+ * @param weightG float64 - 平仓克重（须小于持仓克重）
+ * @param closePrice float64 - 平仓价格
+ * @return float64 - 本次已实现盈亏
+ */
+func (o *Order) PartialClose(weightG, closePrice float64) float64 {
+	if o.WeightG <= 0 {
+		return 0
+	}
+	realized := o.CalculatePnL(closePrice) / o.WeightG * weightG
+	realized = math.Round(realized*100) / 100
+
+	o.Deposit = math.Round((o.Deposit+realized)*100) / 100
+	o.WeightG = math.Round((o.WeightG-weightG)*1000) / 1000
+	o.ClosedWeightG = math.Round((o.ClosedWeightG+weightG)*1000) / 1000
+	o.RealizedPnL = math.Round((o.RealizedPnL+realized)*100) / 100
+	o.UpdatePnLAndMargin(closePrice)
+
+	return realized
+}
@@ -0,0 +1,101 @@
+/**
+ * 在线支付单模型
+ *
+ * 用途：
+ * - 记录客户通过支付通道发起的在线充值
+ * - 支付通道回调或主动查单确认已支付后自动生成充值记录并入账
+ *
+ * 状态流转：
+ * - pending → paid：通道确认支付成功且金额一致，已入账（超过充值双人审批阈值的，充值申请待超级管理员复核后入账）
+ * - pending → abnormal：通道确认支付成功但金额不一致，不入账，需人工核查
+ * - abnormal → paid：管理员核查后按实付金额入账
+ * - abnormal → closed：管理员核查后关闭，不入账（如已原路退款）
+ * - pending → failed：通道返回支付失败/关闭
+ * - pending → expired：超过有效期且查单仍未支付
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 支付单状态常量
+ */
+const (
+	PaymentStatusPending  = "pending"  // 待支付
+	PaymentStatusPaid     = "paid"     // 已支付（已入账或充值申请待复核）
+	PaymentStatusFailed   = "failed"   // 支付失败
+	PaymentStatusExpired  = "expired"  // 已过期
+	PaymentStatusAbnormal = "abnormal" // 金额不符，待人工核查
+	PaymentStatusClosed   = "closed"   // 金额异常已核查关闭，不入账
+)
+
+/**
+ * 支付结果来源常量
+ */
+const (
+	PaymentNotifyCallback = "callback" // 通道异步回调
+	PaymentNotifyQuery    = "query"    // 主动查单
+)
+
+/**
+ * PaymentOrder 在线支付单实体
+ *
+ * 字段说明：
+ * - OrderNo: 商户支付单号（提交给通道，全局唯一）
+ * - Provider: 支付通道名称
+ * - Amount/PaidAmount: 下单金额/通道确认的实付金额
+ * - TradeNo: 通道交易号
+ * - PayURL: 支付页面地址
+ * - DepositID: 入账后生成的充值记录
+ * - NotifySource: 确认支付结果的来源（回调/查单）
+ * - QueryCount/LastQueryAt: 主动查单次数和最近查单时间
+ * - ExpireAt: 支付单过期时间
+ * - ResolverID/ResolveNote/ResolvedAt: 金额异常的核查人、核查说明、核查时间
+ */
+type PaymentOrder struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	OrderNo      string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"order_no"` // 商户支付单号
+	UserID       uint       `gorm:"index;not null" json:"user_id"`                         // 用户ID
+	Provider     string     `gorm:"type:varchar(50);not null" json:"provider"`             // 支付通道
+	Amount       float64    `gorm:"type:decimal(15,2);not null" json:"amount"`             // 下单金额
+	PaidAmount   float64    `gorm:"type:decimal(15,2);default:0" json:"paid_amount"`       // 实付金额
+	Status       string     `gorm:"type:varchar(20);index;not null" json:"status"`         // 状态
+	TradeNo      string     `gorm:"type:varchar(100)" json:"trade_no"`                     // 通道交易号
+	PayURL       string     `gorm:"type:varchar(1000)" json:"pay_url"`                     // 支付页面地址
+	DepositID    uint       `gorm:"default:0" json:"deposit_id"`                           // 充值记录ID
+	NotifySource string     `gorm:"type:varchar(20)" json:"notify_source"`                 // 结果来源
+	QueryCount   int        `gorm:"default:0" json:"query_count"`                          // 查单次数
+	LastQueryAt  *time.Time `json:"last_query_at,omitempty"`                               // 最近查单时间
+	ExpireAt     time.Time  `gorm:"index" json:"expire_at"`                                // 过期时间
+	PaidAt       *time.Time `json:"paid_at,omitempty"`                                     // 支付确认时间
+	Remark       string     `gorm:"type:varchar(500)" json:"remark"`                       // 备注（失败/异常原因）
+	ResolverID   uint       `gorm:"default:0" json:"resolver_id"`                          // 异常核查人
+	ResolveNote  string     `gorm:"type:varchar(500)" json:"resolve_note"`                 // 异常核查说明
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`                                 // 异常核查时间
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+/**
+ * IsAbnormal 判断是否金额异常待核查
+ *
+ * @return bool
+ */
+func (p *PaymentOrder) IsAbnormal() bool {
+	return p.Status == PaymentStatusAbnormal
+}
+
+/**
+ * IsPending 判断是否待支付
+ *
+ * @return bool
+ */
+func (p *PaymentOrder) IsPending() bool {
+	return p.Status == PaymentStatusPending
+}
@@ -0,0 +1,60 @@
+/**
+ * 价格提醒模型
+ *
+ * 用途：
+ * - 客户设置价格提醒（突破上限、跌破下限、当日涨跌幅达到阈值）
+ * - 行情触发后通过站内通知和通知WebSocket推送，客户无需一直打开APP
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * 价格提醒类型常量
+ */
+const (
+	PriceAlertTypeAbove       = "above"        // 价格上涨到目标价及以上
+	PriceAlertTypeBelow       = "below"        // 价格下跌到目标价及以下
+	PriceAlertTypePercentMove = "percent_move" // 相对当日开盘价涨跌幅达到阈值
+)
+
+/**
+ * PriceAlert 价格提醒实体
+ *
+ * 字段说明：
+ * - Symbol: 品种代码（默认 AU9999）
+ * - Type: 提醒类型（above/below/percent_move）
+ * - TargetPrice: 目标价（above/below，元/克）
+ * - Percent: 涨跌幅阈值（percent_move，%）
+ * - Recurring: 是否重复提醒（否则触发一次后自动停用）
+ * - Enabled: 是否启用
+ * - Armed: 是否待触发（重复提醒触发后需价格回到目标价另一侧才会再次触发）
+ * - TriggerCount/LastTriggeredAt/LastTriggerPrice: 触发记录
+ * - Note: 客户备注
+ */
+type PriceAlert struct {
+	ID               uint           `gorm:"primarykey" json:"id"`
+	UserID           uint           `gorm:"index;not null" json:"user_id"`                 // 用户ID
+	Symbol           string         `gorm:"type:varchar(20);index;not null" json:"symbol"` // 品种代码
+	Type             string         `gorm:"type:varchar(20);not null" json:"type"`         // 提醒类型
+	TargetPrice      float64        `gorm:"type:decimal(10,4)" json:"target_price"`        // 目标价
+	Percent          float64        `gorm:"type:decimal(6,2)" json:"percent"`              // 涨跌幅阈值（%）
+	Recurring        bool           `gorm:"default:false" json:"recurring"`                // 是否重复提醒
+	Enabled          bool           `gorm:"index" json:"enabled"`                          // 是否启用
+	Armed            bool           `json:"armed"`                                         // 是否待触发
+	TriggerCount     int            `gorm:"default:0" json:"trigger_count"`                // 触发次数
+	LastTriggeredAt  *time.Time     `json:"last_triggered_at,omitempty"`                   // 最近触发时间
+	LastTriggerPrice float64        `gorm:"type:decimal(10,4)" json:"last_trigger_price"`  // 最近触发价格
+	Note             string         `gorm:"type:varchar(100)" json:"note"`                 // 备注
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
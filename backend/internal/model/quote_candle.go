@@ -0,0 +1,154 @@
+/**
+ * K线模型
+ *
+ * 用途：
+ * - 按品种和周期聚合行情Tick为OHLC K线
+ * - 支持前端历史走势图查询
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * K线周期
+ */
+const (
+	CandleInterval1m  = "1m"
+	CandleInterval5m  = "5m"
+	CandleInterval15m = "15m"
+	CandleInterval1h  = "1h"
+	CandleInterval1d  = "1d"
+)
+
+/**
+ * CandleIntervals 支持的K线周期（从小到大）
+ */
+var CandleIntervals = []string{
+	CandleInterval1m,
+	CandleInterval5m,
+	CandleInterval15m,
+	CandleInterval1h,
+	CandleInterval1d,
+}
+
+var candleDurations = map[string]time.Duration{
+	CandleInterval1m:  time.Minute,
+	CandleInterval5m:  5 * time.Minute,
+	CandleInterval15m: 15 * time.Minute,
+	CandleInterval1h:  time.Hour,
+	CandleInterval1d:  24 * time.Hour,
+}
+
+/**
+ * CandleDuration 获取K线周期时长
+ *
+ * @param interval string - 周期
+ * @return (time.Duration, bool) - 时长、是否为支持的周期
+ */
+func CandleDuration(interval string) (time.Duration, bool) {
+	d, ok := candleDurations[interval]
+	return d, ok
+}
+
+/**
+ * CandleOpenTime 计算时间点所属K线的开始时间
+ *
+ * 日K按本地时间零点切分，其余周期按整分钟/整小时对齐
+ *
+ * @param t time.Time - 时间点
+ * @param interval string - 周期
+ * @return time.Time
+ */
+func CandleOpenTime(t time.Time, interval string) time.Time {
+	t = t.In(time.Local)
+	if interval == CandleInterval1d {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+	return t.Truncate(candleDurations[interval])
+}
+
+/**
+ * QuoteCandle K线实体
+ *
+ * 字段说明：
+ * - Symbol + Interval + OpenTime 唯一确定一根K线
+ * - Open/High/Low/Close: 基于销售价（Sell）
+ * - TickCount: 该K线内的价格变动次数
+ */
+type QuoteCandle struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	Symbol    string    `gorm:"type:varchar(20);uniqueIndex:idx_candle_key,priority:1;not null" json:"symbol"`                // 品种代码
+	Interval  string    `gorm:"column:period;type:varchar(8);uniqueIndex:idx_candle_key,priority:2;not null" json:"interval"` // 周期
+	OpenTime  time.Time `gorm:"uniqueIndex:idx_candle_key,priority:3;not null" json:"time"`                                   // 开始时间
+	Open      float64   `gorm:"type:decimal(10,4)" json:"open"`                                                               // 开盘价
+	High      float64   `gorm:"type:decimal(10,4)" json:"high"`                                                               // 最高价
+	Low       float64   `gorm:"type:decimal(10,4)" json:"low"`                                                                // 最低价
+	Close     float64   `gorm:"type:decimal(10,4)" json:"close"`                                                              // 收盘价
+	TickCount int       `gorm:"default:0" json:"ticks"`                                                                       // 价格变动次数
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+/**
+ * NewQuoteCandle 以首个价格创建K线
+ *
+ * @param symbol string - 品种代码
+ * @param interval string - 周期
+ * @param openTime time.Time - 开始时间
+ * @param price float64 - 首个价格
+ * @return *QuoteCandle
+ */
+func NewQuoteCandle(symbol, interval string, openTime time.Time, price float64) *QuoteCandle {
+	return &QuoteCandle{
+		Symbol:    symbol,
+		Interval:  interval,
+		OpenTime:  openTime,
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+		TickCount: 1,
+	}
+}
+
+/**
+ * Merge 合并同一周期已落库的部分（服务重启或主备切换后，新开K线与库中数据合并）
+ *
+ * 开盘价取已落库部分，收盘价保持当前，最高/最低价和变动次数累计
+ *
+ * @param earlier *QuoteCandle - 已落库的同一周期K线
+ * @return void
+ */
+func (c *QuoteCandle) Merge(earlier *QuoteCandle) {
+	c.Open = earlier.Open
+	if earlier.High > c.High {
+		c.High = earlier.High
+	}
+	if earlier.Low < c.Low {
+		c.Low = earlier.Low
+	}
+	c.TickCount += earlier.TickCount
+}
+
+/**
+ * Apply 将新价格计入K线
+ *
+ * @param price float64 - 价格
+ * @return void
+ */
+func (c *QuoteCandle) Apply(price float64) {
+	if price > c.High {
+		c.High = price
+	}
+	if price < c.Low {
+		c.Low = price
+	}
+	c.Close = price
+	c.TickCount++
+}
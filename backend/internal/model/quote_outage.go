@@ -0,0 +1,72 @@
+/**
+ * 行情中断（降级模式）记录模型
+ *
+ * 用途：
+ * - 记录每一次因行情过期进入降级模式的时间段
+ * - 支持事后审计和故障复盘
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * 平台行情状态常量
+ */
+const (
+	MarketStatusNormal   = "normal"   // 行情正常
+	MarketStatusDegraded = "degraded" // 降级模式（行情过期，暂停下单和结算）
+)
+
+/**
+ * QuoteOutage 行情中断记录实体
+ *
+ * 字段说明：
+ * - StartedAt: 进入降级模式时间
+ * - EndedAt: 恢复正常时间（为空表示仍在降级中）
+ * - LastQuoteAt: 进入降级时最后一次收到行情的时间
+ * - LastPrice: 进入降级时最后一次的价格
+ * - ThresholdSeconds: 判定过期的阈值（秒）
+ * - DurationSeconds: 降级持续时长（秒，恢复时写入）
+ * - Reason: 进入降级的原因
+ */
+type QuoteOutage struct {
+	ID               uint           `gorm:"primarykey" json:"id"`
+	StartedAt        time.Time      `gorm:"index;not null" json:"started_at"`     // 开始时间
+	EndedAt          *time.Time     `json:"ended_at,omitempty"`                   // 结束时间
+	LastQuoteAt      *time.Time     `json:"last_quote_at,omitempty"`              // 最后行情时间
+	LastPrice        float64        `gorm:"type:decimal(10,4)" json:"last_price"` // 最后价格
+	ThresholdSeconds int            `gorm:"default:0" json:"threshold_seconds"`   // 过期阈值（秒）
+	DurationSeconds  int            `gorm:"default:0" json:"duration_seconds"`    // 持续时长（秒）
+	Reason           string         `gorm:"type:varchar(500)" json:"reason"`      // 原因
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+/**
+ * IsOngoing 判断降级是否仍在持续
+ *
+ * @return bool
+ */
+func (q *QuoteOutage) IsOngoing() bool {
+	return q.EndedAt == nil
+}
+
+/**
+ * Close 结束降级记录
+ *
+ * @param endedAt time.Time - 恢复时间
+ * @return void
+ */
+func (q *QuoteOutage) Close(endedAt time.Time) {
+	q.EndedAt = &endedAt
+	q.DurationSeconds = int(endedAt.Sub(q.StartedAt).Seconds())
+}
@@ -0,0 +1,54 @@
+/**
+ * 异常Tick隔离日志模型
+ *
+ * 用途：
+ * - 记录被行情校验拒绝的Tick（偏离中位数、推送过快、多源不一致等）
+ * - 供管理员复核，排查行情源质量问题
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 隔离原因常量
+ */
+const (
+	QuarantineReasonCrossed        = "crossed"         // 回购价高于销售价
+	QuarantineReasonTooFrequent    = "too_frequent"    // 推送间隔过短
+	QuarantineReasonDeviation      = "deviation"       // 偏离滚动中位数过大
+	QuarantineReasonSourceDisagree = "source_disagree" // 与其他行情源报价不一致
+)
+
+/**
+ * QuoteQuarantine 异常Tick隔离记录实体
+ *
+ * 字段说明：
+ * - Symbol/Source/Buy/Sell: 被拒绝的Tick
+ * - Reference: 校验时的参考价（滚动中位数或其他行情源中位数）
+ * - Reason: 隔离原因
+ * - Detail: 说明（偏差比例等）
+ * - TickAt: 收到Tick的时间
+ * - Reviewed/ReviewedBy/ReviewedAt/ReviewNote: 管理员复核信息
+ */
+type QuoteQuarantine struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	Symbol     string     `gorm:"type:varchar(20);index;not null" json:"symbol"` // 品种代码
+	Source     string     `gorm:"type:varchar(50);index" json:"source"`          // 行情源
+	Buy        float64    `gorm:"type:decimal(10,4)" json:"buy"`                 // 回购价
+	Sell       float64    `gorm:"type:decimal(10,4)" json:"sell"`                // 销售价
+	Reference  float64    `gorm:"type:decimal(10,4)" json:"reference"`           // 参考价
+	Reason     string     `gorm:"type:varchar(30);index;not null" json:"reason"` // 隔离原因
+	Detail     string     `gorm:"type:varchar(255)" json:"detail"`               // 说明
+	TickAt     time.Time  `gorm:"index;not null" json:"tick_at"`                 // 行情时间
+	Reviewed   bool       `gorm:"index;default:false" json:"reviewed"`           // 是否已复核
+	ReviewedBy uint       `gorm:"default:0" json:"reviewed_by"`                  // 复核管理员ID
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`                         // 复核时间
+	ReviewNote string     `gorm:"type:varchar(255)" json:"review_note"`          // 复核备注
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+/**
+ * 行情源状态模型
+ *
+ * 用途：
+ * - 行情代理各上游连接的运行状态和计数（管理后台查看）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 行情源连接状态常量
+ */
+const (
+	QuoteSourceStateConnecting = "connecting" // 正在连接
+	QuoteSourceStateConnected  = "connected"  // 已连接
+	QuoteSourceStateRetrying   = "retrying"   // 等待重连（退避中）
+)
+
+/**
+ * QuoteSourceStatus 行情源状态
+ */
+type QuoteSourceStatus struct {
+	Name              string     `json:"name"`
+	Type              string     `json:"type"`
+	Priority          int        `json:"priority"`
+	Active            bool       `json:"active"`              // 是否为当前使用的行情源
+	State             string     `json:"state"`               // 连接状态
+	Connected         bool       `json:"connected"`           // 是否已连接
+	Score             int        `json:"score"`               // 健康分（0-100）
+	ConnectedAt       *time.Time `json:"connected_at"`        // 本次连接建立时间
+	NextRetryAt       *time.Time `json:"next_retry_at"`       // 下次重连时间（退避中）
+	Reconnects        int        `json:"reconnects"`          // 累计重连次数
+	MessageCount      int64      `json:"message_count"`       // 累计收到消息数
+	MessagesPerSecond float64    `json:"messages_per_second"` // 近10秒平均每秒消息数
+	LastPrice         float64    `json:"last_price"`          // 最近一次价格
+	LastPriceAt       *time.Time `json:"last_price_at"`       // 最近一次价格时间
+	LastMessageAt     *time.Time `json:"last_message_at"`     // 最近一次消息时间
+	RecentFailures    int        `json:"recent_failures"`     // 近10分钟失败次数
+	LastError         string     `json:"last_error"`          // 最近一次错误
+}
@@ -0,0 +1,35 @@
+/**
+ * 行情Tick归档模型
+ *
+ * 用途：
+ * - 归档每个品种的价格变动（审计、K线重建）
+ * - 作为行情代理向内部模块分发的标准化Tick
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * QuoteTick 行情Tick实体
+ *
+ * 字段说明：
+ * - Symbol: 品种代码（大写，如 AU9999）
+ * - Buy: 回购价（用户卖出价）
+ * - Sell: 销售价（用户买入价，平台统一使用的价格）
+ * - Source: 行情源名称
+ * - TickAt: 收到行情的时间
+ */
+type QuoteTick struct {
+	ID     uint      `gorm:"primarykey" json:"id"`
+	Symbol string    `gorm:"type:varchar(20);index:idx_tick_symbol_time,priority:1;not null" json:"symbol"` // 品种代码
+	Buy    float64   `gorm:"type:decimal(10,4)" json:"buy"`                                                 // 回购价
+	Sell   float64   `gorm:"type:decimal(10,4)" json:"sell"`                                                // 销售价
+	Source string    `gorm:"type:varchar(50)" json:"source"`                                                // 行情源
+	TickAt time.Time `gorm:"index:idx_tick_symbol_time,priority:2;not null" json:"tick_at"`                 // 行情时间
+}
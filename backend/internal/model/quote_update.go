@@ -0,0 +1,33 @@
+/**
+ * 标准化报价模型
+ *
+ * 用途：
+ * - 行情代理对外推送的品种报价（WebSocket、SSE、REST 共用同一结构）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+/**
+ * QuoteUpdate 标准化品种报价
+ *
+ * 字段说明：
+ * - Bid: 平台回购价（用户卖出价）= 上游回购价 - 卖出点差
+ * - Ask: 平台销售价（用户买入价）= 上游销售价 + 买入点差
+ * - Last: 上游最新价（与风控使用的价格一致，不含点差）
+ * - High/Low/Change: 上游提供时透传（最高/最低/涨跌）
+ * - Source: 行情源名称
+ */
+type QuoteUpdate struct {
+	Symbol    string  `json:"symbol"`
+	Bid       float64 `json:"bid"`
+	Ask       float64 `json:"ask"`
+	Last      float64 `json:"last"`
+	High      float64 `json:"high,omitempty"`
+	Low       float64 `json:"low,omitempty"`
+	Change    float64 `json:"change"`
+	Source    string  `json:"source,omitempty"`
+	Timestamp int64   `json:"ts"` // 毫秒时间戳
+}
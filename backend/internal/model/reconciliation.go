@@ -0,0 +1,99 @@
+/**
+ * 资金对账模型
+ *
+ * 用途：
+ * - 记录每次对账任务（每日定时 / 管理员手动对单个客户）
+ * - 记录对账发现的不一致（对账报告），供管理员核查、标记处理
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 对账触发方式常量
+ */
+const (
+	ReconciliationTriggerScheduled = "scheduled" // 每日定时
+	ReconciliationTriggerManual    = "manual"    // 管理员手动
+)
+
+/**
+ * 对账任务状态常量
+ */
+const (
+	ReconciliationStatusRunning  = "running"  // 执行中
+	ReconciliationStatusOK       = "ok"       // 全部一致
+	ReconciliationStatusMismatch = "mismatch" // 存在不一致
+	ReconciliationStatusFailed   = "failed"   // 执行失败
+)
+
+/**
+ * 对账检查项常量
+ */
+const (
+	ReconcileCheckUsedDeposit = "used_deposit" // 已用定金 = 持仓订单定金合计
+	ReconcileCheckFundLog     = "fund_log"     // 最近一条资金流水的变动后余额 = 当前余额
+	ReconcileCheckEquity      = "equity"       // 充值 - 提现 + 已实现盈亏 + 余额调整 = 可用 + 已用
+	ReconcileCheckLedger      = "ledger"       // 总账试算平衡、客户余额与总账一致
+)
+
+/**
+ * ReconciliationRun 对账任务实体
+ *
+ * 字段说明：
+ * - TriggerType: 触发方式
+ * - UserID: 对账客户（0表示全部客户）
+ * - OperatorID: 手动触发的管理员
+ * - CheckedUsers: 已核对客户数
+ * - MismatchCount: 不一致条数
+ * - Status: 任务状态
+ * - Error: 执行失败原因
+ */
+type ReconciliationRun struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	TriggerType   string     `gorm:"type:varchar(20);index;not null" json:"trigger_type"` // 触发方式
+	UserID        uint       `gorm:"index;default:0" json:"user_id"`                      // 对账客户（0为全部）
+	OperatorID    uint       `gorm:"default:0" json:"operator_id"`                        // 手动触发的管理员
+	CheckedUsers  int        `gorm:"default:0" json:"checked_users"`                      // 已核对客户数
+	MismatchCount int        `gorm:"default:0" json:"mismatch_count"`                     // 不一致条数
+	Status        string     `gorm:"type:varchar(20);index;not null" json:"status"`       // 任务状态
+	Error         string     `gorm:"type:varchar(500)" json:"error"`                      // 失败原因
+	StartedAt     time.Time  `gorm:"index;not null" json:"started_at"`                    // 开始时间
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`                               // 结束时间
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+/**
+ * ReconciliationMismatch 对账不一致记录实体（对账报告）
+ *
+ * 字段说明：
+ * - RunID: 所属对账任务
+ * - UserID: 客户ID（总账试算不平衡等平台级问题为0）
+ * - CheckItem: 检查项
+ * - Expected: 按历史记录推算的金额
+ * - Actual: 当前金额
+ * - Difference: 差额（Actual - Expected）
+ * - Detail: 说明
+ * - Resolved/ResolvedBy/ResolvedAt/ResolveNote: 管理员处理信息
+ */
+type ReconciliationMismatch struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	RunID       uint       `gorm:"index;not null" json:"run_id"`                      // 对账任务ID
+	UserID      uint       `gorm:"index;default:0" json:"user_id"`                    // 客户ID
+	CheckItem   string     `gorm:"type:varchar(20);index;not null" json:"check_item"` // 检查项
+	Expected    float64    `gorm:"type:decimal(15,2)" json:"expected"`                // 推算金额
+	Actual      float64    `gorm:"type:decimal(15,2)" json:"actual"`                  // 当前金额
+	Difference  float64    `gorm:"type:decimal(15,2)" json:"difference"`              // 差额
+	Detail      string     `gorm:"type:varchar(500)" json:"detail"`                   // 说明
+	Resolved    bool       `gorm:"index;default:false" json:"resolved"`               // 是否已处理
+	ResolvedBy  uint       `gorm:"default:0" json:"resolved_by"`                      // 处理管理员
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`                             // 处理时间
+	ResolveNote string     `gorm:"type:varchar(255)" json:"resolve_note"`             // 处理备注
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,54 @@
+/**
+ * 定时任务租约模型
+ *
+ * 用途：
+ * - 多实例部署时基于数据库选主
+ * - 持有未过期租约的实例负责执行定时任务
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * 租约名称常量
+ */
+const (
+	LeaseNameScheduler = "scheduler" // 定时任务（风控等）主实例租约
+)
+
+/**
+ * SchedulerLease 定时任务租约实体
+ *
+ * 字段说明：
+ * - Name: 租约名称（唯一）
+ * - HolderID: 当前持有者实例ID
+ * - AcquiredAt: 当前持有者获得租约的时间
+ * - RenewedAt: 最近一次续约（心跳）时间
+ * - ExpiresAt: 租约过期时间，过期后其他实例可接管
+ */
+type SchedulerLease struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	Name       string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"` // 租约名称
+	HolderID   string    `gorm:"type:varchar(100)" json:"holder_id"`                // 持有者实例ID
+	AcquiredAt time.Time `json:"acquired_at"`                                       // 获得时间
+	RenewedAt  time.Time `json:"renewed_at"`                                        // 最近续约时间
+	ExpiresAt  time.Time `gorm:"index" json:"expires_at"`                           // 过期时间
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+/**
+ * IsExpired 判断租约是否已过期
+ *
+ * @param now time.Time - 当前时间
+ * @return bool
+ */
+func (l *SchedulerLease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
@@ -0,0 +1,87 @@
+/**
+ * 存储文件模型
+ *
+ * 用途：
+ * - 记录上传到文件存储的凭证、证件照片等文件
+ * - 业务表只保存文件引用（file:<内容哈希>），不再保存 Base64 内容
+ *
+ * 说明：
+ * - 按原始内容的 SHA-256 去重，同一文件多次上传只保存一份
+ * - 图片上传后压缩保存，并生成缩略图；其他类型（如PDF）按原文件保存
+ * - 下载使用带签名的限时链接，由文件服务在返回业务数据时生成
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+/**
+ * 文件用途常量
+ */
+const (
+	FileCategoryDepositVoucher  = "deposit_voucher"  // 付定金付款凭证
+	FileCategoryDepositReceipt  = "deposit_receipt"  // 管理员收款凭证
+	FileCategoryWithdrawVoucher = "withdraw_voucher" // 退定金打款凭证
+	FileCategoryIDCard          = "id_card"          // 身份证照片
+	FileCategoryAdjustment      = "adjustment"       // 余额调整附件
+	FileCategoryOther           = "other"            // 其他
+)
+
+// FileRefPrefix 业务表中文件引用的前缀
+const FileRefPrefix = "file:"
+
+/**
+ * StoredFile 存储文件实体
+ *
+ * 字段说明：
+ * - Hash: 原始内容 SHA-256（去重键，也用于文件引用和下载地址）
+ * - StorageKey/ThumbKey: 存储后端中的文件键/缩略图键（非图片无缩略图）
+ * - Size/OriginalSize: 保存后大小/上传原始大小（字节）
+ * - Driver: 保存时使用的存储后端
+ */
+type StoredFile struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	Hash         string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"hash"` // 内容哈希
+	Category     string    `gorm:"type:varchar(30);index" json:"category"`            // 用途
+	FileName     string    `gorm:"type:varchar(255)" json:"file_name"`                // 原始文件名
+	ContentType  string    `gorm:"type:varchar(100)" json:"content_type"`             // 内容类型
+	Size         int64     `json:"size"`                                              // 保存后大小
+	OriginalSize int64     `json:"original_size"`                                     // 原始大小
+	Width        int       `json:"width"`                                             // 图片宽度
+	Height       int       `json:"height"`                                            // 图片高度
+	StorageKey   string    `gorm:"type:varchar(255);not null" json:"-"`               // 存储键
+	ThumbKey     string    `gorm:"type:varchar(255)" json:"-"`                        // 缩略图键
+	Driver       string    `gorm:"type:varchar(20)" json:"driver"`                    // 存储后端
+	UploaderID   uint      `gorm:"index" json:"uploader_id"`                          // 上传人（0为系统迁移/未登录提交）
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+/**
+ * FileRef 文件引用（保存在业务表中）
+ *
+ * @param hash string - 内容哈希
+ * @return string
+ */
+func FileRef(hash string) string {
+	return FileRefPrefix + hash
+}
+
+/**
+ * ParseFileRef 解析文件引用
+ *
+ * @param ref string - 文件引用
+ * @return (string, bool) - 内容哈希，是否为文件引用
+ */
+func ParseFileRef(ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if !strings.HasPrefix(ref, FileRefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ref, FileRefPrefix), true
+}
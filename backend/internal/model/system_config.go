@@ -59,7 +59,7 @@ const (
 	// 交易相关
 	ConfigKeyMinDeposit        = "min_deposit"         // 最小充值金额
 	ConfigKeyMinWithdraw       = "min_withdraw"        // 最小提现金额
-	ConfigKeyWithdrawFeeRate   = "withdraw_fee_rate"   // 提现手续费率
+	ConfigKeyWithdrawFeeRate   = "withdraw_fee_rate"   // 提现手续费率（%）
 	ConfigKeyMinOrderAmount    = "min_order_amount"    // 最小下单金额
 	
 	// 时间相关
@@ -78,4 +78,47 @@ const (
 	// 自动补定金相关
 	ConfigKeyAutoSupplementTrigger = "auto_supplement_trigger" // 自动补定金触发阈值（默认50%）
 	ConfigKeyAutoSupplementTarget  = "auto_supplement_target"  // 自动补定金目标阈值（默认100%）
+	
+	// 追保相关
+	ConfigKeyMarginCallRate         = "margin_call_rate"          // 追保线（%，低于则发起追保，默认20）
+	ConfigKeyForceCloseFloorRate    = "force_close_floor_rate"    // 强平底线（%，低于则立即强平，默认10）
+	ConfigKeyMarginCallGraceMinutes = "margin_call_grace_minutes" // 追保补足期限（分钟，默认120）
+	
+	// 部分平仓相关
+	ConfigKeyPartialCloseMinWeight   = "partial_close_min_weight"   // 启用部分平仓的最小持仓克重（默认1000，0表示关闭）
+	ConfigKeyPartialCloseRestoreRate = "partial_close_restore_rate" // 部分平仓后恢复到的定金率（%，默认30，须高于追保线）
+	ConfigKeyPartialCloseStepGrams   = "partial_close_step_grams"   // 部分平仓克重步长（克，默认1）
+	
+	// 行情保护相关
+	ConfigKeyQuoteStaleSeconds = "quote_stale_seconds" // 行情过期阈值（秒，超过则进入降级模式，默认30）
+	
+	// 价格提醒相关
+	ConfigKeyPriceAlertMaxPerUser = "price_alert_max_per_user" // 每个用户最多启用的价格提醒数（默认20）
+	
+	// 资金对账相关
+	ConfigKeyReconciliationHour = "reconciliation_hour" // 每日对账时间（点，0-23，默认2）
+	
+	// 提现策略相关（金额单位：元；限额类0或不配置表示不限制）
+	ConfigKeyMinWithdrawAmount     = "min_withdraw_amount"     // 单笔最低提现金额（兼容旧键 min_withdraw）
+	ConfigKeyWithdrawFeeFixed      = "withdraw_fee_fixed"      // 单笔固定手续费
+	ConfigKeyWithdrawFeeMin        = "withdraw_fee_min"        // 单笔手续费下限
+	ConfigKeyWithdrawFeeMax        = "withdraw_fee_max"        // 单笔手续费上限
+	ConfigKeyWithdrawDailyCount    = "withdraw_daily_count"    // 每日提现次数上限
+	ConfigKeyWithdrawDailyAmount   = "withdraw_daily_amount"   // 每日提现金额上限
+	ConfigKeyWithdrawMonthlyCount  = "withdraw_monthly_count"  // 每月提现次数上限
+	ConfigKeyWithdrawMonthlyAmount = "withdraw_monthly_amount" // 每月提现金额上限
+	ConfigKeyWithdrawFreePerMonth  = "withdraw_free_per_month" // 每月免手续费次数
+
+	// 双人审批相关（超过金额需超级管理员复核，0表示不启用）
+	ConfigKeyWithdrawDualApprovalAmount = "withdraw_dual_approval_amount" // 提现双人审批金额阈值
+	ConfigKeyDepositDualApprovalAmount  = "deposit_dual_approval_amount"  // 充值双人审批金额阈值
+	ConfigKeyAdjustmentApprovalAmount   = "adjustment_approval_amount"    // 手工余额调整需超级管理员审批的金额阈值（按调整金额绝对值，默认10000，不可关闭）
+
+	// 银行流水匹配相关
+	ConfigKeyStatementAutoConfidence    = "statement_auto_confidence"    // 自动入账置信度（0-100，默认90；超过100表示不自动入账）
+	ConfigKeyStatementProposeConfidence = "statement_propose_confidence" // 建议匹配置信度（0-100，默认60）
+	ConfigKeyStatementMatchWindowHours  = "statement_match_window_hours" // 流水与充值申请时间差上限（小时，默认72）
 )
+
+// DefaultAdjustmentApprovalAmount 手工余额调整需超级管理员审批的默认金额阈值（未配置或配置无效时使用）
+const DefaultAdjustmentApprovalAmount = 10000.0
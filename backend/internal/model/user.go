@@ -16,6 +16,7 @@ type User struct {
 	SalesID          uint           `gorm:"index"`                                     // 归属销售ID
 	AvailableDeposit float64        `gorm:"type:decimal(15,2);default:0"`             // 可用定金
 	UsedDeposit      float64        `gorm:"type:decimal(15,2);default:0"`             // 已用定金（冻结）
+	WithdrawFrozen   float64        `gorm:"type:decimal(15,2);default:0"`             // 提现冻结（提现申请待审核）
 
 	PayPassword    string `gorm:"type:varchar(255)"` // 支付密码（单独加密存储）
 	HasPayPassword bool   `gorm:"default:false"`
@@ -0,0 +1,49 @@
+/**
+ * 提现策略覆盖模型
+ *
+ * 用途：
+ * - 为个别客户（VIP等）单独设置提现手续费、最低金额、限额、每月免费次数
+ * - 字段为空表示沿用系统配置中的全局策略
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package model
+
+import (
+	"time"
+)
+
+/**
+ * WithdrawPolicyOverride 客户提现策略覆盖实体
+ *
+ * 字段说明（均为可空，空表示沿用全局配置；限额类填0表示不限制）：
+ * - FeeFixed: 单笔固定手续费（元）
+ * - FeeRate: 按金额比例手续费（%）
+ * - FeeMin/FeeMax: 单笔手续费下限/上限（元）
+ * - MinAmount: 单笔最低提现金额（元）
+ * - DailyCount/DailyAmount: 每日提现次数/金额上限
+ * - MonthlyCount/MonthlyAmount: 每月提现次数/金额上限
+ * - FreePerMonth: 每月免手续费次数
+ * - Note: 备注（如 VIP 等级）
+ * - OperatorID: 最后修改的管理员
+ */
+type WithdrawPolicyOverride struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	UserID        uint      `gorm:"uniqueIndex;not null" json:"user_id"`      // 客户ID
+	FeeFixed      *float64  `gorm:"type:decimal(15,2)" json:"fee_fixed"`      // 单笔固定手续费
+	FeeRate       *float64  `gorm:"type:decimal(8,4)" json:"fee_rate"`        // 手续费比例（%）
+	FeeMin        *float64  `gorm:"type:decimal(15,2)" json:"fee_min"`        // 手续费下限
+	FeeMax        *float64  `gorm:"type:decimal(15,2)" json:"fee_max"`        // 手续费上限
+	MinAmount     *float64  `gorm:"type:decimal(15,2)" json:"min_amount"`     // 单笔最低金额
+	DailyCount    *int      `json:"daily_count"`                              // 每日次数上限
+	DailyAmount   *float64  `gorm:"type:decimal(15,2)" json:"daily_amount"`   // 每日金额上限
+	MonthlyCount  *int      `json:"monthly_count"`                            // 每月次数上限
+	MonthlyAmount *float64  `gorm:"type:decimal(15,2)" json:"monthly_amount"` // 每月金额上限
+	FreePerMonth  *int      `json:"free_per_month"`                           // 每月免手续费次数
+	Note          string    `gorm:"type:varchar(255)" json:"note"`            // 备注
+	OperatorID    uint      `gorm:"default:0" json:"operator_id"`             // 最后修改的管理员
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
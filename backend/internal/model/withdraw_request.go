@@ -22,10 +22,12 @@ import (
  * 提现状态常量
  */
 const (
-	WithdrawStatusPending  = "pending"  // 待审核
-	WithdrawStatusApproved = "approved" // 已通过
-	WithdrawStatusRejected = "rejected" // 已驳回
-	WithdrawStatusPaid     = "paid"     // 已打款
+	WithdrawStatusPending   = "pending"   // 待审核
+	WithdrawStatusReviewing = "reviewing" // 初审通过，待复核
+	WithdrawStatusApproved  = "approved"  // 已通过
+	WithdrawStatusRejected  = "rejected"  // 已驳回
+	WithdrawStatusPaid      = "paid"      // 已打款
+	WithdrawStatusCancelled = "cancelled" // 用户已取消
 )
 
 /**
@@ -37,7 +39,10 @@ const (
  * - Amount: 提现金额
  * - Fee: 手续费
  * - ActualAmount: 实际到账金额
+ * - FeeDetail: 手续费明细（提交时按提现策略计算）
+ * - Frozen: 提交时是否已冻结资金（早期申请未冻结，审核通过时直接扣可用定金）
  * - Status: 审核状态
+ * - ApprovalChain: 审批链（大额提现需超级管理员复核）
  * - ReviewerID: 审核人ID
  * - ReviewNote: 审核备注
  * - VoucherURL: 打款凭证
@@ -49,7 +54,10 @@ type WithdrawRequest struct {
 	Amount       float64        `gorm:"type:decimal(15,2);not null" json:"amount"` // 提现金额
 	Fee          float64        `gorm:"type:decimal(15,2);default:0" json:"fee"`    // 手续费
 	ActualAmount float64        `gorm:"type:decimal(15,2);not null" json:"actual_amount"` // 实际到账
+	FeeDetail    string         `gorm:"type:varchar(255)" json:"fee_detail"`              // 手续费明细
+	Frozen       bool           `json:"frozen"`                                          // 提交时已冻结资金
 	Status       string         `gorm:"type:varchar(20);index;default:'pending'" json:"status"` // 状态
+	ApprovalChain                                                                                // 审批链
 	ReviewerID   uint           `gorm:"default:0" json:"reviewer_id"`                       // 审核人ID
 	ReviewNote   string         `gorm:"type:varchar(500)" json:"review_note"`               // 审核备注
 	UserNote     string         `gorm:"type:varchar(500)" json:"user_note"`                 // 用户备注
@@ -67,12 +75,21 @@ type WithdrawRequest struct {
  */
 func (w *WithdrawRequest) Approve(reviewerID uint, note string) {
 	now := time.Now()
+	w.ApprovalCount++
 	w.Status = WithdrawStatusApproved
 	w.ReviewerID = reviewerID
 	w.ReviewNote = note
 	w.ReviewedAt = &now
 }
 
+/**
+ * PassStep 通过一级审批（未到最后一级时进入待复核）
+ */
+func (w *WithdrawRequest) PassStep() {
+	w.ApprovalCount++
+	w.Status = WithdrawStatusReviewing
+}
+
 /**
  * Reject 驳回审核
  */
@@ -84,6 +101,13 @@ func (w *WithdrawRequest) Reject(reviewerID uint, note string) {
 	w.ReviewedAt = &now
 }
 
+/**
+ * Cancel 用户取消申请
+ */
+func (w *WithdrawRequest) Cancel() {
+	w.Status = WithdrawStatusCancelled
+}
+
 /**
  * MarkAsPaid 标记为已打款
  */
@@ -101,6 +125,13 @@ func (w *WithdrawRequest) IsPending() bool {
 	return w.Status == WithdrawStatusPending
 }
 
+/**
+ * IsAwaitingApproval 判断是否仍在审批中（待审核或待复核）
+ */
+func (w *WithdrawRequest) IsAwaitingApproval() bool {
+	return w.Status == WithdrawStatusPending || w.Status == WithdrawStatusReviewing
+}
+
 /**
  * IsApproved 判断是否已通过
  */
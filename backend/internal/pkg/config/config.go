@@ -31,6 +31,120 @@ type Config struct {
 		AccessMinutes  int    `yaml:"access_minutes"`
 		RefreshHours   int    `yaml:"refresh_hours"`
 	} `yaml:"auth"`
+
+	// 多实例部署：定时任务选主（租约存于数据库）
+	Cluster struct {
+		InstanceID       string `yaml:"instance_id"`       // 实例标识（为空则使用 主机名-进程号）
+		LeaseSeconds     int    `yaml:"lease_seconds"`     // 租约有效期（秒，默认30）
+		HeartbeatSeconds int    `yaml:"heartbeat_seconds"` // 续约间隔（秒，默认10）
+	} `yaml:"cluster"`
+
+	// 行情源：按优先级排列，主源静默超时后自动切换到备用源
+	Quote struct {
+		SilenceSeconds int                 `yaml:"silence_seconds"` // 行情源静默多久判定失效（秒，默认15）
+		RecordPath     string              `yaml:"record_path"`     // 录制行情到文件（JSON lines，可用于 replay 回放；为空不录制）
+		ClientMaxRate  int                 `yaml:"client_max_rate"` // 每个前端连接每秒最多推送次数（默认2，期间的报价按品种合并）
+		Filter         QuoteFilterConfig   `yaml:"filter"`          // 异常Tick过滤
+		PublicFeed     bool                `yaml:"public_feed"`     // /quotes/latest 和 /quotes/stream 是否免登录（默认需要JWT）
+		// 仅用于本地开发：跳过缺少凭据的 jtd 源，没有可用行情源时使用模拟行情（生产勿开启）
+		SimulateFallback bool              `yaml:"simulate_fallback"`
+		Sources        []QuoteSourceConfig `yaml:"sources"`
+	} `yaml:"quote"`
+
+	// 支付通道：在线付定金（创建支付单 → 通道异步签名回调 → 自动入账）
+	Payment struct {
+		CallbackBaseURL   string                  `yaml:"callback_base_url"`   // 回调地址前缀（通道回调 {callback_base_url}/api/v1/payments/callback/{name}）
+		ReturnURL         string                  `yaml:"return_url"`          // 支付完成后前端跳转地址
+		ExpireMinutes     int                     `yaml:"expire_minutes"`      // 支付单有效期（分钟，默认30）
+		QueryAfterSeconds int                     `yaml:"query_after_seconds"` // 创建后多久仍未收到回调则主动查单（秒，默认60）
+		Providers         []PaymentProviderConfig `yaml:"providers"`
+	} `yaml:"payment"`
+
+	// 文件存储：付款凭证、打款凭证、身份证照片等（数据库只保存文件引用，下载使用带签名的限时链接）
+	Storage struct {
+		Driver           string          `yaml:"driver"`             // local / s3（默认local）
+		LocalDir         string          `yaml:"local_dir"`          // local：存储目录（默认 data/files）
+		SignSecret       string          `yaml:"sign_secret"`        // 下载链接签名密钥（生产环境必填，可用环境变量 STORAGE_SIGN_SECRET；其他环境为空时由 auth.jwt_secret 派生）
+		URLExpireSeconds int             `yaml:"url_expire_seconds"` // 下载链接有效期（秒，默认3600）
+		MaxUploadMB      int             `yaml:"max_upload_mb"`      // 单个文件大小上限（MB，默认10）
+		ImageMaxSide     int             `yaml:"image_max_side"`     // 图片压缩后长边像素上限（默认1920）
+		ThumbMaxSide     int             `yaml:"thumb_max_side"`     // 缩略图长边像素（默认320）
+		S3               StorageS3Config `yaml:"s3"`
+	} `yaml:"storage"`
+}
+
+// StorageS3Config S3兼容对象存储配置（AWS S3、MinIO、阿里云OSS、腾讯云COS等）
+type StorageS3Config struct {
+	Endpoint  string `yaml:"endpoint"`   // 服务地址（如 https://s3.ap-east-1.amazonaws.com、http://127.0.0.1:9000）
+	Region    string `yaml:"region"`     // 区域（签名使用，默认 us-east-1）
+	Bucket    string `yaml:"bucket"`     // 存储桶
+	AccessKey string `yaml:"access_key"` // 访问密钥ID
+	SecretKey string `yaml:"secret_key"` // 访问密钥
+	PathStyle bool   `yaml:"path_style"` // 路径风格访问（MinIO 需开启；否则使用 bucket.endpoint 虚拟主机风格）
+}
+
+// PaymentProviderConfig 单个支付通道配置
+type PaymentProviderConfig struct {
+	Name       string `yaml:"name"`        // 通道名称（回调路径使用，唯一）
+	Type       string `yaml:"type"`        // http / mock
+	Title      string `yaml:"title"`       // 前端展示名称
+	Disabled   bool   `yaml:"disabled"`    // 是否停用
+	MerchantID string `yaml:"merchant_id"` // 商户号
+	Secret     string `yaml:"secret"`      // 签名密钥（HMAC-SHA256）
+	CreateURL  string `yaml:"create_url"`  // http：下单接口
+	QueryURL   string `yaml:"query_url"`   // http：查单接口
+}
+
+// QuoteFilterConfig 异常Tick过滤配置（被拒绝的Tick写入隔离日志）
+type QuoteFilterConfig struct {
+	Disabled          bool    `yaml:"disabled"`             // 是否关闭过滤
+	MaxDeviation      float64 `yaml:"max_deviation"`        // 偏离滚动中位数的最大比例（默认0.02，即2%）
+	MedianWindow      int     `yaml:"median_window"`        // 滚动中位数窗口（Tick数，默认20）
+	ConfirmTicks      int     `yaml:"confirm_ticks"`        // 连续多少个一致的偏离Tick视为真实跳变（默认5）
+	MinTickIntervalMs int     `yaml:"min_tick_interval_ms"` // 同一行情源同一品种的最小Tick间隔（毫秒，默认0不限制；上游正常推送可能短于100ms，按需开启）
+	MaxSourceDiff     float64 `yaml:"max_source_diff"`      // 与其他行情源报价的最大偏差比例（默认0.005；仅配置多个行情源时生效）
+}
+
+// QuoteSourceConfig 单个行情源配置
+type QuoteSourceConfig struct {
+	Name        string            `yaml:"name"`         // 行情源名称（日志/接口展示用）
+	Type        string            `yaml:"type"`         // jtd / websocket / http / replay / simulate
+	Priority    int               `yaml:"priority"`     // 优先级，数字越小越优先
+	Disabled    bool              `yaml:"disabled"`     // 是否停用
+	URL         string            `yaml:"url"`          // 连接地址
+	DempCode    string            `yaml:"demp_code"`    // jtd：商户编码（不入库，见 QuoteSourceEnvPrefix）
+	Secret      string            `yaml:"secret"`       // jtd：密钥（不入库，见 QuoteSourceEnvPrefix）
+	Subscribe   string            `yaml:"subscribe"`    // websocket：连接后发送的订阅消息（原文）
+	Headers     map[string]string `yaml:"headers"`      // http：请求头
+	PollSeconds int               `yaml:"poll_seconds"` // http：轮询间隔（秒，默认3）
+
+	// jtd / websocket：连接监管
+	HeartbeatSeconds int    `yaml:"heartbeat_seconds"` // 心跳间隔（秒，默认20；发送ping帧和心跳消息）
+	HeartbeatMessage string `yaml:"heartbeat_message"` // 应用层心跳消息原文（jtd默认 {"type":"p"}，websocket默认不发送）
+	StaleSeconds     int    `yaml:"stale_seconds"`     // 看门狗：连接后超过该时长未收到行情则主动重连（秒，默认30）
+
+	// replay：回放录制的行情文件
+	Path  string  `yaml:"path"`  // 回放文件（JSON lines）
+	Speed float64 `yaml:"speed"` // 回放倍速（默认1，2表示两倍速）
+	Loop  bool    `yaml:"loop"`  // 回放结束后从头开始
+
+	// simulate：脚本化行情生成器
+	Symbol          string            `yaml:"symbol"`           // 品种代码（默认AU9999）
+	StartPrice      float64           `yaml:"start_price"`      // 初始价格（默认500）
+	IntervalMs      int               `yaml:"interval_ms"`      // 推送间隔（毫秒，默认1000）
+	Volatility      float64           `yaml:"volatility"`       // 随机游走单跳最大波动（元，默认0.3）
+	JumpProbability float64           `yaml:"jump_probability"` // 每跳发生跳空的概率（0-1）
+	JumpSize        float64           `yaml:"jump_size"`        // 跳空幅度（元）
+	GapProbability  float64           `yaml:"gap_probability"`  // 每跳发生断流的概率（0-1）
+	GapSeconds      int               `yaml:"gap_seconds"`      // 断流时长（秒）
+	Script          []QuoteScriptStep `yaml:"script"`           // 脚本（配置后按脚本循环执行，忽略上面的概率参数）
+}
+
+// QuoteScriptStep 行情生成脚本步骤
+type QuoteScriptStep struct {
+	Action  string  `yaml:"action"`  // walk（随机游走）/ jump（价格跳变）/ gap（断流）
+	Seconds int     `yaml:"seconds"` // walk/gap 持续时长（秒）
+	Delta   float64 `yaml:"delta"`   // jump 跳变幅度（元，可为负）
 }
 
 func AppEnv() string {
@@ -51,5 +165,38 @@ func Load(env string) (*Config, error) {
 	if err := yaml.Unmarshal(b, &cfg); err != nil {
 		return nil, err
 	}
+	applyQuoteSourceEnv(&cfg)
+	if v := os.Getenv("STORAGE_SIGN_SECRET"); v != "" {
+		cfg.Storage.SignSecret = v
+	}
+	if env == "prod" && cfg.Storage.SignSecret == "" {
+		return nil, fmt.Errorf("生产环境须配置下载链接签名密钥 storage.sign_secret（或环境变量 STORAGE_SIGN_SECRET）")
+	}
 	return &cfg, nil
 }
+
+// QuoteSourceEnvPrefix 行情源凭据环境变量前缀；
+// 如 jtd-primary 读取 QUOTE_SOURCE_JTD_PRIMARY_DEMP_CODE / QUOTE_SOURCE_JTD_PRIMARY_SECRET
+func QuoteSourceEnvPrefix(name string) string {
+	key := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return "QUOTE_SOURCE_" + strings.ToUpper(key) + "_"
+}
+
+// applyQuoteSourceEnv 从环境变量读取行情源凭据（配置文件中留空，环境变量优先）
+func applyQuoteSourceEnv(cfg *Config) {
+	for i := range cfg.Quote.Sources {
+		sc := &cfg.Quote.Sources[i]
+		prefix := QuoteSourceEnvPrefix(sc.Name)
+		if v := os.Getenv(prefix + "DEMP_CODE"); v != "" {
+			sc.DempCode = v
+		}
+		if v := os.Getenv(prefix + "SECRET"); v != "" {
+			sc.Secret = v
+		}
+	}
+}
@@ -59,5 +59,56 @@ func AutoMigrate(db *gorm.DB) error {
 		&model.InvitationRecord{},
 		&model.TeamRelation{},
 		&model.UserVerification{},
+		&model.QuoteOutage{},
+		&model.SchedulerLease{},
+		&model.MarginCall{},
+		&model.QuoteTick{},
+		&model.QuoteQuarantine{},
+		&model.QuoteCandle{},
+		&model.PriceAlert{},
+		&model.LedgerAccount{},
+		&model.JournalEntry{},
+		&model.JournalLine{},
+		&model.ReconciliationRun{},
+		&model.ReconciliationMismatch{},
+		&model.WithdrawPolicyOverride{},
+		&model.ApprovalStep{},
+		&model.PaymentOrder{},
+		&model.BankStatementMapping{},
+		&model.BankStatementImport{},
+		&model.BankStatementLine{},
+		&model.StoredFile{},
+		&model.AccountStatement{},
+		&model.ExportJob{},
+		&model.BalanceAdjustment{},
 	)
 }
+
+/**
+ * SeedDefaultConfigs 写入缺省的系统配置（已存在的配置不覆盖）
+ *
+ * 说明：
+ * - 目前仅写入手工余额调整审批阈值，保证超级管理员复核默认生效且在后台可见
+ *
+ * @param db *gorm.DB - 数据库连接
+ * @return error
+ */
+func SeedDefaultConfigs(db *gorm.DB) error {
+	defaults := []model.SystemConfig{
+		{
+			Category:    model.ConfigCategoryRisk,
+			Key:         model.ConfigKeyAdjustmentApprovalAmount,
+			Value:       fmt.Sprintf("%.0f", model.DefaultAdjustmentApprovalAmount),
+			Description: "手工余额调整超过该金额（绝对值）需超级管理员审批",
+			ValueType:   "float",
+			IsSystem:    true,
+		},
+	}
+	for _, config := range defaults {
+		config := config
+		if err := db.Where("`key` = ?", config.Key).FirstOrCreate(&config).Error; err != nil {
+			return fmt.Errorf("seed config %s: %w", config.Key, err)
+		}
+	}
+	return nil
+}
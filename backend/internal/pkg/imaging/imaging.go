@@ -0,0 +1,184 @@
+/**
+ * 图片压缩与缩略图（仅依赖标准库）
+ *
+ * 用途：
+ * - 上传的凭证、证件照片按长边上限缩放并重新编码为 JPEG，减少存储和流量
+ * - 生成列表展示用的缩略图
+ *
+ * 说明：
+ * - 支持 JPEG、PNG、GIF（取第一帧）；其他格式返回 ErrUnsupported，由调用方按原文件保存
+ * - 缩放使用区域平均采样，透明背景填充为白色
+ * - 压缩后体积反而变大且无需缩放时保留原图
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// ErrUnsupported 不支持的图片格式
+var ErrUnsupported = errors.New("不支持的图片格式")
+
+/**
+ * Result 处理结果
+ */
+type Result struct {
+	Data        []byte // 文件内容
+	ContentType string // 内容类型
+	Width       int    // 宽度（像素）
+	Height      int    // 高度（像素）
+}
+
+/**
+ * Compress 压缩图片：长边超过上限时缩放，重新编码为 JPEG
+ *
+ * @param data []byte - 原图
+ * @param maxSide int - 长边像素上限
+ * @param quality int - JPEG 质量（1-100）
+ * @return (*Result, error)
+ */
+func Compress(data []byte, maxSide, quality int) (*Result, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	b := img.Bounds()
+	resized := fit(img, maxSide)
+	out, err := encodeJPEG(resized, quality)
+	if err != nil {
+		return nil, err
+	}
+
+	// 无需缩放的 JPEG 重新编码后更大时保留原图
+	if format == "jpeg" && resized == img && len(out) >= len(data) {
+		return &Result{Data: data, ContentType: "image/jpeg", Width: b.Dx(), Height: b.Dy()}, nil
+	}
+	rb := resized.Bounds()
+	return &Result{Data: out, ContentType: "image/jpeg", Width: rb.Dx(), Height: rb.Dy()}, nil
+}
+
+/**
+ * Thumbnail 生成缩略图（JPEG）
+ *
+ * @param data []byte - 原图
+ * @param maxSide int - 长边像素
+ * @return (*Result, error)
+ */
+func Thumbnail(data []byte, maxSide int) (*Result, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	thumb := fit(img, maxSide)
+	out, err := encodeJPEG(thumb, 75)
+	if err != nil {
+		return nil, err
+	}
+	tb := thumb.Bounds()
+	return &Result{Data: out, ContentType: "image/jpeg", Width: tb.Dx(), Height: tb.Dy()}, nil
+}
+
+/**
+ * fit 按长边上限等比缩放（未超过上限时原样返回）
+ */
+func fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxSide <= 0 || (w <= maxSide && h <= maxSide) {
+		return img
+	}
+	if w >= h {
+		h = h * maxSide / w
+		w = maxSide
+	} else {
+		w = w * maxSide / h
+		h = maxSide
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return resize(img, w, h)
+}
+
+/**
+ * resize 区域平均采样缩小
+ */
+func resize(img image.Image, w, h int) image.Image {
+	src := toRGBA(img)
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := (y + 1) * sh / h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := (x + 1) * sw / w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				off := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					bl += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+/**
+ * toRGBA 转换为从原点开始的 RGBA 图像
+ */
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+/**
+ * encodeJPEG 编码为 JPEG（透明部分填充白色）
+ */
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	b := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), img, b.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
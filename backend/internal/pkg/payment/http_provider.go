@@ -0,0 +1,158 @@
+/**
+ * HTTP签名协议支付通道
+ *
+ * 接口约定：
+ * - 下单：POST create_url（表单），参数 merchant_id、order_no、amount、subject、notify_url、return_url、expire_time、timestamp、sign
+ *   响应 {"code": 0, "msg": "", "data": {"trade_no": "...", "pay_url": "..."}}
+ * - 查单：POST query_url（表单），参数 merchant_id、order_no、timestamp、sign
+ *   响应 {"code": 0, "msg": "", "data": {回调同名参数，含 sign}}
+ * - 回调：POST notify_url（表单或JSON），参数见 provider.go，处理成功返回 "success"
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"suxin/internal/pkg/config"
+)
+
+const (
+	httpRequestTimeout = 10 * time.Second // 通道接口请求超时
+	maxResponseSize    = 1 << 20          // 通道响应最大长度
+)
+
+/**
+ * httpProvider HTTP签名协议支付通道
+ */
+type httpProvider struct {
+	cfg    config.PaymentProviderConfig
+	client *http.Client
+}
+
+/**
+ * httpResponse 通道接口响应
+ */
+type httpResponse struct {
+	Code int               `json:"code"`
+	Msg  string            `json:"msg"`
+	Data map[string]string `json:"data"`
+}
+
+func newHTTPProvider(cfg config.PaymentProviderConfig) *httpProvider {
+	return &httpProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: httpRequestTimeout},
+	}
+}
+
+func (p *httpProvider) Name() string    { return p.cfg.Name }
+func (p *httpProvider) Type() string    { return p.cfg.Type }
+func (p *httpProvider) Title() string   { return p.cfg.Title }
+func (p *httpProvider) AckBody() string { return "success" }
+
+/**
+ * CreateOrder 调用通道下单接口
+ *
+ * @param req OrderRequest - 支付单信息
+ * @return (*OrderResult, error)
+ */
+func (p *httpProvider) CreateOrder(req OrderRequest) (*OrderResult, error) {
+	data, err := p.post(p.cfg.CreateURL, map[string]string{
+		"order_no":    req.OrderNo,
+		"amount":      FormatAmount(req.Amount),
+		"subject":     req.Subject,
+		"notify_url":  req.NotifyURL,
+		"return_url":  req.ReturnURL,
+		"expire_time": strconv.FormatInt(req.ExpireAt.Unix(), 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data["pay_url"] == "" {
+		return nil, errors.New("通道未返回支付地址")
+	}
+	return &OrderResult{TradeNo: data["trade_no"], PayURL: data["pay_url"]}, nil
+}
+
+/**
+ * QueryOrder 调用通道查单接口（查单结果同样校验签名）
+ *
+ * @param orderNo string - 商户支付单号
+ * @return (*Notification, error)
+ */
+func (p *httpProvider) QueryOrder(orderNo string) (*Notification, error) {
+	data, err := p.post(p.cfg.QueryURL, map[string]string{"order_no": orderNo})
+	if err != nil {
+		return nil, err
+	}
+	n, err := p.VerifyNotification(data)
+	if err != nil {
+		return nil, fmt.Errorf("查单结果校验失败: %v", err)
+	}
+	if n.OrderNo != orderNo {
+		return nil, fmt.Errorf("查单结果支付单号不一致: %s", n.OrderNo)
+	}
+	return n, nil
+}
+
+/**
+ * VerifyNotification 校验回调签名并解析支付结果
+ *
+ * @param params map[string]string - 回调参数
+ * @return (*Notification, error)
+ */
+func (p *httpProvider) VerifyNotification(params map[string]string) (*Notification, error) {
+	return parseNotification(params, p.cfg.MerchantID, p.cfg.Secret)
+}
+
+/**
+ * post 签名后以表单提交到通道接口，返回 data 部分
+ */
+func (p *httpProvider) post(endpoint string, params map[string]string) (map[string]string, error) {
+	params["merchant_id"] = p.cfg.MerchantID
+	params["timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+	params["sign"] = Sign(params, p.cfg.Secret)
+
+	form := url.Values{}
+	for k, v := range params {
+		form.Set(k, v)
+	}
+
+	resp, err := p.client.Post(endpoint, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("请求支付通道失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("支付通道响应状态异常: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取支付通道响应失败: %v", err)
+	}
+
+	var result httpResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("支付通道响应格式错误: %v", err)
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("支付通道返回错误: %s", result.Msg)
+	}
+	if result.Data == nil {
+		return nil, errors.New("支付通道未返回数据")
+	}
+	return result.Data, nil
+}
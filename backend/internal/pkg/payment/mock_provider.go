@@ -0,0 +1,142 @@
+/**
+ * 模拟支付通道（本地联调/测试用）
+ *
+ * 用途：
+ * - 下单不跳转真实支付页面，由前端或测试调用“模拟支付”
+ * - 模拟支付生成与真实通道相同格式的签名回调参数，走完整的回调校验和入账流程
+ * - 可模拟“已支付但回调丢失”，用于验证主动查单补单
+ *
+ * 说明：
+ * - 交易状态保存在内存中，服务重启后查单结果为未支付
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package payment
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"suxin/internal/pkg/config"
+)
+
+/**
+ * mockTrade 模拟交易
+ */
+type mockTrade struct {
+	tradeNo string
+	amount  float64
+	paid    bool
+}
+
+/**
+ * MockProvider 模拟支付通道
+ */
+type MockProvider struct {
+	cfg    config.PaymentProviderConfig
+	mu     sync.Mutex
+	trades map[string]*mockTrade
+}
+
+/**
+ * NewMockProvider 创建模拟支付通道
+ *
+ * @param cfg config.PaymentProviderConfig - 通道配置
+ * @return *MockProvider
+ */
+func NewMockProvider(cfg config.PaymentProviderConfig) *MockProvider {
+	return &MockProvider{
+		cfg:    cfg,
+		trades: make(map[string]*mockTrade),
+	}
+}
+
+func (p *MockProvider) Name() string    { return p.cfg.Name }
+func (p *MockProvider) Type() string    { return p.cfg.Type }
+func (p *MockProvider) Title() string   { return p.cfg.Title }
+func (p *MockProvider) AckBody() string { return "success" }
+
+/**
+ * CreateOrder 登记模拟交易（不返回支付地址）
+ *
+ * @param req OrderRequest - 支付单信息
+ * @return (*OrderResult, error)
+ */
+func (p *MockProvider) CreateOrder(req OrderRequest) (*OrderResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trade := &mockTrade{
+		tradeNo: "MOCK" + req.OrderNo,
+		amount:  req.Amount,
+	}
+	p.trades[req.OrderNo] = trade
+	return &OrderResult{TradeNo: trade.tradeNo}, nil
+}
+
+/**
+ * QueryOrder 查询模拟交易状态
+ *
+ * @param orderNo string - 商户支付单号
+ * @return (*Notification, error)
+ */
+func (p *MockProvider) QueryOrder(orderNo string) (*Notification, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trade, ok := p.trades[orderNo]
+	if !ok {
+		return &Notification{OrderNo: orderNo, Status: TradeStatusPending}, nil
+	}
+	n := &Notification{OrderNo: orderNo, TradeNo: trade.tradeNo, Amount: trade.amount, Status: TradeStatusPending}
+	if trade.paid {
+		n.Status = TradeStatusPaid
+	}
+	return n, nil
+}
+
+/**
+ * VerifyNotification 校验回调签名并解析支付结果
+ *
+ * @param params map[string]string - 回调参数
+ * @return (*Notification, error)
+ */
+func (p *MockProvider) VerifyNotification(params map[string]string) (*Notification, error) {
+	return parseNotification(params, p.cfg.MerchantID, p.cfg.Secret)
+}
+
+/**
+ * Pay 模拟用户完成支付，返回通道将要发送的签名回调参数
+ *
+ * @param orderNo string - 商户支付单号
+ * @param amount float64 - 实付金额（<=0 表示按下单金额支付，可传入不同金额模拟金额不符）
+ * @return (map[string]string, error)
+ */
+func (p *MockProvider) Pay(orderNo string, amount float64) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trade, ok := p.trades[orderNo]
+	if !ok {
+		return nil, errors.New("模拟通道中不存在该支付单")
+	}
+	if amount > 0 {
+		trade.amount = amount
+	}
+	trade.paid = true
+
+	params := map[string]string{
+		"merchant_id": p.cfg.MerchantID,
+		"order_no":    orderNo,
+		"trade_no":    trade.tradeNo,
+		"amount":      FormatAmount(trade.amount),
+		"status":      "SUCCESS",
+		"timestamp":   strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	params["sign"] = Sign(params, p.cfg.Secret)
+	return params, nil
+}
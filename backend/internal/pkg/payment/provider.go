@@ -0,0 +1,205 @@
+/**
+ * 支付通道抽象
+ *
+ * 用途：
+ * - 定义支付通道接口：创建支付单、查单、校验异步回调
+ * - 根据配置创建支付通道（http：标准HTTP签名协议通道；mock：本地模拟通道）
+ *
+ * 签名协议（http 与 mock 通道一致）：
+ * - 参数：merchant_id、order_no、trade_no、amount（两位小数）、status（SUCCESS/FAIL/PENDING）、timestamp、sign
+ * - 除 sign 及空值外的参数按键名升序拼接为 k1=v1&k2=v2，以密钥做 HMAC-SHA256，结果为小写十六进制
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"suxin/internal/pkg/config"
+)
+
+/**
+ * 支付通道类型常量
+ */
+const (
+	ProviderTypeHTTP = "http" // 标准HTTP签名协议通道
+	ProviderTypeMock = "mock" // 本地模拟通道（测试用）
+)
+
+/**
+ * 通道交易状态常量（已从通道原始状态映射）
+ */
+const (
+	TradeStatusPaid    = "paid"    // 支付成功
+	TradeStatusFailed  = "failed"  // 支付失败/已关闭
+	TradeStatusPending = "pending" // 未支付
+)
+
+/**
+ * OrderRequest 创建支付单请求
+ */
+type OrderRequest struct {
+	OrderNo   string    // 商户支付单号
+	Amount    float64   // 支付金额（元）
+	Subject   string    // 商品描述
+	NotifyURL string    // 异步回调地址
+	ReturnURL string    // 支付完成跳转地址
+	ExpireAt  time.Time // 支付单过期时间
+}
+
+/**
+ * OrderResult 创建支付单结果
+ */
+type OrderResult struct {
+	TradeNo string // 通道交易号
+	PayURL  string // 支付页面地址（mock 通道为空，由前端模拟支付）
+}
+
+/**
+ * Notification 通道支付结果（回调或查单），已校验签名
+ */
+type Notification struct {
+	OrderNo string  // 商户支付单号
+	TradeNo string  // 通道交易号
+	Amount  float64 // 实付金额（元）
+	Status  string  // 交易状态（TradeStatus*）
+}
+
+/**
+ * Provider 支付通道接口
+ */
+type Provider interface {
+	Name() string
+	Type() string
+	Title() string
+	CreateOrder(req OrderRequest) (*OrderResult, error)
+	QueryOrder(orderNo string) (*Notification, error)
+	VerifyNotification(params map[string]string) (*Notification, error)
+	AckBody() string // 回调处理成功后返回给通道的响应内容
+}
+
+/**
+ * NewProviders 根据配置创建支付通道
+ *
+ * @param cfg *config.Config - 应用配置
+ * @return ([]Provider, error)
+ */
+func NewProviders(cfg *config.Config) ([]Provider, error) {
+	var providers []Provider
+	names := make(map[string]bool)
+
+	for i, pc := range cfg.Payment.Providers {
+		if pc.Disabled {
+			continue
+		}
+		if pc.Name == "" {
+			pc.Name = fmt.Sprintf("%s-%d", pc.Type, i+1)
+		}
+		if names[pc.Name] {
+			return nil, fmt.Errorf("支付通道名称重复: %s", pc.Name)
+		}
+		names[pc.Name] = true
+		if pc.Title == "" {
+			pc.Title = pc.Name
+		}
+		if pc.Secret == "" {
+			return nil, fmt.Errorf("支付通道 %s 未配置secret", pc.Name)
+		}
+
+		switch pc.Type {
+		case ProviderTypeHTTP:
+			if pc.CreateURL == "" || pc.QueryURL == "" {
+				return nil, fmt.Errorf("支付通道 %s 未配置create_url/query_url", pc.Name)
+			}
+			if cfg.Payment.CallbackBaseURL == "" {
+				return nil, fmt.Errorf("支付通道 %s 需要配置payment.callback_base_url", pc.Name)
+			}
+			providers = append(providers, newHTTPProvider(pc))
+		case ProviderTypeMock:
+			providers = append(providers, NewMockProvider(pc))
+		default:
+			return nil, fmt.Errorf("支付通道 %s 类型不支持: %s", pc.Name, pc.Type)
+		}
+	}
+	return providers, nil
+}
+
+/**
+ * Sign 计算参数签名
+ *
+ * @param params map[string]string - 参数（忽略 sign 和空值）
+ * @param secret string - 签名密钥
+ * @return string - 小写十六进制 HMAC-SHA256
+ */
+func Sign(params map[string]string, secret string) string {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if k == "sign" || v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+params[k])
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(pairs, "&")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+ * FormatAmount 金额格式化为协议要求的两位小数
+ */
+func FormatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+/**
+ * parseNotification 校验签名和商户号并解析支付结果（http 与 mock 通道共用）
+ */
+func parseNotification(params map[string]string, merchantID, secret string) (*Notification, error) {
+	if !hmac.Equal([]byte(strings.ToLower(params["sign"])), []byte(Sign(params, secret))) {
+		return nil, errors.New("签名校验失败")
+	}
+	if params["merchant_id"] != merchantID {
+		return nil, fmt.Errorf("商户号不匹配: %s", params["merchant_id"])
+	}
+	if params["order_no"] == "" {
+		return nil, errors.New("缺少支付单号")
+	}
+
+	amount, err := strconv.ParseFloat(params["amount"], 64)
+	if err != nil || amount < 0 {
+		return nil, fmt.Errorf("金额格式错误: %s", params["amount"])
+	}
+
+	n := &Notification{
+		OrderNo: params["order_no"],
+		TradeNo: params["trade_no"],
+		Amount:  amount,
+	}
+	switch strings.ToUpper(params["status"]) {
+	case "SUCCESS":
+		n.Status = TradeStatusPaid
+	case "FAIL", "CLOSED":
+		n.Status = TradeStatusFailed
+	default:
+		n.Status = TradeStatusPending
+	}
+	return n, nil
+}
@@ -0,0 +1,283 @@
+/**
+ * 简易PDF生成（仅依赖标准库）
+ *
+ * 用途：
+ * - 生成对账单等表格类文档：文字、直线、灰底矩形，A4 纵向多页
+ *
+ * 说明：
+ * - 使用 PDF 阅读器内置的中文字体 STSong-Light（UniGB-UCS2-H 编码），无需嵌入字体文件
+ * - 坐标以页面左上角为原点、单位为点（1/72 英寸），y 轴向下
+ * - 字宽按半角（ASCII）0.5em、其他字符 1em 计算，用于右对齐和截断
+ * - 基本平面以外的字符（如 emoji）输出为“?”
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 纵向页面尺寸（点）
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+/**
+ * Document PDF 文档
+ */
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+}
+
+/**
+ * New 创建空文档
+ *
+ * @param title string - 文档标题（写入文档属性）
+ * @return *Document
+ */
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+/**
+ * AddPage 新增一页，后续绘制输出到该页
+ *
+ * @return void
+ */
+func (d *Document) AddPage() {
+	d.cur = &bytes.Buffer{}
+	d.pages = append(d.pages, d.cur)
+}
+
+/**
+ * PageCount 当前页数
+ *
+ * @return int
+ */
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+/**
+ * Text 输出文字（x 为左边界，y 为基线）
+ *
+ * @param x float64 - 左边界
+ * @param y float64 - 基线位置
+ * @param size float64 - 字号
+ * @param s string - 文字
+ * @return void
+ */
+func (d *Document) Text(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	d.ensurePage()
+	fmt.Fprintf(d.cur, "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, PageHeight-y, encodeText(s))
+}
+
+/**
+ * TextRight 输出右对齐文字（x 为右边界）
+ *
+ * @param x float64 - 右边界
+ * @param y float64 - 基线位置
+ * @param size float64 - 字号
+ * @param s string - 文字
+ * @return void
+ */
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.Text(x-TextWidth(s, size), y, size, s)
+}
+
+/**
+ * Line 画直线
+ *
+ * @param x1, y1, x2, y2 float64 - 起点、终点
+ * @param width float64 - 线宽
+ * @return void
+ */
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	d.ensurePage()
+	fmt.Fprintf(d.cur, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+/**
+ * FillRect 填充灰色矩形（用于表头底色）
+ *
+ * @param x, y float64 - 左上角
+ * @param w, h float64 - 宽、高
+ * @param gray float64 - 灰度（0黑 - 1白）
+ * @return void
+ */
+func (d *Document) FillRect(x, y, w, h, gray float64) {
+	d.ensurePage()
+	fmt.Fprintf(d.cur, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, PageHeight-y-h, w, h)
+}
+
+/**
+ * TextWidth 估算文字宽度
+ *
+ * @param s string - 文字
+ * @param size float64 - 字号
+ * @return float64
+ */
+func TextWidth(s string, size float64) float64 {
+	var em float64
+	for _, r := range s {
+		if r < 0x80 {
+			em += 0.5
+		} else {
+			em++
+		}
+	}
+	return em * size
+}
+
+/**
+ * Truncate 截断文字使其不超过指定宽度（超出部分以“…”结尾）
+ *
+ * @param s string - 文字
+ * @param size float64 - 字号
+ * @param maxWidth float64 - 最大宽度
+ * @return string
+ */
+func Truncate(s string, size, maxWidth float64) string {
+	if TextWidth(s, size) <= maxWidth {
+		return s
+	}
+	limit := maxWidth - size // 预留省略号宽度
+	var b strings.Builder
+	var width float64
+	for _, r := range s {
+		w := size
+		if r < 0x80 {
+			w = size / 2
+		}
+		if width+w > limit {
+			break
+		}
+		width += w
+		b.WriteRune(r)
+	}
+	return b.String() + "…"
+}
+
+/**
+ * Bytes 输出 PDF 文件内容（没有页面时输出一页空白页）
+ *
+ * @return []byte
+ */
+func (d *Document) Bytes() []byte {
+	d.ensurePage()
+
+	var out bytes.Buffer
+	var offsets []int
+	// 对象编号：1 目录，2 页面树，3 字体，4 CID字体，5 字体描述，6 文档信息，之后每页两个对象（页面、内容流）
+	beginObj := func() int {
+		offsets = append(offsets, out.Len())
+		n := len(offsets)
+		fmt.Fprintf(&out, "%d 0 obj\n", n)
+		return n
+	}
+	endObj := func() {
+		out.WriteString("endobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const firstPageObj = 7
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+
+	beginObj()
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	endObj()
+
+	beginObj()
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(d.pages))
+	endObj()
+
+	beginObj()
+	out.WriteString("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>\n")
+	endObj()
+
+	beginObj()
+	out.WriteString("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>\n")
+	endObj()
+
+	beginObj()
+	out.WriteString("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>\n")
+	endObj()
+
+	beginObj()
+	fmt.Fprintf(&out, "<< /Title <%s> /Producer (suxin) >>\n", encodeTitle(d.title))
+	endObj()
+
+	for _, page := range d.pages {
+		n := beginObj()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\n", PageWidth, PageHeight, n+1)
+		endObj()
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		zw.Write(page.Bytes())
+		zw.Close()
+		beginObj()
+		fmt.Fprintf(&out, "<< /Length %d /Filter /FlateDecode >>\nstream\n", stream.Len())
+		out.Write(stream.Bytes())
+		out.WriteString("\nendstream\n")
+		endObj()
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func (d *Document) ensurePage() {
+	if d.cur == nil {
+		d.AddPage()
+	}
+}
+
+/**
+ * encodeText 文字编码为 UCS-2 大端十六进制串（控制字符替换为空格）
+ */
+func encodeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x20:
+			r = ' '
+		case r > 0xFFFF:
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+/**
+ * encodeTitle 文档属性字符串编码为 UTF-16BE（带字节序标记）十六进制串
+ */
+func encodeTitle(s string) string {
+	return "FEFF" + encodeText(s)
+}
@@ -0,0 +1,100 @@
+/**
+ * 本地磁盘存储
+ *
+ * 用途：
+ * - 文件保存在配置的目录下，键即相对路径
+ * - 写入先写临时文件再重命名，避免读到写了一半的文件
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+/**
+ * LocalStorage 本地磁盘存储
+ */
+type LocalStorage struct {
+	dir string
+}
+
+/**
+ * NewLocalStorage 创建本地磁盘存储（目录不存在时自动创建）
+ *
+ * @param dir string - 存储根目录
+ * @return (*LocalStorage, error)
+ */
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %v", err)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+func (s *LocalStorage) Driver() string {
+	return DriverLocal
+}
+
+func (s *LocalStorage) Put(key string, data []byte, contentType string) error {
+	return s.PutReader(key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+func (s *LocalStorage) PutReader(key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) Get(key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
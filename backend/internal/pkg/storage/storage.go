@@ -0,0 +1,92 @@
+/**
+ * 文件存储抽象
+ *
+ * 用途：
+ * - 定义对象存储接口：按键保存、读取、删除文件
+ * - 根据配置创建存储后端（local：本地磁盘；s3：S3兼容对象存储）
+ *
+ * 说明：
+ * - 键为相对路径（如 deposit_voucher/ab/abcdef....jpg），由调用方保证唯一
+ * - 支持预签名下载的后端（s3）实现 Presigner，下载时直接重定向到对象存储
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"suxin/internal/pkg/config"
+)
+
+/**
+ * 存储后端类型常量
+ */
+const (
+	DriverLocal = "local" // 本地磁盘
+	DriverS3    = "s3"    // S3兼容对象存储
+)
+
+// ErrNotFound 文件不存在
+var ErrNotFound = errors.New("文件不存在")
+
+/**
+ * Storage 文件存储接口
+ */
+type Storage interface {
+	// Driver 存储后端类型
+	Driver() string
+	// Put 保存文件（键已存在时覆盖）
+	Put(key string, data []byte, contentType string) error
+	// PutReader 按流保存文件（键已存在时覆盖），size 为内容长度，用于导出等大文件
+	PutReader(key string, r io.Reader, size int64, contentType string) error
+	// Get 读取文件，调用方负责关闭；不存在时返回 ErrNotFound
+	Get(key string) (io.ReadCloser, error)
+	// Delete 删除文件（不存在不报错）
+	Delete(key string) error
+}
+
+/**
+ * Presigner 支持预签名下载链接的存储后端
+ */
+type Presigner interface {
+	// PresignGet 生成限时下载链接
+	PresignGet(key string, expires time.Duration) (string, error)
+}
+
+/**
+ * New 根据配置创建存储后端
+ *
+ * @param cfg *config.Config - 应用配置
+ * @return (Storage, error)
+ */
+func New(cfg *config.Config) (Storage, error) {
+	switch strings.ToLower(cfg.Storage.Driver) {
+	case "", DriverLocal:
+		dir := cfg.Storage.LocalDir
+		if dir == "" {
+			dir = "./data/files"
+		}
+		return NewLocalStorage(dir)
+	case DriverS3:
+		return NewS3Storage(cfg.Storage.S3)
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", cfg.Storage.Driver)
+	}
+}
+
+/**
+ * validKey 校验存储键（禁止绝对路径和上级目录）
+ */
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") || strings.Contains(key, "\\") {
+		return fmt.Errorf("无效的存储键: %s", key)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+/**
+ * XLSX 读取（仅依赖标准库）
+ *
+ * 用途：
+ * - 读取 Excel 2007+（.xlsx）第一个工作表的全部单元格文本，用于银行流水等文件导入
+ *
+ * 说明：
+ * - 共享字符串、内联字符串、布尔值按文本返回；数字（含日期）返回原始值，日期为 Excel 序列号，可用 SerialToTime 转换
+ * - 不支持旧版二进制 .xls，需另存为 .xlsx 或 .csv
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const maxPartSize = 64 << 20 // 单个XML部件最大解压长度
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type sharedStringsXML struct {
+	Items []richTextXML `xml:"si"`
+}
+
+type richTextXML struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richTextXML) text() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var b strings.Builder
+	for _, run := range r.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref       string      `xml:"r,attr"`
+			Type      string      `xml:"t,attr"`
+			Value     string      `xml:"v"`
+			InlineStr richTextXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+/**
+ * ReadRows 读取第一个工作表的全部行
+ *
+ * @param r io.ReaderAt - 文件内容
+ * @param size int64 - 文件大小
+ * @return ([][]string, error) - 按行列排列的单元格文本（空单元格为空字符串）
+ */
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("不是有效的xlsx文件")
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared sharedStringsXML
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(f, &shared); err != nil {
+			return nil, fmt.Errorf("读取共享字符串失败: %v", err)
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("xlsx文件中没有工作表")
+	}
+	var sheet worksheetXML
+	if err := decodePart(f, &sheet); err != nil {
+		return nil, fmt.Errorf("读取工作表失败: %v", err)
+	}
+
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		rowIndex := row.R - 1
+		if row.R == 0 {
+			rowIndex = i
+		}
+		for len(rows) <= rowIndex {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				if idx, ok := columnIndex(c.Ref); ok {
+					col = idx
+				}
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscanf(c.Value, "%d", &idx); err == nil && idx >= 0 && idx < len(shared.Items) {
+					cells[col] = shared.Items[idx].text()
+				}
+			case "inlineStr":
+				cells[col] = c.InlineStr.text()
+			case "b":
+				if c.Value == "1" {
+					cells[col] = "TRUE"
+				} else {
+					cells[col] = "FALSE"
+				}
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows[rowIndex] = cells
+	}
+	return rows, nil
+}
+
+/**
+ * SerialToTime Excel 日期序列号转换为时间（1900日期系统）
+ *
+ * @param serial float64 - 序列号（整数部分为天，小数部分为一天内的时间）
+ * @param loc *time.Location - 时区
+ * @return time.Time
+ */
+func SerialToTime(serial float64, loc *time.Location) time.Time {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, loc)
+	seconds := int64(serial*86400 + 0.5)
+	return epoch.Add(time.Duration(seconds) * time.Second)
+}
+
+/**
+ * firstSheetPath 通过 workbook.xml 及其关系文件定位第一个工作表
+ */
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wf, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("不是有效的xlsx文件")
+	}
+	var wb workbookXML
+	if err := decodePart(wf, &wb); err != nil || len(wb.Sheets) == 0 {
+		return fallback, nil
+	}
+
+	rf, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	var rels relationshipsXML
+	if err := decodePart(rf, &rels); err != nil {
+		return fallback, nil
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+/**
+ * decodePart 解压并解析XML部件
+ */
+func decodePart(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v)
+}
+
+/**
+ * columnIndex 单元格引用（如 "AB12"）转换为从0开始的列号
+ */
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
@@ -0,0 +1,324 @@
+/**
+ * XLSX 写入（仅依赖标准库）
+ *
+ * 用途：
+ * - 按行流式写出 Excel 2007+（.xlsx）单工作表文件，用于数据导出
+ *
+ * 说明：
+ * - 每写一行立即压缩输出，不在内存中保留已写的行，适合大数据量导出
+ * - 字符串使用内联字符串（不生成共享字符串表）；金额（Money）按两位小数显示，时间按文本写出
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	styleDefault = 0 // 默认样式
+	styleHeader  = 1 // 表头（加粗）
+	styleMoney   = 2 // 两位小数（#,##0.00）
+
+	maxSheetNameLen = 31 // Excel 工作表名称最大长度
+)
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const workbookXMLTemplate = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+</styleSheet>`
+
+const sheetHeadXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`
+
+// Money 金额单元格（按 #,##0.00 显示）
+type Money float64
+
+// ErrClosed 写入已结束
+var ErrClosed = errors.New("xlsx 写入已结束")
+
+/**
+ * Writer 流式 XLSX 写入器
+ *
+ * 使用方式：
+ *   w, _ := xlsx.NewWriter(out, "订单")
+ *   w.WriteHeader([]string{"订单号", "金额"}, []float64{24, 12})
+ *   w.WriteRow([]interface{}{"A001", xlsx.Money(100.5)})
+ *   w.Close()
+ */
+type Writer struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	rows    int
+	started bool // 已输出 <sheetData>
+	closed  bool
+}
+
+/**
+ * NewWriter 创建写入器（立即输出工作簿结构，之后按行输出工作表）
+ *
+ * @param w io.Writer - 输出目标（文件、HTTP响应等）
+ * @param sheetName string - 工作表名称
+ * @return (*Writer, error)
+ */
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXMLTemplate, escapeText(sanitizeSheetName(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriterSize(f, 64<<10)
+	if _, err := sheet.WriteString(sheetHeadXML); err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+/**
+ * WriteHeader 写入表头行（加粗并冻结首行），必须在第一行数据之前调用
+ *
+ * @param titles []string - 列标题
+ * @param widths []float64 - 列宽（字符数，可为nil或少于列数）
+ * @return error
+ */
+func (w *Writer) WriteHeader(titles []string, widths []float64) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if w.started {
+		return errors.New("表头必须在数据行之前写入")
+	}
+
+	w.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if len(widths) > 0 {
+		w.sheet.WriteString("<cols>")
+		for i, width := range widths {
+			if width <= 0 {
+				continue
+			}
+			fmt.Fprintf(w.sheet, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		w.sheet.WriteString("</cols>")
+	}
+
+	values := make([]interface{}, len(titles))
+	for i, title := range titles {
+		values[i] = title
+	}
+	return w.writeRow(values, styleHeader)
+}
+
+/**
+ * WriteRow 写入一行数据
+ *
+ * 支持的值类型：string、Money（两位小数显示）、float64/float32、整数、bool、time.Time、*time.Time，nil 为空单元格
+ *
+ * @param values []interface{} - 单元格值
+ * @return error
+ */
+func (w *Writer) WriteRow(values []interface{}) error {
+	if w.closed {
+		return ErrClosed
+	}
+	return w.writeRow(values, styleDefault)
+}
+
+/**
+ * Rows 已写入行数（含表头）
+ *
+ * @return int
+ */
+func (w *Writer) Rows() int {
+	return w.rows
+}
+
+/**
+ * Close 结束工作表并完成 zip 输出（不关闭底层 io.Writer）
+ *
+ * @return error
+ */
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if !w.started {
+		w.sheet.WriteString("<sheetData>")
+	}
+	w.sheet.WriteString("</sheetData></worksheet>")
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) writeRow(values []interface{}, baseStyle int) error {
+	if !w.started {
+		w.sheet.WriteString("<sheetData>")
+		w.started = true
+	}
+	w.rows++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows)
+	for i, value := range values {
+		ref := columnName(i) + strconv.Itoa(w.rows)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			writeStringCell(w.sheet, ref, v, baseStyle)
+		case Money:
+			writeNumberCell(w.sheet, ref, strconv.FormatFloat(float64(v), 'f', 2, 64), styleMoney)
+		case float64:
+			writeNumberCell(w.sheet, ref, strconv.FormatFloat(v, 'f', -1, 64), baseStyle)
+		case float32:
+			writeNumberCell(w.sheet, ref, strconv.FormatFloat(float64(v), 'f', -1, 32), baseStyle)
+		case int:
+			writeNumberCell(w.sheet, ref, strconv.Itoa(v), baseStyle)
+		case int64:
+			writeNumberCell(w.sheet, ref, strconv.FormatInt(v, 10), baseStyle)
+		case uint:
+			writeNumberCell(w.sheet, ref, strconv.FormatUint(uint64(v), 10), baseStyle)
+		case uint64:
+			writeNumberCell(w.sheet, ref, strconv.FormatUint(v, 10), baseStyle)
+		case bool:
+			text := "否"
+			if v {
+				text = "是"
+			}
+			writeStringCell(w.sheet, ref, text, baseStyle)
+		case time.Time:
+			if !v.IsZero() {
+				writeStringCell(w.sheet, ref, v.Format("2006-01-02 15:04:05"), baseStyle)
+			}
+		case *time.Time:
+			if v != nil && !v.IsZero() {
+				writeStringCell(w.sheet, ref, v.Format("2006-01-02 15:04:05"), baseStyle)
+			}
+		default:
+			writeStringCell(w.sheet, ref, fmt.Sprint(v), baseStyle)
+		}
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+func writeStringCell(b *bufio.Writer, ref, value string, style int) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr(style), escapeText(value))
+}
+
+func writeNumberCell(b *bufio.Writer, ref, value string, style int) {
+	fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr(style), value)
+}
+
+func styleAttr(style int) string {
+	if style == styleDefault {
+		return ""
+	}
+	return fmt.Sprintf(` s="%d"`, style)
+}
+
+/**
+ * columnName 列序号（从0开始）转列名：0→A，25→Z，26→AA
+ */
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+/**
+ * escapeText 转义XML文本并去除XML不允许的控制字符
+ */
+func escapeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+/**
+ * sanitizeSheetName 去除工作表名称中不允许的字符并截断到31个字符
+ */
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > maxSheetNameLen {
+		name = string(runes[:maxSheetNameLen])
+	}
+	return name
+}
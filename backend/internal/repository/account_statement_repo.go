@@ -0,0 +1,124 @@
+/**
+ * 账户对账单仓储层
+ *
+ * 用途：
+ * - 对账单记录的读写
+ * - 生成对账单所需的按周期查询（期初期末资金流水、本期资金流水、开仓/平仓订单）
+ *
+ * 说明：
+ * - 周期均为 [开始, 结束)；订单查询包含已软删除的订单，保证对账单与资金流水一致
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type AccountStatementRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountStatementRepository(db *gorm.DB) *AccountStatementRepository {
+	return &AccountStatementRepository{db: db}
+}
+
+func (r *AccountStatementRepository) Create(statement *model.AccountStatement) error {
+	return r.db.Create(statement).Error
+}
+
+func (r *AccountStatementRepository) FindByID(id uint) (*model.AccountStatement, error) {
+	var statement model.AccountStatement
+	if err := r.db.First(&statement, id).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// FindByPeriod 查询客户指定周期的对账单（不存在时返回 nil, nil）
+func (r *AccountStatementRepository) FindByPeriod(userID uint, periodType string, periodStart time.Time) (*model.AccountStatement, error) {
+	var statement model.AccountStatement
+	err := r.db.Where("user_id = ? AND period_type = ? AND period_start = ?", userID, periodType, periodStart).
+		First(&statement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// FindList 分页查询对账单（userID为0、periodType为空时不筛选）
+func (r *AccountStatementRepository) FindList(userID uint, periodType string, limit, offset int) ([]*model.AccountStatement, int64, error) {
+	var statements []*model.AccountStatement
+	var total int64
+
+	query := r.db.Model(&model.AccountStatement{})
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if periodType != "" {
+		query = query.Where("period_type = ?", periodType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("period_start DESC, id DESC").Limit(limit).Offset(offset).Find(&statements).Error
+	return statements, total, err
+}
+
+// FindLastFundLogBefore 查询指定时间之前客户的最后一条资金流水（没有时返回 nil, nil）
+func (r *AccountStatementRepository) FindLastFundLogBefore(userID uint, before time.Time) (*model.FundLog, error) {
+	var fundLog model.FundLog
+	err := r.db.Where("user_id = ? AND created_at < ?", userID, before).
+		Order("created_at DESC, id DESC").
+		First(&fundLog).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fundLog, nil
+}
+
+// FindFundLogs 查询周期内客户的资金流水（按时间正序）
+func (r *AccountStatementRepository) FindFundLogs(userID uint, start, end time.Time) ([]*model.FundLog, error) {
+	var logs []*model.FundLog
+	err := r.db.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, start, end).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error
+	return logs, err
+}
+
+// FindOrdersOpenedOrClosed 查询周期内开仓或平仓（结算/强平）的客户订单（按开仓时间正序）
+func (r *AccountStatementRepository) FindOrdersOpenedOrClosed(userID uint, start, end time.Time) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.db.Unscoped().
+		Where("user_id = ?", userID).
+		Where("(created_at >= ? AND created_at < ?) OR (settled_at >= ? AND settled_at < ?)", start, end, start, end).
+		Order("created_at ASC, id ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
+// FindUserIDsWithFundLogsBefore 查询在指定时间之前有资金流水的客户ID
+func (r *AccountStatementRepository) FindUserIDsWithFundLogsBefore(before time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.FundLog{}).
+		Where("created_at < ?", before).
+		Distinct("user_id").
+		Order("user_id").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,76 @@
+/**
+ * 手工余额调整仓储层
+ *
+ * 用途：
+ * - 余额调整记录的读写、审批时加锁读取
+ * - 按客户、状态、原因分页查询
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+// AdjustmentFilter 余额调整查询条件（零值不筛选）
+type AdjustmentFilter struct {
+	UserID     uint
+	Status     string
+	ReasonCode string
+}
+
+type AdjustmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAdjustmentRepository(db *gorm.DB) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db}
+}
+
+func (r *AdjustmentRepository) Create(adjustment *model.BalanceAdjustment) error {
+	return r.db.Create(adjustment).Error
+}
+
+func (r *AdjustmentRepository) FindByID(id uint) (*model.BalanceAdjustment, error) {
+	var adjustment model.BalanceAdjustment
+	if err := r.db.Preload("User").First(&adjustment, id).Error; err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// LockByID 在事务中锁定并读取余额调整（防止重复审批入账）
+func (r *AdjustmentRepository) LockByID(id uint) (*model.BalanceAdjustment, error) {
+	var adjustment model.BalanceAdjustment
+	if err := lockByID(r.db, "balance_adjustments", id, &adjustment); err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// FindList 按条件分页查询余额调整（按ID倒序，附带客户信息）
+func (r *AdjustmentRepository) FindList(filter AdjustmentFilter, limit, offset int) ([]*model.BalanceAdjustment, int64, error) {
+	query := r.db.Model(&model.BalanceAdjustment{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var adjustments []*model.BalanceAdjustment
+	err := query.Preload("User").Order("id DESC").Limit(limit).Offset(offset).Find(&adjustments).Error
+	return adjustments, total, err
+}
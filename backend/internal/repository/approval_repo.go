@@ -0,0 +1,45 @@
+/**
+ * 审批步骤仓储层
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type ApprovalRepository struct {
+	db *gorm.DB
+}
+
+func NewApprovalRepository(db *gorm.DB) *ApprovalRepository {
+	return &ApprovalRepository{db: db}
+}
+
+func (r *ApprovalRepository) Create(step *model.ApprovalStep) error {
+	return r.db.Create(step).Error
+}
+
+// FindByTarget 按审批顺序查询审批对象的全部审批步骤
+func (r *ApprovalRepository) FindByTarget(targetType string, targetID uint) ([]*model.ApprovalStep, error) {
+	var steps []*model.ApprovalStep
+	err := r.db.Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("id ASC").
+		Find(&steps).Error
+	return steps, err
+}
+
+// HasApproved 判断审批人是否已通过过该审批对象
+func (r *ApprovalRepository) HasApproved(targetType string, targetID, approverID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ApprovalStep{}).
+		Where("target_type = ? AND target_id = ? AND approver_id = ? AND decision = ?",
+			targetType, targetID, approverID, model.ApprovalDecisionApprove).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,143 @@
+/**
+ * 银行流水仓储层
+ *
+ * 用途：
+ * - 银行流水列映射、导入批次、流水明细的读写
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type BankStatementRepository struct {
+	db *gorm.DB
+}
+
+func NewBankStatementRepository(db *gorm.DB) *BankStatementRepository {
+	return &BankStatementRepository{db: db}
+}
+
+func (r *BankStatementRepository) CreateMapping(mapping *model.BankStatementMapping) error {
+	return r.db.Create(mapping).Error
+}
+
+func (r *BankStatementRepository) UpdateMapping(mapping *model.BankStatementMapping) error {
+	return r.db.Save(mapping).Error
+}
+
+func (r *BankStatementRepository) DeleteMapping(id uint) error {
+	return r.db.Delete(&model.BankStatementMapping{}, id).Error
+}
+
+func (r *BankStatementRepository) FindMappingByID(id uint) (*model.BankStatementMapping, error) {
+	var mapping model.BankStatementMapping
+	if err := r.db.First(&mapping, id).Error; err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (r *BankStatementRepository) FindMappings() ([]*model.BankStatementMapping, error) {
+	var mappings []*model.BankStatementMapping
+	err := r.db.Order("bank_name ASC").Find(&mappings).Error
+	return mappings, err
+}
+
+func (r *BankStatementRepository) CreateImport(imp *model.BankStatementImport) error {
+	return r.db.Create(imp).Error
+}
+
+func (r *BankStatementRepository) UpdateImport(imp *model.BankStatementImport) error {
+	return r.db.Save(imp).Error
+}
+
+// FindImports 分页查询导入批次
+func (r *BankStatementRepository) FindImports(limit, offset int) ([]*model.BankStatementImport, int64, error) {
+	var imports []*model.BankStatementImport
+	var total int64
+
+	query := r.db.Model(&model.BankStatementImport{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&imports).Error
+	return imports, total, err
+}
+
+func (r *BankStatementRepository) CreateLine(line *model.BankStatementLine) error {
+	return r.db.Create(line).Error
+}
+
+func (r *BankStatementRepository) UpdateLine(line *model.BankStatementLine) error {
+	return r.db.Save(line).Error
+}
+
+func (r *BankStatementRepository) FindLineByID(id uint) (*model.BankStatementLine, error) {
+	var line model.BankStatementLine
+	if err := r.db.First(&line, id).Error; err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+// LockLineByID 在事务中锁定并读取流水
+func (r *BankStatementRepository) LockLineByID(id uint) (*model.BankStatementLine, error) {
+	var line model.BankStatementLine
+	if err := lockByID(r.db, "bank_statement_lines", id, &line); err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+// ExistsFingerprint 流水是否已导入
+func (r *BankStatementRepository) ExistsFingerprint(fingerprint string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.BankStatementLine{}).Where("fingerprint = ?", fingerprint).Count(&count).Error
+	return count > 0, err
+}
+
+// FindLines 分页查询流水（status为空、importID为0时不筛选）
+func (r *BankStatementRepository) FindLines(status string, importID uint, limit, offset int) ([]*model.BankStatementLine, int64, error) {
+	var lines []*model.BankStatementLine
+	var total int64
+
+	query := r.db.Model(&model.BankStatementLine{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if importID > 0 {
+		query = query.Where("import_id = ?", importID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("txn_time DESC, id DESC").Limit(limit).Offset(offset).Find(&lines).Error
+	return lines, total, err
+}
+
+// FindClaimedDepositIDs 查询已被流水匹配（自动入账、建议匹配、已确认）的充值申请ID
+func (r *BankStatementRepository) FindClaimedDepositIDs(depositIDs []uint) (map[uint]bool, error) {
+	claimed := make(map[uint]bool)
+	if len(depositIDs) == 0 {
+		return claimed, nil
+	}
+	var ids []uint
+	err := r.db.Model(&model.BankStatementLine{}).
+		Where("deposit_id IN ?", depositIDs).
+		Where("status IN ?", []string{model.StatementLineAutoMatched, model.StatementLineProposed, model.StatementLineConfirmed}).
+		Pluck("deposit_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		claimed[id] = true
+	}
+	return claimed, nil
+}
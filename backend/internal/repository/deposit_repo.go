@@ -13,6 +13,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"suxin/internal/model"
@@ -59,6 +61,20 @@ func (r *DepositRepository) FindByID(id uint) (*model.DepositRequest, error) {
 	return &deposit, nil
 }
 
+/**
+ * LockByID 在事务中锁定并读取充值申请（防止重复审核）
+ * 
+ * @param id uint - 充值申请ID
+ * @return (*model.DepositRequest, error)
+ */
+func (r *DepositRepository) LockByID(id uint) (*model.DepositRequest, error) {
+	var deposit model.DepositRequest
+	if err := lockByID(r.db, "deposit_requests", id, &deposit); err != nil {
+		return nil, err
+	}
+	return &deposit, nil
+}
+
 /**
  * FindByUserID 查询用户的充值记录
  * 
@@ -78,20 +94,45 @@ func (r *DepositRepository) FindByUserID(userID uint, limit, offset int) ([]*mod
 }
 
 /**
- * FindPending 查询待审核的充值申请
+ * FindPending 查询审批中（待审核、待复核）的充值申请
  * 
  * @param limit int - 查询数量限制
  * @return ([]*model.DepositRequest, error)
  */
 func (r *DepositRepository) FindPending(limit int) ([]*model.DepositRequest, error) {
 	var deposits []*model.DepositRequest
-	err := r.db.Preload("User").Where("status = ?", model.DepositStatusPending).
+	err := r.db.Preload("User").
+		Where("status IN ?", []string{model.DepositStatusPending, model.DepositStatusReviewing}).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&deposits).Error
 	return deposits, err
 }
 
+/**
+ * FindAwaitingByAmount 查询审批中、金额一致的银行转账充值申请（用于银行流水匹配）
+ * 
+ * @param amount float64 - 金额
+ * @param from time.Time - 申请时间下限（零值不限制）
+ * @param to time.Time - 申请时间上限（零值不限制）
+ * @return ([]*model.DepositRequest, error)
+ */
+func (r *DepositRepository) FindAwaitingByAmount(amount float64, from, to time.Time) ([]*model.DepositRequest, error) {
+	var deposits []*model.DepositRequest
+	query := r.db.Preload("User").
+		Where("status IN ?", []string{model.DepositStatusPending, model.DepositStatusReviewing}).
+		Where("method <> ?", model.DepositMethodOnline).
+		Where("amount BETWEEN ? AND ?", amount-0.005, amount+0.005)
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at <= ?", to)
+	}
+	err := query.Order("created_at ASC").Find(&deposits).Error
+	return deposits, err
+}
+
 /**
  * FindByStatus 根据状态查询充值申请
  * 
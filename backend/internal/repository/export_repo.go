@@ -0,0 +1,213 @@
+/**
+ * 数据导出仓储层
+ *
+ * 用途：
+ * - 导出任务的读写与排队领取
+ * - 按筛选条件分批（按ID递增）读取订单、资金流水、付定金、退定金、提成记录
+ *
+ * 说明：
+ * - 分批读取按 id > 上一批最后ID 翻页，数据量大时不会一次性加载到内存
+ * - 销售筛选：提成记录按 salesperson_id，其他数据按客户的归属销售（users.sales_id）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+// ExportFilter 导出筛选条件（零值不筛选；日期范围为 [StartDate, EndDate)）
+type ExportFilter struct {
+	StartDate     *time.Time `json:"start_date,omitempty"`
+	EndDate       *time.Time `json:"end_date,omitempty"`
+	Status        string     `json:"status,omitempty"` // 资金流水为流水类型；提成记录无状态
+	UserID        uint       `json:"user_id,omitempty"`
+	SalespersonID uint       `json:"salesperson_id,omitempty"`
+}
+
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) CreateJob(job *model.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ExportRepository) UpdateJob(job *model.ExportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *ExportRepository) FindJobByID(id uint) (*model.ExportJob, error) {
+	var job model.ExportJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindJobs 分页查询导出任务（operatorID为0时不筛选）
+func (r *ExportRepository) FindJobs(operatorID uint, limit, offset int) ([]*model.ExportJob, int64, error) {
+	query := r.db.Model(&model.ExportJob{})
+	if operatorID > 0 {
+		query = query.Where("operator_id = ?", operatorID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var jobs []*model.ExportJob
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+	return jobs, total, err
+}
+
+// ClaimNextJob 领取最早的排队任务并标记为执行中（无任务时返回 nil, nil）
+func (r *ExportRepository) ClaimNextJob() (*model.ExportJob, error) {
+	var job model.ExportJob
+	err := r.db.Where("status = ?", model.ExportStatusPending).Order("id").Limit(1).Find(&job).Error
+	if err != nil || job.ID == 0 {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := r.db.Model(&model.ExportJob{}).
+		Where("id = ? AND status = ?", job.ID, model.ExportStatusPending).
+		Updates(map[string]interface{}{"status": model.ExportStatusRunning, "started_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	job.Status = model.ExportStatusRunning
+	job.StartedAt = &now
+	return &job, nil
+}
+
+// FailStaleJobs 将开始时间早于 before 仍在执行的任务标记为失败
+func (r *ExportRepository) FailStaleJobs(before time.Time, reason string) (int64, error) {
+	result := r.db.Model(&model.ExportJob{}).
+		Where("status = ? AND started_at < ?", model.ExportStatusRunning, before).
+		Updates(map[string]interface{}{"status": model.ExportStatusFailed, "error": reason, "finished_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// FindExpiredJobs 查询文件已过期但尚未清理的任务
+func (r *ExportRepository) FindExpiredJobs(now time.Time, limit int) ([]*model.ExportJob, error) {
+	var jobs []*model.ExportJob
+	err := r.db.Where("status = ? AND expires_at < ?", model.ExportStatusDone, now).
+		Order("id").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// Count 统计符合筛选条件的记录数
+func (r *ExportRepository) Count(dataset string, filter ExportFilter) (int64, error) {
+	query, err := r.scope(dataset, filter)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = query.Count(&total).Error
+	return total, err
+}
+
+func (r *ExportRepository) FindOrdersBatch(filter ExportFilter, afterID uint, limit int) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.batch(model.ExportDatasetOrders, filter, afterID, limit, &orders)
+	return orders, err
+}
+
+func (r *ExportRepository) FindFundLogsBatch(filter ExportFilter, afterID uint, limit int) ([]*model.FundLog, error) {
+	var logs []*model.FundLog
+	err := r.batch(model.ExportDatasetFundLogs, filter, afterID, limit, &logs)
+	return logs, err
+}
+
+func (r *ExportRepository) FindDepositsBatch(filter ExportFilter, afterID uint, limit int) ([]*model.DepositRequest, error) {
+	var deposits []*model.DepositRequest
+	err := r.batch(model.ExportDatasetDeposits, filter, afterID, limit, &deposits)
+	return deposits, err
+}
+
+func (r *ExportRepository) FindWithdrawsBatch(filter ExportFilter, afterID uint, limit int) ([]*model.WithdrawRequest, error) {
+	var withdraws []*model.WithdrawRequest
+	err := r.batch(model.ExportDatasetWithdraws, filter, afterID, limit, &withdraws)
+	return withdraws, err
+}
+
+func (r *ExportRepository) FindCommissionsBatch(filter ExportFilter, afterID uint, limit int) ([]*model.CommissionRecord, error) {
+	var records []*model.CommissionRecord
+	err := r.batch(model.ExportDatasetCommissions, filter, afterID, limit, &records)
+	return records, err
+}
+
+// FindUsersByIDs 批量查询用户（含已删除用户，导出时补充姓名手机号）
+func (r *ExportRepository) FindUsersByIDs(ids []uint) ([]*model.User, error) {
+	var users []*model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Unscoped().Select("id", "phone", "real_name", "sales_id").Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r *ExportRepository) batch(dataset string, filter ExportFilter, afterID uint, limit int, dest interface{}) error {
+	query, err := r.scope(dataset, filter)
+	if err != nil {
+		return err
+	}
+	return query.Where("id > ?", afterID).Order("id").Limit(limit).Find(dest).Error
+}
+
+// scope 按数据集构造带筛选条件的查询
+func (r *ExportRepository) scope(dataset string, filter ExportFilter) (*gorm.DB, error) {
+	var query *gorm.DB
+	dateColumn, userColumn, statusColumn := "created_at", "user_id", "status"
+
+	switch dataset {
+	case model.ExportDatasetOrders:
+		query = r.db.Model(&model.Order{})
+	case model.ExportDatasetFundLogs:
+		query = r.db.Model(&model.FundLog{})
+		statusColumn = "type"
+	case model.ExportDatasetDeposits:
+		query = r.db.Model(&model.DepositRequest{})
+	case model.ExportDatasetWithdraws:
+		query = r.db.Model(&model.WithdrawRequest{})
+	case model.ExportDatasetCommissions:
+		query = r.db.Model(&model.CommissionRecord{})
+		dateColumn, userColumn, statusColumn = "settled_at", "customer_id", ""
+	default:
+		return nil, fmt.Errorf("不支持的导出数据: %s", dataset)
+	}
+
+	if filter.StartDate != nil {
+		query = query.Where(dateColumn+" >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where(dateColumn+" < ?", *filter.EndDate)
+	}
+	if filter.Status != "" && statusColumn != "" {
+		query = query.Where(statusColumn+" = ?", filter.Status)
+	}
+	if filter.UserID > 0 {
+		query = query.Where(userColumn+" = ?", filter.UserID)
+	}
+	if filter.SalespersonID > 0 {
+		if dataset == model.ExportDatasetCommissions {
+			query = query.Where("salesperson_id = ?", filter.SalespersonID)
+		} else {
+			query = query.Where(userColumn+" IN (?)", r.db.Unscoped().Model(&model.User{}).Select("id").Where("sales_id = ?", filter.SalespersonID))
+		}
+	}
+	return query, nil
+}
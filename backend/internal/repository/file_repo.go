@@ -0,0 +1,41 @@
+/**
+ * 存储文件仓储层
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type FileRepository struct {
+	db *gorm.DB
+}
+
+func NewFileRepository(db *gorm.DB) *FileRepository {
+	return &FileRepository{db: db}
+}
+
+func (r *FileRepository) Create(file *model.StoredFile) error {
+	return r.db.Create(file).Error
+}
+
+// FindByHash 按内容哈希查询文件（不存在返回 nil, nil）
+func (r *FileRepository) FindByHash(hash string) (*model.StoredFile, error) {
+	var file model.StoredFile
+	err := r.db.Where("hash = ?", hash).First(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
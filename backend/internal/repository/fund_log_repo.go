@@ -113,3 +113,58 @@ func (r *FundLogRepository) GetSumByType(userID uint, logType string) (float64,
 		Scan(&sum).Error
 	return sum, err
 }
+
+/**
+ * GetSumsGroupByType 按类型汇总资金变动（可选时间范围）
+ * 
+ * @param userID uint - 用户ID
+ * @param startDate *time.Time - 开始时间（nil表示不限）
+ * @param endDate *time.Time - 结束时间（nil表示不限）
+ * @return (map[string]float64, error) - 类型 -> 流水金额合计
+ */
+func (r *FundLogRepository) GetSumsGroupByType(userID uint, startDate, endDate *time.Time) (map[string]float64, error) {
+	var rows []struct {
+		Type   string
+		Amount float64
+	}
+	query := r.db.Model(&model.FundLog{}).Where("user_id = ?", userID)
+	if startDate != nil {
+		query = query.Where("created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("created_at < ?", *endDate)
+	}
+	err := query.Select("type, COALESCE(SUM(amount), 0) AS amount").
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	
+	sums := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		sums[row.Type] = row.Amount
+	}
+	return sums, nil
+}
+
+/**
+ * GetAvailableChange 汇总可用定金变动（可选时间范围）
+ * 
+ * @param userID uint - 用户ID
+ * @param startDate *time.Time - 开始时间（nil表示不限）
+ * @param endDate *time.Time - 结束时间（nil表示不限）
+ * @return (float64, error) - 可用定金变动合计
+ */
+func (r *FundLogRepository) GetAvailableChange(userID uint, startDate, endDate *time.Time) (float64, error) {
+	var sum float64
+	query := r.db.Model(&model.FundLog{}).Where("user_id = ?", userID)
+	if startDate != nil {
+		query = query.Where("created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("created_at < ?", *endDate)
+	}
+	err := query.Select("COALESCE(SUM(available_after - available_before), 0)").Scan(&sum).Error
+	return sum, err
+}
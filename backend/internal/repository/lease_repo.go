@@ -0,0 +1,100 @@
+/**
+ * 定时任务租约仓储层
+ *
+ * 用途：
+ * - 通过条件更新实现租约的抢占、续约和释放
+ * - 同时兼容 SQLite 和 MySQL
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"suxin/internal/model"
+)
+
+type LeaseRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaseRepository(db *gorm.DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+/**
+ * EnsureLease 确保租约行存在（并发创建时忽略冲突）
+ *
+ * @param name string - 租约名称
+ * @return error
+ */
+func (r *LeaseRepository) EnsureLease(name string) error {
+	// 使用1970年作为初始时间，避免MySQL严格模式下零值日期写入失败
+	epoch := time.Unix(0, 0)
+	lease := &model.SchedulerLease{
+		Name:       name,
+		AcquiredAt: epoch,
+		RenewedAt:  epoch,
+		ExpiresAt:  epoch,
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(lease).Error
+}
+
+/**
+ * Renew 续约（仅当前持有者可以续约）
+ *
+ * @return (bool, error) - 是否续约成功
+ */
+func (r *LeaseRepository) Renew(name, holderID string, now time.Time, ttl time.Duration) (bool, error) {
+	res := r.db.Model(&model.SchedulerLease{}).
+		Where("name = ? AND holder_id = ? AND expires_at > ?", name, holderID, now).
+		Updates(map[string]interface{}{
+			"renewed_at": now,
+			"expires_at": now.Add(ttl),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+/**
+ * TryAcquire 抢占已过期（或从未被持有）的租约
+ *
+ * 条件更新保证同一时刻只有一个实例能抢占成功
+ *
+ * @return (bool, error) - 是否抢占成功
+ */
+func (r *LeaseRepository) TryAcquire(name, holderID string, now time.Time, ttl time.Duration) (bool, error) {
+	res := r.db.Model(&model.SchedulerLease{}).
+		Where("name = ? AND (expires_at IS NULL OR expires_at <= ?)", name, now).
+		Updates(map[string]interface{}{
+			"holder_id":   holderID,
+			"acquired_at": now,
+			"renewed_at":  now,
+			"expires_at":  now.Add(ttl),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+/**
+ * Release 主动释放租约（实例退出时调用，便于其他实例立即接管）
+ *
+ * @return error
+ */
+func (r *LeaseRepository) Release(name, holderID string, now time.Time) error {
+	return r.db.Model(&model.SchedulerLease{}).
+		Where("name = ? AND holder_id = ?", name, holderID).
+		Update("expires_at", now).Error
+}
+
+func (r *LeaseRepository) FindByName(name string) (*model.SchedulerLease, error) {
+	var lease model.SchedulerLease
+	if err := r.db.Where("name = ?", name).First(&lease).Error; err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
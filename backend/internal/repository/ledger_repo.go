@@ -0,0 +1,118 @@
+/**
+ * 复式记账总账仓储层
+ *
+ * 用途：
+ * - 科目的查询、创建与余额增量更新
+ * - 凭证（含分录）的写入与查询
+ * - 试算平衡、按科目汇总分录（核对科目余额）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// FindAccount 按科目代码和客户ID查询科目（平台科目userID为0）
+func (r *LedgerRepository) FindAccount(code string, userID uint) (*model.LedgerAccount, error) {
+	var account model.LedgerAccount
+	if err := r.db.Where("code = ? AND user_id = ?", code, userID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *LedgerRepository) CreateAccount(account *model.LedgerAccount) error {
+	return r.db.Create(account).Error
+}
+
+// AddBalance 按增量更新科目余额（在数据库中累加，避免并发丢失更新）
+func (r *LedgerRepository) AddBalance(accountID uint, delta float64) error {
+	return r.db.Model(&model.LedgerAccount{}).Where("id = ?", accountID).
+		Update("balance", gorm.Expr("balance + ?", delta)).Error
+}
+
+// CreateEntry 写入凭证及其分录
+func (r *LedgerRepository) CreateEntry(entry *model.JournalEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// FindAccountsByUser 查询客户的分户科目
+func (r *LedgerRepository) FindAccountsByUser(userID uint) ([]*model.LedgerAccount, error) {
+	var accounts []*model.LedgerAccount
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// FindAllAccounts 查询全部科目
+func (r *LedgerRepository) FindAllAccounts() ([]*model.LedgerAccount, error) {
+	var accounts []*model.LedgerAccount
+	err := r.db.Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// FindEntries 分页查询凭证（userID为0、entryType为空时不筛选）
+func (r *LedgerRepository) FindEntries(userID uint, entryType string, limit, offset int) ([]*model.JournalEntry, int64, error) {
+	var entries []*model.JournalEntry
+	var total int64
+
+	query := r.db.Model(&model.JournalEntry{})
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if entryType != "" {
+		query = query.Where("type = ?", entryType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Lines").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// SumLines 汇总全部分录的借方、贷方合计（试算平衡）
+func (r *LedgerRepository) SumLines() (float64, float64, error) {
+	var result struct {
+		Debit  float64
+		Credit float64
+	}
+	err := r.db.Model(&model.JournalLine{}).
+		Select("COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit").
+		Scan(&result).Error
+	return result.Debit, result.Credit, err
+}
+
+// AccountLineSum 单个科目的分录汇总
+type AccountLineSum struct {
+	AccountID uint
+	Debit     float64
+	Credit    float64
+}
+
+// SumLinesByAccount 按科目汇总分录借方、贷方合计
+func (r *LedgerRepository) SumLinesByAccount() ([]AccountLineSum, error) {
+	var sums []AccountLineSum
+	err := r.db.Model(&model.JournalLine{}).
+		Select("account_id, COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit").
+		Group("account_id").
+		Scan(&sums).Error
+	return sums, err
+}
@@ -0,0 +1,39 @@
+/**
+ * 行锁辅助
+ *
+ * 用途：
+ * - 在事务中对单行加写锁，防止并发读改写丢失更新
+ * - MySQL 使用 SELECT ... FOR UPDATE
+ * - SQLite 不支持 FOR UPDATE，先对目标行执行一次空更新以获取写锁（等价于 BEGIN IMMEDIATE）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * lockByID 在事务中锁定并读取指定ID的记录
+ *
+ * 必须在事务（tx）上调用，事务提交或回滚后锁自动释放
+ *
+ * @param db *gorm.DB - 事务连接
+ * @param table string - 表名
+ * @param id uint - 主键
+ * @param dest interface{} - 读取目标
+ * @return error
+ */
+func lockByID(db *gorm.DB, table string, id uint, dest interface{}) error {
+	if db.Dialector.Name() == "sqlite" {
+		if err := db.Exec("UPDATE "+table+" SET updated_at = updated_at WHERE id = ?", id).Error; err != nil {
+			return err
+		}
+		return db.First(dest, id).Error
+	}
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).First(dest, id).Error
+}
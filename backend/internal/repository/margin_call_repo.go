@@ -0,0 +1,98 @@
+/**
+ * 追保记录仓储层
+ *
+ * 用途：
+ * - 封装追保记录的数据访问
+ * - 支持按订单查询进行中的追保、按状态分页查询
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type MarginCallRepository struct {
+	db *gorm.DB
+}
+
+func NewMarginCallRepository(db *gorm.DB) *MarginCallRepository {
+	return &MarginCallRepository{db: db}
+}
+
+func (r *MarginCallRepository) Create(call *model.MarginCall) error {
+	return r.db.Create(call).Error
+}
+
+func (r *MarginCallRepository) Update(call *model.MarginCall) error {
+	return r.db.Omit("User", "Order").Save(call).Error
+}
+
+func (r *MarginCallRepository) FindByID(id uint) (*model.MarginCall, error) {
+	var call model.MarginCall
+	if err := r.db.Preload("User").Preload("Order").First(&call, id).Error; err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// FindOpenByOrderID 查询订单进行中的追保（每个订单同一时间最多一条）
+func (r *MarginCallRepository) FindOpenByOrderID(orderID uint) (*model.MarginCall, error) {
+	var call model.MarginCall
+	err := r.db.Where("order_id = ? AND status = ?", orderID, model.MarginCallStatusOpen).
+		Order("id DESC").
+		First(&call).Error
+	if err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// FindOpen 查询所有进行中的追保
+func (r *MarginCallRepository) FindOpen() ([]*model.MarginCall, error) {
+	var calls []*model.MarginCall
+	err := r.db.Where("status = ?", model.MarginCallStatusOpen).
+		Order("deadline ASC").
+		Find(&calls).Error
+	return calls, err
+}
+
+// FindByStatus 按状态分页查询（status为空查询全部）
+func (r *MarginCallRepository) FindByStatus(status string, limit, offset int) ([]*model.MarginCall, int64, error) {
+	var calls []*model.MarginCall
+	var total int64
+
+	query := r.db.Model(&model.MarginCall{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").Preload("Order").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&calls).Error
+	return calls, total, err
+}
+
+func (r *MarginCallRepository) FindByUserID(userID uint, status string, limit, offset int) ([]*model.MarginCall, error) {
+	var calls []*model.MarginCall
+	query := r.db.Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Preload("Order").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&calls).Error
+	return calls, err
+}
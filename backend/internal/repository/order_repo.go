@@ -59,6 +59,22 @@ func (r *OrderRepository) FindByID(id uint) (*model.Order, error) {
 	return &order, nil
 }
 
+/**
+ * LockByID 在事务中锁定并读取订单
+ * 
+ * 用途：结算/强平/补定金前锁定订单，防止同一订单被并发处理
+ * 
+ * @param id uint - 订单ID
+ * @return (*model.Order, error)
+ */
+func (r *OrderRepository) LockByID(id uint) (*model.Order, error) {
+	var order model.Order
+	if err := lockByID(r.db, "orders", id, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 /**
  * FindByOrderID 根据订单号查找订单
  * 
@@ -0,0 +1,88 @@
+/**
+ * 在线支付单仓储层
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (r *PaymentRepository) Create(order *model.PaymentOrder) error {
+	return r.db.Create(order).Error
+}
+
+func (r *PaymentRepository) Update(order *model.PaymentOrder) error {
+	return r.db.Save(order).Error
+}
+
+func (r *PaymentRepository) FindByOrderNo(orderNo string) (*model.PaymentOrder, error) {
+	var order model.PaymentOrder
+	if err := r.db.Where("order_no = ?", orderNo).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// LockByOrderNo 在事务中锁定并读取支付单
+func (r *PaymentRepository) LockByOrderNo(orderNo string) (*model.PaymentOrder, error) {
+	order, err := r.FindByOrderNo(orderNo)
+	if err != nil {
+		return nil, err
+	}
+	var locked model.PaymentOrder
+	if err := lockByID(r.db, "payment_orders", order.ID, &locked); err != nil {
+		return nil, err
+	}
+	return &locked, nil
+}
+
+// FindByUserID 分页查询用户的支付单
+func (r *PaymentRepository) FindByUserID(userID uint, limit, offset int) ([]*model.PaymentOrder, int64, error) {
+	return r.find(r.db.Where("user_id = ?", userID), limit, offset)
+}
+
+// FindByStatus 分页查询支付单（status为空时不筛选）
+func (r *PaymentRepository) FindByStatus(status string, limit, offset int) ([]*model.PaymentOrder, int64, error) {
+	query := r.db
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return r.find(query, limit, offset)
+}
+
+// FindStuck 查询待支付且最近查单早于 queriedBefore 的支付单（创建早于 createdBefore）
+func (r *PaymentRepository) FindStuck(createdBefore, queriedBefore time.Time, limit int) ([]*model.PaymentOrder, error) {
+	var orders []*model.PaymentOrder
+	err := r.db.Where("status = ? AND created_at < ?", model.PaymentStatusPending, createdBefore).
+		Where("last_query_at IS NULL OR last_query_at < ?", queriedBefore).
+		Order("id ASC").Limit(limit).Find(&orders).Error
+	return orders, err
+}
+
+func (r *PaymentRepository) find(query *gorm.DB, limit, offset int) ([]*model.PaymentOrder, int64, error) {
+	var orders []*model.PaymentOrder
+	var total int64
+
+	query = query.Model(&model.PaymentOrder{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, total, err
+}
@@ -0,0 +1,72 @@
+/**
+ * 价格提醒仓储层
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type PriceAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceAlertRepository(db *gorm.DB) *PriceAlertRepository {
+	return &PriceAlertRepository{db: db}
+}
+
+func (r *PriceAlertRepository) Create(alert *model.PriceAlert) error {
+	return r.db.Create(alert).Error
+}
+
+func (r *PriceAlertRepository) Update(alert *model.PriceAlert) error {
+	return r.db.Save(alert).Error
+}
+
+func (r *PriceAlertRepository) Delete(alert *model.PriceAlert) error {
+	return r.db.Delete(alert).Error
+}
+
+// FindByUserAndID 查询用户自己的提醒
+func (r *PriceAlertRepository) FindByUserAndID(userID, id uint) (*model.PriceAlert, error) {
+	var alert model.PriceAlert
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&alert).Error; err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *PriceAlertRepository) FindByUser(userID uint) ([]*model.PriceAlert, error) {
+	var alerts []*model.PriceAlert
+	err := r.db.Where("user_id = ?", userID).
+		Order("id DESC").
+		Find(&alerts).Error
+	return alerts, err
+}
+
+// CountEnabledByUser 统计用户启用中的提醒数
+func (r *PriceAlertRepository) CountEnabledByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PriceAlert{}).
+		Where("user_id = ? AND enabled = ?", userID, true).
+		Count(&count).Error
+	return count, err
+}
+
+// FindEnabled 查询所有启用中的提醒（行情触发判断用）
+func (r *PriceAlertRepository) FindEnabled() ([]*model.PriceAlert, error) {
+	var alerts []*model.PriceAlert
+	err := r.db.Where("enabled = ?", true).Find(&alerts).Error
+	return alerts, err
+}
+
+// UpdateFields 更新指定字段（触发时避免覆盖客户同时做的修改）
+func (r *PriceAlertRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.PriceAlert{}).Where("id = ?", id).Updates(fields).Error
+}
@@ -0,0 +1,101 @@
+/**
+ * 行情历史仓储层
+ *
+ * 用途：
+ * - 行情Tick归档的批量写入与按时间范围读取
+ * - K线的写入（按唯一键覆盖）与区间查询
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"suxin/internal/model"
+)
+
+type QuoteTickRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteTickRepository(db *gorm.DB) *QuoteTickRepository {
+	return &QuoteTickRepository{db: db}
+}
+
+// CreateBatch 批量写入Tick
+func (r *QuoteTickRepository) CreateBatch(ticks []*model.QuoteTick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(ticks, 500).Error
+}
+
+// FindRange 按ID游标读取时间范围内的Tick（symbol为空表示全部品种）
+func (r *QuoteTickRepository) FindRange(symbol string, from, to time.Time, afterID uint, limit int) ([]*model.QuoteTick, error) {
+	var ticks []*model.QuoteTick
+	query := r.db.Where("id > ? AND tick_at >= ? AND tick_at < ?", afterID, from, to)
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+	err := query.Order("id ASC").Limit(limit).Find(&ticks).Error
+	return ticks, err
+}
+
+type QuoteCandleRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteCandleRepository(db *gorm.DB) *QuoteCandleRepository {
+	return &QuoteCandleRepository{db: db}
+}
+
+// Upsert 写入K线，同一品种/周期/开始时间已存在时覆盖OHLC
+func (r *QuoteCandleRepository) Upsert(candle *model.QuoteCandle) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}, {Name: "period"}, {Name: "open_time"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open", "high", "low", "close", "tick_count", "updated_at"}),
+	}).Create(candle).Error
+}
+
+// FindOne 查询指定K线（不存在返回nil）
+func (r *QuoteCandleRepository) FindOne(symbol, interval string, openTime time.Time) (*model.QuoteCandle, error) {
+	var candles []*model.QuoteCandle
+	err := r.db.Where("symbol = ? AND period = ? AND open_time = ?", symbol, interval, openTime).
+		Limit(1).
+		Find(&candles).Error
+	if err != nil || len(candles) == 0 {
+		return nil, err
+	}
+	return candles[0], nil
+}
+
+// FindRange 查询时间范围内的K线（按时间升序，取最近limit根）
+func (r *QuoteCandleRepository) FindRange(symbol, interval string, from, to time.Time, limit int) ([]*model.QuoteCandle, error) {
+	var candles []*model.QuoteCandle
+	err := r.db.Where("symbol = ? AND period = ? AND open_time >= ? AND open_time <= ?", symbol, interval, from, to).
+		Order("open_time DESC").
+		Limit(limit).
+		Find(&candles).Error
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(candles)-1; i < j; i, j = i+1, j-1 {
+		candles[i], candles[j] = candles[j], candles[i]
+	}
+	return candles, nil
+}
+
+// DeleteRange 删除时间范围内的K线（symbol为空表示全部品种），用于重建
+func (r *QuoteCandleRepository) DeleteRange(symbol string, from, to time.Time) error {
+	query := r.db.Where("open_time >= ? AND open_time < ?", from, to)
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+	return query.Delete(&model.QuoteCandle{}).Error
+}
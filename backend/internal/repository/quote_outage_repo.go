@@ -0,0 +1,64 @@
+/**
+ * 行情中断记录仓储层
+ *
+ * 用途：
+ * - 封装降级模式记录的数据访问
+ * - 支持查询当前未结束的降级记录
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type QuoteOutageRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteOutageRepository(db *gorm.DB) *QuoteOutageRepository {
+	return &QuoteOutageRepository{db: db}
+}
+
+func (r *QuoteOutageRepository) Create(outage *model.QuoteOutage) error {
+	return r.db.Create(outage).Error
+}
+
+func (r *QuoteOutageRepository) Update(outage *model.QuoteOutage) error {
+	return r.db.Save(outage).Error
+}
+
+func (r *QuoteOutageRepository) FindByID(id uint) (*model.QuoteOutage, error) {
+	var outage model.QuoteOutage
+	if err := r.db.First(&outage, id).Error; err != nil {
+		return nil, err
+	}
+	return &outage, nil
+}
+
+// FindOngoing 查询尚未结束的降级记录（服务重启后用于接续）
+func (r *QuoteOutageRepository) FindOngoing() ([]*model.QuoteOutage, error) {
+	var outages []*model.QuoteOutage
+	err := r.db.Where("ended_at IS NULL").
+		Order("started_at DESC").
+		Find(&outages).Error
+	return outages, err
+}
+
+func (r *QuoteOutageRepository) FindRecent(limit, offset int) ([]*model.QuoteOutage, int64, error) {
+	var outages []*model.QuoteOutage
+	var total int64
+	if err := r.db.Model(&model.QuoteOutage{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.db.Order("started_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&outages).Error
+	return outages, total, err
+}
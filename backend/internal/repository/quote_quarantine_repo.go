@@ -0,0 +1,70 @@
+/**
+ * 异常Tick隔离日志仓储层
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type QuoteQuarantineRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteQuarantineRepository(db *gorm.DB) *QuoteQuarantineRepository {
+	return &QuoteQuarantineRepository{db: db}
+}
+
+// CreateBatch 批量写入隔离记录
+func (r *QuoteQuarantineRepository) CreateBatch(items []*model.QuoteQuarantine) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(items, 500).Error
+}
+
+func (r *QuoteQuarantineRepository) FindByID(id uint) (*model.QuoteQuarantine, error) {
+	var item model.QuoteQuarantine
+	if err := r.db.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *QuoteQuarantineRepository) Update(item *model.QuoteQuarantine) error {
+	return r.db.Save(item).Error
+}
+
+// FindList 分页查询隔离记录（条件为空表示不限；reviewed 为nil表示不限）
+func (r *QuoteQuarantineRepository) FindList(symbol, source, reason string, reviewed *bool, limit, offset int) ([]*model.QuoteQuarantine, int64, error) {
+	query := r.db.Model(&model.QuoteQuarantine{})
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	if reviewed != nil {
+		query = query.Where("reviewed = ?", *reviewed)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []*model.QuoteQuarantine
+	err := query.Order("tick_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, total, err
+}
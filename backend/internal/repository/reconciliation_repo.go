@@ -0,0 +1,189 @@
+/**
+ * 资金对账仓储层
+ *
+ * 用途：
+ * - 对账任务、对账不一致记录的读写
+ * - 对账所需的按客户汇总查询（持仓定金、充值、提现、已实现盈亏、最近资金流水）
+ *
+ * 说明：
+ * - 汇总查询的 userID 为0时汇总全部客户，返回以用户ID为键的映射
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func (r *ReconciliationRepository) CreateRun(run *model.ReconciliationRun) error {
+	return r.db.Create(run).Error
+}
+
+func (r *ReconciliationRepository) UpdateRun(run *model.ReconciliationRun) error {
+	return r.db.Save(run).Error
+}
+
+func (r *ReconciliationRepository) FindRunByID(id uint) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	if err := r.db.First(&run, id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindLatestRun 查询指定触发方式的最近一次对账任务
+func (r *ReconciliationRepository) FindLatestRun(triggerType string) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	err := r.db.Where("trigger_type = ?", triggerType).Order("id DESC").First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindRuns 分页查询对账任务（userID为0时不筛选）
+func (r *ReconciliationRepository) FindRuns(userID uint, limit, offset int) ([]*model.ReconciliationRun, int64, error) {
+	var runs []*model.ReconciliationRun
+	var total int64
+
+	query := r.db.Model(&model.ReconciliationRun{})
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&runs).Error
+	return runs, total, err
+}
+
+func (r *ReconciliationRepository) CreateMismatches(mismatches []*model.ReconciliationMismatch) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(mismatches, 200).Error
+}
+
+func (r *ReconciliationRepository) FindMismatchByID(id uint) (*model.ReconciliationMismatch, error) {
+	var mismatch model.ReconciliationMismatch
+	if err := r.db.First(&mismatch, id).Error; err != nil {
+		return nil, err
+	}
+	return &mismatch, nil
+}
+
+func (r *ReconciliationRepository) UpdateMismatch(mismatch *model.ReconciliationMismatch) error {
+	return r.db.Save(mismatch).Error
+}
+
+// FindMismatches 分页查询对账不一致记录（runID、userID为0，resolved为nil时不筛选）
+func (r *ReconciliationRepository) FindMismatches(runID, userID uint, resolved *bool, limit, offset int) ([]*model.ReconciliationMismatch, int64, error) {
+	var mismatches []*model.ReconciliationMismatch
+	var total int64
+
+	query := r.db.Model(&model.ReconciliationMismatch{})
+	if runID > 0 {
+		query = query.Where("run_id = ?", runID)
+	}
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if resolved != nil {
+		query = query.Where("resolved = ?", *resolved)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&mismatches).Error
+	return mismatches, total, err
+}
+
+// userAmount 按客户汇总的金额
+type userAmount struct {
+	UserID uint
+	Amount float64
+}
+
+// sumByUser 执行按客户汇总查询
+func sumByUser(query *gorm.DB, userID uint) (map[uint]float64, error) {
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var rows []userAmount
+	if err := query.Group("user_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		result[row.UserID] = row.Amount
+	}
+	return result, nil
+}
+
+// SumHoldingDeposits 按客户汇总持仓订单定金
+func (r *ReconciliationRepository) SumHoldingDeposits(userID uint) (map[uint]float64, error) {
+	return sumByUser(r.db.Model(&model.Order{}).
+		Select("user_id, COALESCE(SUM(deposit), 0) AS amount").
+		Where("status = ?", model.OrderStatusHolding), userID)
+}
+
+// SumApprovedDeposits 按客户汇总已入账充值
+func (r *ReconciliationRepository) SumApprovedDeposits(userID uint) (map[uint]float64, error) {
+	return sumByUser(r.db.Model(&model.DepositRequest{}).
+		Select("user_id, COALESCE(SUM(amount), 0) AS amount").
+		Where("status = ?", model.DepositStatusApproved), userID)
+}
+
+// SumWithdrawals 按客户汇总已扣款提现（已通过、已打款）
+func (r *ReconciliationRepository) SumWithdrawals(userID uint) (map[uint]float64, error) {
+	return sumByUser(r.db.Model(&model.WithdrawRequest{}).
+		Select("user_id, COALESCE(SUM(amount), 0) AS amount").
+		Where("status IN ?", []string{model.WithdrawStatusApproved, model.WithdrawStatusPaid}), userID)
+}
+
+// SumRealizedPnL 按客户汇总已实现盈亏（已结算/已平仓订单的总盈亏 + 持仓订单的部分平仓已实现盈亏）
+func (r *ReconciliationRepository) SumRealizedPnL(userID uint) (map[uint]float64, error) {
+	// 注意：SettledPnL 未指定列名，gorm 默认列名为 settled_pn_l；已结算订单的 settled_pn_l 已包含 realized_pnl
+	return sumByUser(r.db.Model(&model.Order{}).
+		Select(`user_id, COALESCE(SUM(CASE WHEN status IN ? THEN settled_pn_l ELSE realized_pnl END), 0) AS amount`,
+			[]string{model.OrderStatusSettled, model.OrderStatusClosed}), userID)
+}
+
+// SumAdjustments 按客户汇总已入账的手工余额调整（调减为负数）
+func (r *ReconciliationRepository) SumAdjustments(userID uint) (map[uint]float64, error) {
+	return sumByUser(r.db.Model(&model.BalanceAdjustment{}).
+		Select("user_id, COALESCE(SUM(amount), 0) AS amount").
+		Where("status = ?", model.AdjustmentStatusApplied), userID)
+}
+
+// FindLatestFundLogs 查询每个客户最近一条资金流水
+func (r *ReconciliationRepository) FindLatestFundLogs(userID uint) (map[uint]*model.FundLog, error) {
+	latest := r.db.Model(&model.FundLog{}).Select("MAX(id)").Group("user_id")
+	if userID > 0 {
+		latest = latest.Where("user_id = ?", userID)
+	}
+	var logs []*model.FundLog
+	if err := r.db.Where("id IN (?)", latest).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint]*model.FundLog, len(logs))
+	for _, log := range logs {
+		result[log.UserID] = log
+	}
+	return result, nil
+}
@@ -37,6 +37,15 @@ func (r *SupplementRepository) FindByID(id uint) (*model.SupplementDeposit, erro
 	return &supplement, nil
 }
 
+// LockByID 在事务中锁定并读取补定金申请（防止重复审核）
+func (r *SupplementRepository) LockByID(id uint) (*model.SupplementDeposit, error) {
+	var supplement model.SupplementDeposit
+	if err := lockByID(r.db, "supplement_deposits", id, &supplement); err != nil {
+		return nil, err
+	}
+	return &supplement, nil
+}
+
 func (r *SupplementRepository) FindByUserID(userID uint, limit, offset int) ([]*model.SupplementDeposit, error) {
 	var supplements []*model.SupplementDeposit
 	err := r.db.Where("user_id = ?", userID).
@@ -36,6 +36,15 @@ func (r *UserRepository) Create(u *model.User) error {
 	return r.db.Create(u).Error
 }
 
+// LockByID 在事务中锁定并读取用户（资金变更前调用，需传入事务构造的仓储）
+func (r *UserRepository) LockByID(id uint) (*model.User, error) {
+	var u model.User
+	if err := lockByID(r.db, "users", id, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 func (r *UserRepository) Update(u *model.User) error {
 	return r.db.Save(u).Error
 }
@@ -63,3 +72,10 @@ func (r *UserRepository) FindBySalesID(salesID uint) ([]*model.User, error) {
 func (r *UserRepository) UpdateSalesID(userID, salesID uint) error {
 	return r.db.Model(&model.User{}).Where("id = ?", userID).Update("sales_id", salesID).Error
 }
+
+// FindAllBalances 查询全部用户的资金余额（对账用，只读取ID和余额字段）
+func (r *UserRepository) FindAllBalances() ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.Select("id", "phone", "available_deposit", "used_deposit", "withdraw_frozen").Order("id ASC").Find(&users).Error
+	return users, err
+}
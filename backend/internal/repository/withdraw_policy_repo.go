@@ -0,0 +1,44 @@
+/**
+ * 提现策略覆盖仓储层
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"suxin/internal/model"
+)
+
+type WithdrawPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewWithdrawPolicyRepository(db *gorm.DB) *WithdrawPolicyRepository {
+	return &WithdrawPolicyRepository{db: db}
+}
+
+func (r *WithdrawPolicyRepository) FindByUserID(userID uint) (*model.WithdrawPolicyOverride, error) {
+	var override model.WithdrawPolicyOverride
+	if err := r.db.Where("user_id = ?", userID).First(&override).Error; err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *WithdrawPolicyRepository) FindAll() ([]*model.WithdrawPolicyOverride, error) {
+	var overrides []*model.WithdrawPolicyOverride
+	err := r.db.Order("id DESC").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *WithdrawPolicyRepository) Save(override *model.WithdrawPolicyOverride) error {
+	return r.db.Save(override).Error
+}
+
+func (r *WithdrawPolicyRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.WithdrawPolicyOverride{}).Error
+}
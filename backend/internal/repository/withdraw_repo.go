@@ -12,6 +12,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"suxin/internal/model"
@@ -37,6 +39,15 @@ func (r *WithdrawRepository) FindByID(id uint) (*model.WithdrawRequest, error) {
 	return &withdraw, nil
 }
 
+// LockByID 在事务中锁定并读取提现申请（防止重复审核）
+func (r *WithdrawRepository) LockByID(id uint) (*model.WithdrawRequest, error) {
+	var withdraw model.WithdrawRequest
+	if err := lockByID(r.db, "withdraw_requests", id, &withdraw); err != nil {
+		return nil, err
+	}
+	return &withdraw, nil
+}
+
 func (r *WithdrawRepository) FindByUserID(userID uint, limit, offset int) ([]*model.WithdrawRequest, error) {
 	var withdraws []*model.WithdrawRequest
 	err := r.db.Where("user_id = ?", userID).
@@ -47,9 +58,11 @@ func (r *WithdrawRepository) FindByUserID(userID uint, limit, offset int) ([]*mo
 	return withdraws, err
 }
 
+// FindPending 查询审批中（待审核、待复核）的提现申请
 func (r *WithdrawRepository) FindPending(limit int) ([]*model.WithdrawRequest, error) {
 	var withdraws []*model.WithdrawRequest
-	err := r.db.Preload("User").Where("status = ?", model.WithdrawStatusPending).
+	err := r.db.Preload("User").
+		Where("status IN ?", []string{model.WithdrawStatusPending, model.WithdrawStatusReviewing}).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&withdraws).Error
@@ -72,3 +85,18 @@ func (r *WithdrawRepository) FindByStatus(status string, limit int) ([]*model.Wi
 func (r *WithdrawRepository) Update(withdraw *model.WithdrawRequest) error {
 	return r.db.Save(withdraw).Error
 }
+
+// SumActiveSince 统计用户自某时间起的有效提现（待审核、待复核、已通过、已打款）次数和金额
+func (r *WithdrawRepository) SumActiveSince(userID uint, since time.Time) (int64, float64, error) {
+	var row struct {
+		Count  int64
+		Amount float64
+	}
+	err := r.db.Model(&model.WithdrawRequest{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+		Where("user_id = ? AND created_at >= ? AND status IN ?", userID, since,
+			[]string{model.WithdrawStatusPending, model.WithdrawStatusReviewing,
+				model.WithdrawStatusApproved, model.WithdrawStatusPaid}).
+		Scan(&row).Error
+	return row.Count, row.Amount, err
+}
@@ -0,0 +1,93 @@
+/**
+ * 月度对账单定时任务
+ *
+ * 用途：
+ * - 定期检查是否已进入新的月份，进入后为客户生成上月对账单
+ *
+ * 说明：
+ * - 是否为主实例、上月对账单是否已生成由对账单服务判断，本任务只负责按间隔驱动
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"suxin/internal/service"
+)
+
+/**
+ * AccountStatementScheduler 月度对账单调度器
+ */
+type AccountStatementScheduler struct {
+	statement *service.AccountStatementService
+	ticker    *time.Ticker
+	stopChan  chan bool
+	interval  time.Duration
+}
+
+/**
+ * NewAccountStatementScheduler 创建月度对账单调度器实例
+ *
+ * @param statement *service.AccountStatementService - 账户对账单服务
+ * @param intervalSeconds int - 检查间隔（秒）
+ * @return *AccountStatementScheduler
+ */
+func NewAccountStatementScheduler(statement *service.AccountStatementService, intervalSeconds int) *AccountStatementScheduler {
+	return &AccountStatementScheduler{
+		statement: statement,
+		stopChan:  make(chan bool),
+		interval:  time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+/**
+ * Start 启动月度对账单调度器
+ *
+ * @return void
+ */
+func (s *AccountStatementScheduler) Start() {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.runCheck()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	log.Printf("[Statement] ✅ 月度对账单调度器已启动，检查间隔: %v", s.interval)
+}
+
+/**
+ * runCheck 检查并生成上月对账单
+ */
+func (s *AccountStatementScheduler) runCheck() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Statement] ❌ 生成月度对账单发生异常: %v", r)
+		}
+	}()
+
+	s.statement.RunMonthEnd()
+}
+
+/**
+ * Stop 停止月度对账单调度器
+ *
+ * @return void
+ */
+func (s *AccountStatementScheduler) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+	log.Println("[Statement] ✅ 月度对账单调度器已停止")
+}
@@ -0,0 +1,93 @@
+/**
+ * 数据导出定时任务
+ *
+ * 用途：
+ * - 定期执行排队中的异步导出任务，并清理过期的导出文件
+ *
+ * 说明：
+ * - 是否为主实例由导出服务判断，本任务只负责按间隔驱动；任务依次执行，执行期间不会重复触发
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"suxin/internal/service"
+)
+
+/**
+ * ExportScheduler 数据导出调度器
+ */
+type ExportScheduler struct {
+	export   *service.ExportService
+	ticker   *time.Ticker
+	stopChan chan bool
+	interval time.Duration
+}
+
+/**
+ * NewExportScheduler 创建数据导出调度器实例
+ *
+ * @param export *service.ExportService - 数据导出服务
+ * @param intervalSeconds int - 检查间隔（秒）
+ * @return *ExportScheduler
+ */
+func NewExportScheduler(export *service.ExportService, intervalSeconds int) *ExportScheduler {
+	return &ExportScheduler{
+		export:   export,
+		stopChan: make(chan bool),
+		interval: time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+/**
+ * Start 启动数据导出调度器
+ *
+ * @return void
+ */
+func (s *ExportScheduler) Start() {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.runCheck()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	log.Printf("[Export] ✅ 数据导出调度器已启动，检查间隔: %v", s.interval)
+}
+
+/**
+ * runCheck 执行排队中的导出任务
+ */
+func (s *ExportScheduler) runCheck() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Export] ❌ 执行导出任务发生异常: %v", r)
+		}
+	}()
+
+	s.export.RunPendingJobs()
+}
+
+/**
+ * Stop 停止数据导出调度器
+ *
+ * @return void
+ */
+func (s *ExportScheduler) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+	log.Println("[Export] ✅ 数据导出调度器已停止")
+}
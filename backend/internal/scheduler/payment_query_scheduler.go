@@ -0,0 +1,93 @@
+/**
+ * 在线支付查单定时任务
+ *
+ * 用途：
+ * - 定期对超过等待时间仍未收到回调的支付单主动查单，已支付的补单入账，过期的关闭
+ *
+ * 说明：
+ * - 是否为主实例、哪些支付单需要查单由在线支付服务判断，本任务只负责按间隔驱动
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"suxin/internal/service"
+)
+
+/**
+ * PaymentQueryScheduler 在线支付查单调度器
+ */
+type PaymentQueryScheduler struct {
+	payment  *service.PaymentService
+	ticker   *time.Ticker
+	stopChan chan bool
+	interval time.Duration
+}
+
+/**
+ * NewPaymentQueryScheduler 创建在线支付查单调度器实例
+ *
+ * @param payment *service.PaymentService - 在线支付服务
+ * @param intervalSeconds int - 查单间隔（秒）
+ * @return *PaymentQueryScheduler
+ */
+func NewPaymentQueryScheduler(payment *service.PaymentService, intervalSeconds int) *PaymentQueryScheduler {
+	return &PaymentQueryScheduler{
+		payment:  payment,
+		stopChan: make(chan bool),
+		interval: time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+/**
+ * Start 启动在线支付查单调度器
+ *
+ * @return void
+ */
+func (s *PaymentQueryScheduler) Start() {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.runQuery()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	log.Printf("[Payment] ✅ 在线支付查单调度器已启动，查单间隔: %v", s.interval)
+}
+
+/**
+ * runQuery 执行一轮查单
+ */
+func (s *PaymentQueryScheduler) runQuery() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Payment] ❌ 支付查单发生异常: %v", r)
+		}
+	}()
+
+	s.payment.QueryStuckOrders()
+}
+
+/**
+ * Stop 停止在线支付查单调度器
+ *
+ * @return void
+ */
+func (s *PaymentQueryScheduler) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+	log.Println("[Payment] ✅ 在线支付查单调度器已停止")
+}
@@ -0,0 +1,94 @@
+/**
+ * 行情保护定时任务
+ *
+ * 用途：
+ * - 定期检查行情新鲜度
+ * - 驱动平台降级模式的进入与自动恢复
+ *
+ * 说明：
+ * - 每个实例都维护自己的上游行情连接，因此每个实例都需要运行本任务
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"suxin/internal/service"
+)
+
+/**
+ * QuoteFailsafeScheduler 行情保护调度器
+ */
+type QuoteFailsafeScheduler struct {
+	failsafe *service.QuoteFailsafeService
+	ticker   *time.Ticker
+	stopChan chan bool
+	interval time.Duration
+}
+
+/**
+ * NewQuoteFailsafeScheduler 创建行情保护调度器实例
+ *
+ * @param failsafe *service.QuoteFailsafeService - 行情保护服务
+ * @param intervalSeconds int - 检查间隔（秒）
+ * @return *QuoteFailsafeScheduler
+ */
+func NewQuoteFailsafeScheduler(failsafe *service.QuoteFailsafeService, intervalSeconds int) *QuoteFailsafeScheduler {
+	return &QuoteFailsafeScheduler{
+		failsafe: failsafe,
+		stopChan: make(chan bool),
+		interval: time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+/**
+ * Start 启动行情保护调度器
+ *
+ * @return void
+ */
+func (s *QuoteFailsafeScheduler) Start() {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.runCheck()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	log.Printf("[QuoteFailsafe] ✅ 行情保护调度器已启动，检查间隔: %v", s.interval)
+}
+
+/**
+ * runCheck 执行一次行情新鲜度检查
+ */
+func (s *QuoteFailsafeScheduler) runCheck() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[QuoteFailsafe] ❌ 行情保护检查发生异常: %v", r)
+		}
+	}()
+
+	s.failsafe.Evaluate()
+}
+
+/**
+ * Stop 停止行情保护调度器
+ *
+ * @return void
+ */
+func (s *QuoteFailsafeScheduler) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+	log.Println("[QuoteFailsafe] ✅ 行情保护调度器已停止")
+}
@@ -0,0 +1,99 @@
+/**
+ * 行情历史落库定时任务
+ *
+ * 用途：
+ * - 定期将内存中的行情Tick和K线批量写入数据库
+ * - 同时写入异常Tick隔离记录
+ *
+ * 说明：
+ * - 每个实例都运行本任务以清空内存缓存，是否落库由服务内部按选主结果判断
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"suxin/internal/service"
+)
+
+/**
+ * QuoteHistoryScheduler 行情历史落库调度器
+ */
+type QuoteHistoryScheduler struct {
+	history    *service.QuoteHistoryService
+	quarantine *service.QuoteQuarantineService
+	ticker     *time.Ticker
+	stopChan   chan bool
+	interval   time.Duration
+}
+
+/**
+ * NewQuoteHistoryScheduler 创建行情历史落库调度器实例
+ *
+ * @param history *service.QuoteHistoryService - 行情历史服务
+ * @param quarantine *service.QuoteQuarantineService - 隔离日志服务
+ * @param intervalSeconds int - 落库间隔（秒）
+ * @return *QuoteHistoryScheduler
+ */
+func NewQuoteHistoryScheduler(history *service.QuoteHistoryService, quarantine *service.QuoteQuarantineService, intervalSeconds int) *QuoteHistoryScheduler {
+	return &QuoteHistoryScheduler{
+		history:    history,
+		quarantine: quarantine,
+		stopChan:   make(chan bool),
+		interval:   time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+/**
+ * Start 启动行情历史落库调度器
+ *
+ * @return void
+ */
+func (s *QuoteHistoryScheduler) Start() {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.runFlush()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				s.runFlush()
+				return
+			}
+		}
+	}()
+
+	log.Printf("[QuoteHistory] ✅ 行情历史落库调度器已启动，间隔: %v", s.interval)
+}
+
+/**
+ * runFlush 执行一次落库
+ */
+func (s *QuoteHistoryScheduler) runFlush() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[QuoteHistory] ❌ 行情历史落库发生异常: %v", r)
+		}
+	}()
+
+	s.history.Flush()
+	s.quarantine.Flush()
+}
+
+/**
+ * Stop 停止行情历史落库调度器（停止前落库一次）
+ *
+ * @return void
+ */
+func (s *QuoteHistoryScheduler) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+	log.Println("[QuoteHistory] ✅ 行情历史落库调度器已停止")
+}
@@ -0,0 +1,93 @@
+/**
+ * 资金对账定时任务
+ *
+ * 用途：
+ * - 定期检查是否到达每日对账时间，到达且当天尚未对账时执行全量对账
+ *
+ * 说明：
+ * - 是否为主实例、当天是否已对账由对账服务判断，本任务只负责按间隔驱动
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"suxin/internal/service"
+)
+
+/**
+ * ReconciliationScheduler 资金对账调度器
+ */
+type ReconciliationScheduler struct {
+	reconciliation *service.ReconciliationService
+	ticker         *time.Ticker
+	stopChan       chan bool
+	interval       time.Duration
+}
+
+/**
+ * NewReconciliationScheduler 创建资金对账调度器实例
+ *
+ * @param reconciliation *service.ReconciliationService - 资金对账服务
+ * @param intervalSeconds int - 检查间隔（秒）
+ * @return *ReconciliationScheduler
+ */
+func NewReconciliationScheduler(reconciliation *service.ReconciliationService, intervalSeconds int) *ReconciliationScheduler {
+	return &ReconciliationScheduler{
+		reconciliation: reconciliation,
+		stopChan:       make(chan bool),
+		interval:       time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+/**
+ * Start 启动资金对账调度器
+ *
+ * @return void
+ */
+func (s *ReconciliationScheduler) Start() {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.runCheck()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	log.Printf("[Reconcile] ✅ 资金对账调度器已启动，检查间隔: %v", s.interval)
+}
+
+/**
+ * runCheck 检查并执行每日对账
+ */
+func (s *ReconciliationScheduler) runCheck() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Reconcile] ❌ 每日对账发生异常: %v", r)
+		}
+	}()
+
+	s.reconciliation.RunScheduled()
+}
+
+/**
+ * Stop 停止资金对账调度器
+ *
+ * @return void
+ */
+func (s *ReconciliationScheduler) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+	log.Println("[Reconcile] ✅ 资金对账调度器已停止")
+}
@@ -30,6 +30,7 @@ type RiskScheduler struct {
 	ticker       *time.Ticker
 	stopChan     chan bool
 	interval     time.Duration
+	leader       *service.LeaderElectionService // 选主服务（为空表示单实例，始终执行）
 }
 
 /**
@@ -51,6 +52,18 @@ func NewRiskScheduler(ctx *appctx.AppContext, intervalSeconds int, quoteHub serv
 	}
 }
 
+/**
+ * SetLeaderElection 设置选主服务
+ * 
+ * 设置后只有主实例执行风控检查，其他实例的定时器空转，主实例失效后自动接管
+ * 
+ * @param leader *service.LeaderElectionService - 选主服务
+ * @return void
+ */
+func (s *RiskScheduler) SetLeaderElection(leader *service.LeaderElectionService) {
+	s.leader = leader
+}
+
 /**
  * getCurrentMarketPrice 获取当前市场价格
  * 
@@ -126,9 +139,20 @@ func (s *RiskScheduler) runCheck() {
 		}
 	}()
 
+	// 多实例部署时只有主实例执行风控检查，避免重复强平和重复通知
+	if s.leader != nil && !s.leader.IsLeader() {
+		return
+	}
+
 	startTime := time.Now()
 	log.Println("[RiskScheduler] ⏰ 开始执行风控检查...")
 
+	// 行情降级模式下价格不可信，暂停风控强平
+	if err := service.CheckMarketAvailable(); err != nil {
+		log.Println("[RiskScheduler] ⚠️ 平台处于行情降级模式，跳过本次风控检查")
+		return
+	}
+
 	// 获取当前市场价格
 	currentPrice, err := s.getCurrentMarketPrice()
 	if err != nil {
@@ -168,6 +192,7 @@ func (s *RiskScheduler) GetStatus() map[string]interface{} {
 	status := map[string]interface{}{
 		"interval": s.interval.String(),
 		"running":  s.ticker != nil,
+		"active":   s.leader == nil || s.leader.IsLeader(),
 	}
 	
 	// 尝试获取当前价格
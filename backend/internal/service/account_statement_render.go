@@ -0,0 +1,483 @@
+/**
+ * 账户对账单文件生成（PDF/CSV）
+ *
+ * 用途：
+ * - 按对账单记录的周期，从资金流水、订单生成明细，输出 PDF 或 CSV 文件
+ *
+ * 内容：
+ * - 客户信息、账单周期
+ * - 资金汇总：期初/期末余额（可用、已用、提现冻结）、充值、提现、手续费、已实现盈亏、补定金
+ * - 资金明细：本期全部资金流水
+ * - 订单明细：本期开仓或平仓的订单（期末仍持仓的订单按持仓中列示）
+ *
+ * 说明：
+ * - CSV 带 UTF-8 BOM，Excel 可直接打开
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"time"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/pdf"
+)
+
+/**
+ * 对账单文件格式常量
+ */
+const (
+	StatementFormatPDF = "pdf"
+	StatementFormatCSV = "csv"
+)
+
+// statementTitle 对账单标题
+const statementTitle = "速金盈 账户对账单"
+
+/**
+ * StatementFile 对账单文件
+ */
+type StatementFile struct {
+	FileName    string // 下载文件名
+	ContentType string // 内容类型
+	Data        []byte // 文件内容
+}
+
+// statementFundLogTypeText 资金流水类型名称
+var statementFundLogTypeText = map[string]string{
+	model.FundLogTypeDeposit:         "充值入账",
+	model.FundLogTypeWithdraw:        "提现",
+	model.FundLogTypeWithdrawFee:     "提现手续费",
+	model.FundLogTypeWithdrawFreeze:  "提现冻结",
+	model.FundLogTypeWithdrawRelease: "提现解冻",
+	model.FundLogTypeOrderFreeze:     "下单冻结",
+	model.FundLogTypeOrderRelease:    "订单释放",
+	model.FundLogTypeSettle:          "结算",
+	model.FundLogTypeForceClose:      "强制平仓",
+	model.FundLogTypePartialClose:    "部分平仓",
+	model.FundLogTypeSupplement:      "补定金",
+	model.FundLogTypeAdjustment:      "余额调整",
+}
+
+/**
+ * statementData 对账单渲染数据
+ */
+type statementData struct {
+	statement *model.AccountStatement
+	user      *model.User
+	entries   [][]string // 资金明细行
+	orders    [][]string // 订单明细行
+}
+
+// 资金明细、订单明细表头
+var (
+	statementEntryHeader = []string{"时间", "类型", "可用变动", "可用定金", "已用定金", "提现冻结", "备注"}
+	statementOrderHeader = []string{"订单号", "方向", "克重", "锁定价", "开仓时间", "平仓价", "平仓时间", "状态", "已实现盈亏"}
+)
+
+/**
+ * Render 生成对账单文件
+ *
+ * @param statement *model.AccountStatement - 对账单
+ * @param format string - 文件格式（pdf/csv）
+ * @return (*StatementFile, error)
+ */
+func (s *AccountStatementService) Render(statement *model.AccountStatement, format string) (*StatementFile, error) {
+	if format != StatementFormatPDF && format != StatementFormatCSV {
+		return nil, errors.New("不支持的对账单格式")
+	}
+
+	data, err := s.loadStatementData(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("statement_%d_%s.%s", statement.UserID, statementPeriodKey(statement), format)
+	if format == StatementFormatCSV {
+		content, err := renderStatementCSV(data)
+		if err != nil {
+			return nil, err
+		}
+		return &StatementFile{FileName: fileName, ContentType: "text/csv; charset=utf-8", Data: content}, nil
+	}
+	return &StatementFile{FileName: fileName, ContentType: "application/pdf", Data: renderStatementPDF(data)}, nil
+}
+
+/**
+ * loadStatementData 加载对账单明细
+ */
+func (s *AccountStatementService) loadStatementData(statement *model.AccountStatement) (*statementData, error) {
+	user, err := s.userRepo.FindByID(statement.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	logs, err := s.repo.FindFundLogs(statement.UserID, statement.PeriodStart, statement.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("查询资金流水失败: %v", err)
+	}
+	orders, err := s.repo.FindOrdersOpenedOrClosed(statement.UserID, statement.PeriodStart, statement.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单失败: %v", err)
+	}
+
+	data := &statementData{statement: statement, user: user}
+	for _, fundLog := range logs {
+		typeText := statementFundLogTypeText[fundLog.Type]
+		if typeText == "" {
+			typeText = fundLog.Type
+		}
+		data.entries = append(data.entries, []string{
+			fundLog.CreatedAt.In(time.Local).Format("2006-01-02 15:04:05"),
+			typeText,
+			signedMoney(fundLog.AvailableAfter - fundLog.AvailableBefore),
+			money(fundLog.AvailableAfter),
+			money(fundLog.UsedAfter),
+			money(fundLog.FrozenAfter),
+			fundLog.Note,
+		})
+	}
+	for _, order := range orders {
+		typeText := "买料"
+		if order.Type == model.OrderTypeShortSell {
+			typeText = "卖料"
+		}
+		row := []string{
+			order.OrderID,
+			typeText,
+			fmt.Sprintf("%.3f", order.WeightG+order.ClosedWeightG),
+			fmt.Sprintf("%.2f", order.LockedPrice),
+			order.CreatedAt.In(time.Local).Format("2006-01-02 15:04"),
+		}
+		// 期末仍持仓（含期后才平仓）的订单按持仓中列示
+		if orderClosedInPeriod(order, statement.PeriodStart, statement.PeriodEnd) {
+			status := "已结算"
+			if order.Status == model.OrderStatusClosed {
+				status = "强制平仓"
+			}
+			row = append(row,
+				fmt.Sprintf("%.2f", order.SettledPrice),
+				order.SettledAt.In(time.Local).Format("2006-01-02 15:04"),
+				status,
+				signedMoney(order.SettledPnL),
+			)
+		} else {
+			row = append(row, "-", "-", "持仓中", "-")
+		}
+		data.orders = append(data.orders, row)
+	}
+	return data, nil
+}
+
+/**
+ * summaryRows 资金汇总（项目、金额）
+ */
+func (d *statementData) summaryRows() [][]string {
+	st := d.statement
+	return [][]string{
+		{"期初总权益", money(st.OpeningTotal())},
+		{"入账充值", signedMoney(st.Deposits)},
+		{"提现", signedMoney(-st.Withdrawals)},
+		{"手续费", signedMoney(-st.Fees)},
+		{"已实现盈亏", signedMoney(st.RealizedPnL)},
+		{"其他变动", signedMoney(st.OtherAmount)},
+		{"期末总权益", money(st.ClosingTotal())},
+		{"本期补定金", money(st.Supplements)},
+		{"开仓订单数", fmt.Sprintf("%d", st.OrdersOpened)},
+		{"平仓订单数", fmt.Sprintf("%d", st.OrdersClosed)},
+	}
+}
+
+/**
+ * balanceRows 期初/期末余额（项目、期初、期末）
+ */
+func (d *statementData) balanceRows() [][]string {
+	st := d.statement
+	return [][]string{
+		{"可用定金", money(st.OpeningAvailable), money(st.ClosingAvailable)},
+		{"已用定金", money(st.OpeningUsed), money(st.ClosingUsed)},
+		{"提现冻结", money(st.OpeningFrozen), money(st.ClosingFrozen)},
+		{"合计", money(st.OpeningTotal()), money(st.ClosingTotal())},
+	}
+}
+
+/**
+ * infoRows 客户与账单信息（项目、内容）
+ */
+func (d *statementData) infoRows() [][]string {
+	st := d.statement
+	return [][]string{
+		{"对账单编号", fmt.Sprintf("%d", st.ID)},
+		{"客户", d.user.RealName},
+		{"手机号", maskPhone(d.user.Phone)},
+		{"账单类型", statementPeriodText(st)},
+		{"账单周期", fmt.Sprintf("%s 至 %s", st.PeriodStart.In(time.Local).Format("2006-01-02"),
+			st.PeriodEnd.In(time.Local).AddDate(0, 0, -1).Format("2006-01-02"))},
+		{"生成时间", st.CreatedAt.In(time.Local).Format("2006-01-02 15:04:05")},
+	}
+}
+
+/**
+ * renderStatementCSV 生成 CSV 对账单
+ */
+func renderStatementCSV(d *statementData) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{statementTitle})
+	w.WriteAll(d.infoRows())
+	w.Write(nil)
+
+	w.Write([]string{"资金汇总"})
+	w.Write([]string{"项目", "期初", "期末"})
+	w.WriteAll(d.balanceRows())
+	w.Write([]string{"项目", "金额"})
+	w.WriteAll(d.summaryRows())
+	w.Write(nil)
+
+	w.Write([]string{"资金明细"})
+	w.Write(statementEntryHeader)
+	w.WriteAll(d.entries)
+	w.Write(nil)
+
+	w.Write([]string{"订单明细"})
+	w.Write(statementOrderHeader)
+	w.WriteAll(d.orders)
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("生成CSV失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF 版式（单位：点）
+const (
+	statementMargin     = 40.0
+	statementRowHeight  = 16.0
+	statementFontSize   = 8.0
+	statementBottomLine = pdf.PageHeight - 50
+)
+
+/**
+ * pdfColumn PDF 表格列
+ */
+type pdfColumn struct {
+	width float64
+	right bool // 右对齐（金额、数量）
+}
+
+/**
+ * statementPDF PDF 对账单绘制状态
+ */
+type statementPDF struct {
+	doc    *pdf.Document
+	y      float64
+	header string // 页眉（客户、周期）
+}
+
+/**
+ * renderStatementPDF 生成 PDF 对账单
+ */
+func renderStatementPDF(d *statementData) []byte {
+	p := &statementPDF{
+		doc: pdf.New(statementTitle),
+		header: fmt.Sprintf("%s  %s  %s", d.user.RealName, maskPhone(d.user.Phone),
+			statementPeriodText(d.statement)),
+	}
+	p.newPage()
+
+	// 标题
+	title := statementTitle
+	p.doc.Text((pdf.PageWidth-pdf.TextWidth(title, 18))/2, p.y+18, 18, title)
+	p.y += 36
+
+	// 客户与账单信息
+	for _, row := range d.infoRows() {
+		p.doc.Text(statementMargin, p.y+10, 9, row[0]+"：")
+		p.doc.Text(statementMargin+70, p.y+10, 9, row[1])
+		p.y += 15
+	}
+	p.y += 8
+
+	// 资金汇总
+	p.section("一、资金汇总")
+	p.table([]string{"项目", "期初", "期末"},
+		[]pdfColumn{{width: 175}, {width: 170, right: true}, {width: 170, right: true}}, d.balanceRows())
+	p.y += 8
+	p.table([]string{"项目", "金额"},
+		[]pdfColumn{{width: 175}, {width: 170, right: true}}, d.summaryRows())
+	p.y += 8
+
+	// 资金明细
+	p.section("二、资金明细")
+	if len(d.entries) == 0 {
+		p.emptyLine("本期无资金变动")
+	} else {
+		p.table(statementEntryHeader, []pdfColumn{
+			{width: 84}, {width: 50}, {width: 60, right: true}, {width: 64, right: true},
+			{width: 60, right: true}, {width: 55, right: true}, {width: 142},
+		}, d.entries)
+	}
+	p.y += 8
+
+	// 订单明细
+	p.section("三、订单明细")
+	if len(d.orders) == 0 {
+		p.emptyLine("本期无开仓或平仓订单")
+	} else {
+		p.table(statementOrderHeader, []pdfColumn{
+			{width: 88}, {width: 28}, {width: 48, right: true}, {width: 50, right: true}, {width: 66},
+			{width: 50, right: true}, {width: 66}, {width: 45}, {width: 74, right: true},
+		}, d.orders)
+	}
+
+	p.ensureSpace(40)
+	p.y += 20
+	p.doc.Text(statementMargin, p.y, statementFontSize,
+		"本对账单由系统根据资金流水和订单记录生成。如对账单内容有疑问，请在收到后7日内联系客服核对。")
+
+	return p.doc.Bytes()
+}
+
+/**
+ * newPage 新起一页并绘制页眉页脚
+ */
+func (p *statementPDF) newPage() {
+	p.doc.AddPage()
+	page := p.doc.PageCount()
+	if page > 1 {
+		p.doc.Text(statementMargin, 28, statementFontSize, statementTitle)
+		p.doc.TextRight(pdf.PageWidth-statementMargin, 28, statementFontSize, p.header)
+		p.doc.Line(statementMargin, 32, pdf.PageWidth-statementMargin, 32, 0.5)
+	}
+	footer := fmt.Sprintf("第 %d 页", page)
+	p.doc.Text((pdf.PageWidth-pdf.TextWidth(footer, statementFontSize))/2, pdf.PageHeight-25, statementFontSize, footer)
+	p.y = 48
+}
+
+/**
+ * ensureSpace 剩余空间不足时换页，返回是否换页
+ */
+func (p *statementPDF) ensureSpace(h float64) bool {
+	if p.y+h <= statementBottomLine {
+		return false
+	}
+	p.newPage()
+	return true
+}
+
+/**
+ * section 小节标题
+ */
+func (p *statementPDF) section(title string) {
+	p.ensureSpace(22 + statementRowHeight*2)
+	p.doc.Text(statementMargin, p.y+12, 11, title)
+	p.y += 20
+}
+
+/**
+ * emptyLine 无数据提示
+ */
+func (p *statementPDF) emptyLine(text string) {
+	p.doc.Text(statementMargin+4, p.y+11, statementFontSize, text)
+	p.y += statementRowHeight
+}
+
+/**
+ * table 绘制表格（跨页时在新页重复表头）
+ */
+func (p *statementPDF) table(header []string, columns []pdfColumn, rows [][]string) {
+	var width float64
+	for _, col := range columns {
+		width += col.width
+	}
+
+	drawRow := func(cells []string) {
+		x := statementMargin
+		for i, col := range columns {
+			if i < len(cells) {
+				text := pdf.Truncate(cells[i], statementFontSize, col.width-6)
+				if col.right {
+					p.doc.TextRight(x+col.width-3, p.y+11, statementFontSize, text)
+				} else {
+					p.doc.Text(x+3, p.y+11, statementFontSize, text)
+				}
+			}
+			x += col.width
+		}
+		p.y += statementRowHeight
+	}
+	drawHeader := func() {
+		p.doc.FillRect(statementMargin, p.y, width, statementRowHeight, 0.92)
+		drawRow(header)
+		p.doc.Line(statementMargin, p.y, statementMargin+width, p.y, 0.5)
+	}
+
+	drawHeader()
+	for _, row := range rows {
+		if p.ensureSpace(statementRowHeight) {
+			drawHeader()
+		}
+		drawRow(row)
+		p.doc.Line(statementMargin, p.y, statementMargin+width, p.y, 0.2)
+	}
+}
+
+/**
+ * statementPeriodKey 对账单周期标识（用于文件名）
+ */
+func statementPeriodKey(st *model.AccountStatement) string {
+	if st.PeriodType == model.StatementPeriodMonthly {
+		return st.PeriodStart.In(time.Local).Format("2006-01")
+	}
+	return st.PeriodStart.In(time.Local).Format("2006-01-02")
+}
+
+/**
+ * statementPeriodText 对账单周期名称
+ */
+func statementPeriodText(st *model.AccountStatement) string {
+	if st.PeriodType == model.StatementPeriodMonthly {
+		return st.PeriodStart.In(time.Local).Format("2006年01月") + " 月度对账单"
+	}
+	return st.PeriodStart.In(time.Local).Format("2006年01月02日") + " 日对账单"
+}
+
+/**
+ * money 金额格式化（两位小数）
+ */
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", roundMoney(v))
+}
+
+/**
+ * signedMoney 金额格式化（正数带+号）
+ */
+func signedMoney(v float64) string {
+	v = roundMoney(v)
+	if v > 0 {
+		return fmt.Sprintf("+%.2f", v)
+	}
+	if v == 0 {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+/**
+ * maskPhone 手机号脱敏（138****1234）
+ */
+func maskPhone(phone string) string {
+	if len(phone) != 11 {
+		return phone
+	}
+	return phone[:3] + "****" + phone[7:]
+}
@@ -0,0 +1,332 @@
+/**
+ * 账户对账单服务
+ *
+ * 用途：
+ * - 为客户生成日/月对账单：期初期末余额、充值、提现、手续费、已实现盈亏、补定金、开仓/平仓订单
+ * - 月末自动为全部有资金流水的客户生成上月对账单，并通知客户
+ * - 对账单下载（PDF/CSV，见 account_statement_render.go）
+ *
+ * 计算口径：
+ * - 期初/期末余额取周期开始前/结束前最后一条资金流水的变动后余额（没有流水为0）
+ * - 本期各项金额按资金流水的总权益变动（可用 + 已用 + 提现冻结）汇总：
+ *   期初总权益 + 充值 - 提现 - 手续费 + 已实现盈亏 + 其他变动 = 期末总权益
+ * - 补定金、下单冻结、提现冻结/解冻只在可用、已用、冻结之间转移，不影响总权益
+ *
+ * 说明：
+ * - 多实例部署时只有主实例自动生成月度对账单
+ * - 同一客户同一周期只生成一份，重复生成返回已有对账单
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+// monthlyStatementHour 每月1日几点后生成上月对账单（留出时间让跨月的业务处理完成）
+const monthlyStatementHour = 1
+
+/**
+ * AccountStatementService 账户对账单服务
+ */
+type AccountStatementService struct {
+	ctx      *appctx.AppContext
+	repo     *repository.AccountStatementRepository
+	userRepo *repository.UserRepository
+	notiSvc  *NotificationService
+
+	lastMonthly time.Time // 已完成自动生成的月份（本实例内存记录，重启后会重新检查）
+}
+
+/**
+ * NewAccountStatementService 创建账户对账单服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *AccountStatementService
+ */
+func NewAccountStatementService(ctx *appctx.AppContext) *AccountStatementService {
+	return &AccountStatementService{
+		ctx:      ctx,
+		repo:     repository.NewAccountStatementRepository(ctx.DB),
+		userRepo: repository.NewUserRepository(ctx.DB),
+		notiSvc:  NewNotificationService(ctx),
+	}
+}
+
+/**
+ * ParsePeriod 解析账单周期
+ *
+ * @param periodType string - 周期类型（daily/monthly）
+ * @param value string - 日对账单为日期（2025-11-03），月对账单为月份（2025-11）
+ * @return (time.Time, error) - 周期开始时间（本地时区）
+ */
+func (s *AccountStatementService) ParsePeriod(periodType, value string) (time.Time, error) {
+	layout := ""
+	switch periodType {
+	case model.StatementPeriodDaily:
+		layout = "2006-01-02"
+	case model.StatementPeriodMonthly:
+		layout = "2006-01"
+	default:
+		return time.Time{}, errors.New("无效的对账单周期类型")
+	}
+	start, err := time.ParseInLocation(layout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("账单周期格式应为 %s", layout)
+	}
+	return start, nil
+}
+
+/**
+ * Generate 生成对账单（已存在时返回已有对账单）
+ *
+ * @param userID uint - 客户ID
+ * @param periodType string - 周期类型（daily/monthly）
+ * @param periodStart time.Time - 周期内任意时间（按周期类型取周期开始）
+ * @param triggerType string - 生成方式（model.StatementTrigger*）
+ * @param operatorID uint - 手动生成人（自动生成为0）
+ * @return (*model.AccountStatement, error)
+ */
+func (s *AccountStatementService) Generate(userID uint, periodType string, periodStart time.Time, triggerType string, operatorID uint) (*model.AccountStatement, error) {
+	statement, _, err := s.generate(userID, periodType, periodStart, triggerType, operatorID)
+	return statement, err
+}
+
+/**
+ * generate 生成对账单
+ *
+ * @return (*model.AccountStatement, bool, error) - 对账单、是否本次新生成
+ */
+func (s *AccountStatementService) generate(userID uint, periodType string, periodStart time.Time, triggerType string, operatorID uint) (*model.AccountStatement, bool, error) {
+	start, end, err := statementPeriod(periodType, periodStart)
+	if err != nil {
+		return nil, false, err
+	}
+	if end.After(time.Now()) {
+		return nil, false, errors.New("账单周期尚未结束")
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, false, errors.New("用户不存在")
+	}
+
+	if existing, err := s.repo.FindByPeriod(userID, periodType, start); err != nil {
+		return nil, false, fmt.Errorf("查询对账单失败: %v", err)
+	} else if existing != nil {
+		return existing, false, nil
+	}
+
+	statement := &model.AccountStatement{
+		UserID:      userID,
+		PeriodType:  periodType,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		TriggerType: triggerType,
+		OperatorID:  operatorID,
+	}
+
+	// 1. 期初、期末余额
+	opening, err := s.repo.FindLastFundLogBefore(userID, start)
+	if err != nil {
+		return nil, false, fmt.Errorf("查询期初余额失败: %v", err)
+	}
+	if opening != nil {
+		statement.OpeningAvailable = opening.AvailableAfter
+		statement.OpeningUsed = opening.UsedAfter
+		statement.OpeningFrozen = opening.FrozenAfter
+	}
+	closing, err := s.repo.FindLastFundLogBefore(userID, end)
+	if err != nil {
+		return nil, false, fmt.Errorf("查询期末余额失败: %v", err)
+	}
+	if closing != nil {
+		statement.ClosingAvailable = closing.AvailableAfter
+		statement.ClosingUsed = closing.UsedAfter
+		statement.ClosingFrozen = closing.FrozenAfter
+	}
+
+	// 2. 本期资金流水汇总
+	logs, err := s.repo.FindFundLogs(userID, start, end)
+	if err != nil {
+		return nil, false, fmt.Errorf("查询资金流水失败: %v", err)
+	}
+	statement.EntryCount = len(logs)
+	for _, fundLog := range logs {
+		delta := fundLogTotalDelta(fundLog)
+		switch fundLog.Type {
+		case model.FundLogTypeDeposit:
+			statement.Deposits += delta
+		case model.FundLogTypeWithdraw:
+			statement.Withdrawals -= delta
+		case model.FundLogTypeWithdrawFee:
+			statement.Fees -= delta
+		case model.FundLogTypeSettle, model.FundLogTypeForceClose, model.FundLogTypePartialClose:
+			statement.RealizedPnL += delta
+		case model.FundLogTypeSupplement:
+			statement.Supplements += fundLog.UsedAfter - fundLog.UsedBefore
+		default:
+			statement.OtherAmount += delta
+		}
+	}
+	statement.Deposits = roundMoney(statement.Deposits)
+	statement.Withdrawals = roundMoney(statement.Withdrawals)
+	statement.Fees = roundMoney(statement.Fees)
+	statement.RealizedPnL = roundMoney(statement.RealizedPnL)
+	statement.Supplements = roundMoney(statement.Supplements)
+	statement.OtherAmount = roundMoney(statement.OtherAmount)
+
+	// 3. 本期开仓、平仓订单数
+	orders, err := s.repo.FindOrdersOpenedOrClosed(userID, start, end)
+	if err != nil {
+		return nil, false, fmt.Errorf("查询订单失败: %v", err)
+	}
+	for _, order := range orders {
+		if inPeriod(order.CreatedAt, start, end) {
+			statement.OrdersOpened++
+		}
+		if orderClosedInPeriod(order, start, end) {
+			statement.OrdersClosed++
+		}
+	}
+
+	if err := s.repo.Create(statement); err != nil {
+		// 并发生成同一周期时以先写入的为准
+		if existing, findErr := s.repo.FindByPeriod(userID, periodType, start); findErr == nil && existing != nil {
+			return existing, false, nil
+		}
+		return nil, false, fmt.Errorf("保存对账单失败: %v", err)
+	}
+	return statement, true, nil
+}
+
+/**
+ * RunMonthEnd 月末后为全部有资金流水的客户生成上月对账单（由定时任务调用）
+ *
+ * @return void
+ */
+func (s *AccountStatementService) RunMonthEnd() {
+	// 多实例部署时只有主实例生成
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		return
+	}
+
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	if s.lastMonthly.Equal(lastMonth) || now.Before(thisMonth.Add(monthlyStatementHour*time.Hour)) {
+		return
+	}
+
+	userIDs, err := s.repo.FindUserIDsWithFundLogsBefore(thisMonth)
+	if err != nil {
+		log.Printf("[Statement] ❌ 查询待生成对账单客户失败: %v", err)
+		return
+	}
+
+	created, failed := 0, 0
+	for _, userID := range userIDs {
+		statement, isNew, err := s.generate(userID, model.StatementPeriodMonthly, lastMonth, model.StatementTriggerScheduled, 0)
+		if err != nil {
+			failed++
+			log.Printf("[Statement] ❌ 生成客户 %d 的 %s 对账单失败: %v", userID, lastMonth.Format("2006-01"), err)
+			continue
+		}
+		if !isNew {
+			continue
+		}
+		created++
+		s.notiSvc.SendSystemNotificationToUser(userID, "月度对账单已生成",
+			fmt.Sprintf("您 %s 的账户对账单已生成（期末总权益 %.2f 元），可在「我的 - 对账单」中下载。",
+				lastMonth.Format("2006年01月"), statement.ClosingTotal()), "")
+	}
+
+	// 有失败时下次检查重试（已生成的不会重复生成）
+	if failed == 0 {
+		s.lastMonthly = lastMonth
+	}
+	if created > 0 || failed > 0 {
+		log.Printf("[Statement] ✅ %s 月度对账单生成完成：新生成 %d 份，失败 %d 份",
+			lastMonth.Format("2006-01"), created, failed)
+	}
+}
+
+/**
+ * GetStatements 分页查询对账单
+ *
+ * @param userID uint - 客户ID（0表示全部）
+ * @param periodType string - 周期类型（为空不筛选）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.AccountStatement, int64, error)
+ */
+func (s *AccountStatementService) GetStatements(userID uint, periodType string, limit, offset int) ([]*model.AccountStatement, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.FindList(userID, periodType, limit, offset)
+}
+
+/**
+ * GetStatement 查询对账单
+ *
+ * @param id uint - 对账单ID
+ * @return (*model.AccountStatement, error)
+ */
+func (s *AccountStatementService) GetStatement(id uint) (*model.AccountStatement, error) {
+	statement, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("对账单不存在")
+	}
+	return statement, nil
+}
+
+/**
+ * statementPeriod 按周期类型计算周期 [开始, 结束)
+ */
+func statementPeriod(periodType string, t time.Time) (time.Time, time.Time, error) {
+	t = t.In(time.Local)
+	switch periodType {
+	case model.StatementPeriodDaily:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		return start, start.AddDate(0, 0, 1), nil
+	case model.StatementPeriodMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, errors.New("无效的对账单周期类型")
+	}
+}
+
+/**
+ * fundLogTotalDelta 资金流水的总权益变动（可用 + 已用 + 提现冻结）
+ */
+func fundLogTotalDelta(fundLog *model.FundLog) float64 {
+	return (fundLog.AvailableAfter + fundLog.UsedAfter + fundLog.FrozenAfter) -
+		(fundLog.AvailableBefore + fundLog.UsedBefore + fundLog.FrozenBefore)
+}
+
+/**
+ * inPeriod 时间是否在周期 [开始, 结束) 内
+ */
+func inPeriod(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+/**
+ * orderClosedInPeriod 订单是否在周期内结算或强平
+ */
+func orderClosedInPeriod(order *model.Order, start, end time.Time) bool {
+	return order.Status != model.OrderStatusHolding && order.SettledAt != nil && inPeriod(*order.SettledAt, start, end)
+}
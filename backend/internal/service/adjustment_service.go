@@ -0,0 +1,378 @@
+/**
+ * 手工余额调整服务
+ *
+ * 用途：
+ * - 管理员按原因（补偿、差错更正、赠送、手续费退还）调增/调减客户可用定金
+ * - 每笔调整须由发起人以外的管理员审批后才入账，金额（绝对值）超过审批阈值时还需超级管理员审批
+ *
+ * 说明：
+ * - 每笔调整须填写原因、说明并上传附件；发起即记为第一级审批，审计轨迹见审批记录
+ * - 入账生成 adjustment 类型资金流水并通知客户；总账对方科目按原因确定
+ * - 调减不能使可用定金为负
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+// adjustmentNoteMaxLen 调整说明最大长度（字符）
+const adjustmentNoteMaxLen = 200
+
+// adjustmentCounterAccounts 调整原因对应的总账对方科目（差错更正视为出入金差错，记银行清算）
+var adjustmentCounterAccounts = map[string]string{
+	model.AdjustmentReasonCompensation: model.LedgerAccountHousePnL,
+	model.AdjustmentReasonCorrection:   model.LedgerAccountBankClearing,
+	model.AdjustmentReasonBonus:        model.LedgerAccountHousePnL,
+	model.AdjustmentReasonFeeRefund:    model.LedgerAccountFeeIncome,
+}
+
+/**
+ * AdjustmentService 手工余额调整服务
+ */
+type AdjustmentService struct {
+	ctx         *appctx.AppContext
+	repo        *repository.AdjustmentRepository
+	userRepo    *repository.UserRepository
+	notiSvc     *NotificationService
+	approvalSvc *ApprovalService
+	fileSvc     *FileService
+}
+
+/**
+ * NewAdjustmentService 创建手工余额调整服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *AdjustmentService
+ */
+func NewAdjustmentService(ctx *appctx.AppContext) *AdjustmentService {
+	return &AdjustmentService{
+		ctx:         ctx,
+		repo:        repository.NewAdjustmentRepository(ctx.DB),
+		userRepo:    repository.NewUserRepository(ctx.DB),
+		notiSvc:     NewNotificationService(ctx),
+		approvalSvc: NewApprovalService(ctx),
+		fileSvc:     NewFileService(ctx),
+	}
+}
+
+/**
+ * CreateAdjustment 发起手工余额调整
+ *
+ * 业务流程：
+ * 1. 校验金额、原因、说明、附件和客户
+ * 2. 附件转存到文件存储
+ * 3. 事务内创建调整记录，记录发起人的审批步骤
+ * 4. 通知管理员审批（发起人不能审批，调整不会在发起时直接入账）
+ *
+ * @param operatorID uint - 发起人ID
+ * @param userID uint - 客户ID
+ * @param amount float64 - 调整金额（正数调增，负数调减）
+ * @param reasonCode string - 调整原因
+ * @param attachment string - 说明附件（文件引用/链接，或 Data URL）
+ * @param note string - 调整说明
+ * @return (*model.BalanceAdjustment, error)
+ */
+func (s *AdjustmentService) CreateAdjustment(operatorID, userID uint, amount float64, reasonCode, attachment, note string) (*model.BalanceAdjustment, error) {
+	// 1. 校验参数
+	amount = roundMoney(amount)
+	if amount == 0 {
+		return nil, errors.New("调整金额不能为0")
+	}
+	if _, ok := model.AdjustmentReasonText[reasonCode]; !ok {
+		return nil, fmt.Errorf("无效的调整原因: %s", reasonCode)
+	}
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.New("请填写调整说明")
+	}
+	if len([]rune(note)) > adjustmentNoteMaxLen {
+		return nil, fmt.Errorf("调整说明不能超过%d个字", adjustmentNoteMaxLen)
+	}
+	if strings.TrimSpace(attachment) == "" {
+		return nil, errors.New("请上传调整附件")
+	}
+	if userID == operatorID {
+		return nil, errors.New("不能调整本人账户")
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("客户不存在")
+	}
+	if user.Role != "customer" {
+		return nil, errors.New("只能调整客户账户")
+	}
+	if amount < 0 && user.AvailableDeposit+amount < 0 {
+		return nil, &InsufficientBalanceError{Available: user.AvailableDeposit, Required: -amount}
+	}
+
+	// 2. 附件转存到文件存储（事务外完成，避免长时间持锁）
+	attachment, err = s.fileSvc.Ingest(attachment, model.FileCategoryAdjustment, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("保存调整附件失败: %v", err)
+	}
+
+	// 3. 开启事务，创建调整记录并记录发起步骤
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	adjustment := &model.BalanceAdjustment{
+		UserID:        userID,
+		Amount:        amount,
+		ReasonCode:    reasonCode,
+		AttachmentURL: attachment,
+		Note:          note,
+		Status:        model.AdjustmentStatusPending,
+		ApprovalChain: model.ApprovalChain{
+			RequiredApprovals: s.approvalSvc.RequiredApprovals(model.ApprovalTargetAdjustment, amount),
+		},
+		OperatorID: operatorID,
+	}
+	if err := repository.NewAdjustmentRepository(tx).Create(adjustment); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("创建余额调整失败: %v", err)
+	}
+	if err := s.approvalSvc.Submit(tx, s.approvalTarget(adjustment), note); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	adjustment.PassStep()
+	if err := tx.Save(adjustment).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("更新调整状态失败")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("事务提交失败")
+	}
+
+	// 4. 通知管理员审批
+	reviewer := "另一位管理员"
+	if adjustment.Required() > 2 {
+		reviewer = "另一位管理员及超级管理员"
+	}
+	s.notiSvc.SendSystemNotificationToAdmins("余额调整待审批",
+		fmt.Sprintf("余额调整 #%d（客户 %d，%s %.2f 元）待审批，需%s审批",
+			adjustment.ID, userID, adjustment.ReasonText(), amount, reviewer), "")
+	log.Printf("[Adjustment] 余额调整待审批: ID=%d, 用户=%d, 金额=%.2f, 原因=%s, 发起人=%d, 审批级数=%d",
+		adjustment.ID, userID, amount, reasonCode, operatorID, adjustment.Required())
+	return adjustment, nil
+}
+
+/**
+ * ApproveAdjustment 审批通过余额调整并入账
+ *
+ * 校验（见 ApprovalService.Approve）：
+ * - 发起人不能审批
+ * - 超过审批阈值的调整最后一级须由超级管理员审批
+ *
+ * @param adjustmentID uint - 调整ID
+ * @param reviewerID uint - 审批人ID
+ * @param note string - 审批备注
+ * @return (*model.BalanceAdjustment, error)
+ */
+func (s *AdjustmentService) ApproveAdjustment(adjustmentID, reviewerID uint, note string) (*model.BalanceAdjustment, error) {
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 1. 锁定调整记录并确认状态，防止重复审批入账
+	adjustment, err := s.lockPending(tx, adjustmentID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 2. 记录审批步骤
+	final, err := s.approvalSvc.Approve(tx, s.approvalTarget(adjustment), reviewerID, note)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	adjustment.PassStep()
+	if !final {
+		if err := tx.Save(adjustment).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("更新调整状态失败")
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("事务提交失败")
+		}
+		return adjustment, nil
+	}
+
+	// 3. 入账
+	fundLog, err := s.apply(tx, adjustment, reviewerID, note)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("事务提交失败")
+	}
+
+	s.notifyApplied(adjustment, fundLog)
+	log.Printf("[Adjustment] 余额调整审批通过并入账: ID=%d, 用户=%d, 金额=%.2f, 审批人=%d",
+		adjustmentID, adjustment.UserID, adjustment.Amount, reviewerID)
+	return adjustment, nil
+}
+
+/**
+ * RejectAdjustment 驳回余额调整（不影响客户余额，通知发起人）
+ *
+ * @param adjustmentID uint - 调整ID
+ * @param reviewerID uint - 审批人ID
+ * @param note string - 驳回原因
+ * @return error
+ */
+func (s *AdjustmentService) RejectAdjustment(adjustmentID, reviewerID uint, note string) error {
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	adjustment, err := s.lockPending(tx, adjustmentID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.approvalSvc.Reject(tx, s.approvalTarget(adjustment), reviewerID, note); err != nil {
+		tx.Rollback()
+		return err
+	}
+	adjustment.Reject(reviewerID, note)
+	if err := tx.Save(adjustment).Error; err != nil {
+		tx.Rollback()
+		return errors.New("更新调整状态失败")
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("事务提交失败")
+	}
+
+	s.notiSvc.SendSystemNotificationToUser(adjustment.OperatorID, "余额调整被驳回",
+		fmt.Sprintf("余额调整 #%d（客户 %d，%.2f 元）已被驳回\n驳回原因：%s",
+			adjustment.ID, adjustment.UserID, adjustment.Amount, note), "")
+	log.Printf("[Adjustment] 余额调整驳回: ID=%d, 审批人=%d, 原因=%s", adjustmentID, reviewerID, note)
+	return nil
+}
+
+/**
+ * GetAdjustments 分页查询余额调整（附件引用替换为下载链接）
+ *
+ * @param filter repository.AdjustmentFilter - 查询条件
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.BalanceAdjustment, int64, error)
+ */
+func (s *AdjustmentService) GetAdjustments(filter repository.AdjustmentFilter, limit, offset int) ([]*model.BalanceAdjustment, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	adjustments, total, err := s.repo.FindList(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, a := range adjustments {
+		a.AttachmentURL = s.fileSvc.ResolveRefs(a.AttachmentURL)
+	}
+	return adjustments, total, nil
+}
+
+/**
+ * GetAdjustmentApprovals 查询余额调整的审批记录（含发起步骤）
+ *
+ * @param adjustmentID uint - 调整ID
+ * @return ([]*model.ApprovalStep, error)
+ */
+func (s *AdjustmentService) GetAdjustmentApprovals(adjustmentID uint) ([]*model.ApprovalStep, error) {
+	return s.approvalSvc.ListSteps(model.ApprovalTargetAdjustment, adjustmentID)
+}
+
+/**
+ * lockPending 锁定待审批的调整记录
+ */
+func (s *AdjustmentService) lockPending(tx *gorm.DB, adjustmentID uint) (*model.BalanceAdjustment, error) {
+	adjustment, err := repository.NewAdjustmentRepository(tx).LockByID(adjustmentID)
+	if err != nil {
+		return nil, errors.New("余额调整不存在")
+	}
+	if !adjustment.IsPending() {
+		return nil, fmt.Errorf("余额调整状态不允许审批（当前状态: %s）", adjustment.Status)
+	}
+	return adjustment, nil
+}
+
+/**
+ * apply 调整入账：变更可用定金、记录资金流水并将调整置为已入账（在调用方事务中执行）
+ */
+func (s *AdjustmentService) apply(tx *gorm.DB, adjustment *model.BalanceAdjustment, reviewerID uint, note string) (*model.FundLog, error) {
+	fundLog, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:         adjustment.UserID,
+		Type:           model.FundLogTypeAdjustment,
+		AvailableDelta: adjustment.Amount,
+		RelatedID:      adjustment.ID,
+		RelatedType:    "adjustment",
+		Note:           fmt.Sprintf("余额调整（%s）: %s", adjustment.ReasonText(), adjustment.Note),
+		CounterAccount: adjustmentCounterAccounts[adjustment.ReasonCode],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment.Apply(reviewerID, note, fundLog.ID)
+	if err := tx.Save(adjustment).Error; err != nil {
+		return nil, errors.New("更新调整状态失败")
+	}
+	return fundLog, nil
+}
+
+/**
+ * notifyApplied 通知客户余额调整已入账
+ */
+func (s *AdjustmentService) notifyApplied(adjustment *model.BalanceAdjustment, fundLog *model.FundLog) {
+	direction := "调增"
+	if adjustment.Amount < 0 {
+		direction = "调减"
+	}
+	notifyMsg := fmt.Sprintf("您的可用定金已%s %.2f 元\n调整原因：%s\n说明：%s\n当前可用定金：%.2f 元",
+		direction, math.Abs(adjustment.Amount), adjustment.ReasonText(), adjustment.Note, fundLog.AvailableAfter)
+	s.notiSvc.SendFundNotification(adjustment.UserID, "账户余额调整", notifyMsg)
+}
+
+/**
+ * approvalTarget 余额调整对应的审批对象（发起人为发起调整的管理员）
+ */
+func (s *AdjustmentService) approvalTarget(adjustment *model.BalanceAdjustment) ApprovalTarget {
+	return ApprovalTarget{
+		Type:        model.ApprovalTargetAdjustment,
+		ID:          adjustment.ID,
+		RequesterID: adjustment.OperatorID,
+		Chain:       &adjustment.ApprovalChain,
+	}
+}
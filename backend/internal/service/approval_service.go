@@ -0,0 +1,288 @@
+/**
+ * 审批链服务
+ *
+ * 用途：
+ * - 按金额确定充值、提现申请、手工余额调整需要的审批级数（超过阈值需超级管理员复核）
+ * - 记录每一步审批，校验发起人与审批人分离（maker-checker）
+ *
+ * 说明：
+ * - 审批步骤在调用方的事务中写入，调用方负责锁定申请并更新申请状态
+ * - 驳回不受级数限制，任一审批人（发起人除外）均可驳回
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+/**
+ * ApprovalTarget 待审批对象
+ *
+ * 字段说明：
+ * - Type/ID: 审批对象类型和ID
+ * - RequesterID: 发起人ID（不能审批自己的申请）
+ * - Chain: 申请上的审批链（由调用方在审批通过后更新）
+ */
+type ApprovalTarget struct {
+	Type        string
+	ID          uint
+	RequesterID uint
+	Chain       *model.ApprovalChain
+}
+
+/**
+ * ApprovalService 审批链服务
+ */
+type ApprovalService struct {
+	ctx        *appctx.AppContext
+	repo       *repository.ApprovalRepository
+	configRepo *repository.ConfigRepository
+}
+
+/**
+ * NewApprovalService 创建审批链服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *ApprovalService
+ */
+func NewApprovalService(ctx *appctx.AppContext) *ApprovalService {
+	return &ApprovalService{
+		ctx:        ctx,
+		repo:       repository.NewApprovalRepository(ctx.DB),
+		configRepo: repository.NewConfigRepository(ctx.DB),
+	}
+}
+
+/**
+ * RequiredApprovals 按金额确定审批级数（超过双人审批阈值需两级审批）
+ *
+ * 手工余额调整由管理员直接发起，发起计为第一级，因此至少还需一位其他管理员审批；
+ * 超过调整审批阈值时再增加超级管理员一级。阈值未配置或无效时按默认阈值处理，不会放宽审批
+ *
+ * @param targetType string - 审批对象类型
+ * @param amount float64 - 申请金额（按绝对值比较，余额调整可为负数）
+ * @return int
+ */
+func (s *ApprovalService) RequiredApprovals(targetType string, amount float64) int {
+	keys := map[string]string{
+		model.ApprovalTargetWithdraw:   model.ConfigKeyWithdrawDualApprovalAmount,
+		model.ApprovalTargetDeposit:    model.ConfigKeyDepositDualApprovalAmount,
+		model.ApprovalTargetAdjustment: model.ConfigKeyAdjustmentApprovalAmount,
+	}
+	base := baseApprovals(targetType)
+	key, ok := keys[targetType]
+	if !ok {
+		return base
+	}
+
+	threshold := s.threshold(key)
+	if threshold <= 0 && targetType == model.ApprovalTargetAdjustment {
+		threshold = model.DefaultAdjustmentApprovalAmount
+	}
+	if threshold > 0 && math.Abs(amount) > threshold {
+		return base + 1
+	}
+	return base
+}
+
+/**
+ * baseApprovals 未超过阈值时的审批级数（余额调整的发起步骤计为一级，另需一位审批人）
+ */
+func baseApprovals(targetType string) int {
+	if targetType == model.ApprovalTargetAdjustment {
+		return 2
+	}
+	return 1
+}
+
+/**
+ * threshold 读取审批金额阈值（未配置或无效返回0）
+ */
+func (s *ApprovalService) threshold(key string) float64 {
+	config, err := s.configRepo.FindByKey(key)
+	if err != nil || config == nil || strings.TrimSpace(config.Value) == "" {
+		return 0
+	}
+	var threshold float64
+	if _, err := fmt.Sscanf(config.Value, "%f", &threshold); err != nil {
+		return 0
+	}
+	return threshold
+}
+
+/**
+ * Submit 记录发起人发起（计为审批链第一级，仅用于由管理员直接发起的操作，如手工余额调整）
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param target ApprovalTarget - 待审批对象（审批链尚无审批记录）
+ * @param note string - 发起说明
+ * @return error
+ */
+func (s *ApprovalService) Submit(tx *gorm.DB, target ApprovalTarget, note string) error {
+	if target.Chain.ApprovalCount > 0 {
+		return errors.New("该申请已发起")
+	}
+	requester, err := repository.NewUserRepository(tx).FindByID(target.RequesterID)
+	if err != nil {
+		return errors.New("发起人不存在")
+	}
+	return s.record(repository.NewApprovalRepository(tx), target, requester, model.ApprovalDecisionSubmit, note)
+}
+
+/**
+ * Approve 记录一级审批通过
+ *
+ * 校验：
+ * - 发起人不能审批
+ * - 同一审批人不能重复审批
+ * - 超过金额阈值时最后一级须由超级管理员审批
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param target ApprovalTarget - 待审批对象
+ * @param approverID uint - 审批人ID
+ * @param note string - 审批备注
+ * @return (bool, error) - 是否已完成全部审批
+ */
+func (s *ApprovalService) Approve(tx *gorm.DB, target ApprovalTarget, approverID uint, note string) (bool, error) {
+	approver, err := s.checkApprover(tx, target, approverID)
+	if err != nil {
+		return false, err
+	}
+
+	repo := repository.NewApprovalRepository(tx)
+	approved, err := repo.HasApproved(target.Type, target.ID, approverID)
+	if err != nil {
+		return false, fmt.Errorf("查询审批记录失败: %v", err)
+	}
+	if approved {
+		return false, errors.New("您已审批过该申请，需由另一位审核人复核")
+	}
+
+	final := target.Chain.IsFinalStep()
+	if final && target.Chain.Required() > baseApprovals(target.Type) && approver.Role != "super_admin" {
+		return false, errors.New("该申请金额较大，需由超级管理员复核")
+	}
+
+	if err := s.record(repo, target, approver, model.ApprovalDecisionApprove, note); err != nil {
+		return false, err
+	}
+	return final, nil
+}
+
+/**
+ * Reject 记录审批驳回
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param target ApprovalTarget - 待审批对象
+ * @param approverID uint - 审批人ID
+ * @param note string - 驳回原因
+ * @return error
+ */
+func (s *ApprovalService) Reject(tx *gorm.DB, target ApprovalTarget, approverID uint, note string) error {
+	approver, err := s.checkApprover(tx, target, approverID)
+	if err != nil {
+		return err
+	}
+	return s.record(repository.NewApprovalRepository(tx), target, approver, model.ApprovalDecisionReject, note)
+}
+
+/**
+ * ApproveBySystem 记录系统自动审批（仅用于单级审批、结果已由外部凭据确认的申请）
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param target ApprovalTarget - 待审批对象
+ * @param source string - 审批依据（如支付通道名称）
+ * @param note string - 审批备注
+ * @return error
+ */
+func (s *ApprovalService) ApproveBySystem(tx *gorm.DB, target ApprovalTarget, source, note string) error {
+	if !target.Chain.IsFinalStep() {
+		return errors.New("该申请需要人工审批")
+	}
+	system := &model.User{RealName: source, Role: model.ApprovalRoleSystem}
+	return s.record(repository.NewApprovalRepository(tx), target, system, model.ApprovalDecisionApprove, note)
+}
+
+/**
+ * ConfirmBySystem 以外部到账凭据记录审批链第一级（如支付通道确认到账）
+ *
+ * 单级审批时即完成审批；超过双人审批阈值时后续级仍需人工复核，最后一级须由超级管理员审批
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param target ApprovalTarget - 待审批对象（审批链尚无审批记录）
+ * @param source string - 审批依据（如支付通道名称）
+ * @param note string - 审批备注
+ * @return (bool, error) - 是否已完成全部审批
+ */
+func (s *ApprovalService) ConfirmBySystem(tx *gorm.DB, target ApprovalTarget, source, note string) (bool, error) {
+	if target.Chain.ApprovalCount > 0 {
+		return false, errors.New("该申请已有审批记录")
+	}
+	system := &model.User{RealName: source, Role: model.ApprovalRoleSystem}
+	if err := s.record(repository.NewApprovalRepository(tx), target, system, model.ApprovalDecisionApprove, note); err != nil {
+		return false, err
+	}
+	return target.Chain.IsFinalStep(), nil
+}
+
+/**
+ * ListSteps 查询审批对象的审批记录
+ *
+ * @param targetType string - 审批对象类型
+ * @param targetID uint - 审批对象ID
+ * @return ([]*model.ApprovalStep, error)
+ */
+func (s *ApprovalService) ListSteps(targetType string, targetID uint) ([]*model.ApprovalStep, error) {
+	return s.repo.FindByTarget(targetType, targetID)
+}
+
+/**
+ * checkApprover 校验审批人（发起人不能审批自己的申请）
+ */
+func (s *ApprovalService) checkApprover(tx *gorm.DB, target ApprovalTarget, approverID uint) (*model.User, error) {
+	if approverID == target.RequesterID {
+		return nil, errors.New("不能审批本人发起的申请")
+	}
+	approver, err := repository.NewUserRepository(tx).FindByID(approverID)
+	if err != nil {
+		return nil, errors.New("审批人不存在")
+	}
+	return approver, nil
+}
+
+/**
+ * record 写入审批步骤
+ */
+func (s *ApprovalService) record(repo *repository.ApprovalRepository, target ApprovalTarget, approver *model.User, decision, note string) error {
+	name := approver.RealName
+	if name == "" {
+		name = approver.Phone
+	}
+	step := &model.ApprovalStep{
+		TargetType:   target.Type,
+		TargetID:     target.ID,
+		Step:         target.Chain.NextStep(),
+		ApproverID:   approver.ID,
+		ApproverName: name,
+		ApproverRole: approver.Role,
+		Decision:     decision,
+		Note:         note,
+	}
+	if err := repo.Create(step); err != nil {
+		return fmt.Errorf("记录审批步骤失败: %v", err)
+	}
+	return nil
+}
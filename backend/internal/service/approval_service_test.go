@@ -0,0 +1,148 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"suxin/internal/model"
+)
+
+func TestApprovalServiceRequiredApprovals(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetType string
+		key, value string // 阈值配置（key为空表示不配置）
+		amount     float64
+		want       int
+	}{
+		{name: "withdraw without threshold", targetType: model.ApprovalTargetWithdraw, amount: 1e6, want: 1},
+		{name: "withdraw below threshold", targetType: model.ApprovalTargetWithdraw,
+			key: model.ConfigKeyWithdrawDualApprovalAmount, value: "5000", amount: 5000, want: 1},
+		{name: "withdraw above threshold", targetType: model.ApprovalTargetWithdraw,
+			key: model.ConfigKeyWithdrawDualApprovalAmount, value: "5000", amount: 5000.01, want: 2},
+		{name: "deposit threshold disabled with zero", targetType: model.ApprovalTargetDeposit,
+			key: model.ConfigKeyDepositDualApprovalAmount, value: "0", amount: 1e6, want: 1},
+		{name: "deposit above threshold", targetType: model.ApprovalTargetDeposit,
+			key: model.ConfigKeyDepositDualApprovalAmount, value: "100", amount: 200, want: 2},
+		// 余额调整：发起计为第一级，至少还需一位其他审批人
+		{name: "adjustment below threshold", targetType: model.ApprovalTargetAdjustment,
+			key: model.ConfigKeyAdjustmentApprovalAmount, value: "1000", amount: -1000, want: 2},
+		{name: "adjustment above threshold uses absolute amount", targetType: model.ApprovalTargetAdjustment,
+			key: model.ConfigKeyAdjustmentApprovalAmount, value: "1000", amount: -1500, want: 3},
+		{name: "adjustment without threshold falls back to default", targetType: model.ApprovalTargetAdjustment,
+			amount: model.DefaultAdjustmentApprovalAmount + 1, want: 3},
+		{name: "adjustment with invalid threshold falls back to default", targetType: model.ApprovalTargetAdjustment,
+			key: model.ConfigKeyAdjustmentApprovalAmount, value: "abc", amount: 100, want: 2},
+		{name: "adjustment threshold cannot be disabled", targetType: model.ApprovalTargetAdjustment,
+			key: model.ConfigKeyAdjustmentApprovalAmount, value: "0", amount: model.DefaultAdjustmentApprovalAmount + 1, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			if tt.key != "" {
+				setTestConfig(t, ctx, tt.key, tt.value)
+			}
+			if got := NewApprovalService(ctx).RequiredApprovals(tt.targetType, tt.amount); got != tt.want {
+				t.Errorf("RequiredApprovals = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApprovalServiceApprove(t *testing.T) {
+	tests := []struct {
+		name         string
+		targetType   string
+		required     int
+		prior        int    // 已通过的级数（由其他审批人完成）
+		approverRole string // 审批人角色
+		self         bool   // 审批人即发起人
+		repeat       bool   // 审批人已审批过
+		wantFinal    bool
+		wantErr      string
+	}{
+		{name: "single approval by admin", targetType: model.ApprovalTargetWithdraw, required: 1,
+			approverRole: "support", wantFinal: true},
+		{name: "requester cannot approve", targetType: model.ApprovalTargetWithdraw, required: 1,
+			approverRole: "super_admin", self: true, wantErr: "不能审批本人"},
+		{name: "first of two approvals", targetType: model.ApprovalTargetWithdraw, required: 2,
+			approverRole: "support"},
+		{name: "final dual approval requires super admin", targetType: model.ApprovalTargetWithdraw, required: 2, prior: 1,
+			approverRole: "support", wantErr: "超级管理员"},
+		{name: "final dual approval by super admin", targetType: model.ApprovalTargetWithdraw, required: 2, prior: 1,
+			approverRole: "super_admin", wantFinal: true},
+		{name: "same approver cannot approve twice", targetType: model.ApprovalTargetWithdraw, required: 2, prior: 1,
+			approverRole: "super_admin", repeat: true, wantErr: "已审批过"},
+		{name: "adjustment below threshold approved by another admin", targetType: model.ApprovalTargetAdjustment, required: 2, prior: 1,
+			approverRole: "support", wantFinal: true},
+		{name: "adjustment requester cannot approve", targetType: model.ApprovalTargetAdjustment, required: 2, prior: 1,
+			approverRole: "super_admin", self: true, wantErr: "不能审批本人"},
+		{name: "adjustment above threshold needs super admin last", targetType: model.ApprovalTargetAdjustment, required: 3, prior: 2,
+			approverRole: "support", wantErr: "超级管理员"},
+		{name: "adjustment above threshold second step by admin", targetType: model.ApprovalTargetAdjustment, required: 3, prior: 1,
+			approverRole: "support"},
+		{name: "adjustment above threshold final by super admin", targetType: model.ApprovalTargetAdjustment, required: 3, prior: 2,
+			approverRole: "super_admin", wantFinal: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			requester := createTestUser(t, ctx, &model.User{Role: "support"})
+			approver := requester
+			if !tt.self {
+				approver = createTestUser(t, ctx, &model.User{Role: tt.approverRole})
+			}
+
+			chain := &model.ApprovalChain{RequiredApprovals: tt.required}
+			target := ApprovalTarget{Type: tt.targetType, ID: 1, RequesterID: requester.ID, Chain: chain}
+			for i := 0; i < tt.prior; i++ {
+				priorID := createTestUser(t, ctx, &model.User{Role: "support"}).ID
+				if tt.repeat && i == tt.prior-1 {
+					priorID = approver.ID
+				}
+				ctx.DB.Create(&model.ApprovalStep{TargetType: tt.targetType, TargetID: 1, Step: i + 1,
+					ApproverID: priorID, Decision: model.ApprovalDecisionApprove})
+				chain.ApprovalCount++
+			}
+
+			tx := ctx.DB.Begin()
+			defer tx.Rollback()
+			final, err := NewApprovalService(ctx).Approve(tx, target, approver.ID, "ok")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Approve: %v", err)
+			}
+			if final != tt.wantFinal {
+				t.Errorf("final = %v, want %v", final, tt.wantFinal)
+			}
+			var steps []model.ApprovalStep
+			tx.Where("target_type = ? AND target_id = ? AND approver_id = ?", tt.targetType, 1, approver.ID).Find(&steps)
+			if len(steps) != 1 || steps[0].Step != tt.prior+1 || steps[0].ApproverRole != tt.approverRole {
+				t.Errorf("recorded steps = %+v", steps)
+			}
+		})
+	}
+}
+
+func TestApprovalServiceReject(t *testing.T) {
+	ctx := newTestContext(t)
+	requester := createTestUser(t, ctx, &model.User{Role: "support"})
+	other := createTestUser(t, ctx, &model.User{Role: "support"})
+	target := ApprovalTarget{Type: model.ApprovalTargetWithdraw, ID: 1, RequesterID: requester.ID,
+		Chain: &model.ApprovalChain{RequiredApprovals: 2}}
+	svc := NewApprovalService(ctx)
+
+	if err := svc.Reject(ctx.DB, target, requester.ID, "no"); err == nil {
+		t.Error("requester should not be able to reject")
+	}
+	if err := svc.Reject(ctx.DB, target, other.ID, "no"); err != nil {
+		t.Errorf("Reject: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+/**
+ * 账户资金变更服务
+ *
+ * 用途：
+ * - 所有客户资金（可用定金/已用定金/提现冻结）变更的唯一入口
+ * - 在调用方事务中锁定用户行，按增量计算新余额，杜绝并发丢失更新
+ * - 每次变更都写入对应的资金流水，并在总账记一张借贷平衡的凭证
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+/**
+ * InsufficientBalanceError 可用定金不足错误
+ */
+type InsufficientBalanceError struct {
+	Available float64 // 当前可用
+	Required  float64 // 需要扣减
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("可用定金不足（可用: %.2f, 需要: %.2f）", e.Available, e.Required)
+}
+
+/**
+ * BalanceChange 资金变更请求
+ *
+ * 字段说明：
+ * - UserID: 用户ID
+ * - Type: 资金流水类型（model.FundLogType*）
+ * - AvailableDelta: 可用定金变化量（正数增加，负数减少）
+ * - UsedDelta: 已用定金变化量（正数增加，负数减少）
+ * - FrozenDelta: 提现冻结变化量（正数增加，负数减少）
+ * - RelatedID/RelatedType: 关联业务
+ * - Note: 流水备注
+ * - Fee: 其中的平台手续费（已包含在变化量中，记入手续费收入）
+ * - CounterAccount: 总账对方科目（为空按流水类型：充值/提现为银行清算，其他为平台盈亏）
+ */
+type BalanceChange struct {
+	UserID         uint
+	Type           string
+	AvailableDelta float64
+	UsedDelta      float64
+	FrozenDelta    float64
+	RelatedID      uint
+	RelatedType    string
+	Note           string
+	Fee            float64
+	CounterAccount string
+}
+
+/**
+ * BalanceService 账户资金变更服务
+ */
+type BalanceService struct {
+	ctx *appctx.AppContext
+}
+
+/**
+ * NewBalanceService 创建资金变更服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *BalanceService
+ */
+func NewBalanceService(ctx *appctx.AppContext) *BalanceService {
+	return &BalanceService{ctx: ctx}
+}
+
+/**
+ * Apply 在事务中执行一次资金变更
+ *
+ * 业务流程：
+ * 1. 锁定用户行（MySQL: SELECT ... FOR UPDATE；SQLite: 先获取写锁）
+ * 2. 基于锁定后的最新余额叠加增量
+ * 3. 校验可用定金、已用定金、提现冻结不为负
+ * 4. 写回余额并记录资金流水
+ * 5. 总账记账（客户分户科目余额与用户余额同步变化）
+ *
+ * 调用方负责开启/提交/回滚事务，资金变更与业务数据在同一事务内生效
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param change BalanceChange - 资金变更请求
+ * @return (*model.FundLog, error) - 写入的资金流水（含变更前后余额）
+ */
+func (s *BalanceService) Apply(tx *gorm.DB, change BalanceChange) (*model.FundLog, error) {
+	if change.Type == "" {
+		return nil, errors.New("资金变更类型不能为空")
+	}
+
+	// 1. 锁定用户行
+	user, err := repository.NewUserRepository(tx).LockByID(change.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 2. 计算新余额
+	newAvailable := roundMoney(user.AvailableDeposit + change.AvailableDelta)
+	newUsed := roundMoney(user.UsedDeposit + change.UsedDelta)
+	newFrozen := roundMoney(user.WithdrawFrozen + change.FrozenDelta)
+
+	// 3. 校验余额
+	if newAvailable < 0 {
+		return nil, &InsufficientBalanceError{
+			Available: user.AvailableDeposit,
+			Required:  -change.AvailableDelta,
+		}
+	}
+	if newFrozen < 0 {
+		return nil, fmt.Errorf("提现冻结金额不足（当前冻结: %.2f）", user.WithdrawFrozen)
+	}
+	if newUsed < 0 {
+		return nil, fmt.Errorf("已用定金不足（当前已用: %.2f）", user.UsedDeposit)
+	}
+
+	// 4. 写回余额
+	if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"available_deposit": newAvailable,
+		"used_deposit":      newUsed,
+		"withdraw_frozen":   newFrozen,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新用户资金失败: %v", err)
+	}
+
+	// 5. 记录资金流水
+	fundLog := &model.FundLog{
+		UserID:          user.ID,
+		Type:            change.Type,
+		Amount:          fundLogAmount(change.Type, newAvailable-user.AvailableDeposit, newFrozen-user.WithdrawFrozen),
+		AvailableBefore: user.AvailableDeposit,
+		AvailableAfter:  newAvailable,
+		UsedBefore:      user.UsedDeposit,
+		UsedAfter:       newUsed,
+		FrozenBefore:    user.WithdrawFrozen,
+		FrozenAfter:     newFrozen,
+		RelatedID:       change.RelatedID,
+		RelatedType:     change.RelatedType,
+		Note:            change.Note,
+	}
+	if err := tx.Create(fundLog).Error; err != nil {
+		return nil, fmt.Errorf("记录资金流水失败: %v", err)
+	}
+
+	// 6. 总账记账
+	if _, err := NewLedgerService(s.ctx).PostBalanceChange(tx, fundLog, change); err != nil {
+		return nil, fmt.Errorf("总账记账失败: %v", err)
+	}
+
+	return fundLog, nil
+}
+
+/**
+ * fundLogAmount 资金流水变动金额
+ *
+ * 一般为可用定金变化量；提现扣款和提现手续费从提现冻结中扣除时可用定金不变，
+ * 按实际离开账户的金额（可用 + 冻结变化量）记录，保证流水汇总的提现金额准确
+ */
+func fundLogAmount(logType string, availableDelta, frozenDelta float64) float64 {
+	switch logType {
+	case model.FundLogTypeWithdraw, model.FundLogTypeWithdrawFee:
+		return roundMoney(availableDelta + frozenDelta)
+	}
+	return roundMoney(availableDelta)
+}
+
+/**
+ * roundMoney 金额保留两位小数（与数据库 decimal(15,2) 一致）
+ */
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"suxin/internal/model"
+)
+
+func TestBalanceServiceApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    model.User
+		change  BalanceChange
+		wantErr bool
+		// 期望的变更后余额和流水金额
+		available, used, frozen, amount float64
+	}{
+		{
+			name:      "deposit",
+			user:      model.User{AvailableDeposit: 100},
+			change:    BalanceChange{Type: model.FundLogTypeDeposit, AvailableDelta: 50},
+			available: 150, amount: 50,
+		},
+		{
+			name:      "order freeze moves available to used",
+			user:      model.User{AvailableDeposit: 100},
+			change:    BalanceChange{Type: model.FundLogTypeOrderFreeze, AvailableDelta: -40, UsedDelta: 40},
+			available: 60, used: 40, amount: -40,
+		},
+		{
+			name:      "settle with fee",
+			user:      model.User{AvailableDeposit: 10, UsedDeposit: 40},
+			change:    BalanceChange{Type: model.FundLogTypeSettle, AvailableDelta: 45, UsedDelta: -40, Fee: 5},
+			available: 55, amount: 45,
+		},
+		{
+			name:      "withdraw freeze moves available to frozen",
+			user:      model.User{AvailableDeposit: 100},
+			change:    BalanceChange{Type: model.FundLogTypeWithdrawFreeze, AvailableDelta: -30, FrozenDelta: 30},
+			available: 70, frozen: 30, amount: -30,
+		},
+		{
+			name:    "insufficient available",
+			user:    model.User{AvailableDeposit: 10},
+			change:  BalanceChange{Type: model.FundLogTypeOrderFreeze, AvailableDelta: -20, UsedDelta: 20},
+			wantErr: true,
+		},
+		{
+			name:    "used deposit would go negative",
+			user:    model.User{AvailableDeposit: 10, UsedDeposit: 5},
+			change:  BalanceChange{Type: model.FundLogTypeOrderRelease, AvailableDelta: 10, UsedDelta: -10},
+			wantErr: true,
+		},
+		{
+			name:    "frozen would go negative",
+			user:    model.User{AvailableDeposit: 10},
+			change:  BalanceChange{Type: model.FundLogTypeWithdrawRelease, AvailableDelta: 10, FrozenDelta: -10},
+			wantErr: true,
+		},
+		{
+			name:    "missing type",
+			user:    model.User{AvailableDeposit: 10},
+			change:  BalanceChange{AvailableDelta: 10},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			user := createTestUser(t, ctx, &tt.user)
+			svc := NewBalanceService(ctx)
+
+			change := tt.change
+			change.UserID = user.ID
+			tx := ctx.DB.Begin()
+			fundLog, err := svc.Apply(tx, change)
+			if tt.wantErr {
+				tx.Rollback()
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				var stored model.User
+				ctx.DB.First(&stored, user.ID)
+				if stored.AvailableDeposit != tt.user.AvailableDeposit || stored.UsedDeposit != tt.user.UsedDeposit {
+					t.Errorf("balance changed on error: %+v", stored)
+				}
+				return
+			}
+			if err != nil {
+				tx.Rollback()
+				t.Fatalf("Apply: %v", err)
+			}
+			if err := tx.Commit().Error; err != nil {
+				t.Fatalf("commit: %v", err)
+			}
+
+			var stored model.User
+			ctx.DB.First(&stored, user.ID)
+			if stored.AvailableDeposit != tt.available || stored.UsedDeposit != tt.used || stored.WithdrawFrozen != tt.frozen {
+				t.Errorf("balance = %.2f/%.2f/%.2f, want %.2f/%.2f/%.2f",
+					stored.AvailableDeposit, stored.UsedDeposit, stored.WithdrawFrozen, tt.available, tt.used, tt.frozen)
+			}
+			if fundLog.Amount != tt.amount {
+				t.Errorf("fund log amount = %.2f, want %.2f", fundLog.Amount, tt.amount)
+			}
+			if fundLog.AvailableAfter != tt.available || fundLog.UsedAfter != tt.used || fundLog.FrozenAfter != tt.frozen {
+				t.Errorf("fund log after = %.2f/%.2f/%.2f", fundLog.AvailableAfter, fundLog.UsedAfter, fundLog.FrozenAfter)
+			}
+
+			// 总账：借贷平衡，客户分户科目与余额一致
+			check, err := NewLedgerService(ctx).Check()
+			if err != nil {
+				t.Fatalf("ledger check: %v", err)
+			}
+			if !check.Balanced {
+				t.Errorf("ledger not balanced: debit %.2f, credit %.2f", check.TotalDebit, check.TotalCredit)
+			}
+			if len(check.AccountMismatches) > 0 || len(check.UserMismatches) > 0 {
+				t.Errorf("ledger mismatches: %d account, %d user", len(check.AccountMismatches), len(check.UserMismatches))
+			}
+		})
+	}
+}
+
+func TestBalanceServiceApplyInsufficientError(t *testing.T) {
+	ctx := newTestContext(t)
+	user := createTestUser(t, ctx, &model.User{AvailableDeposit: 10})
+
+	tx := ctx.DB.Begin()
+	defer tx.Rollback()
+	_, err := NewBalanceService(ctx).Apply(tx, BalanceChange{
+		UserID: user.ID, Type: model.FundLogTypeOrderFreeze, AvailableDelta: -25, UsedDelta: 25,
+	})
+	var insufficient *InsufficientBalanceError
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("err = %v, want InsufficientBalanceError", err)
+	}
+	if insufficient.Available != 10 || insufficient.Required != 25 {
+		t.Errorf("got %+v", insufficient)
+	}
+}
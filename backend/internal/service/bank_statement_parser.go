@@ -0,0 +1,322 @@
+/**
+ * 银行流水文件解析
+ *
+ * 用途：
+ * - 按银行列映射解析 CSV/XLSX 流水文件，提取入账交易
+ *
+ * 规则：
+ * - 表头所在行之前的内容忽略；表头按列映射中的名称定位列
+ * - 收入金额为空、为0或为负（支出）的行跳过；日期列为空或不含数字的行（如合计行）跳过
+ * - 日期含数字但无法识别、金额无法识别时整个文件解析失败，提示行号，需调整列映射后重新导入
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/xlsx"
+)
+
+// statementTimeLayouts 未配置时间格式时依次尝试的常见格式
+var statementTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-1-2 15:04:05",
+	"2006/1/2 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04",
+	"2006/1/2 15:04",
+	"20060102 15:04:05",
+	"20060102 150405",
+	"20060102150405",
+	"2006年01月02日 15:04:05",
+	"2006年1月2日 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"2006/1/2",
+	"20060102",
+	"2006年01月02日",
+	"2006年1月2日",
+}
+
+// statementClockLayouts 单独时间列的格式
+var statementClockLayouts = []string{"15:04:05", "15:04", "150405"}
+
+/**
+ * statementRecord 解析出的入账交易
+ */
+type statementRecord struct {
+	Row          int
+	SerialNo     string
+	TxnTime      time.Time
+	Amount       float64
+	PayerName    string
+	PayerAccount string
+	Reference    string
+}
+
+/**
+ * statementParseResult 流水文件解析结果
+ */
+type statementParseResult struct {
+	Records []statementRecord
+	Total   int // 数据行数
+	Skipped int // 支出/合计等跳过行数
+}
+
+/**
+ * parseStatementFile 按列映射解析流水文件
+ *
+ * @param mapping *model.BankStatementMapping - 列映射
+ * @param fileName string - 文件名（按扩展名区分 CSV/XLSX）
+ * @param data []byte - 文件内容
+ * @return (*statementParseResult, error)
+ */
+func parseStatementFile(mapping *model.BankStatementMapping, fileName string, data []byte) (*statementParseResult, error) {
+	rows, err := readStatementRows(mapping, fileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow := mapping.HeaderRow
+	if headerRow < 1 {
+		headerRow = 1
+	}
+	if len(rows) < headerRow {
+		return nil, fmt.Errorf("文件不足%d行，未找到表头", headerRow)
+	}
+
+	header := make(map[string]int)
+	for i, name := range rows[headerRow-1] {
+		name = cleanStatementCell(name)
+		if _, ok := header[name]; !ok && name != "" {
+			header[name] = i
+		}
+	}
+	column := func(name string, required bool) (int, error) {
+		if name == "" {
+			if required {
+				return -1, errors.New("列映射缺少必填列")
+			}
+			return -1, nil
+		}
+		idx, ok := header[name]
+		if !ok {
+			return -1, fmt.Errorf("表头中未找到列「%s」，请检查列映射的表头行和列名", name)
+		}
+		return idx, nil
+	}
+
+	cols := make(map[string]int)
+	for key, spec := range map[string]struct {
+		name     string
+		required bool
+	}{
+		"date":    {mapping.DateColumn, true},
+		"time":    {mapping.TimeColumn, false},
+		"amount":  {mapping.AmountColumn, true},
+		"debit":   {mapping.DebitColumn, false},
+		"payer":   {mapping.PayerNameColumn, false},
+		"account": {mapping.PayerAccountColumn, false},
+		"ref":     {mapping.ReferenceColumn, false},
+		"serial":  {mapping.SerialColumn, false},
+	} {
+		idx, err := column(spec.name, spec.required)
+		if err != nil {
+			return nil, err
+		}
+		cols[key] = idx
+	}
+
+	result := &statementParseResult{}
+	for i := headerRow; i < len(rows); i++ {
+		row := rows[i]
+		rowNo := i + 1
+		cell := func(key string) string {
+			idx := cols[key]
+			if idx < 0 || idx >= len(row) {
+				return ""
+			}
+			return cleanStatementCell(row[idx])
+		}
+
+		if isBlankRow(row) {
+			continue
+		}
+		result.Total++
+
+		dateValue := cell("date")
+		if !strings.ContainsFunc(dateValue, unicode.IsDigit) {
+			result.Skipped++
+			continue
+		}
+
+		amountValue := cell("amount")
+		if amountValue == "" {
+			result.Skipped++
+			continue
+		}
+		amount, err := parseStatementAmount(amountValue)
+		if err != nil {
+			return nil, fmt.Errorf("第%d行收入金额无法识别: %s", rowNo, amountValue)
+		}
+		if amount <= 0 {
+			result.Skipped++
+			continue
+		}
+		if debit, err := parseStatementAmount(cell("debit")); err == nil && debit > 0 {
+			result.Skipped++
+			continue
+		}
+
+		txnTime, err := parseStatementTime(dateValue, cell("time"), mapping.DateFormat)
+		if err != nil {
+			return nil, fmt.Errorf("第%d行交易时间无法识别: %s %s", rowNo, dateValue, cell("time"))
+		}
+
+		result.Records = append(result.Records, statementRecord{
+			Row:          rowNo,
+			SerialNo:     cell("serial"),
+			TxnTime:      txnTime,
+			Amount:       amount,
+			PayerName:    cell("payer"),
+			PayerAccount: cell("account"),
+			Reference:    cell("ref"),
+		})
+	}
+	return result, nil
+}
+
+/**
+ * readStatementRows 读取文件全部行（CSV 按映射编码解码）
+ */
+func readStatementRows(mapping *model.BankStatementMapping, fileName string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx":
+		return xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+	case ".csv", ".txt":
+		if strings.EqualFold(mapping.Encoding, model.StatementEncodingGBK) {
+			decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(data)
+			if err != nil {
+				return nil, fmt.Errorf("GBK解码失败: %v", err)
+			}
+			data = decoded
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("CSV解析失败: %v", err)
+		}
+		return rows, nil
+	case ".xls":
+		return nil, errors.New("不支持旧版.xls文件，请在Excel中另存为.xlsx或.csv后导入")
+	default:
+		return nil, errors.New("仅支持.csv和.xlsx文件")
+	}
+}
+
+/**
+ * parseStatementTime 解析交易时间（支持 Excel 日期序列号、日期与时间分列）
+ */
+func parseStatementTime(dateValue, clockValue, layout string) (time.Time, error) {
+	// XLSX 日期单元格为序列号（如 45967.5）
+	if serial, err := strconv.ParseFloat(dateValue, 64); err == nil && serial > 1000 && serial < 200000 {
+		t := xlsx.SerialToTime(serial, time.Local)
+		if clockValue == "" {
+			return t, nil
+		}
+		if fraction, err := strconv.ParseFloat(clockValue, 64); err == nil && fraction < 1 {
+			return t.Add(time.Duration(fraction*86400+0.5) * time.Second), nil
+		}
+		for _, l := range statementClockLayouts {
+			if clock, err := time.Parse(l, clockValue); err == nil {
+				return t.Add(time.Duration(clock.Hour())*time.Hour +
+					time.Duration(clock.Minute())*time.Minute +
+					time.Duration(clock.Second())*time.Second), nil
+			}
+		}
+		return time.Time{}, errors.New("时间无法识别")
+	}
+
+	value := strings.TrimSpace(dateValue + " " + clockValue)
+	layouts := statementTimeLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("时间无法识别")
+}
+
+/**
+ * parseStatementAmount 解析金额（去除千分位、货币符号）
+ */
+func parseStatementAmount(value string) (float64, error) {
+	value = strings.NewReplacer(",", "", "，", "", "¥", "", "￥", "", "元", "", " ", "").Replace(value)
+	if value == "" {
+		return 0, errors.New("金额为空")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+/**
+ * cleanStatementCell 去除单元格首尾空白及网银导出常见的前缀（制表符、="..."）
+ */
+func cleanStatementCell(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "=\"") && strings.HasSuffix(value, "\"") {
+		value = value[2 : len(value)-1]
+	}
+	return strings.TrimSpace(strings.Trim(value, "\t'"))
+}
+
+/**
+ * isBlankRow 是否为空行
+ */
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * statementFingerprint 流水去重指纹（有流水号按银行+流水号，否则按交易要素）
+ */
+func statementFingerprint(bankName string, r statementRecord) string {
+	var key string
+	if r.SerialNo != "" {
+		key = bankName + "|" + r.SerialNo
+	} else {
+		key = fmt.Sprintf("%s|%s|%.2f|%s|%s|%s", bankName, r.TxnTime.Format(time.RFC3339),
+			r.Amount, r.PayerName, r.PayerAccount, r.Reference)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,634 @@
+/**
+ * 银行流水导入与充值自动匹配服务
+ *
+ * 用途：
+ * - 管理各银行流水文件的列映射
+ * - 导入流水文件，按金额、付款人、时间窗口、转账附言识别码与待审核充值申请匹配
+ * - 置信度达到自动入账阈值的直接审核通过入账，达到建议阈值的待人工确认，其余进入异常队列
+ * - 人工确认/驳回建议匹配，为异常流水指定充值申请或忽略
+ *
+ * 置信度（满分100）：
+ * - 金额一致 40（必要条件）
+ * - 附言含客户识别码 30
+ * - 付款人户名或账号与客户实名/绑定银行卡一致 20
+ * - 交易时间与申请时间相差6小时内 10
+ *
+ * 说明：
+ * - 同分候选多于一个时不自动入账；需要超级管理员复核的大额申请不自动入账，只建议匹配
+ * - 自动入账走充值审批链：以银行名称为依据记录系统审批步骤，再写资金流水和总账
+ * - 同一笔流水按去重指纹只导入一次；一笔充值申请只能被一条流水匹配
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+const (
+	defaultStatementAutoConfidence    = 90 // 默认自动入账置信度
+	defaultStatementProposeConfidence = 60 // 默认建议匹配置信度
+	defaultStatementWindowHours       = 72 // 默认流水与申请时间差上限（小时）
+	statementCloseTimeHours           = 6  // 时间接近加分的时间差（小时）
+
+	statementScoreAmount    = 40
+	statementScoreReference = 30
+	statementScorePayer     = 20
+	statementScoreTime      = 10
+)
+
+/**
+ * StatementCandidate 流水匹配候选充值申请
+ */
+type StatementCandidate struct {
+	Deposit    *model.DepositRequest `json:"deposit"`
+	Confidence int                   `json:"confidence"`
+	Reasons    []string              `json:"reasons"`
+}
+
+/**
+ * BankStatementService 银行流水服务
+ */
+type BankStatementService struct {
+	ctx          *appctx.AppContext
+	repo         *repository.BankStatementRepository
+	depositRepo  *repository.DepositRepository
+	bankCardRepo *repository.BankCardRepository
+	configRepo   *repository.ConfigRepository
+	depositSvc   *DepositService
+	notiSvc      *NotificationService
+}
+
+/**
+ * NewBankStatementService 创建银行流水服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *BankStatementService
+ */
+func NewBankStatementService(ctx *appctx.AppContext) *BankStatementService {
+	return &BankStatementService{
+		ctx:          ctx,
+		repo:         repository.NewBankStatementRepository(ctx.DB),
+		depositRepo:  repository.NewDepositRepository(ctx.DB),
+		bankCardRepo: repository.NewBankCardRepository(ctx.DB),
+		configRepo:   repository.NewConfigRepository(ctx.DB),
+		depositSvc:   NewDepositService(ctx),
+		notiSvc:      NewNotificationService(ctx),
+	}
+}
+
+/**
+ * ListMappings 查询全部列映射
+ *
+ * @return ([]*model.BankStatementMapping, error)
+ */
+func (s *BankStatementService) ListMappings() ([]*model.BankStatementMapping, error) {
+	return s.repo.FindMappings()
+}
+
+/**
+ * SaveMapping 新增或修改列映射（ID为0时新增）
+ *
+ * @param mapping *model.BankStatementMapping - 列映射
+ * @return error
+ */
+func (s *BankStatementService) SaveMapping(mapping *model.BankStatementMapping) error {
+	mapping.BankName = strings.TrimSpace(mapping.BankName)
+	if mapping.BankName == "" {
+		return errors.New("请填写银行名称")
+	}
+	if strings.TrimSpace(mapping.DateColumn) == "" || strings.TrimSpace(mapping.AmountColumn) == "" {
+		return errors.New("交易日期列和收入金额列为必填")
+	}
+	if mapping.Encoding == "" {
+		mapping.Encoding = model.StatementEncodingUTF8
+	}
+	if mapping.Encoding != model.StatementEncodingUTF8 && mapping.Encoding != model.StatementEncodingGBK {
+		return fmt.Errorf("不支持的文件编码: %s", mapping.Encoding)
+	}
+	if mapping.HeaderRow < 1 {
+		mapping.HeaderRow = 1
+	}
+
+	if mapping.ID == 0 {
+		if err := s.repo.CreateMapping(mapping); err != nil {
+			return fmt.Errorf("保存列映射失败（银行名称可能重复）: %v", err)
+		}
+		return nil
+	}
+	existing, err := s.repo.FindMappingByID(mapping.ID)
+	if err != nil {
+		return errors.New("列映射不存在")
+	}
+	mapping.CreatedAt = existing.CreatedAt
+	if err := s.repo.UpdateMapping(mapping); err != nil {
+		return fmt.Errorf("保存列映射失败（银行名称可能重复）: %v", err)
+	}
+	return nil
+}
+
+/**
+ * DeleteMapping 删除列映射
+ *
+ * @param id uint - 列映射ID
+ * @return error
+ */
+func (s *BankStatementService) DeleteMapping(id uint) error {
+	return s.repo.DeleteMapping(id)
+}
+
+/**
+ * Import 导入流水文件并自动匹配
+ *
+ * 业务流程：
+ * 1. 按列映射解析文件（解析失败不落库）
+ * 2. 创建导入批次，逐条去重写入入账流水
+ * 3. 为每条流水计算候选充值申请和置信度，自动入账/建议匹配/进入异常队列
+ * 4. 更新批次统计，有待处理流水时通知管理员
+ *
+ * @param mappingID uint - 列映射ID
+ * @param operatorID uint - 导入人ID
+ * @param fileName string - 文件名
+ * @param data []byte - 文件内容
+ * @return (*model.BankStatementImport, error)
+ */
+func (s *BankStatementService) Import(mappingID, operatorID uint, fileName string, data []byte) (*model.BankStatementImport, error) {
+	mapping, err := s.repo.FindMappingByID(mappingID)
+	if err != nil {
+		return nil, errors.New("列映射不存在")
+	}
+
+	parsed, err := parseStatementFile(mapping, fileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &model.BankStatementImport{
+		MappingID:    mapping.ID,
+		BankName:     mapping.BankName,
+		FileName:     fileName,
+		OperatorID:   operatorID,
+		TotalLines:   parsed.Total,
+		SkippedLines: parsed.Skipped,
+	}
+	if err := s.repo.CreateImport(imp); err != nil {
+		return nil, fmt.Errorf("创建导入记录失败: %v", err)
+	}
+
+	autoConfidence := s.configInt(model.ConfigKeyStatementAutoConfidence, defaultStatementAutoConfidence)
+	proposeConfidence := s.configInt(model.ConfigKeyStatementProposeConfidence, defaultStatementProposeConfidence)
+
+	for _, record := range parsed.Records {
+		fingerprint := statementFingerprint(mapping.BankName, record)
+		exists, err := s.repo.ExistsFingerprint(fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("查询流水失败: %v", err)
+		}
+		if exists {
+			imp.DuplicateLines++
+			continue
+		}
+
+		line := &model.BankStatementLine{
+			ImportID:     imp.ID,
+			BankName:     mapping.BankName,
+			Fingerprint:  fingerprint,
+			SerialNo:     record.SerialNo,
+			TxnTime:      record.TxnTime,
+			Amount:       record.Amount,
+			PayerName:    record.PayerName,
+			PayerAccount: record.PayerAccount,
+			Reference:    record.Reference,
+			Status:       model.StatementLineException,
+		}
+		if err := s.repo.CreateLine(line); err != nil {
+			// 同一文件内重复行触发唯一索引
+			imp.DuplicateLines++
+			continue
+		}
+		imp.CreditLines++
+
+		s.match(line, autoConfidence, proposeConfidence)
+		switch line.Status {
+		case model.StatementLineAutoMatched:
+			imp.AutoMatched++
+		case model.StatementLineProposed:
+			imp.Proposed++
+		default:
+			imp.Exceptions++
+		}
+	}
+
+	if err := s.repo.UpdateImport(imp); err != nil {
+		log.Printf("[Statement] 更新导入记录失败: %v", err)
+	}
+
+	if imp.Proposed > 0 || imp.Exceptions > 0 {
+		s.notiSvc.SendSystemNotificationToAdmins("银行流水待处理",
+			fmt.Sprintf("%s 流水导入完成：入账 %d 笔，自动入账 %d 笔，待确认 %d 笔，异常 %d 笔",
+				imp.BankName, imp.CreditLines, imp.AutoMatched, imp.Proposed, imp.Exceptions), "")
+	}
+	log.Printf("[Statement] 导入流水 %s（%s）: 入账 %d, 重复 %d, 跳过 %d, 自动入账 %d, 待确认 %d, 异常 %d",
+		fileName, imp.BankName, imp.CreditLines, imp.DuplicateLines, imp.SkippedLines,
+		imp.AutoMatched, imp.Proposed, imp.Exceptions)
+	return imp, nil
+}
+
+/**
+ * GetImports 分页查询导入批次
+ *
+ * @param limit int - 查询数量限制
+ * @param offset int - 偏移量
+ * @return ([]*model.BankStatementImport, int64, error)
+ */
+func (s *BankStatementService) GetImports(limit, offset int) ([]*model.BankStatementImport, int64, error) {
+	return s.repo.FindImports(limit, offset)
+}
+
+/**
+ * GetLines 分页查询流水
+ *
+ * @param status string - 匹配状态（为空不筛选）
+ * @param importID uint - 导入批次（0不筛选）
+ * @param limit int - 查询数量限制
+ * @param offset int - 偏移量
+ * @return ([]*model.BankStatementLine, int64, error)
+ */
+func (s *BankStatementService) GetLines(status string, importID uint, limit, offset int) ([]*model.BankStatementLine, int64, error) {
+	return s.repo.FindLines(status, importID, limit, offset)
+}
+
+/**
+ * GetCandidates 查询流水的候选充值申请（不限时间窗口，供人工匹配参考）
+ *
+ * @param lineID uint - 流水ID
+ * @return ([]StatementCandidate, error)
+ */
+func (s *BankStatementService) GetCandidates(lineID uint) ([]StatementCandidate, error) {
+	line, err := s.repo.FindLineByID(lineID)
+	if err != nil {
+		return nil, errors.New("流水不存在")
+	}
+	return s.candidates(line, false)
+}
+
+/**
+ * ConfirmLine 人工确认匹配（建议匹配直接确认；异常流水需指定充值申请）
+ *
+ * 说明：
+ * - 确认即以人工身份审核通过充值申请，走正常审批链（大额仍需超级管理员复核）
+ * - 充值申请已在充值审核页通过的，仅关联流水不重复入账
+ *
+ * @param lineID uint - 流水ID
+ * @param depositID uint - 充值申请ID（为0时使用建议匹配的申请）
+ * @param operatorID uint - 操作人ID
+ * @param note string - 备注
+ * @return (*model.BankStatementLine, error)
+ */
+func (s *BankStatementService) ConfirmLine(lineID, depositID, operatorID uint, note string) (*model.BankStatementLine, error) {
+	line, err := s.repo.FindLineByID(lineID)
+	if err != nil {
+		return nil, errors.New("流水不存在")
+	}
+	if line.Status != model.StatementLineProposed && line.Status != model.StatementLineException {
+		return nil, fmt.Errorf("流水状态不允许确认（当前状态: %s）", line.Status)
+	}
+	if depositID == 0 {
+		depositID = line.DepositID
+	}
+	if depositID == 0 {
+		return nil, errors.New("请指定要匹配的充值申请")
+	}
+
+	deposit, err := s.depositRepo.FindByID(depositID)
+	if err != nil {
+		return nil, errors.New("充值申请不存在")
+	}
+	if deposit.Method == model.DepositMethodOnline {
+		return nil, errors.New("在线支付充值不能与银行流水匹配")
+	}
+	if diff := deposit.Amount - line.Amount; diff > 0.005 || diff < -0.005 {
+		return nil, fmt.Errorf("充值申请金额 %.2f 与流水金额 %.2f 不一致", deposit.Amount, line.Amount)
+	}
+	if depositID != line.DepositID {
+		claimed, err := s.repo.FindClaimedDepositIDs([]uint{depositID})
+		if err != nil {
+			return nil, fmt.Errorf("查询匹配记录失败: %v", err)
+		}
+		if claimed[depositID] {
+			return nil, errors.New("该充值申请已与其他流水匹配")
+		}
+	}
+
+	reason := line.MatchReason
+	switch {
+	case deposit.IsAwaitingApproval():
+		approveNote := fmt.Sprintf("银行流水匹配确认：%s %s", line.BankName, line.TxnTime.Format("2006-01-02 15:04:05"))
+		if note != "" {
+			approveNote += "，" + note
+		}
+		deposit, err = s.depositSvc.ApproveDeposit(depositID, operatorID, approveNote, "")
+		if err != nil {
+			return nil, err
+		}
+		if deposit.Status == model.DepositStatusReviewing {
+			reason = "人工确认匹配，充值申请待超级管理员复核"
+		} else {
+			reason = "人工确认匹配，已入账"
+		}
+	case deposit.Status == model.DepositStatusApproved:
+		reason = "人工确认匹配，充值申请此前已审核入账"
+	default:
+		return nil, fmt.Errorf("充值申请状态不允许匹配（当前状态: %s）", deposit.Status)
+	}
+
+	now := time.Now()
+	line.Status = model.StatementLineConfirmed
+	line.DepositID = depositID
+	line.MatchReason = reason
+	line.HandledBy = operatorID
+	line.HandleNote = note
+	line.HandledAt = &now
+	if err := s.repo.UpdateLine(line); err != nil {
+		return nil, fmt.Errorf("更新流水失败: %v", err)
+	}
+
+	log.Printf("[Statement] 流水 %d 人工确认匹配充值申请 %d，操作人=%d", line.ID, depositID, operatorID)
+	return line, nil
+}
+
+/**
+ * RejectProposal 驳回建议匹配，流水转入异常队列
+ *
+ * @param lineID uint - 流水ID
+ * @param operatorID uint - 操作人ID
+ * @param note string - 驳回原因
+ * @return (*model.BankStatementLine, error)
+ */
+func (s *BankStatementService) RejectProposal(lineID, operatorID uint, note string) (*model.BankStatementLine, error) {
+	return s.handle(lineID, model.StatementLineProposed, model.StatementLineException, operatorID, note)
+}
+
+/**
+ * IgnoreLine 忽略异常流水（非客户充值，如利息、退款）
+ *
+ * @param lineID uint - 流水ID
+ * @param operatorID uint - 操作人ID
+ * @param note string - 忽略原因
+ * @return (*model.BankStatementLine, error)
+ */
+func (s *BankStatementService) IgnoreLine(lineID, operatorID uint, note string) (*model.BankStatementLine, error) {
+	if strings.TrimSpace(note) == "" {
+		return nil, errors.New("请填写忽略原因")
+	}
+	return s.handle(lineID, model.StatementLineException, model.StatementLineIgnored, operatorID, note)
+}
+
+/**
+ * handle 流水状态流转（驳回建议、忽略）
+ */
+func (s *BankStatementService) handle(lineID uint, from, to string, operatorID uint, note string) (*model.BankStatementLine, error) {
+	line, err := s.repo.FindLineByID(lineID)
+	if err != nil {
+		return nil, errors.New("流水不存在")
+	}
+	if line.Status != from {
+		return nil, fmt.Errorf("流水状态不允许该操作（当前状态: %s）", line.Status)
+	}
+
+	now := time.Now()
+	line.Status = to
+	if to == model.StatementLineException {
+		line.DepositID = 0
+		line.MatchReason = "建议匹配已驳回"
+	}
+	line.HandledBy = operatorID
+	line.HandleNote = note
+	line.HandledAt = &now
+	if err := s.repo.UpdateLine(line); err != nil {
+		return nil, fmt.Errorf("更新流水失败: %v", err)
+	}
+	return line, nil
+}
+
+/**
+ * match 为新导入的流水匹配充值申请并更新状态
+ */
+func (s *BankStatementService) match(line *model.BankStatementLine, autoConfidence, proposeConfidence int) {
+	candidates, err := s.candidates(line, true)
+	if err != nil {
+		log.Printf("[Statement] 流水 %d 查询候选失败: %v", line.ID, err)
+		return
+	}
+	if len(candidates) == 0 {
+		line.MatchReason = "无金额一致的待审核充值申请"
+		s.saveLine(line)
+		return
+	}
+
+	best := candidates[0]
+	ambiguous := len(candidates) > 1 && candidates[1].Confidence == best.Confidence
+	line.Confidence = best.Confidence
+	line.MatchReason = strings.Join(best.Reasons, "、")
+
+	if best.Confidence < proposeConfidence {
+		line.MatchReason = fmt.Sprintf("最高置信度 %d 未达建议阈值（%s）", best.Confidence, line.MatchReason)
+		s.saveLine(line)
+		return
+	}
+
+	line.Status = model.StatementLineProposed
+	line.DepositID = best.Deposit.ID
+
+	switch {
+	case best.Confidence < autoConfidence:
+	case ambiguous:
+		line.MatchReason += "；存在同分候选，需人工确认"
+	case !best.Deposit.IsPending() || best.Deposit.Required() > 1:
+		line.MatchReason += "；大额申请需人工审批"
+	default:
+		s.autoApprove(line, best.Deposit)
+		return
+	}
+	s.saveLine(line)
+}
+
+/**
+ * autoApprove 自动审核通过充值申请并标记流水（失败时保留为建议匹配）
+ */
+func (s *BankStatementService) autoApprove(line *model.BankStatementLine, deposit *model.DepositRequest) {
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	note := fmt.Sprintf("银行流水自动匹配：%s %s，置信度 %d", line.BankName, line.TxnTime.Format("2006-01-02 15:04:05"), line.Confidence)
+	deposit, fundLog, err := s.depositSvc.ApproveBySystem(tx, deposit.ID, line.BankName, note)
+	if err != nil {
+		tx.Rollback()
+		line.MatchReason += "；自动入账失败: " + err.Error()
+		s.saveLine(line)
+		return
+	}
+
+	line.Status = model.StatementLineAutoMatched
+	if err := repository.NewBankStatementRepository(tx).UpdateLine(line); err != nil {
+		tx.Rollback()
+		line.Status = model.StatementLineProposed
+		line.MatchReason += "；自动入账失败: 更新流水失败"
+		s.saveLine(line)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		line.Status = model.StatementLineProposed
+		line.MatchReason += "；自动入账失败: 事务提交失败"
+		s.saveLine(line)
+		return
+	}
+
+	notifyMsg := fmt.Sprintf("您的充值申请已审核通过\n充值金额：%.2f 元\n当前可用定金：%.2f 元",
+		deposit.Amount, fundLog.AvailableAfter)
+	s.notiSvc.SendFundNotification(deposit.UserID, "充值成功", notifyMsg)
+}
+
+/**
+ * candidates 计算流水的候选充值申请（按置信度降序，同分按申请时间先后）
+ *
+ * @param line *model.BankStatementLine - 流水
+ * @param withinWindow bool - 是否限制时间窗口
+ */
+func (s *BankStatementService) candidates(line *model.BankStatementLine, withinWindow bool) ([]StatementCandidate, error) {
+	var from, to time.Time
+	if withinWindow {
+		window := time.Duration(s.configInt(model.ConfigKeyStatementMatchWindowHours, defaultStatementWindowHours)) * time.Hour
+		from, to = line.TxnTime.Add(-window), line.TxnTime.Add(window)
+	}
+
+	deposits, err := s.depositRepo.FindAwaitingByAmount(line.Amount, from, to)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(deposits))
+	for _, d := range deposits {
+		ids = append(ids, d.ID)
+	}
+	claimed, err := s.repo.FindClaimedDepositIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := strings.ToUpper(strings.ReplaceAll(line.Reference, " ", ""))
+	payerAccount := strings.ReplaceAll(line.PayerAccount, " ", "")
+	payerName := strings.TrimSpace(line.PayerName)
+
+	var result []StatementCandidate
+	for _, d := range deposits {
+		if claimed[d.ID] && d.ID != line.DepositID {
+			continue
+		}
+		c := StatementCandidate{Deposit: d, Confidence: statementScoreAmount, Reasons: []string{"金额一致"}}
+
+		if containsReferenceCode(reference, model.DepositReferenceCode(d.UserID)) {
+			c.Confidence += statementScoreReference
+			c.Reasons = append(c.Reasons, "附言识别码一致")
+		}
+		if s.payerMatches(d, payerName, payerAccount) {
+			c.Confidence += statementScorePayer
+			c.Reasons = append(c.Reasons, "付款人一致")
+		}
+		gap := d.CreatedAt.Sub(line.TxnTime)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= statementCloseTimeHours*time.Hour {
+			c.Confidence += statementScoreTime
+			c.Reasons = append(c.Reasons, "时间接近")
+		}
+		result = append(result, c)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Confidence > result[j].Confidence
+	})
+	return result, nil
+}
+
+/**
+ * payerMatches 付款人户名与客户实名或绑定银行卡持卡人一致，或付款账号为客户绑定银行卡
+ */
+func (s *BankStatementService) payerMatches(deposit *model.DepositRequest, payerName, payerAccount string) bool {
+	if payerName == "" && payerAccount == "" {
+		return false
+	}
+	if deposit.User != nil && payerName != "" && deposit.User.RealName == payerName {
+		return true
+	}
+	cards, err := s.bankCardRepo.FindByUserID(deposit.UserID)
+	if err != nil {
+		return false
+	}
+	for _, card := range cards {
+		if payerName != "" && card.CardHolder == payerName {
+			return true
+		}
+		if payerAccount != "" && strings.ReplaceAll(card.CardNumber, " ", "") == payerAccount {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * saveLine 保存流水匹配结果
+ */
+func (s *BankStatementService) saveLine(line *model.BankStatementLine) {
+	if err := s.repo.UpdateLine(line); err != nil {
+		log.Printf("[Statement] 更新流水 %d 失败: %v", line.ID, err)
+	}
+}
+
+/**
+ * configInt 读取整数配置（未配置或格式错误时使用默认值）
+ */
+func (s *BankStatementService) configInt(key string, def int) int {
+	config, err := s.configRepo.FindByKey(key)
+	if err != nil || config == nil || strings.TrimSpace(config.Value) == "" {
+		return def
+	}
+	var v int
+	if _, err := fmt.Sscanf(config.Value, "%d", &v); err != nil || v < 0 {
+		return def
+	}
+	return v
+}
+
+/**
+ * containsReferenceCode 附言中是否包含识别码（识别码后紧跟数字的不算，避免 SJ000012 匹配 SJ0000123）
+ */
+func containsReferenceCode(reference, code string) bool {
+	for start := 0; ; {
+		idx := strings.Index(reference[start:], code)
+		if idx < 0 {
+			return false
+		}
+		end := start + idx + len(code)
+		if end >= len(reference) || reference[end] < '0' || reference[end] > '9' {
+			return true
+		}
+		start = end
+	}
+}
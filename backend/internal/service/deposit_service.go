@@ -5,6 +5,7 @@
  * - 实现充值业务逻辑
  * - 处理充值审核流程
  * - 集成资金流水记录
+ * - 超过双人审批阈值的充值需另一位超级管理员复核后才入账
  * 
  * 作者：速金盈技术团队
  * 日期：2025-11
@@ -17,6 +18,8 @@ import (
 	"fmt"
 	"log"
 
+	"gorm.io/gorm"
+
 	"suxin/internal/appctx"
 	"suxin/internal/model"
 	"suxin/internal/repository"
@@ -31,6 +34,8 @@ type DepositService struct {
 	userRepo     *repository.UserRepository
 	fundLogRepo  *repository.FundLogRepository
 	notiSvc      *NotificationService
+	approvalSvc  *ApprovalService
+	fileSvc      *FileService
 }
 
 /**
@@ -46,6 +51,8 @@ func NewDepositService(ctx *appctx.AppContext) *DepositService {
 		userRepo:    repository.NewUserRepository(ctx.DB),
 		fundLogRepo: repository.NewFundLogRepository(ctx.DB),
 		notiSvc:     NewNotificationService(ctx),
+		approvalSvc: NewApprovalService(ctx),
+		fileSvc:     NewFileService(ctx),
 	}
 }
 
@@ -65,6 +72,12 @@ func (s *DepositService) SubmitDeposit(userID uint, amount float64, method, vouc
 		return nil, errors.New("充值金额必须大于0")
 	}
 	
+	// 付款凭证转存到文件存储，申请中只保存文件引用
+	voucherURL, err := s.fileSvc.Ingest(voucherURL, model.FileCategoryDepositVoucher, userID)
+	if err != nil {
+		return nil, fmt.Errorf("保存付款凭证失败: %v", err)
+	}
+	
 	// 创建充值申请
 	deposit := &model.DepositRequest{
 		UserID:     userID,
@@ -73,6 +86,9 @@ func (s *DepositService) SubmitDeposit(userID uint, amount float64, method, vouc
 		VoucherURL: voucherURL,
 		UserNote:   note,
 		Status:     model.DepositStatusPending,
+		ApprovalChain: model.ApprovalChain{
+			RequiredApprovals: s.approvalSvc.RequiredApprovals(model.ApprovalTargetDeposit, amount),
+		},
 	}
 	
 	if err := s.depositRepo.Create(deposit); err != nil {
@@ -89,36 +105,36 @@ func (s *DepositService) SubmitDeposit(userID uint, amount float64, method, vouc
  * 业务流程：
  * 1. 查找充值申请
  * 2. 验证状态
- * 3. 更新用户可用定金
- * 4. 记录资金流水
- * 5. 更新申请状态
+ * 3. 事务内锁定申请并再次确认状态
+ * 4. 记录审批步骤，未到最后一级时转为待复核
+ * 5. 更新用户可用定金、记录资金流水并更新申请状态
  * 6. 发送通知
  * 
  * @param depositID uint - 充值申请ID
  * @param reviewerID uint - 审核人ID
  * @param note string - 审核备注
  * @param receiptVoucher string - 管理员收款凭证
- * @return error
+ * @return (*model.DepositRequest, error) - 审批后的充值申请
  */
-func (s *DepositService) ApproveDeposit(depositID, reviewerID uint, note, receiptVoucher string) error {
+func (s *DepositService) ApproveDeposit(depositID, reviewerID uint, note, receiptVoucher string) (*model.DepositRequest, error) {
 	// 1. 查找充值申请
 	deposit, err := s.depositRepo.FindByID(depositID)
 	if err != nil {
-		return errors.New("充值申请不存在")
+		return nil, errors.New("充值申请不存在")
 	}
 	
 	// 2. 验证状态
-	if !deposit.IsPending() {
-		return fmt.Errorf("充值申请状态不允许审核（当前状态: %s）", deposit.Status)
+	if !deposit.IsAwaitingApproval() {
+		return nil, fmt.Errorf("充值申请状态不允许审核（当前状态: %s）", deposit.Status)
 	}
 	
-	// 3. 查询用户信息
-	user, err := s.userRepo.FindByID(deposit.UserID)
+	// 收款凭证转存到文件存储（事务外完成，避免长时间持锁）
+	receiptVoucher, err = s.fileSvc.Ingest(receiptVoucher, model.FileCategoryDepositReceipt, reviewerID)
 	if err != nil {
-		return errors.New("用户不存在")
+		return nil, fmt.Errorf("保存收款凭证失败: %v", err)
 	}
 	
-	// 4. 开启事务
+	// 3. 开启事务
 	tx := s.ctx.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -126,56 +142,161 @@ func (s *DepositService) ApproveDeposit(depositID, reviewerID uint, note, receip
 		}
 	}()
 	
-	// 5. 更新用户可用定金
-	newAvailable := user.AvailableDeposit + deposit.Amount
-	if err := tx.Model(&model.User{}).Where("id = ?", deposit.UserID).
-		Update("available_deposit", newAvailable).Error; err != nil {
+	// 4. 锁定申请并再次确认状态，防止重复审核入账
+	deposit, err = repository.NewDepositRepository(tx).LockByID(depositID)
+	if err != nil {
 		tx.Rollback()
-		return errors.New("更新用户资金失败")
-	}
-	
-	// 6. 记录资金流水
-	fundLog := &model.FundLog{
-		UserID:          deposit.UserID,
-		Type:            model.FundLogTypeDeposit,
-		Amount:          deposit.Amount,
-		AvailableBefore: user.AvailableDeposit,
-		AvailableAfter:  newAvailable,
-		UsedBefore:      user.UsedDeposit,
-		UsedAfter:       user.UsedDeposit,
-		RelatedID:       deposit.ID,
-		RelatedType:     "deposit",
-		Note:            fmt.Sprintf("充值审核通过: %s", deposit.Method),
-	}
-	if err := tx.Create(fundLog).Error; err != nil {
+		return nil, errors.New("充值申请不存在")
+	}
+	if !deposit.IsAwaitingApproval() {
 		tx.Rollback()
-		return errors.New("记录资金流水失败")
+		return nil, fmt.Errorf("充值申请状态不允许审核（当前状态: %s）", deposit.Status)
 	}
 	
-	// 7. 更新申请状态和收款凭证
+	// 5. 记录审批步骤，未到最后一级时转为待复核（收款凭证随初审保存）
+	final, err := s.approvalSvc.Approve(tx, s.approvalTarget(deposit), reviewerID, note)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 	if receiptVoucher != "" {
 		deposit.ReceiptVoucherURL = receiptVoucher
 	}
-	deposit.Approve(reviewerID, note)
-	if err := tx.Save(deposit).Error; err != nil {
+	if !final {
+		deposit.PassStep()
+		if err := tx.Save(deposit).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("更新申请状态失败")
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("事务提交失败")
+		}
+		
+		s.notiSvc.SendSystemNotificationToAdmins("大额充值待复核",
+			fmt.Sprintf("充值申请 #%d（%.2f 元）已初审通过，需超级管理员复核", deposit.ID, deposit.Amount), "")
+		log.Printf("[Deposit] 充值初审通过，待复核: ID=%d, 审核人=%d", depositID, reviewerID)
+		return deposit, nil
+	}
+	
+	// 6. 增加用户可用定金、记录资金流水并更新申请状态
+	fundLog, err := s.credit(tx, deposit, reviewerID, note)
+	if err != nil {
 		tx.Rollback()
-		return errors.New("更新申请状态失败")
+		return nil, err
 	}
 	
-	// 8. 提交事务
+	// 7. 提交事务
 	if err := tx.Commit().Error; err != nil {
-		return errors.New("事务提交失败")
+		return nil, errors.New("事务提交失败")
 	}
 	
-	// 9. 发送通知
+	// 8. 发送通知
 	notifyMsg := fmt.Sprintf("您的充值申请已审核通过\n充值金额：%.2f 元\n当前可用定金：%.2f 元", 
-		deposit.Amount, newAvailable)
+		deposit.Amount, fundLog.AvailableAfter)
 	s.notiSvc.SendFundNotification(deposit.UserID, "充值成功", notifyMsg)
 	
 	log.Printf("[Deposit] 充值审核通过: ID=%d, 用户=%d, 金额=%.2f", 
 		depositID, deposit.UserID, deposit.Amount)
 	
-	return nil
+	return deposit, nil
+}
+
+/**
+ * CreditOnlineDeposit 在线支付到账入账（支付通道回调或查单确认已支付）
+ * 
+ * 业务流程：
+ * 1. 创建在线支付充值申请，按金额确定审批级数（与人工审核充值同一阈值）
+ * 2. 以支付通道为依据记录系统审批步骤（计为第一级）
+ * 3. 单级审批：增加用户可用定金、记录资金流水并更新申请状态
+ * 4. 超过双人审批阈值：转为待复核，由超级管理员复核后入账（不返回资金流水）
+ * 
+ * 说明：
+ * - 在调用方事务中执行，调用方负责锁定支付单保证只入账一次
+ * 
+ * @param tx *gorm.DB - 调用方事务
+ * @param userID uint - 用户ID
+ * @param amount float64 - 实付金额
+ * @param provider string - 支付通道名称
+ * @param orderNo string - 支付单号
+ * @param note string - 审批备注
+ * @return (*model.DepositRequest, *model.FundLog, error) - 充值申请、资金流水（待复核时为nil）
+ */
+func (s *DepositService) CreditOnlineDeposit(tx *gorm.DB, userID uint, amount float64, provider, orderNo, note string) (*model.DepositRequest, *model.FundLog, error) {
+	if amount <= 0 {
+		return nil, nil, errors.New("充值金额必须大于0")
+	}
+	
+	deposit := &model.DepositRequest{
+		UserID:   userID,
+		Amount:   amount,
+		Method:   model.DepositMethodOnline,
+		UserNote: fmt.Sprintf("在线支付 %s", orderNo),
+		Status:   model.DepositStatusPending,
+		ApprovalChain: model.ApprovalChain{
+			RequiredApprovals: s.approvalSvc.RequiredApprovals(model.ApprovalTargetDeposit, amount),
+		},
+	}
+	if err := repository.NewDepositRepository(tx).Create(deposit); err != nil {
+		return nil, nil, fmt.Errorf("创建充值记录失败: %v", err)
+	}
+	
+	final, err := s.approvalSvc.ConfirmBySystem(tx, s.approvalTarget(deposit), provider, note)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !final {
+		deposit.PassStep()
+		if err := tx.Save(deposit).Error; err != nil {
+			return nil, nil, errors.New("更新申请状态失败")
+		}
+		log.Printf("[Deposit] 在线支付到账，金额超过双人审批阈值待复核: ID=%d, 用户=%d, 金额=%.2f, 支付单=%s", 
+			deposit.ID, userID, amount, orderNo)
+		return deposit, nil, nil
+	}
+	
+	fundLog, err := s.credit(tx, deposit, 0, note)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	log.Printf("[Deposit] 在线支付入账: ID=%d, 用户=%d, 金额=%.2f, 支付单=%s", 
+		deposit.ID, userID, amount, orderNo)
+	return deposit, fundLog, nil
+}
+
+/**
+ * ApproveBySystem 依据外部到账凭据（如银行流水）自动审核通过充值申请并入账
+ * 
+ * 说明：
+ * - 在调用方事务中执行，锁定申请后再次确认状态
+ * - 仅适用于单级审批且尚未审批的申请，需要超级管理员复核的大额申请须人工审批
+ * 
+ * @param tx *gorm.DB - 调用方事务
+ * @param depositID uint - 充值申请ID
+ * @param source string - 审批依据（如银行名称）
+ * @param note string - 审批备注
+ * @return (*model.DepositRequest, *model.FundLog, error)
+ */
+func (s *DepositService) ApproveBySystem(tx *gorm.DB, depositID uint, source, note string) (*model.DepositRequest, *model.FundLog, error) {
+	deposit, err := repository.NewDepositRepository(tx).LockByID(depositID)
+	if err != nil {
+		return nil, nil, errors.New("充值申请不存在")
+	}
+	if !deposit.IsPending() {
+		return nil, nil, fmt.Errorf("充值申请状态不允许自动审核（当前状态: %s）", deposit.Status)
+	}
+	
+	if err := s.approvalSvc.ApproveBySystem(tx, s.approvalTarget(deposit), source, note); err != nil {
+		return nil, nil, err
+	}
+	fundLog, err := s.credit(tx, deposit, 0, note)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	log.Printf("[Deposit] 充值自动审核通过: ID=%d, 用户=%d, 金额=%.2f, 依据=%s", 
+		deposit.ID, deposit.UserID, deposit.Amount, source)
+	return deposit, fundLog, nil
 }
 
 /**
@@ -187,19 +308,36 @@ func (s *DepositService) ApproveDeposit(depositID, reviewerID uint, note, receip
  * @return error
  */
 func (s *DepositService) RejectDeposit(depositID, reviewerID uint, note string) error {
-	deposit, err := s.depositRepo.FindByID(depositID)
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+	
+	// 锁定申请并确认状态，防止与审核通过并发
+	deposit, err := repository.NewDepositRepository(tx).LockByID(depositID)
 	if err != nil {
+		tx.Rollback()
 		return errors.New("充值申请不存在")
 	}
-	
-	if !deposit.IsPending() {
+	if !deposit.IsAwaitingApproval() {
+		tx.Rollback()
 		return fmt.Errorf("充值申请状态不允许审核（当前状态: %s）", deposit.Status)
 	}
 	
+	if err := s.approvalSvc.Reject(tx, s.approvalTarget(deposit), reviewerID, note); err != nil {
+		tx.Rollback()
+		return err
+	}
 	deposit.Reject(reviewerID, note)
-	if err := s.depositRepo.Update(deposit); err != nil {
+	if err := tx.Save(deposit).Error; err != nil {
+		tx.Rollback()
 		return errors.New("更新申请状态失败")
 	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("事务提交失败")
+	}
 	
 	// 发送通知
 	notifyMsg := fmt.Sprintf("您的充值申请已被驳回\n驳回原因：%s", note)
@@ -241,3 +379,48 @@ func (s *DepositService) GetPendingDeposits(limit int) ([]*model.DepositRequest,
 func (s *DepositService) GetDepositsByStatus(status string, limit int) ([]*model.DepositRequest, error) {
 	return s.depositRepo.FindByStatus(status, limit)
 }
+
+/**
+ * GetDepositApprovals 查询充值申请的审批记录
+ * 
+ * @param depositID uint - 充值申请ID
+ * @return ([]*model.ApprovalStep, error)
+ */
+func (s *DepositService) GetDepositApprovals(depositID uint) ([]*model.ApprovalStep, error) {
+	return s.approvalSvc.ListSteps(model.ApprovalTargetDeposit, depositID)
+}
+
+/**
+ * credit 充值入账：增加用户可用定金、记录资金流水并将申请置为已通过（在调用方事务中执行）
+ */
+func (s *DepositService) credit(tx *gorm.DB, deposit *model.DepositRequest, reviewerID uint, note string) (*model.FundLog, error) {
+	fundLog, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:         deposit.UserID,
+		Type:           model.FundLogTypeDeposit,
+		AvailableDelta: deposit.Amount,
+		RelatedID:      deposit.ID,
+		RelatedType:    "deposit",
+		Note:           fmt.Sprintf("充值审核通过: %s", deposit.Method),
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	deposit.Approve(reviewerID, note)
+	if err := tx.Save(deposit).Error; err != nil {
+		return nil, errors.New("更新申请状态失败")
+	}
+	return fundLog, nil
+}
+
+/**
+ * approvalTarget 充值申请对应的审批对象（发起人为充值客户）
+ */
+func (s *DepositService) approvalTarget(deposit *model.DepositRequest) ApprovalTarget {
+	return ApprovalTarget{
+		Type:        model.ApprovalTargetDeposit,
+		ID:          deposit.ID,
+		RequesterID: deposit.UserID,
+		Chain:       &deposit.ApprovalChain,
+	}
+}
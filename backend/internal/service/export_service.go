@@ -0,0 +1,747 @@
+/**
+ * 数据导出服务
+ *
+ * 用途：
+ * - 管理员按筛选条件导出订单、资金流水、付定金申请、退定金申请、销售提成记录（CSV/XLSX）
+ * - 小数据量直接流式下载；大数据量创建异步导出任务，后台生成文件后再下载
+ *
+ * 说明：
+ * - 按ID分批读取并逐行写出，导出过程中不会把全部数据加载到内存
+ * - 异步任务文件先写入临时文件，再按流保存到文件存储，保留 exportFileTTL 后自动删除
+ * - 多实例部署时只有主实例执行异步任务；主实例切换或重启时，上一实例执行中的任务标记为失败
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/pkg/xlsx"
+	"suxin/internal/repository"
+)
+
+const (
+	exportBatchSize   = 500                // 每批读取条数
+	exportSyncMaxRows = 10000              // 直接下载的最大条数，超过需创建异步任务
+	exportFileTTL     = 7 * 24 * time.Hour // 异步导出文件保留时间
+	exportJobsPerRun  = 5                  // 每次检查最多执行的任务数
+)
+
+// ErrExportTooLarge 数据量超过直接下载上限
+var ErrExportTooLarge = errors.New("数据量较大，请创建异步导出任务")
+
+// exportDatasetNames 导出数据集名称（用于文件名和工作表名）
+var exportDatasetNames = map[string]string{
+	model.ExportDatasetOrders:      "订单",
+	model.ExportDatasetFundLogs:    "资金流水",
+	model.ExportDatasetDeposits:    "付定金记录",
+	model.ExportDatasetWithdraws:   "退定金记录",
+	model.ExportDatasetCommissions: "提成记录",
+}
+
+type exportColumn struct {
+	title string
+	width float64
+}
+
+// exportColumns 各数据集的导出列
+var exportColumns = map[string][]exportColumn{
+	model.ExportDatasetOrders: {
+		{"订单号", 24}, {"客户ID", 8}, {"客户姓名", 10}, {"手机号", 14}, {"归属销售", 10},
+		{"类型", 10}, {"锁定价格", 10}, {"克重(g)", 10}, {"定金", 12}, {"状态", 8},
+		{"结算价格", 10}, {"结算盈亏(含部分平仓)", 18}, {"部分平仓克重(g)", 14}, {"部分平仓盈亏", 12},
+		{"下单时间", 20}, {"结算时间", 20},
+	},
+	model.ExportDatasetFundLogs: {
+		{"流水ID", 8}, {"客户ID", 8}, {"客户姓名", 10}, {"手机号", 14}, {"归属销售", 10},
+		{"类型", 12}, {"变动金额", 12}, {"变动前可用", 12}, {"变动后可用", 12}, {"变动前已用", 12},
+		{"变动后已用", 12}, {"变动前冻结", 12}, {"变动后冻结", 12}, {"关联类型", 10}, {"关联ID", 8},
+		{"备注", 40}, {"时间", 20},
+	},
+	model.ExportDatasetDeposits: {
+		{"申请ID", 8}, {"客户ID", 8}, {"客户姓名", 10}, {"手机号", 14}, {"归属销售", 10},
+		{"金额", 12}, {"方式", 10}, {"状态", 8}, {"审批次数", 8}, {"客户备注", 30},
+		{"审核人ID", 8}, {"审核备注", 30}, {"提交时间", 20}, {"审核时间", 20},
+	},
+	model.ExportDatasetWithdraws: {
+		{"申请ID", 8}, {"客户ID", 8}, {"客户姓名", 10}, {"手机号", 14}, {"归属销售", 10},
+		{"提现金额", 12}, {"手续费", 10}, {"实际到账", 12}, {"状态", 8}, {"审批次数", 8},
+		{"客户备注", 30}, {"审核人ID", 8}, {"审核备注", 30}, {"提交时间", 20}, {"审核时间", 20},
+		{"打款时间", 20},
+	},
+	model.ExportDatasetCommissions: {
+		{"记录ID", 8}, {"销售ID", 8}, {"销售姓名", 10}, {"客户ID", 8}, {"客户姓名", 10},
+		{"订单ID", 8}, {"克重(g)", 10}, {"提成点数", 10}, {"提成积分", 12}, {"结算时间", 20},
+	},
+}
+
+var exportOrderTypeText = map[string]string{
+	model.OrderTypeLongBuy:   "锁价买料",
+	model.OrderTypeShortSell: "锁价卖料",
+}
+
+var exportOrderStatusText = map[string]string{
+	model.OrderStatusHolding: "持仓中",
+	model.OrderStatusSettled: "已结算",
+	model.OrderStatusClosed:  "已平仓",
+}
+
+var exportRequestStatusText = map[string]string{
+	model.WithdrawStatusPending:   "待审核",
+	model.WithdrawStatusReviewing: "待复核",
+	model.WithdrawStatusApproved:  "已通过",
+	model.WithdrawStatusRejected:  "已驳回",
+	model.WithdrawStatusPaid:      "已打款",
+	model.WithdrawStatusCancelled: "已取消",
+}
+
+var exportDepositMethodText = map[string]string{
+	model.DepositMethodBank:   "银行转账",
+	model.DepositMethodWechat: "微信支付",
+	model.DepositMethodAlipay: "支付宝",
+	model.DepositMethodOnline: "在线支付",
+}
+
+/**
+ * ExportService 数据导出服务
+ */
+type ExportService struct {
+	ctx  *appctx.AppContext
+	repo *repository.ExportRepository
+
+	recovered bool // 本实例成为主实例后是否已清理上一实例遗留的执行中任务
+}
+
+/**
+ * NewExportService 创建数据导出服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *ExportService
+ */
+func NewExportService(ctx *appctx.AppContext) *ExportService {
+	return &ExportService{
+		ctx:  ctx,
+		repo: repository.NewExportRepository(ctx.DB),
+	}
+}
+
+/**
+ * Validate 校验导出数据集和格式
+ *
+ * @param dataset string - 数据集
+ * @param format string - 格式（csv/xlsx）
+ * @return error
+ */
+func (s *ExportService) Validate(dataset, format string) error {
+	if _, ok := exportColumns[dataset]; !ok {
+		return fmt.Errorf("不支持的导出数据: %s", dataset)
+	}
+	if format != model.ExportFormatCSV && format != model.ExportFormatXLSX {
+		return fmt.Errorf("不支持的导出格式: %s", format)
+	}
+	return nil
+}
+
+/**
+ * Count 统计符合筛选条件的记录数
+ *
+ * @param dataset string - 数据集
+ * @param filter repository.ExportFilter - 筛选条件
+ * @return (int64, error)
+ */
+func (s *ExportService) Count(dataset string, filter repository.ExportFilter) (int64, error) {
+	return s.repo.Count(dataset, filter)
+}
+
+/**
+ * SyncMaxRows 直接下载的最大条数
+ *
+ * @return int64
+ */
+func (s *ExportService) SyncMaxRows() int64 {
+	return exportSyncMaxRows
+}
+
+/**
+ * CheckSyncExport 校验是否可以直接下载（超过 exportSyncMaxRows 返回 ErrExportTooLarge）
+ *
+ * @param dataset string - 数据集
+ * @param filter repository.ExportFilter - 筛选条件
+ * @return (int64, error) - 记录数
+ */
+func (s *ExportService) CheckSyncExport(dataset string, filter repository.ExportFilter) (int64, error) {
+	total, err := s.repo.Count(dataset, filter)
+	if err != nil {
+		return 0, err
+	}
+	if total > exportSyncMaxRows {
+		return total, ErrExportTooLarge
+	}
+	return total, nil
+}
+
+/**
+ * Write 按筛选条件流式写出导出文件
+ *
+ * @param out io.Writer - 输出目标
+ * @param dataset string - 数据集
+ * @param format string - 格式（csv/xlsx）
+ * @param filter repository.ExportFilter - 筛选条件
+ * @return (int64, error) - 导出行数（不含表头）
+ */
+func (s *ExportService) Write(out io.Writer, dataset, format string, filter repository.ExportFilter) (int64, error) {
+	if err := s.Validate(dataset, format); err != nil {
+		return 0, err
+	}
+
+	var w exportRowWriter
+	if format == model.ExportFormatXLSX {
+		xw, err := xlsx.NewWriter(out, exportDatasetNames[dataset])
+		if err != nil {
+			return 0, err
+		}
+		w = xw
+	} else {
+		w = newExportCSVWriter(out)
+	}
+
+	columns := exportColumns[dataset]
+	titles := make([]string, len(columns))
+	widths := make([]float64, len(columns))
+	for i, col := range columns {
+		titles[i], widths[i] = col.title, col.width
+	}
+	if err := w.WriteHeader(titles, widths); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	var afterID uint
+	for {
+		rows, lastID, err := s.nextBatch(dataset, filter, afterID)
+		if err != nil {
+			return count, err
+		}
+		for _, row := range rows {
+			if err := w.WriteRow(row); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(rows) < exportBatchSize {
+			break
+		}
+		afterID = lastID
+	}
+	return count, w.Close()
+}
+
+/**
+ * CreateJob 创建异步导出任务
+ *
+ * @param operatorID uint - 发起人
+ * @param dataset string - 数据集
+ * @param format string - 格式（csv/xlsx）
+ * @param filter repository.ExportFilter - 筛选条件
+ * @return (*model.ExportJob, error)
+ */
+func (s *ExportService) CreateJob(operatorID uint, dataset, format string, filter repository.ExportFilter) (*model.ExportJob, error) {
+	if err := s.Validate(dataset, format); err != nil {
+		return nil, err
+	}
+	if defaultFileStorage == nil {
+		return nil, errors.New("文件存储未初始化")
+	}
+	filterJSON, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.ExportJob{
+		Dataset:    dataset,
+		Format:     format,
+		Filter:     string(filterJSON),
+		Status:     model.ExportStatusPending,
+		FileName:   ExportFileName(dataset, format, time.Now()),
+		OperatorID: operatorID,
+	}
+	if err := s.repo.CreateJob(job); err != nil {
+		return nil, fmt.Errorf("创建导出任务失败: %v", err)
+	}
+	return job, nil
+}
+
+/**
+ * GetJobs 分页查询导出任务
+ *
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.ExportJob, int64, error)
+ */
+func (s *ExportService) GetJobs(limit, offset int) ([]*model.ExportJob, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.FindJobs(0, limit, offset)
+}
+
+/**
+ * OpenJobFile 打开已完成任务的导出文件（调用方负责关闭）
+ *
+ * @param id uint - 任务ID
+ * @return (*model.ExportJob, io.ReadCloser, error)
+ */
+func (s *ExportService) OpenJobFile(id uint) (*model.ExportJob, io.ReadCloser, error) {
+	job, err := s.repo.FindJobByID(id)
+	if err != nil {
+		return nil, nil, errors.New("导出任务不存在")
+	}
+	if job.Status != model.ExportStatusDone {
+		return nil, nil, errors.New("导出文件尚未生成或已过期")
+	}
+	if defaultFileStorage == nil {
+		return nil, nil, errors.New("文件存储未初始化")
+	}
+	body, err := defaultFileStorage.Get(job.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取导出文件失败: %v", err)
+	}
+	return job, body, nil
+}
+
+/**
+ * RunPendingJobs 执行排队中的导出任务并清理过期文件（由定时任务调用）
+ */
+func (s *ExportService) RunPendingJobs() {
+	// 多实例部署时只有主实例执行
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		s.recovered = false
+		return
+	}
+	if defaultFileStorage == nil {
+		return
+	}
+
+	if !s.recovered {
+		if n, err := s.repo.FailStaleJobs(time.Now(), "服务重启，导出中断，请重新创建任务"); err != nil {
+			log.Printf("[Export] ❌ 清理中断的导出任务失败: %v", err)
+			return
+		} else if n > 0 {
+			log.Printf("[Export] ⚠️ %d 个导出任务因服务重启中断，已标记为失败", n)
+		}
+		s.recovered = true
+	}
+
+	for i := 0; i < exportJobsPerRun; i++ {
+		job, err := s.repo.ClaimNextJob()
+		if err != nil {
+			log.Printf("[Export] ❌ 领取导出任务失败: %v", err)
+			return
+		}
+		if job == nil {
+			break
+		}
+		s.runJob(job)
+	}
+
+	s.cleanupExpired()
+}
+
+/**
+ * runJob 执行导出任务：写入临时文件，再保存到文件存储
+ */
+func (s *ExportService) runJob(job *model.ExportJob) {
+	startedAt := time.Now()
+	err := func() error {
+		var filter repository.ExportFilter
+		if job.Filter != "" {
+			if err := json.Unmarshal([]byte(job.Filter), &filter); err != nil {
+				return fmt.Errorf("筛选条件无效: %v", err)
+			}
+		}
+
+		tmp, err := os.CreateTemp("", "export-*."+job.Format)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}()
+
+		rows, err := s.Write(tmp, job.Dataset, job.Format, filter)
+		if err != nil {
+			return err
+		}
+		size, err := tmp.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("exports/%s/%d.%s", startedAt.Format("200601"), job.ID, job.Format)
+		if err := defaultFileStorage.PutReader(key, tmp, size, ExportContentType(job.Format)); err != nil {
+			return fmt.Errorf("保存导出文件失败: %v", err)
+		}
+
+		expiresAt := time.Now().Add(exportFileTTL)
+		job.StorageKey = key
+		job.RowCount = rows
+		job.FileSize = size
+		job.ExpiresAt = &expiresAt
+		return nil
+	}()
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		job.Status = model.ExportStatusFailed
+		msg := []rune(err.Error())
+		if len(msg) > 500 {
+			msg = msg[:500]
+		}
+		job.Error = string(msg)
+		log.Printf("[Export] ❌ 导出任务 %d（%s）失败: %v", job.ID, job.Dataset, err)
+	} else {
+		job.Status = model.ExportStatusDone
+		log.Printf("[Export] ✅ 导出任务 %d（%s）完成：%d 行，%d 字节，耗时 %v",
+			job.ID, job.Dataset, job.RowCount, job.FileSize, finishedAt.Sub(startedAt).Round(time.Millisecond))
+	}
+	if err := s.repo.UpdateJob(job); err != nil {
+		log.Printf("[Export] ❌ 更新导出任务 %d 失败: %v", job.ID, err)
+	}
+}
+
+/**
+ * cleanupExpired 删除过期的导出文件
+ */
+func (s *ExportService) cleanupExpired() {
+	jobs, err := s.repo.FindExpiredJobs(time.Now(), 50)
+	if err != nil {
+		log.Printf("[Export] ❌ 查询过期导出任务失败: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := defaultFileStorage.Delete(job.StorageKey); err != nil {
+			log.Printf("[Export] ❌ 删除过期导出文件 %s 失败: %v", job.StorageKey, err)
+			continue
+		}
+		job.Status = model.ExportStatusExpired
+		job.StorageKey = ""
+		if err := s.repo.UpdateJob(job); err != nil {
+			log.Printf("[Export] ❌ 更新导出任务 %d 失败: %v", job.ID, err)
+		}
+	}
+}
+
+/**
+ * nextBatch 读取下一批数据并转换为导出行
+ *
+ * @return ([][]interface{}, uint, error) - 导出行、本批最后一条ID
+ */
+func (s *ExportService) nextBatch(dataset string, filter repository.ExportFilter, afterID uint) ([][]interface{}, uint, error) {
+	var rows [][]interface{}
+	var lastID uint
+	users := exportUsers{}
+
+	switch dataset {
+	case model.ExportDatasetOrders:
+		orders, err := s.repo.FindOrdersBatch(filter, afterID, exportBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, o := range orders {
+			users.add(o.UserID)
+		}
+		if err := users.load(s.repo); err != nil {
+			return nil, 0, err
+		}
+		for _, o := range orders {
+			name, phone, sales := users.info(o.UserID)
+			row := []interface{}{
+				o.OrderID, o.UserID, name, phone, sales,
+				exportText(exportOrderTypeText, o.Type), o.LockedPrice, o.WeightG, xlsx.Money(o.Deposit),
+				exportText(exportOrderStatusText, o.Status), nil, nil, o.ClosedWeightG, xlsx.Money(o.RealizedPnL),
+				o.CreatedAt, o.SettledAt,
+			}
+			if o.Status != model.OrderStatusHolding {
+				row[10], row[11] = o.SettledPrice, xlsx.Money(o.SettledPnL)
+			}
+			rows = append(rows, row)
+			lastID = o.ID
+		}
+
+	case model.ExportDatasetFundLogs:
+		logs, err := s.repo.FindFundLogsBatch(filter, afterID, exportBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, l := range logs {
+			users.add(l.UserID)
+		}
+		if err := users.load(s.repo); err != nil {
+			return nil, 0, err
+		}
+		for _, l := range logs {
+			name, phone, sales := users.info(l.UserID)
+			rows = append(rows, []interface{}{
+				l.ID, l.UserID, name, phone, sales,
+				exportText(statementFundLogTypeText, l.Type), xlsx.Money(l.Amount),
+				xlsx.Money(l.AvailableBefore), xlsx.Money(l.AvailableAfter),
+				xlsx.Money(l.UsedBefore), xlsx.Money(l.UsedAfter),
+				xlsx.Money(l.FrozenBefore), xlsx.Money(l.FrozenAfter),
+				l.RelatedType, l.RelatedID, l.Note, l.CreatedAt,
+			})
+			lastID = l.ID
+		}
+
+	case model.ExportDatasetDeposits:
+		deposits, err := s.repo.FindDepositsBatch(filter, afterID, exportBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, d := range deposits {
+			users.add(d.UserID)
+		}
+		if err := users.load(s.repo); err != nil {
+			return nil, 0, err
+		}
+		for _, d := range deposits {
+			name, phone, sales := users.info(d.UserID)
+			rows = append(rows, []interface{}{
+				d.ID, d.UserID, name, phone, sales,
+				xlsx.Money(d.Amount), exportText(exportDepositMethodText, d.Method),
+				exportText(exportRequestStatusText, d.Status), d.ApprovalCount, d.UserNote,
+				exportID(d.ReviewerID), d.ReviewNote, d.CreatedAt, d.ReviewedAt,
+			})
+			lastID = d.ID
+		}
+
+	case model.ExportDatasetWithdraws:
+		withdraws, err := s.repo.FindWithdrawsBatch(filter, afterID, exportBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, w := range withdraws {
+			users.add(w.UserID)
+		}
+		if err := users.load(s.repo); err != nil {
+			return nil, 0, err
+		}
+		for _, w := range withdraws {
+			name, phone, sales := users.info(w.UserID)
+			rows = append(rows, []interface{}{
+				w.ID, w.UserID, name, phone, sales,
+				xlsx.Money(w.Amount), xlsx.Money(w.Fee), xlsx.Money(w.ActualAmount),
+				exportText(exportRequestStatusText, w.Status), w.ApprovalCount, w.UserNote,
+				exportID(w.ReviewerID), w.ReviewNote, w.CreatedAt, w.ReviewedAt, w.PaidAt,
+			})
+			lastID = w.ID
+		}
+
+	case model.ExportDatasetCommissions:
+		records, err := s.repo.FindCommissionsBatch(filter, afterID, exportBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, r := range records {
+			users.add(r.SalespersonID)
+			users.add(r.CustomerID)
+		}
+		if err := users.load(s.repo); err != nil {
+			return nil, 0, err
+		}
+		for _, r := range records {
+			salesName, _, _ := users.info(r.SalespersonID)
+			customerName, _, _ := users.info(r.CustomerID)
+			rows = append(rows, []interface{}{
+				r.ID, r.SalespersonID, salesName, r.CustomerID, customerName,
+				r.OrderID, r.WeightG, r.CommissionRate, xlsx.Money(r.Points), r.SettledAt,
+			})
+			lastID = r.ID
+		}
+
+	default:
+		return nil, 0, fmt.Errorf("不支持的导出数据: %s", dataset)
+	}
+	return rows, lastID, nil
+}
+
+/**
+ * ExportFileName 导出文件名（数据集名称_时间.格式）
+ *
+ * @param dataset string - 数据集
+ * @param format string - 格式
+ * @param t time.Time - 导出时间
+ * @return string
+ */
+func ExportFileName(dataset, format string, t time.Time) string {
+	name := exportDatasetNames[dataset]
+	if name == "" {
+		name = dataset
+	}
+	return fmt.Sprintf("%s_%s.%s", name, t.Format("20060102150405"), format)
+}
+
+/**
+ * ExportContentType 导出文件内容类型
+ *
+ * @param format string - 格式
+ * @return string
+ */
+func ExportContentType(format string) string {
+	if format == model.ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+/**
+ * exportUsers 导出时按批查询的客户/销售信息
+ */
+type exportUsers map[uint]*model.User
+
+func (u exportUsers) add(id uint) {
+	if id > 0 {
+		u[id] = nil
+	}
+}
+
+// load 查询已添加的用户及其归属销售
+func (u exportUsers) load(repo *repository.ExportRepository) error {
+	for round := 0; round < 2; round++ {
+		var ids []uint
+		for id, user := range u {
+			if user == nil {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		users, err := repo.FindUsersByIDs(ids)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			u[user.ID] = user
+			if user.SalesID > 0 {
+				if _, ok := u[user.SalesID]; !ok {
+					u.add(user.SalesID)
+				}
+			}
+		}
+		// 已删除或不存在的用户不再重复查询
+		for _, id := range ids {
+			if u[id] == nil {
+				u[id] = &model.User{ID: id}
+			}
+		}
+	}
+	return nil
+}
+
+// info 用户姓名、手机号、归属销售姓名
+func (u exportUsers) info(id uint) (string, string, string) {
+	user := u[id]
+	if user == nil {
+		return "", "", ""
+	}
+	sales := ""
+	if s := u[user.SalesID]; s != nil && user.SalesID > 0 {
+		sales = s.RealName
+		if sales == "" {
+			sales = s.Phone
+		}
+	}
+	return user.RealName, user.Phone, sales
+}
+
+func exportText(texts map[string]string, value string) string {
+	if text, ok := texts[value]; ok {
+		return text
+	}
+	return value
+}
+
+// exportID ID为0时导出为空
+func exportID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+/**
+ * exportRowWriter 导出文件写入器（xlsx.Writer 与 CSV 写入器）
+ */
+type exportRowWriter interface {
+	WriteHeader(titles []string, widths []float64) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+/**
+ * exportCSVWriter CSV 写入器（带 UTF-8 BOM，Excel 可直接打开）
+ */
+type exportCSVWriter struct {
+	out io.Writer
+	w   *csv.Writer
+}
+
+func newExportCSVWriter(out io.Writer) *exportCSVWriter {
+	return &exportCSVWriter{out: out, w: csv.NewWriter(out)}
+}
+
+func (c *exportCSVWriter) WriteHeader(titles []string, widths []float64) error {
+	if _, err := io.WriteString(c.out, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+	return c.w.Write(titles)
+}
+
+func (c *exportCSVWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case xlsx.Money:
+			record[i] = strconv.FormatFloat(float64(v), 'f', 2, 64)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			if !v.IsZero() {
+				record[i] = v.Format("2006-01-02 15:04:05")
+			}
+		case *time.Time:
+			if v != nil && !v.IsZero() {
+				record[i] = v.Format("2006-01-02 15:04:05")
+			}
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *exportCSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
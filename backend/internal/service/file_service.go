@@ -0,0 +1,556 @@
+/**
+ * 文件服务
+ *
+ * 用途：
+ * - 上传凭证、证件照片：按内容哈希去重，图片压缩并生成缩略图，保存到文件存储
+ * - 将业务提交中的 Base64（Data URL）内容转存为文件引用，数据库不再保存文件内容
+ * - 为业务数据中的文件引用生成带签名的限时下载链接，并校验下载请求
+ *
+ * 下载链接：
+ * - /api/v1/files/{hash}?e={过期时间戳}&s={签名}，签名为 HMAC-SHA256(hash + "." + e)
+ * - 追加 thumb=1 返回缩略图（与原图共用签名）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/pkg/imaging"
+	"suxin/internal/pkg/storage"
+	"suxin/internal/repository"
+)
+
+const (
+	defaultFileMaxUploadMB   = 10   // 默认单个文件大小上限（MB）
+	defaultFileURLExpire     = 3600 // 默认下载链接有效期（秒）
+	defaultFileImageMaxSide  = 1920 // 默认图片长边上限（像素）
+	defaultFileThumbMaxSide  = 320  // 默认缩略图长边（像素）
+	fileImageQuality         = 82   // 图片压缩 JPEG 质量
+	fileDownloadPath         = "/api/v1/files/"
+	fileInlineBase64MinBytes = 256                           // 不带 data: 前缀的裸 Base64 最短长度（更短的视为普通文本）
+	fileSignKeyLabel         = "suxin/file-download-sign/v1" // 由 JWT 密钥派生下载签名子密钥的用途标签
+)
+
+// fileAllowedTypes 允许上传的文件类型及保存扩展名
+var fileAllowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+var (
+	fileDataURLPattern   = regexp.MustCompile(`data:[^,;]*(;[^,]*)?,[^,]+`)
+	fileSignedURLPattern = regexp.MustCompile(`/api/v1/files/([0-9a-f]{64})`)
+)
+
+var defaultFileStorage storage.Storage
+
+/**
+ * SetDefaultFileStorage 设置全局文件存储（由main注入）
+ */
+func SetDefaultFileStorage(st storage.Storage) {
+	defaultFileStorage = st
+}
+
+/**
+ * FileService 文件服务
+ */
+type FileService struct {
+	ctx  *appctx.AppContext
+	repo *repository.FileRepository
+}
+
+/**
+ * NewFileService 创建文件服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *FileService
+ */
+func NewFileService(ctx *appctx.AppContext) *FileService {
+	return &FileService{
+		ctx:  ctx,
+		repo: repository.NewFileRepository(ctx.DB),
+	}
+}
+
+/**
+ * MaxUploadBytes 单个文件大小上限（字节）
+ *
+ * @return int64
+ */
+func (s *FileService) MaxUploadBytes() int64 {
+	mb := s.ctx.Config.Storage.MaxUploadMB
+	if mb <= 0 {
+		mb = defaultFileMaxUploadMB
+	}
+	return int64(mb) << 20
+}
+
+/**
+ * Upload 上传文件
+ *
+ * 业务流程：
+ * 1. 校验大小和类型（按内容识别，不信任文件名）
+ * 2. 按原始内容哈希去重，已存在直接返回
+ * 3. 图片压缩并生成缩略图，其他类型按原文件保存
+ * 4. 写入存储并记录文件
+ *
+ * @param uploaderID uint - 上传人ID（0为系统）
+ * @param category string - 用途
+ * @param fileName string - 原始文件名
+ * @param data []byte - 文件内容
+ * @return (*model.StoredFile, error)
+ */
+func (s *FileService) Upload(uploaderID uint, category, fileName string, data []byte) (*model.StoredFile, error) {
+	if defaultFileStorage == nil {
+		return nil, errors.New("文件存储未初始化")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("文件内容为空")
+	}
+	if int64(len(data)) > s.MaxUploadBytes() {
+		return nil, fmt.Errorf("文件不能超过%dMB", s.MaxUploadBytes()>>20)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := fileAllowedTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的文件类型: %s（仅支持图片和PDF）", contentType)
+	}
+	if category == "" {
+		category = model.FileCategoryOther
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if existing, err := s.repo.FindByHash(hash); err != nil {
+		return nil, fmt.Errorf("查询文件失败: %v", err)
+	} else if existing != nil {
+		return existing, nil
+	}
+
+	file := &model.StoredFile{
+		Hash:         hash,
+		Category:     category,
+		FileName:     truncateFileName(path.Base(strings.ReplaceAll(fileName, "\\", "/"))),
+		ContentType:  contentType,
+		OriginalSize: int64(len(data)),
+		Driver:       defaultFileStorage.Driver(),
+		UploaderID:   uploaderID,
+	}
+	content := data
+
+	if compressed, err := imaging.Compress(data, positiveOr(s.ctx.Config.Storage.ImageMaxSide, defaultFileImageMaxSide), fileImageQuality); err == nil {
+		content = compressed.Data
+		file.ContentType = compressed.ContentType
+		file.Width, file.Height = compressed.Width, compressed.Height
+		ext = fileAllowedTypes[compressed.ContentType]
+
+		if thumb, err := imaging.Thumbnail(data, positiveOr(s.ctx.Config.Storage.ThumbMaxSide, defaultFileThumbMaxSide)); err == nil {
+			file.ThumbKey = "thumbs/" + hash[:2] + "/" + hash + ".jpg"
+			if err := defaultFileStorage.Put(file.ThumbKey, thumb.Data, thumb.ContentType); err != nil {
+				return nil, fmt.Errorf("保存缩略图失败: %v", err)
+			}
+		}
+	}
+
+	file.StorageKey = category + "/" + hash[:2] + "/" + hash + ext
+	file.Size = int64(len(content))
+	if err := defaultFileStorage.Put(file.StorageKey, content, file.ContentType); err != nil {
+		return nil, fmt.Errorf("保存文件失败: %v", err)
+	}
+
+	if err := s.repo.Create(file); err != nil {
+		// 并发上传同一文件时唯一索引冲突，返回已保存的记录
+		if existing, findErr := s.repo.FindByHash(hash); findErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("保存文件记录失败: %v", err)
+	}
+
+	log.Printf("[File] 保存文件 %s（%s，%d → %d 字节，上传人=%d）", hash[:12], category, file.OriginalSize, file.Size, uploaderID)
+	return file, nil
+}
+
+/**
+ * Ingest 将业务字段中的文件内容转存为文件引用
+ *
+ * 说明：
+ * - 字段可包含多个文件（逗号分隔），逐个处理：Data URL 和裸 Base64 转存为 file:<hash>
+ * - 本服务生成的下载链接还原为文件引用（客户端回传已签名链接时避免保存会过期的地址）
+ * - 普通 URL、已有文件引用原样保留
+ * - 未初始化文件存储时原样返回（兼容未启用存储的工具程序）
+ *
+ * @param value string - 字段原值
+ * @param category string - 用途
+ * @param uploaderID uint - 上传人ID（0为系统）
+ * @return (string, error) - 转存后的字段值
+ */
+func (s *FileService) Ingest(value, category string, uploaderID uint) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || defaultFileStorage == nil {
+		return value, nil
+	}
+
+	var items []string
+	if matches := fileDataURLPattern.FindAllString(value, -1); len(matches) > 0 {
+		items = matches
+		// Data URL 之外的普通链接/引用
+		for _, rest := range strings.Split(fileDataURLPattern.ReplaceAllString(value, ""), ",") {
+			if strings.TrimSpace(rest) != "" {
+				items = append(items, rest)
+			}
+		}
+	} else {
+		items = strings.Split(value, ",")
+	}
+
+	refs := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if m := fileSignedURLPattern.FindStringSubmatch(item); m != nil {
+			refs = append(refs, model.FileRef(m[1]))
+			continue
+		}
+
+		data, ok, err := decodeInlineFile(item)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			refs = append(refs, item)
+			continue
+		}
+		file, err := s.Upload(uploaderID, category, "", data)
+		if err != nil {
+			return "", err
+		}
+		refs = append(refs, model.FileRef(file.Hash))
+	}
+	return strings.Join(refs, ","), nil
+}
+
+/**
+ * SignedURL 生成文件下载链接（相对路径，追加 &thumb=1 获取缩略图）
+ *
+ * @param hash string - 内容哈希
+ * @return string
+ */
+func (s *FileService) SignedURL(hash string) string {
+	expire := s.ctx.Config.Storage.URLExpireSeconds
+	if expire <= 0 {
+		expire = defaultFileURLExpire
+	}
+	// 过期时间按有效期对齐，同一时段内链接不变，便于浏览器缓存
+	now := time.Now().Unix()
+	exp := (now/int64(expire) + 2) * int64(expire)
+	return fmt.Sprintf("%s%s?e=%d&s=%s", fileDownloadPath, hash, exp, s.sign(hash, exp))
+}
+
+/**
+ * ResolveRefs 将业务字段中的文件引用替换为下载链接（其他内容原样保留）
+ *
+ * @param value string - 字段值（可包含多个，逗号分隔）
+ * @return string
+ */
+func (s *FileService) ResolveRefs(value string) string {
+	if !strings.Contains(value, model.FileRefPrefix) {
+		return value
+	}
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		if hash, ok := model.ParseFileRef(part); ok {
+			parts[i] = s.SignedURL(hash)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+/**
+ * ResolveDeposits 替换充值记录中的凭证引用为下载链接
+ */
+func (s *FileService) ResolveDeposits(deposits []*model.DepositRequest) {
+	for _, d := range deposits {
+		d.VoucherURL = s.ResolveRefs(d.VoucherURL)
+		d.ReceiptVoucherURL = s.ResolveRefs(d.ReceiptVoucherURL)
+	}
+}
+
+/**
+ * ResolveWithdraws 替换提现记录中的打款凭证引用为下载链接
+ */
+func (s *FileService) ResolveWithdraws(withdraws []*model.WithdrawRequest) {
+	for _, w := range withdraws {
+		w.VoucherURL = s.ResolveRefs(w.VoucherURL)
+	}
+}
+
+/**
+ * FileDownload 文件下载结果（RedirectURL 非空时重定向到对象存储预签名链接）
+ */
+type FileDownload struct {
+	File        *model.StoredFile
+	ContentType string
+	Body        io.ReadCloser
+	RedirectURL string
+	ExpireAt    time.Time
+}
+
+/**
+ * Open 校验下载签名并打开文件
+ *
+ * @param hash string - 内容哈希
+ * @param expires string - 过期时间戳
+ * @param signature string - 签名
+ * @param thumb bool - 是否下载缩略图（无缩略图时返回原文件）
+ * @return (*FileDownload, error)
+ */
+func (s *FileService) Open(hash, expires, signature string, thumb bool) (*FileDownload, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.sign(hash, exp))) {
+		return nil, errors.New("下载链接无效")
+	}
+	expireAt := time.Unix(exp, 0)
+	if time.Now().After(expireAt) {
+		return nil, errors.New("下载链接已过期")
+	}
+	if defaultFileStorage == nil {
+		return nil, errors.New("文件存储未初始化")
+	}
+
+	file, err := s.repo.FindByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询文件失败: %v", err)
+	}
+	if file == nil {
+		return nil, storage.ErrNotFound
+	}
+
+	key, contentType := file.StorageKey, file.ContentType
+	if thumb && file.ThumbKey != "" {
+		key, contentType = file.ThumbKey, "image/jpeg"
+	}
+	download := &FileDownload{File: file, ContentType: contentType, ExpireAt: expireAt}
+
+	if presigner, ok := defaultFileStorage.(storage.Presigner); ok {
+		download.RedirectURL, err = presigner.PresignGet(key, time.Until(expireAt))
+		return download, err
+	}
+	download.Body, err = defaultFileStorage.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return download, nil
+}
+
+/**
+ * sign 下载链接签名
+ *
+ * 未配置 storage.sign_secret 时（仅非生产环境允许）用 JWT 密钥按用途标签派生子密钥，不直接复用 JWT 密钥
+ */
+func (s *FileService) sign(hash string, exp int64) string {
+	secret := []byte(s.ctx.Config.Storage.SignSecret)
+	if len(secret) == 0 {
+		derive := hmac.New(sha256.New, []byte(s.ctx.Config.Auth.JWTSecret))
+		derive.Write([]byte(fileSignKeyLabel))
+		secret = derive.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(hash + "." + strconv.FormatInt(exp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+/**
+ * positiveOr 正整数配置（未配置时使用默认值）
+ */
+func positiveOr(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+/**
+ * decodeInlineFile 解码 Data URL 或裸 Base64 内容
+ *
+ * @return ([]byte, bool, error) - 内容，是否为内联文件，解码错误
+ */
+func decodeInlineFile(item string) ([]byte, bool, error) {
+	payload := item
+	if strings.HasPrefix(item, "data:") {
+		idx := strings.Index(item, ",")
+		if idx < 0 || !strings.Contains(item[:idx], ";base64") {
+			return nil, false, errors.New("仅支持Base64编码的Data URL")
+		}
+		payload = item[idx+1:]
+	} else if len(item) < fileInlineBase64MinBytes || (strings.Contains(item, "/") && strings.Contains(item, ":")) {
+		// 普通链接或文本
+		return nil, false, nil
+	}
+
+	payload = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, payload)
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+	}
+	if err != nil {
+		if strings.HasPrefix(item, "data:") {
+			return nil, false, errors.New("文件内容Base64解码失败")
+		}
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+/**
+ * truncateFileName 截断过长的文件名（按字符）
+ */
+func truncateFileName(name string) string {
+	if name == "." || name == "/" {
+		return ""
+	}
+	runes := []rune(name)
+	if len(runes) > 100 {
+		return string(runes[len(runes)-100:])
+	}
+	return name
+}
+
+/**
+ * FileMigrationResult Base64 内容迁移结果
+ */
+type FileMigrationResult struct {
+	Scanned     int   // 扫描记录数
+	Migrated    int   // 已转存记录数（dry-run 时为待转存数）
+	Failed      int   // 转存失败记录数
+	BytesBefore int64 // 转存前字段总长度
+	BytesAfter  int64 // 转存后字段总长度
+}
+
+// fileMigrationTargets 保存文件内容的业务字段
+var fileMigrationTargets = []struct {
+	Table    string
+	Column   string
+	Category string
+}{
+	{"deposit_requests", "voucher_url", model.FileCategoryDepositVoucher},
+	{"deposit_requests", "receipt_voucher_url", model.FileCategoryDepositReceipt},
+	{"withdraw_requests", "voucher_url", model.FileCategoryWithdrawVoucher},
+	{"user_verifications", "id_front_url", model.FileCategoryIDCard},
+	{"user_verifications", "id_back_url", model.FileCategoryIDCard},
+}
+
+/**
+ * MigrateInlineFiles 将业务表中的 Base64 文件内容转存到文件存储，字段改为文件引用
+ *
+ * 说明：
+ * - 按主键分批扫描（含软删除记录），逐条转存，单条失败记录日志后继续
+ * - 直接更新字段，不修改记录的更新时间
+ * - 可重复执行：已是文件引用或普通链接的字段不会变化
+ *
+ * @param batchSize int - 每批记录数
+ * @param dryRun bool - 只统计不转存
+ * @return (*FileMigrationResult, error)
+ */
+func (s *FileService) MigrateInlineFiles(batchSize int, dryRun bool) (*FileMigrationResult, error) {
+	if defaultFileStorage == nil && !dryRun {
+		return nil, errors.New("文件存储未初始化")
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	result := &FileMigrationResult{}
+	for _, target := range fileMigrationTargets {
+		var lastID uint
+		for {
+			var rows []struct {
+				ID    uint
+				Value string
+			}
+			err := s.ctx.DB.Table(target.Table).
+				Select("id, "+target.Column+" AS value").
+				Where("id > ? AND "+target.Column+" <> '' AND "+target.Column+" NOT LIKE ?", lastID, model.FileRefPrefix+"%").
+				Order("id").Limit(batchSize).
+				Scan(&rows).Error
+			if err != nil {
+				return result, fmt.Errorf("查询 %s.%s 失败: %v", target.Table, target.Column, err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+
+			for _, row := range rows {
+				lastID = row.ID
+				result.Scanned++
+				if !hasInlineFile(row.Value) {
+					continue
+				}
+				if dryRun {
+					result.Migrated++
+					result.BytesBefore += int64(len(row.Value))
+					continue
+				}
+
+				value, err := s.Ingest(row.Value, target.Category, 0)
+				if err != nil {
+					result.Failed++
+					log.Printf("[File] 迁移 %s.%s id=%d 失败: %v", target.Table, target.Column, row.ID, err)
+					continue
+				}
+				if err := s.ctx.DB.Table(target.Table).Where("id = ?", row.ID).UpdateColumn(target.Column, value).Error; err != nil {
+					result.Failed++
+					log.Printf("[File] 更新 %s.%s id=%d 失败: %v", target.Table, target.Column, row.ID, err)
+					continue
+				}
+				result.Migrated++
+				result.BytesBefore += int64(len(row.Value))
+				result.BytesAfter += int64(len(value))
+			}
+		}
+	}
+	return result, nil
+}
+
+/**
+ * hasInlineFile 字段中是否包含 Base64 文件内容
+ */
+func hasInlineFile(value string) bool {
+	if fileDataURLPattern.MatchString(value) {
+		return true
+	}
+	for _, item := range strings.Split(value, ",") {
+		if _, ok, _ := decodeInlineFile(strings.TrimSpace(item)); ok {
+			return true
+		}
+	}
+	return false
+}
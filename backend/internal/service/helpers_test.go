@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/pkg/config"
+	"suxin/internal/pkg/database"
+)
+
+// newTestContext 创建使用临时SQLite库的应用上下文（已自动迁移）
+func newTestContext(t *testing.T) *appctx.AppContext {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := gorm.Open(sqlite.Open(path+"?_journal_mode=MEMORY&_synchronous=OFF"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return appctx.New(db, &config.Config{})
+}
+
+// createTestUser 创建测试用户（手机号按ID区分）
+func createTestUser(t *testing.T, ctx *appctx.AppContext, user *model.User) *model.User {
+	t.Helper()
+	if user.Phone == "" {
+		var count int64
+		ctx.DB.Model(&model.User{}).Count(&count)
+		user.Phone = fmt.Sprintf("1380000%04d", count+1)
+	}
+	if user.Password == "" {
+		user.Password = "x"
+	}
+	if user.Role == "" {
+		user.Role = "customer"
+	}
+	if err := ctx.DB.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// setTestConfig 写入系统配置
+func setTestConfig(t *testing.T, ctx *appctx.AppContext, key, value string) {
+	t.Helper()
+	config := &model.SystemConfig{Category: model.ConfigCategorySystem, Key: key, Value: value}
+	if err := ctx.DB.Where("`key` = ?", key).Assign(model.SystemConfig{Value: value}).FirstOrCreate(config).Error; err != nil {
+		t.Fatalf("set config %s: %v", key, err)
+	}
+}
@@ -0,0 +1,282 @@
+/**
+ * 定时任务选主服务
+ *
+ * 用途：
+ * - 多实例部署时通过数据库租约选出唯一的主实例
+ * - 主实例定期心跳续约，失效后由其他实例自动接管
+ * - 只有主实例执行风控强平等定时任务，HTTP接口和WebSocket在所有实例上正常运行
+ *
+ * 说明：
+ * - 租约时间使用各实例本地时钟，部署时需保证实例间时钟同步（NTP）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+// 默认租约参数
+const (
+	defaultLeaseSeconds     = 30
+	defaultHeartbeatSeconds = 10
+)
+
+/**
+ * LeaderElectionService 定时任务选主服务
+ */
+type LeaderElectionService struct {
+	ctx        *appctx.AppContext
+	leaseRepo  *repository.LeaseRepository
+	leaseName  string
+	instanceID string
+	ttl        time.Duration
+	heartbeat  time.Duration
+
+	mu          sync.RWMutex
+	isLeader    bool
+	leaderUntil time.Time
+	leaderSince time.Time
+	lastError   string
+	stopChan    chan bool
+}
+
+var defaultLeaderElection *LeaderElectionService
+
+/**
+ * SetDefaultLeaderElection 设置全局选主服务（由main注入）
+ */
+func SetDefaultLeaderElection(e *LeaderElectionService) {
+	defaultLeaderElection = e
+}
+
+/**
+ * DefaultLeaderElection 获取全局选主服务
+ */
+func DefaultLeaderElection() *LeaderElectionService {
+	return defaultLeaderElection
+}
+
+/**
+ * NewLeaderElectionService 创建选主服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *LeaderElectionService
+ */
+func NewLeaderElectionService(ctx *appctx.AppContext) *LeaderElectionService {
+	cluster := ctx.Config.Cluster
+
+	instanceID := cluster.InstanceID
+	if instanceID == "" {
+		hostname, _ := os.Hostname()
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	leaseSeconds := cluster.LeaseSeconds
+	if leaseSeconds <= 0 {
+		leaseSeconds = defaultLeaseSeconds
+	}
+	heartbeatSeconds := cluster.HeartbeatSeconds
+	if heartbeatSeconds <= 0 || heartbeatSeconds >= leaseSeconds {
+		heartbeatSeconds = leaseSeconds / 3
+		if heartbeatSeconds <= 0 {
+			heartbeatSeconds = 1
+		}
+	}
+
+	return &LeaderElectionService{
+		ctx:        ctx,
+		leaseRepo:  repository.NewLeaseRepository(ctx.DB),
+		leaseName:  model.LeaseNameScheduler,
+		instanceID: instanceID,
+		ttl:        time.Duration(leaseSeconds) * time.Second,
+		heartbeat:  time.Duration(heartbeatSeconds) * time.Second,
+		stopChan:   make(chan bool),
+	}
+}
+
+/**
+ * Start 启动选主心跳
+ *
+ * 立即尝试一次抢占，之后按心跳间隔续约或抢占
+ *
+ * @return void
+ */
+func (s *LeaderElectionService) Start() {
+	if err := s.leaseRepo.EnsureLease(s.leaseName); err != nil {
+		log.Printf("[Leader] 初始化租约失败: %v", err)
+	}
+
+	s.tick()
+
+	go func() {
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.tick()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+
+	log.Printf("[Leader] ✅ 选主服务已启动，实例: %s，租约: %v，心跳: %v", s.instanceID, s.ttl, s.heartbeat)
+}
+
+/**
+ * tick 执行一次续约/抢占
+ */
+func (s *LeaderElectionService) tick() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Leader] ❌ 选主心跳发生异常: %v", r)
+		}
+	}()
+
+	now := time.Now()
+
+	s.mu.RLock()
+	wasLeader := s.isLeader
+	s.mu.RUnlock()
+
+	var ok bool
+	var err error
+	if wasLeader {
+		ok, err = s.leaseRepo.Renew(s.leaseName, s.instanceID, now, s.ttl)
+	}
+	if !ok && err == nil {
+		ok, err = s.leaseRepo.TryAcquire(s.leaseName, s.instanceID, now, s.ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.lastError = err.Error()
+		log.Printf("[Leader] 租约续约/抢占失败: %v", err)
+		// 数据库异常时保留本地租约直到过期，过期后自动失去主身份
+		if s.isLeader && !now.Before(s.leaderUntil) {
+			s.isLeader = false
+			log.Printf("[Leader] ⚠️ 本地租约已过期，实例 %s 放弃主身份", s.instanceID)
+		}
+		return
+	}
+	s.lastError = ""
+
+	if ok {
+		s.leaderUntil = now.Add(s.ttl)
+		if !s.isLeader {
+			s.isLeader = true
+			s.leaderSince = now
+			log.Printf("[Leader] 👑 实例 %s 成为主实例，开始执行定时任务", s.instanceID)
+		}
+		return
+	}
+
+	if s.isLeader {
+		s.isLeader = false
+		log.Printf("[Leader] ⚠️ 实例 %s 失去主身份，停止执行定时任务", s.instanceID)
+	}
+}
+
+/**
+ * IsLeader 当前实例是否为主实例
+ *
+ * 同时校验本地租约期限，防止心跳卡住时继续以主身份运行
+ *
+ * @return bool
+ */
+func (s *LeaderElectionService) IsLeader() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isLeader && time.Now().Before(s.leaderUntil)
+}
+
+/**
+ * InstanceID 获取当前实例ID
+ */
+func (s *LeaderElectionService) InstanceID() string {
+	return s.instanceID
+}
+
+/**
+ * Stop 停止心跳并释放租约
+ *
+ * @return void
+ */
+func (s *LeaderElectionService) Stop() {
+	s.stopChan <- true
+	close(s.stopChan)
+
+	s.mu.Lock()
+	wasLeader := s.isLeader
+	s.isLeader = false
+	s.mu.Unlock()
+
+	if wasLeader {
+		if err := s.leaseRepo.Release(s.leaseName, s.instanceID, time.Now()); err != nil {
+			log.Printf("[Leader] 释放租约失败: %v", err)
+		} else {
+			log.Printf("[Leader] ✅ 实例 %s 已释放主租约", s.instanceID)
+		}
+	}
+}
+
+/**
+ * GetStatus 获取选主状态
+ *
+ * 租约信息从数据库读取，任意实例都能看到当前主实例
+ *
+ * @return map[string]interface{}
+ */
+func (s *LeaderElectionService) GetStatus() map[string]interface{} {
+	s.mu.RLock()
+	status := map[string]interface{}{
+		"instance_id":       s.instanceID,
+		"is_leader":         s.isLeader && time.Now().Before(s.leaderUntil),
+		"lease_seconds":     int(s.ttl.Seconds()),
+		"heartbeat_seconds": int(s.heartbeat.Seconds()),
+	}
+	if !s.leaderSince.IsZero() && s.isLeader {
+		status["leader_since"] = s.leaderSince
+	}
+	if s.lastError != "" {
+		status["last_error"] = s.lastError
+	}
+	s.mu.RUnlock()
+
+	lease, err := s.leaseRepo.FindByName(s.leaseName)
+	if err != nil {
+		status["leader"] = nil
+		return status
+	}
+
+	now := time.Now()
+	leader := map[string]interface{}{
+		"instance_id": lease.HolderID,
+		"acquired_at": lease.AcquiredAt,
+		"renewed_at":  lease.RenewedAt,
+		"expires_at":  lease.ExpiresAt,
+		"expired":     lease.IsExpired(now),
+	}
+	if lease.HolderID == "" || lease.IsExpired(now) {
+		status["leader"] = nil
+		status["last_lease"] = leader
+	} else {
+		status["leader"] = leader
+	}
+	return status
+}
@@ -0,0 +1,471 @@
+/**
+ * 复式记账总账服务
+ *
+ * 用途：
+ * - 每次客户资金变更（BalanceService.Apply）在同一事务内生成一张借贷平衡的凭证
+ * - 核对总账：试算平衡、科目余额与分录汇总一致、客户余额与客户分户科目一致
+ *
+ * 记账规则（客户定金为平台负债，贷增借减）：
+ * - 客户可用定金/已用定金/提现冻结按实际变化量记贷方（增加）或借方（减少）
+ * - 手续费记手续费收入贷方
+ * - 差额记对方科目：充值、提现为银行清算；结算、强平、部分平仓等盈亏为平台盈亏
+ * - 订单冻结、补定金、提现冻结/解冻只在客户分户科目之间划转，没有对方科目
+ *
+ * 说明：
+ * - 启用总账前已有余额的客户，在第一次资金变更时先按变更前余额补记期初凭证（对方科目：银行清算）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+/**
+ * ledgerAccountMeta 科目定义
+ */
+type ledgerAccountMeta struct {
+	Category string
+	Name     string
+}
+
+var ledgerAccounts = map[string]ledgerAccountMeta{
+	model.LedgerAccountCustomerAvailable: {model.LedgerCategoryLiability, "客户可用定金"},
+	model.LedgerAccountCustomerMargin:    {model.LedgerCategoryLiability, "客户已用定金"},
+	model.LedgerAccountCustomerFrozen:    {model.LedgerCategoryLiability, "客户提现冻结"},
+	model.LedgerAccountHousePnL:          {model.LedgerCategoryEquity, "平台盈亏"},
+	model.LedgerAccountFeeIncome:         {model.LedgerCategoryIncome, "手续费收入"},
+	model.LedgerAccountBankClearing:      {model.LedgerCategoryAsset, "银行清算"},
+}
+
+// houseLedgerAccounts 平台科目（不分户）
+var houseLedgerAccounts = []string{
+	model.LedgerAccountBankClearing,
+	model.LedgerAccountHousePnL,
+	model.LedgerAccountFeeIncome,
+}
+
+/**
+ * ledgerCounterAccount 按资金流水类型确定对方科目
+ */
+func ledgerCounterAccount(fundLogType string) string {
+	switch fundLogType {
+	case model.FundLogTypeDeposit, model.FundLogTypeWithdraw:
+		return model.LedgerAccountBankClearing
+	default:
+		return model.LedgerAccountHousePnL
+	}
+}
+
+/**
+ * LedgerAccountMismatch 科目余额与分录汇总不一致
+ */
+type LedgerAccountMismatch struct {
+	AccountID uint    `json:"account_id"`
+	Code      string  `json:"code"`
+	UserID    uint    `json:"user_id"`
+	Balance   float64 `json:"balance"`    // 科目余额
+	LineTotal float64 `json:"line_total"` // 分录汇总
+}
+
+/**
+ * LedgerUserMismatch 客户余额与分户科目不一致
+ */
+type LedgerUserMismatch struct {
+	UserID           uint    `json:"user_id"`
+	Phone            string  `json:"phone"`
+	AvailableDeposit float64 `json:"available_deposit"` // 用户表可用定金
+	LedgerAvailable  float64 `json:"ledger_available"`  // 总账可用定金
+	UsedDeposit      float64 `json:"used_deposit"`      // 用户表已用定金
+	LedgerMargin     float64 `json:"ledger_margin"`     // 总账已用定金
+	WithdrawFrozen   float64 `json:"withdraw_frozen"`   // 用户表提现冻结
+	LedgerFrozen     float64 `json:"ledger_frozen"`     // 总账提现冻结
+}
+
+/**
+ * LedgerCheckResult 总账核对结果
+ */
+type LedgerCheckResult struct {
+	TotalDebit        float64                  `json:"total_debit"`
+	TotalCredit       float64                  `json:"total_credit"`
+	Balanced          bool                     `json:"balanced"`           // 试算是否平衡
+	AccountMismatches []*LedgerAccountMismatch `json:"account_mismatches"` // 科目余额与分录不一致
+	UserMismatches    []*LedgerUserMismatch    `json:"user_mismatches"`    // 客户余额与总账不一致
+	CheckedUsers      int                      `json:"checked_users"`      // 已建账客户数
+	UnopenedUsers     int                      `json:"unopened_users"`     // 有余额但尚未建账的客户数
+	HouseAccounts     []*model.LedgerAccount   `json:"house_accounts"`     // 平台科目余额
+}
+
+/**
+ * OK 核对是否全部通过
+ *
+ * @return bool
+ */
+func (r *LedgerCheckResult) OK() bool {
+	return r.Balanced && len(r.AccountMismatches) == 0 && len(r.UserMismatches) == 0
+}
+
+/**
+ * LedgerService 总账服务
+ */
+type LedgerService struct {
+	ctx  *appctx.AppContext
+	repo *repository.LedgerRepository
+}
+
+/**
+ * NewLedgerService 创建总账服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *LedgerService
+ */
+func NewLedgerService(ctx *appctx.AppContext) *LedgerService {
+	return &LedgerService{
+		ctx:  ctx,
+		repo: repository.NewLedgerRepository(ctx.DB),
+	}
+}
+
+/**
+ * EnsureHouseAccounts 创建平台科目（启动时调用，避免并发记账时重复创建）
+ *
+ * @return error
+ */
+func (s *LedgerService) EnsureHouseAccounts() error {
+	for _, code := range houseLedgerAccounts {
+		if _, err := s.ensureAccount(s.repo, code, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * ensureAccount 查询科目，不存在则创建
+ */
+func (s *LedgerService) ensureAccount(repo *repository.LedgerRepository, code string, userID uint) (*model.LedgerAccount, error) {
+	account, err := repo.FindAccount(code, userID)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	meta := ledgerAccounts[code]
+	account = &model.LedgerAccount{
+		Code:     code,
+		UserID:   userID,
+		Category: meta.Category,
+		Name:     meta.Name,
+	}
+	if err := repo.CreateAccount(account); err != nil {
+		return nil, fmt.Errorf("创建科目失败(%s): %v", code, err)
+	}
+	return account, nil
+}
+
+/**
+ * journalLine 待记账分录（Amount 正数记借方，负数记贷方）
+ */
+type journalLine struct {
+	account *model.LedgerAccount
+	amount  float64
+}
+
+/**
+ * post 写入一张凭证并更新科目余额（调用方事务内）
+ */
+func (s *LedgerService) post(repo *repository.LedgerRepository, entry *model.JournalEntry, lines []journalLine) error {
+	var debit, credit float64
+	accounts := make([]*model.LedgerAccount, 0, len(lines))
+	for _, l := range lines {
+		amount := roundMoney(l.amount)
+		if amount == 0 {
+			continue
+		}
+		line := &model.JournalLine{AccountID: l.account.ID, AccountCode: l.account.Code}
+		if amount > 0 {
+			line.Debit = amount
+			debit += amount
+		} else {
+			line.Credit = -amount
+			credit += -amount
+		}
+		entry.Lines = append(entry.Lines, line)
+		accounts = append(accounts, l.account)
+	}
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+	if math.Abs(debit-credit) >= 0.005 {
+		return fmt.Errorf("凭证借贷不平衡（借: %.2f, 贷: %.2f）", debit, credit)
+	}
+	entry.Amount = roundMoney(debit)
+
+	if err := repo.CreateEntry(entry); err != nil {
+		return fmt.Errorf("写入凭证失败: %v", err)
+	}
+	for i, line := range entry.Lines {
+		if err := repo.AddBalance(line.AccountID, accounts[i].SignedAmount(line.Debit, line.Credit)); err != nil {
+			return fmt.Errorf("更新科目余额失败(%s): %v", line.AccountCode, err)
+		}
+	}
+	return nil
+}
+
+/**
+ * PostBalanceChange 为一次客户资金变更记账（由 BalanceService.Apply 在同一事务内调用）
+ *
+ * 调用方已锁定用户行，客户分户科目的创建和期初凭证不会并发重复
+ *
+ * @param tx *gorm.DB - 调用方事务
+ * @param fundLog *model.FundLog - 本次资金流水（含变更前后余额）
+ * @param change BalanceChange - 资金变更请求（手续费、对方科目）
+ * @return (*model.JournalEntry, error)
+ */
+func (s *LedgerService) PostBalanceChange(tx *gorm.DB, fundLog *model.FundLog, change BalanceChange) (*model.JournalEntry, error) {
+	repo := repository.NewLedgerRepository(tx)
+
+	// 客户可用定金科目不存在说明是首次记账
+	_, err := repo.FindAccount(model.LedgerAccountCustomerAvailable, fundLog.UserID)
+	opening := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !opening {
+		return nil, err
+	}
+	available, err := s.ensureAccount(repo, model.LedgerAccountCustomerAvailable, fundLog.UserID)
+	if err != nil {
+		return nil, err
+	}
+	margin, err := s.ensureAccount(repo, model.LedgerAccountCustomerMargin, fundLog.UserID)
+	if err != nil {
+		return nil, err
+	}
+	frozen, err := s.ensureAccount(repo, model.LedgerAccountCustomerFrozen, fundLog.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 首次记账：按变更前余额补记期初凭证
+	if opening && (fundLog.AvailableBefore != 0 || fundLog.UsedBefore != 0 || fundLog.FrozenBefore != 0) {
+		bank, err := s.ensureAccount(repo, model.LedgerAccountBankClearing, 0)
+		if err != nil {
+			return nil, err
+		}
+		openingEntry := &model.JournalEntry{
+			Type:   model.JournalTypeOpening,
+			UserID: fundLog.UserID,
+			Note:   "期初余额（启用总账前的客户定金）",
+		}
+		if err := s.post(repo, openingEntry, []journalLine{
+			{account: available, amount: -fundLog.AvailableBefore},
+			{account: margin, amount: -fundLog.UsedBefore},
+			{account: frozen, amount: -fundLog.FrozenBefore},
+			{account: bank, amount: fundLog.AvailableBefore + fundLog.UsedBefore + fundLog.FrozenBefore},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 本次变更（按实际变化量，已用定金归零等修正也如实记账）
+	availableDelta := roundMoney(fundLog.AvailableAfter - fundLog.AvailableBefore)
+	usedDelta := roundMoney(fundLog.UsedAfter - fundLog.UsedBefore)
+	frozenDelta := roundMoney(fundLog.FrozenAfter - fundLog.FrozenBefore)
+	lines := []journalLine{
+		{account: available, amount: -availableDelta},
+		{account: margin, amount: -usedDelta},
+		{account: frozen, amount: -frozenDelta},
+	}
+
+	counterAmount := availableDelta + usedDelta + frozenDelta
+	if change.Fee > 0 {
+		fee, err := s.ensureAccount(repo, model.LedgerAccountFeeIncome, 0)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, journalLine{account: fee, amount: -change.Fee})
+		counterAmount += change.Fee
+	}
+	if roundMoney(counterAmount) != 0 {
+		code := change.CounterAccount
+		if code == "" {
+			code = ledgerCounterAccount(fundLog.Type)
+		}
+		counter, err := s.ensureAccount(repo, code, 0)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, journalLine{account: counter, amount: counterAmount})
+	}
+
+	entry := &model.JournalEntry{
+		Type:        fundLog.Type,
+		UserID:      fundLog.UserID,
+		FundLogID:   fundLog.ID,
+		RelatedID:   fundLog.RelatedID,
+		RelatedType: fundLog.RelatedType,
+		Note:        fundLog.Note,
+	}
+	if err := s.post(repo, entry, lines); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+/**
+ * Check 核对总账
+ *
+ * 核对内容：
+ * 1. 全部分录借贷合计相等
+ * 2. 每个科目余额等于其分录汇总
+ * 3. 已建账客户的可用/已用定金、提现冻结等于对应分户科目余额
+ *
+ * @return (*LedgerCheckResult, error)
+ */
+func (s *LedgerService) Check() (*LedgerCheckResult, error) {
+	result := &LedgerCheckResult{
+		AccountMismatches: []*LedgerAccountMismatch{},
+		UserMismatches:    []*LedgerUserMismatch{},
+		HouseAccounts:     []*model.LedgerAccount{},
+	}
+
+	// 1. 试算平衡
+	debit, credit, err := s.repo.SumLines()
+	if err != nil {
+		return nil, err
+	}
+	result.TotalDebit = roundMoney(debit)
+	result.TotalCredit = roundMoney(credit)
+	result.Balanced = math.Abs(debit-credit) < 0.005
+
+	// 2. 科目余额与分录汇总
+	accounts, err := s.repo.FindAllAccounts()
+	if err != nil {
+		return nil, err
+	}
+	sums, err := s.repo.SumLinesByAccount()
+	if err != nil {
+		return nil, err
+	}
+	sumByAccount := make(map[uint]repository.AccountLineSum, len(sums))
+	for _, sum := range sums {
+		sumByAccount[sum.AccountID] = sum
+	}
+
+	type userAccounts struct{ available, margin, frozen *model.LedgerAccount }
+	byUser := make(map[uint]*userAccounts)
+	for _, account := range accounts {
+		sum := sumByAccount[account.ID]
+		if total := roundMoney(account.SignedAmount(sum.Debit, sum.Credit)); math.Abs(account.Balance-total) >= 0.005 {
+			result.AccountMismatches = append(result.AccountMismatches, &LedgerAccountMismatch{
+				AccountID: account.ID,
+				Code:      account.Code,
+				UserID:    account.UserID,
+				Balance:   account.Balance,
+				LineTotal: total,
+			})
+		}
+		if account.UserID == 0 {
+			result.HouseAccounts = append(result.HouseAccounts, account)
+			continue
+		}
+		ua := byUser[account.UserID]
+		if ua == nil {
+			ua = &userAccounts{}
+			byUser[account.UserID] = ua
+		}
+		switch account.Code {
+		case model.LedgerAccountCustomerAvailable:
+			ua.available = account
+		case model.LedgerAccountCustomerMargin:
+			ua.margin = account
+		case model.LedgerAccountCustomerFrozen:
+			ua.frozen = account
+		}
+	}
+
+	// 3. 客户余额与分户科目
+	users, err := repository.NewUserRepository(s.ctx.DB).FindAllBalances()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		ua := byUser[user.ID]
+		if ua == nil || ua.available == nil {
+			if user.AvailableDeposit != 0 || user.UsedDeposit != 0 || user.WithdrawFrozen != 0 {
+				result.UnopenedUsers++
+			}
+			continue
+		}
+		result.CheckedUsers++
+
+		ledgerMargin, ledgerFrozen := 0.0, 0.0
+		if ua.margin != nil {
+			ledgerMargin = ua.margin.Balance
+		}
+		if ua.frozen != nil {
+			ledgerFrozen = ua.frozen.Balance
+		}
+		if math.Abs(user.AvailableDeposit-ua.available.Balance) >= 0.005 ||
+			math.Abs(user.UsedDeposit-ledgerMargin) >= 0.005 ||
+			math.Abs(user.WithdrawFrozen-ledgerFrozen) >= 0.005 {
+			result.UserMismatches = append(result.UserMismatches, &LedgerUserMismatch{
+				UserID:           user.ID,
+				Phone:            user.Phone,
+				AvailableDeposit: user.AvailableDeposit,
+				LedgerAvailable:  ua.available.Balance,
+				UsedDeposit:      user.UsedDeposit,
+				LedgerMargin:     ledgerMargin,
+				WithdrawFrozen:   user.WithdrawFrozen,
+				LedgerFrozen:     ledgerFrozen,
+			})
+		}
+	}
+
+	if !result.OK() {
+		log.Printf("[Ledger] ⚠️ 总账核对不一致: 试算平衡=%v, 科目不一致 %d 个, 客户不一致 %d 个",
+			result.Balanced, len(result.AccountMismatches), len(result.UserMismatches))
+	}
+	return result, nil
+}
+
+/**
+ * GetUserAccounts 查询客户的分户科目
+ *
+ * @param userID uint - 用户ID
+ * @return ([]*model.LedgerAccount, error)
+ */
+func (s *LedgerService) GetUserAccounts(userID uint) ([]*model.LedgerAccount, error) {
+	return s.repo.FindAccountsByUser(userID)
+}
+
+/**
+ * GetEntries 分页查询凭证
+ *
+ * @param userID uint - 客户ID（0表示全部）
+ * @param entryType string - 凭证类型（空表示全部）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.JournalEntry, int64, error)
+ */
+func (s *LedgerService) GetEntries(userID uint, entryType string, limit, offset int) ([]*model.JournalEntry, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.FindEntries(userID, entryType, limit, offset)
+}
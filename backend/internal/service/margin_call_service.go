@@ -0,0 +1,460 @@
+/**
+ * 追保（追加定金）服务
+ *
+ * 用途：
+ * - 定金率跌破追保线时发起追保，通知客户及其归属销售，给出补足期限
+ * - 期限内补足定金（或行情回升、客户自行结算）则解除追保
+ * - 期限已过仍未补足，或定金率跌破强平底线，才执行强平（大额订单优先部分平仓）
+ *
+ * 阈值配置（system_configs）：
+ * - margin_call_rate: 追保线（%，默认20）
+ * - force_close_floor_rate: 强平底线（%，默认10，跌破后不等期限立即强平）
+ * - margin_call_grace_minutes: 补足期限（分钟，默认120）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+// 追保默认参数
+const (
+	defaultMarginCallRate         = 20.0
+	defaultForceCloseFloorRate    = 10.0
+	defaultMarginCallGraceMinutes = 120
+)
+
+/**
+ * MarginCallPolicy 追保阈值
+ */
+type MarginCallPolicy struct {
+	CallRate     float64 `json:"call_rate"`     // 追保线（%）
+	FloorRate    float64 `json:"floor_rate"`    // 强平底线（%）
+	GraceMinutes int     `json:"grace_minutes"` // 补足期限（分钟）
+}
+
+/**
+ * MarginCallService 追保服务
+ */
+type MarginCallService struct {
+	ctx        *appctx.AppContext
+	callRepo   *repository.MarginCallRepository
+	orderRepo  *repository.OrderRepository
+	userRepo   *repository.UserRepository
+	configRepo *repository.ConfigRepository
+	notiSvc    *NotificationService
+}
+
+/**
+ * NewMarginCallService 创建追保服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *MarginCallService
+ */
+func NewMarginCallService(ctx *appctx.AppContext) *MarginCallService {
+	return &MarginCallService{
+		ctx:        ctx,
+		callRepo:   repository.NewMarginCallRepository(ctx.DB),
+		orderRepo:  repository.NewOrderRepository(ctx.DB),
+		userRepo:   repository.NewUserRepository(ctx.DB),
+		configRepo: repository.NewConfigRepository(ctx.DB),
+		notiSvc:    NewNotificationService(ctx),
+	}
+}
+
+/**
+ * GetPolicy 读取追保阈值配置
+ *
+ * 配置缺失或不合法时使用默认值，并保证 强平底线 < 追保线
+ *
+ * @return MarginCallPolicy
+ */
+func (s *MarginCallService) GetPolicy() MarginCallPolicy {
+	policy := MarginCallPolicy{
+		CallRate:     defaultMarginCallRate,
+		FloorRate:    defaultForceCloseFloorRate,
+		GraceMinutes: defaultMarginCallGraceMinutes,
+	}
+
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyMarginCallRate); err == nil && config != nil {
+		var rate float64
+		fmt.Sscanf(config.Value, "%f", &rate)
+		if rate > 0 && rate < 100 {
+			policy.CallRate = rate
+		}
+	}
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyForceCloseFloorRate); err == nil && config != nil {
+		var rate float64
+		fmt.Sscanf(config.Value, "%f", &rate)
+		if rate >= 0 && rate < 100 {
+			policy.FloorRate = rate
+		}
+	}
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyMarginCallGraceMinutes); err == nil && config != nil {
+		var minutes int
+		fmt.Sscanf(config.Value, "%d", &minutes)
+		if minutes > 0 {
+			policy.GraceMinutes = minutes
+		}
+	}
+
+	if policy.FloorRate >= policy.CallRate {
+		log.Printf("[MarginCall] ⚠️ 强平底线 %.2f%% 不低于追保线 %.2f%%，强平底线按追保线的一半处理",
+			policy.FloorRate, policy.CallRate)
+		policy.FloorRate = policy.CallRate / 2
+	}
+
+	return policy
+}
+
+/**
+ * ReviewOrders 处理一轮风控检查中的追保
+ *
+ * 业务流程：
+ * 1. 跌破追保线且没有进行中追保的订单：发起追保并通知客户和销售
+ * 2. 进行中的追保：
+ *    - 订单已回到追保线以上：解除追保
+ *    - 已过期限仍未补足：加入强平列表
+ *    - 仍在期限内：更新需补充金额
+ *
+ * 跌破强平底线的订单由调用方直接强平，不经过本方法
+ *
+ * @param belowCall []*model.Order - 本轮定金率低于追保线（高于强平底线）的订单
+ * @param forceClose []*model.Order - 本轮跌破强平底线的订单
+ * @param policy MarginCallPolicy - 追保阈值
+ * @return []*model.Order - 追保逾期需要强平的订单
+ */
+func (s *MarginCallService) ReviewOrders(belowCall, forceClose []*model.Order, policy MarginCallPolicy) []*model.Order {
+	now := time.Now()
+	overdue := make([]*model.Order, 0)
+
+	belowCallMap := make(map[uint]*model.Order, len(belowCall))
+	for _, order := range belowCall {
+		belowCallMap[order.ID] = order
+	}
+	forceCloseMap := make(map[uint]bool, len(forceClose))
+	for _, order := range forceClose {
+		forceCloseMap[order.ID] = true
+	}
+
+	// 1. 处理进行中的追保
+	openCalls, err := s.callRepo.FindOpen()
+	if err != nil {
+		log.Printf("[MarginCall] 查询进行中的追保失败: %v", err)
+		return overdue
+	}
+
+	for _, call := range openCalls {
+		// 跌破强平底线的订单本轮直接强平，追保在强平事务中关闭
+		if forceCloseMap[call.OrderID] {
+			continue
+		}
+
+		order, stillBelow := belowCallMap[call.OrderID]
+		delete(belowCallMap, call.OrderID)
+
+		if !stillBelow {
+			s.closeRecoveredCall(call)
+			continue
+		}
+
+		if call.IsOverdue(now) {
+			log.Printf("[MarginCall] ⏰ 订单 %s 追保已逾期（期限 %s），定金率 %.2f%%，执行强平",
+				order.OrderID, call.Deadline.Format("2006-01-02 15:04:05"), order.MarginRate)
+			overdue = append(overdue, order)
+			continue
+		}
+
+		required := roundMoney(model.RequiredSupplement(order, call.CallRate))
+		if required != call.RequiredAmount {
+			call.RequiredAmount = required
+			if err := s.callRepo.Update(call); err != nil {
+				log.Printf("[MarginCall] 更新追保 %d 需补充金额失败: %v", call.ID, err)
+			}
+		}
+	}
+
+	// 2. 新跌破追保线的订单发起追保
+	for _, order := range belowCallMap {
+		if _, err := s.OpenCall(order, policy); err != nil {
+			log.Printf("[MarginCall] 订单 %s 发起追保失败: %v", order.OrderID, err)
+		}
+	}
+
+	return overdue
+}
+
+/**
+ * closeRecoveredCall 关闭已不在追保线以下的订单的追保
+ *
+ * 订单仍持仓说明行情回升，解除追保；订单已结算/强平的追保通常已在对应事务中关闭，这里兜底处理
+ */
+func (s *MarginCallService) closeRecoveredCall(call *model.MarginCall) {
+	order, err := s.orderRepo.FindByID(call.OrderID)
+	if err != nil {
+		log.Printf("[MarginCall] 追保 %d 关联订单 %d 不存在，解除追保", call.ID, call.OrderID)
+		call.Meet(0, "关联订单不存在")
+		s.callRepo.Update(call)
+		return
+	}
+
+	switch order.Status {
+	case model.OrderStatusHolding:
+		call.Meet(order.MarginRate, fmt.Sprintf("行情回升，定金率恢复至 %.2f%%", order.MarginRate))
+	case model.OrderStatusClosed:
+		call.Expire(order.MarginRate, "订单已强制平仓")
+	default:
+		call.Meet(order.MarginRate, "订单已结算")
+	}
+	if err := s.callRepo.Update(call); err != nil {
+		log.Printf("[MarginCall] 关闭追保 %d 失败: %v", call.ID, err)
+		return
+	}
+
+	log.Printf("[MarginCall] ✅ 订单 %s 追保已关闭（%s）", order.OrderID, call.ResolveNote)
+	if call.Status == model.MarginCallStatusMet && order.Status == model.OrderStatusHolding {
+		s.NotifyResolved(call)
+	}
+}
+
+/**
+ * OpenCall 为订单发起追保（已有进行中的追保则直接返回）
+ *
+ * @param order *model.Order - 订单（需已按当前价格计算定金率）
+ * @param policy MarginCallPolicy - 追保阈值
+ * @return (*model.MarginCall, error)
+ */
+func (s *MarginCallService) OpenCall(order *model.Order, policy MarginCallPolicy) (*model.MarginCall, error) {
+	if existing, err := s.callRepo.FindOpenByOrderID(order.ID); err == nil {
+		return existing, nil
+	}
+
+	user, err := s.userRepo.FindByID(order.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	call := &model.MarginCall{
+		UserID:         order.UserID,
+		OrderID:        order.ID,
+		OrderNo:        order.OrderID,
+		SalesID:        user.SalesID,
+		Status:         model.MarginCallStatusOpen,
+		CallRate:       policy.CallRate,
+		TriggerRate:    order.MarginRate,
+		TriggerPrice:   order.CurrentPrice,
+		RequiredAmount: roundMoney(model.RequiredSupplement(order, policy.CallRate)),
+		Deadline:       time.Now().Add(time.Duration(policy.GraceMinutes) * time.Minute),
+	}
+	if err := s.callRepo.Create(call); err != nil {
+		return nil, fmt.Errorf("创建追保记录失败: %v", err)
+	}
+
+	log.Printf("[MarginCall] 📣 订单 %s 定金率 %.2f%% 跌破追保线 %.2f%%，发起追保，需补充 %.2f 元，期限 %s",
+		order.OrderID, order.MarginRate, policy.CallRate, call.RequiredAmount, call.Deadline.Format("2006-01-02 15:04:05"))
+
+	s.notifyOpened(call, user)
+	return call, nil
+}
+
+/**
+ * notifyOpened 通知客户及其归属销售
+ */
+func (s *MarginCallService) notifyOpened(call *model.MarginCall, user *model.User) {
+	deadline := call.Deadline.Format("2006-01-02 15:04")
+
+	customerMsg := fmt.Sprintf("订单号：%s\n当前定金率：%.2f%%，已低于追保线 %.2f%%\n需补充定金：%.2f 元\n请在 %s 前补足定金，逾期未补足系统将强制平仓",
+		call.OrderNo, call.TriggerRate, call.CallRate, call.RequiredAmount, deadline)
+	if _, err := s.notiSvc.SendNotification(
+		call.UserID,
+		model.NotifyTypeRisk,
+		model.NotifyLevelCritical,
+		"追加定金通知",
+		customerMsg,
+		call.OrderID,
+		"order",
+	); err != nil {
+		log.Printf("[MarginCall] 通知客户 %d 失败: %v", call.UserID, err)
+	}
+
+	if call.SalesID == 0 {
+		return
+	}
+	customerName := user.RealName
+	if customerName == "" {
+		customerName = user.Phone
+	}
+	salesMsg := fmt.Sprintf("您的客户 %s 的订单 %s 定金率 %.2f%% 已低于追保线 %.2f%%\n需补充定金：%.2f 元\n补足期限：%s\n请及时联系客户",
+		customerName, call.OrderNo, call.TriggerRate, call.CallRate, call.RequiredAmount, deadline)
+	if _, err := s.notiSvc.SendNotification(
+		call.SalesID,
+		model.NotifyTypeRisk,
+		model.NotifyLevelWarning,
+		"客户追保提醒",
+		salesMsg,
+		call.OrderID,
+		"order",
+	); err != nil {
+		log.Printf("[MarginCall] 通知销售 %d 失败: %v", call.SalesID, err)
+	}
+}
+
+/**
+ * NotifyResolved 通知客户追保已解除
+ *
+ * @param call *model.MarginCall - 已解除的追保
+ * @return void
+ */
+func (s *MarginCallService) NotifyResolved(call *model.MarginCall) {
+	msg := fmt.Sprintf("订单号：%s\n%s\n追保已解除，当前定金率：%.2f%%", call.OrderNo, call.ResolveNote, call.ResolvedRate)
+	if _, err := s.notiSvc.SendNotification(
+		call.UserID,
+		model.NotifyTypeRisk,
+		model.NotifyLevelInfo,
+		"追保已解除",
+		msg,
+		call.OrderID,
+		"order",
+	); err != nil {
+		log.Printf("[MarginCall] 通知客户 %d 追保解除失败: %v", call.UserID, err)
+	}
+}
+
+/**
+ * ResolveOnSupplement 补定金后检查追保（在补定金事务中调用）
+ *
+ * 定金率回到追保线以上则解除追保，否则更新剩余需补充金额
+ *
+ * @param tx *gorm.DB - 补定金事务
+ * @param order *model.Order - 已增加定金并重算定金率的订单
+ * @param supplementID uint - 补定金记录ID
+ * @return (*model.MarginCall, error) - 本次解除的追保（未解除返回nil）
+ */
+func (s *MarginCallService) ResolveOnSupplement(tx *gorm.DB, order *model.Order, supplementID uint) (*model.MarginCall, error) {
+	return s.resolveIfRestored(tx, order, supplementID,
+		fmt.Sprintf("已补充定金，定金率恢复至 %.2f%%", order.MarginRate))
+}
+
+/**
+ * ResolveOnPartialClose 风控部分平仓后检查追保（在部分平仓事务中调用）
+ *
+ * @param tx *gorm.DB - 部分平仓事务
+ * @param order *model.Order - 已部分平仓并重算定金率的订单
+ * @param closedWeightG float64 - 本次平仓克重
+ * @return (*model.MarginCall, error) - 本次解除的追保（未解除返回nil）
+ */
+func (s *MarginCallService) ResolveOnPartialClose(tx *gorm.DB, order *model.Order, closedWeightG float64) (*model.MarginCall, error) {
+	return s.resolveIfRestored(tx, order, 0,
+		fmt.Sprintf("系统部分平仓 %.3f 克，定金率恢复至 %.2f%%", closedWeightG, order.MarginRate))
+}
+
+/**
+ * resolveIfRestored 定金率回到追保线以上则解除追保，否则更新剩余需补充金额
+ */
+func (s *MarginCallService) resolveIfRestored(tx *gorm.DB, order *model.Order, supplementID uint, note string) (*model.MarginCall, error) {
+	callRepo := repository.NewMarginCallRepository(tx)
+	call, err := callRepo.FindOpenByOrderID(order.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if order.MarginRate <= call.CallRate {
+		call.RequiredAmount = roundMoney(model.RequiredSupplement(order, call.CallRate))
+		return nil, callRepo.Update(call)
+	}
+
+	call.Meet(order.MarginRate, note)
+	call.SupplementID = supplementID
+	if err := callRepo.Update(call); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+/**
+ * CloseOnOrderClosed 订单结算或强平时关闭进行中的追保（在结算/强平事务中调用）
+ *
+ * - 客户自行结算：追保解除
+ * - 强制平仓：追保逾期（区分期限已过和跌破强平底线）
+ *
+ * @param tx *gorm.DB - 结算/强平事务
+ * @param order *model.Order - 已结算或强平的订单
+ * @return error
+ */
+func (s *MarginCallService) CloseOnOrderClosed(tx *gorm.DB, order *model.Order) error {
+	callRepo := repository.NewMarginCallRepository(tx)
+	call, err := callRepo.FindOpenByOrderID(order.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if order.Status == model.OrderStatusClosed {
+		if call.IsOverdue(time.Now()) {
+			call.Expire(order.MarginRate, "追保期限已过仍未补足，已强制平仓")
+		} else {
+			call.Expire(order.MarginRate, fmt.Sprintf("定金率 %.2f%% 跌破强平底线，已强制平仓", order.MarginRate))
+		}
+	} else {
+		call.Meet(order.MarginRate, "客户已自行结算订单")
+	}
+
+	return callRepo.Update(call)
+}
+
+/**
+ * GetMarginCalls 按状态分页查询追保记录（管理后台）
+ *
+ * @param status string - 状态（为空查询全部）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.MarginCall, int64, error)
+ */
+func (s *MarginCallService) GetMarginCalls(status string, limit, offset int) ([]*model.MarginCall, int64, error) {
+	return s.callRepo.FindByStatus(status, limit, offset)
+}
+
+/**
+ * GetMarginCall 查询追保详情
+ *
+ * @param id uint - 追保ID
+ * @return (*model.MarginCall, error)
+ */
+func (s *MarginCallService) GetMarginCall(id uint) (*model.MarginCall, error) {
+	call, err := s.callRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("追保记录不存在")
+	}
+	return call, nil
+}
+
+/**
+ * GetUserMarginCalls 查询用户的追保记录
+ *
+ * @param userID uint - 用户ID
+ * @param status string - 状态（为空查询全部）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.MarginCall, error)
+ */
+func (s *MarginCallService) GetUserMarginCalls(userID uint, status string, limit, offset int) ([]*model.MarginCall, error) {
+	return s.callRepo.FindByUserID(userID, status, limit, offset)
+}
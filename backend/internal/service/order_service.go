@@ -29,8 +29,9 @@ import (
  */
 type OrderService struct {
 	ctx       *appctx.AppContext
-	orderRepo *repository.OrderRepository
-	userRepo  *repository.UserRepository
+	orderRepo  *repository.OrderRepository
+	userRepo   *repository.UserRepository
+	balanceSvc *BalanceService
 }
 
 /**
@@ -42,8 +43,9 @@ type OrderService struct {
 func NewOrderService(ctx *appctx.AppContext) *OrderService {
 	return &OrderService{
 		ctx:       ctx,
-		orderRepo: repository.NewOrderRepository(ctx.DB),
-		userRepo:  repository.NewUserRepository(ctx.DB),
+		orderRepo:  repository.NewOrderRepository(ctx.DB),
+		userRepo:   repository.NewUserRepository(ctx.DB),
+		balanceSvc: NewBalanceService(ctx),
 	}
 }
 
@@ -62,10 +64,9 @@ type CreateOrderRequest struct {
  * 
  * 业务流程：
  * 1. 验证订单类型和参数
- * 2. 检查用户可用定金是否足够
- * 3. 冻结定金（从可用转到已用）
- * 4. 生成订单号并创建订单
- * 5. 初始化订单的盈亏和定金率
+ * 2. 生成订单号并创建订单
+ * 3. 初始化订单的盈亏和定金率
+ * 4. 锁定用户资金并冻结定金（从可用转到已用，余额不足则回滚）
  * 
  * @param userID uint - 用户ID
  * @param req CreateOrderRequest - 创建订单请求
@@ -88,18 +89,12 @@ func (s *OrderService) CreateOrder(userID uint, req CreateOrderRequest) (*model.
 		return nil, errors.New("定金必须大于0")
 	}
 	
-	// 2. 检查用户可用定金
-	user, err := s.userRepo.FindByID(userID)
-	if err != nil {
-		return nil, errors.New("用户不存在")
-	}
-	
-	if user.AvailableDeposit < req.Deposit {
-		return nil, fmt.Errorf("可用定金不足（可用: %.2f, 需要: %.2f）", 
-			user.AvailableDeposit, req.Deposit)
+	// 行情降级模式下禁止下单
+	if err := CheckMarketAvailable(); err != nil {
+		return nil, err
 	}
 	
-	// 3. 开启数据库事务
+	// 2. 开启数据库事务
 	tx := s.ctx.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -107,19 +102,10 @@ func (s *OrderService) CreateOrder(userID uint, req CreateOrderRequest) (*model.
 		}
 	}()
 	
-	// 4. 冻结定金（可用 -> 已用）
-	if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
-		"available_deposit": user.AvailableDeposit - req.Deposit,
-		"used_deposit":      user.UsedDeposit + req.Deposit,
-	}).Error; err != nil {
-		tx.Rollback()
-		return nil, errors.New("冻结定金失败")
-	}
-	
-	// 5. 生成订单号（格式：年月日时分秒+用户ID）
+	// 3. 生成订单号（格式：年月日时分秒+用户ID）
 	orderID := fmt.Sprintf("%s%d", time.Now().Format("20060102150405"), userID)
 	
-	// 6. 创建订单
+	// 4. 创建订单
 	order := &model.Order{
 		OrderID:      orderID,
 		UserID:       userID,
@@ -131,16 +117,30 @@ func (s *OrderService) CreateOrder(userID uint, req CreateOrderRequest) (*model.
 		Status:       model.OrderStatusHolding,
 	}
 	
-	// 7. 初始化盈亏和定金率
+	// 5. 初始化盈亏和定金率
 	order.UpdatePnLAndMargin(req.LockedPrice)
 	
-	// 8. 保存订单
+	// 6. 保存订单
 	if err := tx.Create(order).Error; err != nil {
 		tx.Rollback()
 		return nil, errors.New("创建订单失败")
 	}
 	
-	// 9. 提交事务
+	// 7. 冻结定金（可用 -> 已用），锁定用户行后按增量扣减
+	if _, err := s.balanceSvc.Apply(tx, BalanceChange{
+		UserID:         userID,
+		Type:           model.FundLogTypeOrderFreeze,
+		AvailableDelta: -req.Deposit,
+		UsedDelta:      req.Deposit,
+		RelatedID:      order.ID,
+		RelatedType:    "order",
+		Note:           fmt.Sprintf("下单冻结定金: %.2f元 (订单%s)", req.Deposit, orderID),
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	
+	// 8. 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("事务提交失败")
 	}
@@ -149,9 +149,12 @@ func (s *OrderService) CreateOrder(userID uint, req CreateOrderRequest) (*model.
 }
 
 func (s *OrderService) autoForceCloseOrder(order *model.Order) error {
-	user, err := s.userRepo.FindByID(order.UserID)
-	if err != nil {
-		return errors.New("用户不存在")
+	// 大额订单优先部分平仓，只平掉恢复定金率所需的最少克重
+	riskSvc := NewRiskService(s.ctx)
+	if partial, err := riskSvc.PartialLiquidate(order, order.CurrentPrice, riskSvc.GetPartialClosePolicy()); err != nil {
+		log.Printf("[Order] 订单 %s 部分平仓失败，改为全部强平: %v", order.OrderID, err)
+	} else if partial != nil {
+		return nil
 	}
 
 	tx := s.ctx.DB.Begin()
@@ -161,29 +164,51 @@ func (s *OrderService) autoForceCloseOrder(order *model.Order) error {
 		}
 	}()
 
-	settledPnL := order.CalculatePnL(order.CurrentPrice)
-	newAvailable := user.AvailableDeposit + order.Deposit + settledPnL
-	newUsed := user.UsedDeposit - order.Deposit
-
-	if newAvailable < 0 {
+	// 锁定订单并确认仍为持仓，防止与客户结算或风控强平并发
+	locked, err := repository.NewOrderRepository(tx).LockByID(order.ID)
+	if err != nil {
 		tx.Rollback()
-		return errors.New("强平后资金异常（可用定金为负）")
+		return errors.New("订单不存在")
 	}
+	if !locked.CanSettle() {
+		tx.Rollback()
+		return fmt.Errorf("订单状态已变更（当前状态: %s）", locked.Status)
+	}
+	closePrice := order.CurrentPrice
+	order = locked
+
+	settledPnL := order.CalculatePnL(closePrice)
 
-	if err := tx.Model(&model.User{}).Where("id = ?", order.UserID).Updates(map[string]interface{}{
-		"available_deposit": newAvailable,
-		"used_deposit":      newUsed,
-	}).Error; err != nil {
+	// 释放已用定金，结算金额（定金 + 盈亏）加回可用定金
+	if _, err := s.balanceSvc.Apply(tx, BalanceChange{
+		UserID:         order.UserID,
+		Type:           model.FundLogTypeForceClose,
+		AvailableDelta: order.Deposit + settledPnL,
+		UsedDelta:      -order.Deposit,
+		RelatedID:      order.ID,
+		RelatedType:    "order",
+		Note:           fmt.Sprintf("强制平仓: 平仓价%.2f，盈亏%.2f元 (订单%s)", closePrice, settledPnL, order.OrderID),
+	}); err != nil {
 		tx.Rollback()
-		return errors.New("更新用户资金失败")
+		var insufficient *InsufficientBalanceError
+		if errors.As(err, &insufficient) {
+			return errors.New("强平后资金异常（可用定金为负）")
+		}
+		return err
 	}
 
-	order.ForceClose(order.CurrentPrice)
+	order.ForceClose(closePrice)
 	if err := tx.Save(order).Error; err != nil {
 		tx.Rollback()
 		return errors.New("更新订单状态失败")
 	}
 
+	// 关闭进行中的追保
+	if err := NewMarginCallService(s.ctx).CloseOnOrderClosed(tx, order); err != nil {
+		tx.Rollback()
+		return errors.New("关闭追保失败")
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return errors.New("事务提交失败")
 	}
@@ -243,10 +268,10 @@ func (s *OrderService) GetOrderDetail(userID uint, orderID string) (*model.Order
  * 
  * 业务流程：
  * 1. 验证订单状态（只能结算持仓订单）
- * 2. 计算最终盈亏
- * 3. 更新用户资金（释放定金 + 盈亏）
- * 4. 更新订单状态为已结算
- * 5. 记录资金变更日志
+ * 2. 锁定订单并再次确认状态
+ * 3. 计算最终盈亏
+ * 4. 更新用户资金（释放定金 + 盈亏）并记录资金流水
+ * 5. 更新订单状态为已结算
  * 
  * @param userID uint - 用户ID
  * @param orderID string - 订单号
@@ -275,16 +300,12 @@ func (s *OrderService) SettleOrder(userID uint, orderID string, settlePrice floa
 		return nil, errors.New("结算价格必须大于0")
 	}
 	
-	// 3. 计算结算盈亏
-	settledPnL := order.CalculatePnL(settlePrice)
-	
-	// 4. 查询用户信息
-	user, err := s.userRepo.FindByID(userID)
-	if err != nil {
-		return nil, errors.New("用户不存在")
+	// 行情降级模式下禁止结算
+	if err := CheckMarketAvailable(); err != nil {
+		return nil, err
 	}
 	
-	// 5. 开启事务
+	// 3. 开启事务
 	tx := s.ctx.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -292,23 +313,38 @@ func (s *OrderService) SettleOrder(userID uint, orderID string, settlePrice floa
 		}
 	}()
 	
-	// 6. 更新用户资金
-	// 释放已用定金，结算金额（定金 + 盈亏）加回可用定金
-	newAvailable := user.AvailableDeposit + order.Deposit + settledPnL
-	newUsed := user.UsedDeposit - order.Deposit
-	
-	// 防止资金为负（理论上不应该发生）
-	if newAvailable < 0 {
+	// 4. 锁定订单并再次确认状态，防止重复结算
+	order, err = repository.NewOrderRepository(tx).LockByID(order.ID)
+	if err != nil {
 		tx.Rollback()
-		return nil, errors.New("结算后资金异常（可用定金为负）")
+		return nil, errors.New("订单不存在")
+	}
+	if !order.CanSettle() {
+		tx.Rollback()
+		return nil, fmt.Errorf("订单状态不允许结算（当前状态: %s）", order.Status)
 	}
 	
-	if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
-		"available_deposit": newAvailable,
-		"used_deposit":      newUsed,
-	}).Error; err != nil {
+	// 5. 计算结算盈亏
+	settledPnL := order.CalculatePnL(settlePrice)
+	
+	// 6. 更新用户资金并记录流水
+	// 释放已用定金，结算金额（定金 + 盈亏）加回可用定金
+	if _, err := s.balanceSvc.Apply(tx, BalanceChange{
+		UserID:         userID,
+		Type:           model.FundLogTypeSettle,
+		AvailableDelta: order.Deposit + settledPnL,
+		UsedDelta:      -order.Deposit,
+		RelatedID:      order.ID,
+		RelatedType:    "order",
+		Note:           fmt.Sprintf("结算: 结算价%.2f，盈亏%.2f元 (订单%s)", settlePrice, settledPnL, order.OrderID),
+	}); err != nil {
 		tx.Rollback()
-		return nil, errors.New("更新用户资金失败")
+		// 防止资金为负（理论上不应该发生）
+		var insufficient *InsufficientBalanceError
+		if errors.As(err, &insufficient) {
+			return nil, errors.New("结算后资金异常（可用定金为负）")
+		}
+		return nil, err
 	}
 	
 	// 7. 更新订单状态
@@ -318,14 +354,18 @@ func (s *OrderService) SettleOrder(userID uint, orderID string, settlePrice floa
 		return nil, errors.New("更新订单状态失败")
 	}
 	
-	// TODO: 8. 记录资金变更日志（后续实现）
+	// 客户自行结算后，进行中的追保随之解除
+	if err := NewMarginCallService(s.ctx).CloseOnOrderClosed(tx, order); err != nil {
+		tx.Rollback()
+		return nil, errors.New("解除追保失败")
+	}
 	
-	// 9. 提交事务
+	// 8. 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("事务提交失败")
 	}
 	
-	// 10. 异步计算销售提成
+	// 9. 异步计算销售提成
 	go func() {
 		salesSvc := NewSalesService(s.ctx)
 		if err := salesSvc.ProcessOrderCommission(order.ID); err != nil {
@@ -353,6 +393,8 @@ func (s *OrderService) UpdateOrderPrices(currentPrice float64) error {
 	
 	// 批量更新每个订单
 	notiSvc := NewNotificationService(s.ctx)
+	marginCallSvc := NewMarginCallService(s.ctx)
+	policy := marginCallSvc.GetPolicy()
 	for _, order := range orders {
 		// 记录更新前的定金率，用于判断是否刚跨越风险阈值
 		oldMargin := order.MarginRate
@@ -380,11 +422,15 @@ func (s *OrderService) UpdateOrderPrices(currentPrice float64) error {
 			}
 		}
 
-		// 20% 强平线：触发自动强制平仓
-		if order.IsNeedForceClose() {
+		// 强平底线：立即强制平仓；追保线：发起追保，逾期由风控检查强平
+		if order.MarginRate <= policy.FloorRate {
 			if err := s.autoForceCloseOrder(order); err != nil {
 				fmt.Printf("强平订单 %s 失败: %v\n", order.OrderID, err)
 			}
+		} else if order.MarginRate <= policy.CallRate {
+			if _, err := marginCallSvc.OpenCall(order, policy); err != nil {
+				log.Printf("[Order] 订单 %s 发起追保失败: %v", order.OrderID, err)
+			}
 		}
 	}
 	
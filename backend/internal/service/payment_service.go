@@ -0,0 +1,602 @@
+/**
+ * 在线支付服务
+ *
+ * 用途：
+ * - 客户选择支付通道发起在线充值，生成支付单并向通道下单
+ * - 处理通道异步回调：校验签名、核对金额，确认支付后自动生成充值记录并入账
+ * - 长时间未收到回调的支付单主动向通道查单补单，超过有效期仍未支付的置为过期
+ *
+ * 说明：
+ * - 入账与人工审核充值走同一条充值审批链：以支付通道为依据记录系统审批步骤，再写资金流水和总账
+ * - 金额超过充值双人审批阈值时，通道确认计为第一级，充值申请转为待复核，由超级管理员复核后入账
+ * - 支付单在事务中加锁后处理，回调重复推送或与查单并发时只入账一次
+ * - 实付金额与下单金额不一致时不入账，支付单置为异常并通知管理员人工核查；管理员核查后按实付金额入账或关闭
+ * - 多实例部署时只有主实例执行定时查单
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/pkg/payment"
+	"suxin/internal/repository"
+)
+
+const (
+	defaultPaymentExpireMinutes = 30    // 默认支付单有效期（分钟）
+	defaultPaymentQueryAfter    = 60    // 默认未收到回调多久后主动查单（秒）
+	paymentQueryBatchSize       = 50    // 每轮最多查单数量
+	paymentAmountTolerance      = 0.005 // 金额比较容差（不足1分视为一致）
+)
+
+/**
+ * 金额异常支付单处理方式常量
+ */
+const (
+	PaymentResolveCredit = "credit" // 按实付金额入账
+	PaymentResolveClose  = "close"  // 不入账关闭
+)
+
+var defaultPaymentProviders []payment.Provider
+
+/**
+ * SetDefaultPaymentProviders 设置全局支付通道（由main注入）
+ */
+func SetDefaultPaymentProviders(providers []payment.Provider) {
+	defaultPaymentProviders = providers
+}
+
+/**
+ * PaymentProviderInfo 支付通道展示信息
+ */
+type PaymentProviderInfo struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+/**
+ * PaymentService 在线支付服务
+ */
+type PaymentService struct {
+	ctx        *appctx.AppContext
+	repo       *repository.PaymentRepository
+	depositSvc *DepositService
+	notiSvc    *NotificationService
+}
+
+/**
+ * NewPaymentService 创建在线支付服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *PaymentService
+ */
+func NewPaymentService(ctx *appctx.AppContext) *PaymentService {
+	return &PaymentService{
+		ctx:        ctx,
+		repo:       repository.NewPaymentRepository(ctx.DB),
+		depositSvc: NewDepositService(ctx),
+		notiSvc:    NewNotificationService(ctx),
+	}
+}
+
+/**
+ * ListProviders 查询可用的支付通道
+ *
+ * @return []PaymentProviderInfo
+ */
+func (s *PaymentService) ListProviders() []PaymentProviderInfo {
+	infos := make([]PaymentProviderInfo, 0, len(defaultPaymentProviders))
+	for _, p := range defaultPaymentProviders {
+		infos = append(infos, PaymentProviderInfo{Name: p.Name(), Title: p.Title(), Type: p.Type()})
+	}
+	return infos
+}
+
+/**
+ * CreateOrder 发起在线充值
+ *
+ * 业务流程：
+ * 1. 校验金额和支付通道
+ * 2. 生成支付单（先落库，保证回调到达时能查到）
+ * 3. 向通道下单，保存通道交易号和支付地址；下单失败时支付单置为失败
+ *
+ * @param userID uint - 用户ID
+ * @param providerName string - 支付通道名称
+ * @param amount float64 - 充值金额
+ * @return (*model.PaymentOrder, error)
+ */
+func (s *PaymentService) CreateOrder(userID uint, providerName string, amount float64) (*model.PaymentOrder, error) {
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 {
+		return nil, errors.New("充值金额必须大于0")
+	}
+	provider, ok := s.provider(providerName)
+	if !ok {
+		return nil, errors.New("支付通道不可用")
+	}
+
+	cfg := s.ctx.Config.Payment
+	expireMinutes := cfg.ExpireMinutes
+	if expireMinutes <= 0 {
+		expireMinutes = defaultPaymentExpireMinutes
+	}
+
+	order := &model.PaymentOrder{
+		OrderNo:  newPaymentOrderNo(userID),
+		UserID:   userID,
+		Provider: provider.Name(),
+		Amount:   amount,
+		Status:   model.PaymentStatusPending,
+		ExpireAt: time.Now().Add(time.Duration(expireMinutes) * time.Minute),
+	}
+	if err := s.repo.Create(order); err != nil {
+		return nil, fmt.Errorf("创建支付单失败: %v", err)
+	}
+
+	result, err := provider.CreateOrder(payment.OrderRequest{
+		OrderNo:   order.OrderNo,
+		Amount:    amount,
+		Subject:   "定金充值",
+		NotifyURL: strings.TrimRight(cfg.CallbackBaseURL, "/") + "/api/v1/payments/callback/" + provider.Name(),
+		ReturnURL: cfg.ReturnURL,
+		ExpireAt:  order.ExpireAt,
+	})
+	if err != nil {
+		order.Status = model.PaymentStatusFailed
+		order.Remark = err.Error()
+		if uerr := s.repo.Update(order); uerr != nil {
+			log.Printf("[Payment] 更新支付单失败: %s, %v", order.OrderNo, uerr)
+		}
+		log.Printf("[Payment] 通道下单失败: %s, 通道=%s, %v", order.OrderNo, provider.Name(), err)
+		return nil, errors.New("支付通道下单失败，请稍后重试或更换支付方式")
+	}
+
+	order.TradeNo = result.TradeNo
+	order.PayURL = result.PayURL
+	if err := s.repo.Update(order); err != nil {
+		return nil, fmt.Errorf("更新支付单失败: %v", err)
+	}
+
+	log.Printf("[Payment] 用户 %d 发起在线充值: %s, 通道=%s, 金额=%.2f", userID, order.OrderNo, provider.Name(), amount)
+	return order, nil
+}
+
+/**
+ * HandleCallback 处理通道异步回调
+ *
+ * @param providerName string - 回调路径中的通道名称
+ * @param params map[string]string - 回调参数
+ * @return error - 返回错误时通道应重试推送
+ */
+func (s *PaymentService) HandleCallback(providerName string, params map[string]string) error {
+	provider, ok := s.provider(providerName)
+	if !ok {
+		return errors.New("支付通道不存在")
+	}
+	n, err := provider.VerifyNotification(params)
+	if err != nil {
+		log.Printf("[Payment] 回调校验失败: 通道=%s, 支付单=%s, %v", providerName, params["order_no"], err)
+		return err
+	}
+	_, err = s.settle(provider, n, model.PaymentNotifyCallback)
+	return err
+}
+
+/**
+ * AckBody 回调处理成功后返回给通道的应答内容
+ *
+ * @param providerName string - 支付通道名称
+ * @return string
+ */
+func (s *PaymentService) AckBody(providerName string) string {
+	if p, ok := s.provider(providerName); ok {
+		return p.AckBody()
+	}
+	return "success"
+}
+
+/**
+ * QueryOrder 主动向通道查单（已支付则入账，超过有效期仍未支付则置为过期）
+ *
+ * @param orderNo string - 支付单号
+ * @return (*model.PaymentOrder, error)
+ */
+func (s *PaymentService) QueryOrder(orderNo string) (*model.PaymentOrder, error) {
+	order, err := s.repo.FindByOrderNo(orderNo)
+	if err != nil {
+		return nil, errors.New("支付单不存在")
+	}
+	if !order.IsPending() {
+		return order, nil
+	}
+
+	provider, ok := s.provider(order.Provider)
+	if !ok {
+		if time.Now().After(order.ExpireAt) {
+			return s.expire(orderNo, "支付通道已停用")
+		}
+		return nil, errors.New("支付通道不可用")
+	}
+
+	n, queryErr := provider.QueryOrder(orderNo)
+	now := time.Now()
+	if err := s.ctx.DB.Model(&model.PaymentOrder{}).Where("id = ?", order.ID).
+		Updates(map[string]interface{}{"query_count": order.QueryCount + 1, "last_query_at": now}).Error; err != nil {
+		log.Printf("[Payment] 更新查单记录失败: %s, %v", orderNo, err)
+	}
+	if queryErr != nil {
+		log.Printf("[Payment] 查单失败: %s, 通道=%s, %v", orderNo, order.Provider, queryErr)
+		return nil, fmt.Errorf("查单失败: %v", queryErr)
+	}
+
+	if n.Status == payment.TradeStatusPending {
+		if now.After(order.ExpireAt) {
+			return s.expire(orderNo, "超过有效期未支付")
+		}
+		return s.repo.FindByOrderNo(orderNo)
+	}
+	return s.settle(provider, n, model.PaymentNotifyQuery)
+}
+
+/**
+ * QueryStuckOrders 对超过查单等待时间仍未收到回调的支付单主动查单（定时任务调用）
+ *
+ * @return void
+ */
+func (s *PaymentService) QueryStuckOrders() {
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		return
+	}
+
+	after := s.ctx.Config.Payment.QueryAfterSeconds
+	if after <= 0 {
+		after = defaultPaymentQueryAfter
+	}
+	before := time.Now().Add(-time.Duration(after) * time.Second)
+
+	orders, err := s.repo.FindStuck(before, before, paymentQueryBatchSize)
+	if err != nil {
+		log.Printf("[Payment] 查询待查单支付单失败: %v", err)
+		return
+	}
+	for _, order := range orders {
+		// 查单失败已记录日志，下一轮重试
+		s.QueryOrder(order.OrderNo)
+	}
+}
+
+/**
+ * MockPay 模拟支付（仅模拟通道）
+ *
+ * @param orderNo string - 支付单号
+ * @param userID uint - 操作用户ID
+ * @param isAdmin bool - 是否管理员（管理员可操作任意支付单）
+ * @param amount float64 - 实付金额（<=0 按下单金额）
+ * @param notify bool - 是否推送回调（false 模拟回调丢失，由定时查单补单）
+ * @return (*model.PaymentOrder, error)
+ */
+func (s *PaymentService) MockPay(orderNo string, userID uint, isAdmin bool, amount float64, notify bool) (*model.PaymentOrder, error) {
+	order, err := s.repo.FindByOrderNo(orderNo)
+	if err != nil || (!isAdmin && order.UserID != userID) {
+		return nil, errors.New("支付单不存在")
+	}
+	provider, ok := s.provider(order.Provider)
+	if !ok {
+		return nil, errors.New("支付通道不可用")
+	}
+	mock, ok := provider.(*payment.MockProvider)
+	if !ok {
+		return nil, errors.New("该支付单不是模拟通道，不能模拟支付")
+	}
+	if !order.IsPending() {
+		return nil, fmt.Errorf("支付单状态不允许支付（当前状态: %s）", order.Status)
+	}
+
+	params, err := mock.Pay(orderNo, amount)
+	if err != nil {
+		return nil, err
+	}
+	if notify {
+		if err := s.HandleCallback(mock.Name(), params); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindByOrderNo(orderNo)
+}
+
+/**
+ * GetUserOrders 查询用户的支付单
+ *
+ * @param userID uint - 用户ID
+ * @param limit int - 查询数量限制
+ * @param offset int - 偏移量
+ * @return ([]*model.PaymentOrder, int64, error)
+ */
+func (s *PaymentService) GetUserOrders(userID uint, limit, offset int) ([]*model.PaymentOrder, int64, error) {
+	return s.repo.FindByUserID(userID, limit, offset)
+}
+
+/**
+ * GetUserOrder 查询用户的单个支付单
+ *
+ * @param orderNo string - 支付单号
+ * @param userID uint - 用户ID
+ * @return (*model.PaymentOrder, error)
+ */
+func (s *PaymentService) GetUserOrder(orderNo string, userID uint) (*model.PaymentOrder, error) {
+	order, err := s.repo.FindByOrderNo(orderNo)
+	if err != nil || order.UserID != userID {
+		return nil, errors.New("支付单不存在")
+	}
+	return order, nil
+}
+
+/**
+ * GetOrders 管理员查询支付单
+ *
+ * @param status string - 状态（为空不筛选）
+ * @param limit int - 查询数量限制
+ * @param offset int - 偏移量
+ * @return ([]*model.PaymentOrder, int64, error)
+ */
+func (s *PaymentService) GetOrders(status string, limit, offset int) ([]*model.PaymentOrder, int64, error) {
+	return s.repo.FindByStatus(status, limit, offset)
+}
+
+/**
+ * ResolveAbnormal 管理员核查金额异常的支付单
+ *
+ * 处理方式：
+ * - credit：按通道确认的实付金额生成充值记录并入账（超过双人审批阈值的同样需超级管理员复核）
+ * - close：不入账关闭（如已与客户核实并原路退款）
+ *
+ * @param orderNo string - 支付单号
+ * @param adminID uint - 核查管理员ID
+ * @param action string - 处理方式（credit/close）
+ * @param note string - 核查说明
+ * @return (*model.PaymentOrder, error)
+ */
+func (s *PaymentService) ResolveAbnormal(orderNo string, adminID uint, action, note string) (*model.PaymentOrder, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.New("请填写核查说明")
+	}
+	if action != PaymentResolveCredit && action != PaymentResolveClose {
+		return nil, errors.New("不支持的处理方式")
+	}
+
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := repository.NewPaymentRepository(tx).LockByOrderNo(orderNo)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("支付单不存在")
+	}
+	if !order.IsAbnormal() {
+		tx.Rollback()
+		return nil, fmt.Errorf("支付单状态不允许核查（当前状态: %s）", order.Status)
+	}
+
+	now := time.Now()
+	var fundLog *model.FundLog
+	if action == PaymentResolveCredit {
+		if order.PaidAmount <= 0 {
+			tx.Rollback()
+			return nil, errors.New("实付金额无效，不能入账")
+		}
+		creditNote := fmt.Sprintf("支付单 %s 金额异常（下单 %.2f，实付 %.2f），管理员#%d 核查后按实付金额入账：%s",
+			order.OrderNo, order.Amount, order.PaidAmount, adminID, note)
+		deposit, credited, err := s.depositSvc.CreditOnlineDeposit(tx, order.UserID, order.PaidAmount, order.Provider, order.OrderNo, creditNote)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		fundLog = credited
+		order.Status = model.PaymentStatusPaid
+		order.DepositID = deposit.ID
+		if fundLog == nil {
+			order.Remark = fmt.Sprintf("%s；核查后按实付金额入账，充值申请 #%d 待超级管理员复核", order.Remark, deposit.ID)
+		}
+	} else {
+		order.Status = model.PaymentStatusClosed
+	}
+	order.ResolverID = adminID
+	order.ResolveNote = note
+	order.ResolvedAt = &now
+
+	if err := tx.Save(order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("更新支付单失败")
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("事务提交失败")
+	}
+
+	log.Printf("[Payment] 金额异常支付单已核查: %s, 处理=%s, 管理员=%d, 说明=%s", order.OrderNo, action, adminID, note)
+	if order.Status == model.PaymentStatusPaid {
+		s.notifySettled(order, fundLog, "resolve")
+	}
+	return order, nil
+}
+
+/**
+ * settle 根据通道确认的支付结果更新支付单并入账
+ *
+ * 业务流程：
+ * 1. 事务内锁定支付单，已入账或已标记异常的直接返回（幂等）
+ * 2. 支付失败：待支付单置为失败
+ * 3. 支付成功：核对实付金额，不一致置为异常；一致则生成充值记录、记录系统审批并入账
+ * 4. 提交后通知客户（入账）或管理员（异常）
+ */
+func (s *PaymentService) settle(provider payment.Provider, n *payment.Notification, source string) (*model.PaymentOrder, error) {
+	if n.Status == payment.TradeStatusPending {
+		return s.repo.FindByOrderNo(n.OrderNo)
+	}
+
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := repository.NewPaymentRepository(tx).LockByOrderNo(n.OrderNo)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("支付单不存在")
+	}
+	if order.Provider != provider.Name() {
+		tx.Rollback()
+		return nil, errors.New("支付单与支付通道不匹配")
+	}
+	if order.Status == model.PaymentStatusPaid || order.Status == model.PaymentStatusAbnormal {
+		tx.Rollback()
+		return order, nil
+	}
+
+	now := time.Now()
+	if n.TradeNo != "" {
+		order.TradeNo = n.TradeNo
+	}
+	order.NotifySource = source
+
+	var fundLog *model.FundLog
+	switch {
+	case n.Status == payment.TradeStatusFailed:
+		if !order.IsPending() {
+			tx.Rollback()
+			return order, nil
+		}
+		order.Status = model.PaymentStatusFailed
+		order.Remark = "支付通道返回支付失败"
+	case math.Abs(n.Amount-order.Amount) >= paymentAmountTolerance:
+		order.Status = model.PaymentStatusAbnormal
+		order.PaidAmount = n.Amount
+		order.PaidAt = &now
+		order.Remark = fmt.Sprintf("实付金额 %.2f 与下单金额 %.2f 不一致", n.Amount, order.Amount)
+	default:
+		// 过期/失败后通道仍确认支付成功的（用户在过期前已付款），同样入账
+		note := fmt.Sprintf("支付通道 %s 已确认到账，支付单 %s", order.Provider, order.OrderNo)
+		deposit, credited, err := s.depositSvc.CreditOnlineDeposit(tx, order.UserID, order.Amount, order.Provider, order.OrderNo, note)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		fundLog = credited
+		order.Status = model.PaymentStatusPaid
+		order.PaidAmount = n.Amount
+		order.PaidAt = &now
+		order.DepositID = deposit.ID
+		order.Remark = ""
+		if fundLog == nil {
+			order.Remark = fmt.Sprintf("金额超过双人审批阈值，充值申请 #%d 待超级管理员复核后入账", deposit.ID)
+		}
+	}
+
+	if err := tx.Save(order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("更新支付单失败")
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("事务提交失败")
+	}
+
+	s.notifySettled(order, fundLog, source)
+	return order, nil
+}
+
+/**
+ * notifySettled 支付单处理完成后通知客户（入账/待复核）或管理员（金额异常）
+ */
+func (s *PaymentService) notifySettled(order *model.PaymentOrder, fundLog *model.FundLog, source string) {
+	switch {
+	case order.Status == model.PaymentStatusPaid && fundLog == nil:
+		s.notiSvc.SendFundNotification(order.UserID, "充值待复核",
+			fmt.Sprintf("您的在线充值 %.2f 元已收到，金额较大需人工复核，复核通过后入账", order.PaidAmount))
+		s.notiSvc.SendSystemNotificationToAdmins("大额充值待复核",
+			fmt.Sprintf("在线支付单 %s（客户ID %d，%.2f 元）已到账，充值申请 #%d 需超级管理员复核", order.OrderNo, order.UserID, order.PaidAmount, order.DepositID), "")
+		log.Printf("[Payment] 在线充值待复核: %s, 用户=%d, 金额=%.2f, 来源=%s", order.OrderNo, order.UserID, order.PaidAmount, source)
+	case order.Status == model.PaymentStatusPaid:
+		s.notiSvc.SendFundNotification(order.UserID, "充值成功",
+			fmt.Sprintf("您的在线充值已到账\n充值金额：%.2f 元\n当前可用定金：%.2f 元", order.PaidAmount, fundLog.AvailableAfter))
+		log.Printf("[Payment] 在线充值入账: %s, 用户=%d, 金额=%.2f, 来源=%s", order.OrderNo, order.UserID, order.PaidAmount, source)
+	case order.Status == model.PaymentStatusAbnormal:
+		s.notiSvc.SendSystemNotificationToAdmins("在线支付金额异常",
+			fmt.Sprintf("支付单 %s（客户ID %d）%s，未自动入账，请核查", order.OrderNo, order.UserID, order.Remark), "")
+		log.Printf("[Payment] 支付金额异常: %s, %s", order.OrderNo, order.Remark)
+	}
+}
+
+/**
+ * expire 将仍待支付的支付单置为过期
+ */
+func (s *PaymentService) expire(orderNo, remark string) (*model.PaymentOrder, error) {
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := repository.NewPaymentRepository(tx).LockByOrderNo(orderNo)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("支付单不存在")
+	}
+	if !order.IsPending() {
+		tx.Rollback()
+		return order, nil
+	}
+	order.Status = model.PaymentStatusExpired
+	order.Remark = remark
+	if err := tx.Save(order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("更新支付单失败")
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("事务提交失败")
+	}
+	return order, nil
+}
+
+/**
+ * provider 按名称查找支付通道
+ */
+func (s *PaymentService) provider(name string) (payment.Provider, bool) {
+	for _, p := range defaultPaymentProviders {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+/**
+ * newPaymentOrderNo 生成支付单号：PAY + 时间 + 用户ID + 随机数
+ */
+func newPaymentOrderNo(userID uint) string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("PAY%s%d%d", time.Now().Format("20060102150405"), userID, time.Now().UnixNano()%100000000)
+	}
+	return fmt.Sprintf("PAY%s%d%s", time.Now().Format("20060102150405"), userID, hex.EncodeToString(buf))
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/config"
+	"suxin/internal/pkg/payment"
+)
+
+func TestPaymentServiceHandleCallback(t *testing.T) {
+	const (
+		providerName = "mockpay"
+		merchantID   = "M1001"
+		secret       = "test-secret"
+	)
+
+	tests := []struct {
+		name      string
+		threshold string // 充值双人审批阈值（为空不配置）
+		amount    string // 回调实付金额
+		status    string
+		merchant  string
+		provider  string // 回调路径中的通道名称（为空为 providerName）
+		tamper    bool   // 签名后篡改金额
+		repeat    bool   // 重复推送同一回调
+
+		wantErr     bool
+		wantStatus  string
+		wantBalance float64
+	}{
+		{name: "paid and credited", amount: "100.00", status: "SUCCESS",
+			wantStatus: model.PaymentStatusPaid, wantBalance: 100},
+		{name: "duplicate callback credits once", amount: "100.00", status: "SUCCESS", repeat: true,
+			wantStatus: model.PaymentStatusPaid, wantBalance: 100},
+		{name: "sub-cent rounding accepted", amount: "100.001", status: "SUCCESS",
+			wantStatus: model.PaymentStatusPaid, wantBalance: 100},
+		{name: "bad signature rejected", amount: "100.00", status: "SUCCESS", tamper: true,
+			wantErr: true, wantStatus: model.PaymentStatusPending},
+		{name: "merchant mismatch rejected", amount: "100.00", status: "SUCCESS", merchant: "M9999",
+			wantErr: true, wantStatus: model.PaymentStatusPending},
+		{name: "unknown provider rejected", amount: "100.00", status: "SUCCESS", provider: "other",
+			wantErr: true, wantStatus: model.PaymentStatusPending},
+		{name: "amount mismatch marked abnormal", amount: "10.00", status: "SUCCESS",
+			wantStatus: model.PaymentStatusAbnormal},
+		{name: "failed payment", amount: "100.00", status: "FAIL",
+			wantStatus: model.PaymentStatusFailed},
+		{name: "pending status ignored", amount: "100.00", status: "PENDING",
+			wantStatus: model.PaymentStatusPending},
+		{name: "above dual approval threshold awaits review", threshold: "50", amount: "100.00", status: "SUCCESS",
+			wantStatus: model.PaymentStatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			provider := payment.NewMockProvider(config.PaymentProviderConfig{
+				Name: providerName, Type: payment.ProviderTypeMock, MerchantID: merchantID, Secret: secret,
+			})
+			SetDefaultPaymentProviders([]payment.Provider{provider})
+			t.Cleanup(func() { SetDefaultPaymentProviders(nil) })
+			if tt.threshold != "" {
+				setTestConfig(t, ctx, model.ConfigKeyDepositDualApprovalAmount, tt.threshold)
+			}
+
+			user := createTestUser(t, ctx, &model.User{})
+			svc := NewPaymentService(ctx)
+			order, err := svc.CreateOrder(user.ID, providerName, 100)
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+
+			merchant := merchantID
+			if tt.merchant != "" {
+				merchant = tt.merchant
+			}
+			params := map[string]string{
+				"merchant_id": merchant,
+				"order_no":    order.OrderNo,
+				"trade_no":    order.TradeNo,
+				"amount":      tt.amount,
+				"status":      tt.status,
+				"timestamp":   strconv.FormatInt(time.Now().Unix(), 10),
+			}
+			params["sign"] = payment.Sign(params, secret)
+			if tt.tamper {
+				params["amount"] = "1000.00"
+			}
+			name := providerName
+			if tt.provider != "" {
+				name = tt.provider
+			}
+
+			err = svc.HandleCallback(name, params)
+			if tt.repeat && err == nil {
+				err = svc.HandleCallback(name, params)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HandleCallback err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			stored, err := svc.GetUserOrder(order.OrderNo, user.ID)
+			if err != nil {
+				t.Fatalf("GetUserOrder: %v", err)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s (remark %q)", stored.Status, tt.wantStatus, stored.Remark)
+			}
+
+			var storedUser model.User
+			ctx.DB.First(&storedUser, user.ID)
+			if storedUser.AvailableDeposit != tt.wantBalance {
+				t.Errorf("available = %.2f, want %.2f", storedUser.AvailableDeposit, tt.wantBalance)
+			}
+			var deposits int64
+			ctx.DB.Model(&model.DepositRequest{}).Where("user_id = ?", user.ID).Count(&deposits)
+			wantDeposits := int64(0)
+			if tt.wantStatus == model.PaymentStatusPaid {
+				wantDeposits = 1
+			}
+			if deposits != wantDeposits {
+				t.Errorf("deposit requests = %d, want %d", deposits, wantDeposits)
+			}
+		})
+	}
+}
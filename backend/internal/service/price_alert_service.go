@@ -0,0 +1,531 @@
+/**
+ * 价格提醒服务
+ *
+ * 用途：
+ * - 客户价格提醒的增删改查（每个用户启用数量有上限）
+ * - 接收行情代理分发的Tick，判断提醒是否触发，并通过站内通知和通知WebSocket推送
+ *
+ * 触发规则：
+ * - above：价格 ≥ 目标价；below：价格 ≤ 目标价（价格取客户看到的销售价，即上游销售价加平台买入点差）
+ * - percent_move：相对当日开盘价（同样计入点差）的涨跌幅绝对值 ≥ 阈值，重复提醒每天最多触发一次
+ * - 一次性提醒触发后自动停用；重复提醒触发后需价格回到目标价另一侧（留0.1%回撤）才会再次触发
+ *
+ * 说明：
+ * - 启用中的提醒缓存在内存，增删改后立即刷新，并每30秒从数据库重新加载（兼容多实例修改）
+ * - 多实例部署时只有主实例判断触发，避免重复通知
+ * - 行情接收协程只把Tick放入队列，加载提醒、判断触发和写库都在独立的处理协程中完成（队列满时丢弃并记录日志）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+const (
+	defaultPriceAlertMaxPerUser = 20               // 默认每个用户最多启用的提醒数
+	defaultPriceAlertSymbol     = "AU9999"         // 默认品种
+	maxPriceAlertPercent        = 50.0             // 涨跌幅阈值上限（%）
+	priceAlertReloadInterval    = 30 * time.Second // 提醒缓存重新加载间隔
+	priceAlertRearmBand         = 0.001            // 重复提醒重新待触发的回撤比例
+	priceAlertTickQueueSize     = 1024             // Tick队列长度
+)
+
+/**
+ * PriceAlertInput 创建/修改价格提醒参数
+ */
+type PriceAlertInput struct {
+	Symbol      string  `json:"symbol"`
+	Type        string  `json:"type" binding:"required"`
+	TargetPrice float64 `json:"target_price"`
+	Percent     float64 `json:"percent"`
+	Recurring   bool    `json:"recurring"`
+	Note        string  `json:"note" binding:"max=100"`
+}
+
+/**
+ * PriceAlertService 价格提醒服务
+ */
+type PriceAlertService struct {
+	ctx        *appctx.AppContext
+	repo       *repository.PriceAlertRepository
+	configRepo *repository.ConfigRepository
+	notiSvc    *NotificationService
+	spreadSvc  *QuoteSpreadService
+
+	ticks   chan []*model.QuoteTick // 待判断的Tick（行情接收协程写入，处理协程读取）
+	dropped int64                   // 队列满时丢弃的批次数（仅用于日志）
+
+	mu       sync.Mutex
+	alerts   map[string][]*model.PriceAlert // 启用中的提醒（按品种）
+	loadedAt time.Time
+	dirty    bool
+}
+
+var defaultPriceAlert *PriceAlertService
+
+/**
+ * SetDefaultPriceAlert 设置全局价格提醒服务（由main注入，负责行情触发判断）
+ */
+func SetDefaultPriceAlert(s *PriceAlertService) {
+	defaultPriceAlert = s
+}
+
+/**
+ * DefaultPriceAlert 获取全局价格提醒服务
+ */
+func DefaultPriceAlert() *PriceAlertService {
+	return defaultPriceAlert
+}
+
+/**
+ * NewPriceAlertService 创建价格提醒服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *PriceAlertService
+ */
+func NewPriceAlertService(ctx *appctx.AppContext) *PriceAlertService {
+	return &PriceAlertService{
+		ctx:        ctx,
+		repo:       repository.NewPriceAlertRepository(ctx.DB),
+		configRepo: repository.NewConfigRepository(ctx.DB),
+		notiSvc:    NewNotificationService(ctx),
+		spreadSvc:  NewQuoteSpreadService(ctx),
+		ticks:      make(chan []*model.QuoteTick, priceAlertTickQueueSize),
+		alerts:     make(map[string][]*model.PriceAlert),
+		dirty:      true,
+	}
+}
+
+/**
+ * getMaxPerUser 读取每个用户最多启用的提醒数
+ */
+func (s *PriceAlertService) getMaxPerUser() int64 {
+	max := int64(defaultPriceAlertMaxPerUser)
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyPriceAlertMaxPerUser); err == nil && config != nil {
+		var v int64
+		if _, err := fmt.Sscanf(config.Value, "%d", &v); err == nil && v > 0 {
+			max = v
+		}
+	}
+	return max
+}
+
+/**
+ * checkCap 检查用户启用中的提醒是否已达上限
+ */
+func (s *PriceAlertService) checkCap(userID uint) error {
+	count, err := s.repo.CountEnabledByUser(userID)
+	if err != nil {
+		return err
+	}
+	if max := s.getMaxPerUser(); count >= max {
+		return fmt.Errorf("最多只能启用%d个价格提醒", max)
+	}
+	return nil
+}
+
+/**
+ * applyPriceAlertInput 校验参数并写入提醒
+ */
+func applyPriceAlertInput(alert *model.PriceAlert, input PriceAlertInput) error {
+	symbol := strings.ToUpper(strings.TrimSpace(input.Symbol))
+	if symbol == "" {
+		symbol = defaultPriceAlertSymbol
+	}
+	if len(symbol) > 20 {
+		return errors.New("品种代码不合法")
+	}
+
+	switch input.Type {
+	case model.PriceAlertTypeAbove, model.PriceAlertTypeBelow:
+		if input.TargetPrice <= 0 {
+			return errors.New("请设置目标价")
+		}
+		input.Percent = 0
+	case model.PriceAlertTypePercentMove:
+		if input.Percent <= 0 || input.Percent > maxPriceAlertPercent {
+			return fmt.Errorf("涨跌幅阈值须在0-%.0f%%之间", maxPriceAlertPercent)
+		}
+		input.TargetPrice = 0
+	default:
+		return errors.New("不支持的提醒类型")
+	}
+
+	alert.Symbol = symbol
+	alert.Type = input.Type
+	alert.TargetPrice = input.TargetPrice
+	alert.Percent = input.Percent
+	alert.Recurring = input.Recurring
+	alert.Note = strings.TrimSpace(input.Note)
+	return nil
+}
+
+/**
+ * initialArmed 根据当前价格决定提醒是否待触发
+ *
+ * 设置时价格已在目标价另一侧（如已高于上涨提醒的目标价）则不立即触发，等价格回到目标价以内后再待触发
+ */
+func initialArmed(alert *model.PriceAlert) bool {
+	quoteSvc := DefaultQuoteService()
+	if quoteSvc == nil {
+		return true
+	}
+	quotes := quoteSvc.GetLatestQuotes([]string{alert.Symbol})
+	if len(quotes) == 0 || quotes[0].Stale {
+		return true
+	}
+	price := quotes[0].Ask
+	switch alert.Type {
+	case model.PriceAlertTypeAbove:
+		return price < alert.TargetPrice
+	case model.PriceAlertTypeBelow:
+		return price > alert.TargetPrice
+	}
+	return true
+}
+
+/**
+ * Invalidate 标记提醒缓存需要重新加载
+ *
+ * @return void
+ */
+func (s *PriceAlertService) Invalidate() {
+	s.mu.Lock()
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+/**
+ * invalidatePriceAlerts 通知负责触发判断的全局服务刷新缓存
+ */
+func invalidatePriceAlerts() {
+	if d := DefaultPriceAlert(); d != nil {
+		d.Invalidate()
+	}
+}
+
+/**
+ * Create 创建价格提醒
+ *
+ * @param userID uint - 用户ID
+ * @param input PriceAlertInput - 提醒参数
+ * @return (*model.PriceAlert, error)
+ */
+func (s *PriceAlertService) Create(userID uint, input PriceAlertInput) (*model.PriceAlert, error) {
+	alert := &model.PriceAlert{UserID: userID, Enabled: true}
+	if err := applyPriceAlertInput(alert, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkCap(userID); err != nil {
+		return nil, err
+	}
+	alert.Armed = initialArmed(alert)
+
+	if err := s.repo.Create(alert); err != nil {
+		return nil, err
+	}
+	invalidatePriceAlerts()
+	return alert, nil
+}
+
+/**
+ * Update 修改价格提醒（修改后重新待触发）
+ *
+ * @param userID uint - 用户ID
+ * @param id uint - 提醒ID
+ * @param input PriceAlertInput - 提醒参数
+ * @return (*model.PriceAlert, error)
+ */
+func (s *PriceAlertService) Update(userID, id uint, input PriceAlertInput) (*model.PriceAlert, error) {
+	alert, err := s.repo.FindByUserAndID(userID, id)
+	if err != nil {
+		return nil, errors.New("价格提醒不存在")
+	}
+	if err := applyPriceAlertInput(alert, input); err != nil {
+		return nil, err
+	}
+	alert.Armed = initialArmed(alert)
+
+	if err := s.repo.Update(alert); err != nil {
+		return nil, err
+	}
+	invalidatePriceAlerts()
+	return alert, nil
+}
+
+/**
+ * SetEnabled 启用/停用价格提醒
+ *
+ * @param userID uint - 用户ID
+ * @param id uint - 提醒ID
+ * @param enabled bool - 是否启用
+ * @return (*model.PriceAlert, error)
+ */
+func (s *PriceAlertService) SetEnabled(userID, id uint, enabled bool) (*model.PriceAlert, error) {
+	alert, err := s.repo.FindByUserAndID(userID, id)
+	if err != nil {
+		return nil, errors.New("价格提醒不存在")
+	}
+	if alert.Enabled == enabled {
+		return alert, nil
+	}
+	if enabled {
+		if err := s.checkCap(userID); err != nil {
+			return nil, err
+		}
+		alert.Armed = initialArmed(alert)
+	}
+	alert.Enabled = enabled
+
+	if err := s.repo.Update(alert); err != nil {
+		return nil, err
+	}
+	invalidatePriceAlerts()
+	return alert, nil
+}
+
+/**
+ * Delete 删除价格提醒
+ *
+ * @param userID uint - 用户ID
+ * @param id uint - 提醒ID
+ * @return error
+ */
+func (s *PriceAlertService) Delete(userID, id uint) error {
+	alert, err := s.repo.FindByUserAndID(userID, id)
+	if err != nil {
+		return errors.New("价格提醒不存在")
+	}
+	if err := s.repo.Delete(alert); err != nil {
+		return err
+	}
+	invalidatePriceAlerts()
+	return nil
+}
+
+/**
+ * List 查询用户的价格提醒
+ *
+ * @param userID uint - 用户ID
+ * @return ([]*model.PriceAlert, error)
+ */
+func (s *PriceAlertService) List(userID uint) ([]*model.PriceAlert, error) {
+	return s.repo.FindByUser(userID)
+}
+
+/**
+ * GetLimit 获取每个用户最多启用的提醒数
+ *
+ * @return int64
+ */
+func (s *PriceAlertService) GetLimit() int64 {
+	return s.getMaxPerUser()
+}
+
+/**
+ * reload 从数据库重新加载启用中的提醒（调用方持有锁）
+ */
+func (s *PriceAlertService) reload(now time.Time) {
+	alerts, err := s.repo.FindEnabled()
+	if err != nil {
+		log.Printf("[PriceAlert] ❌ 加载价格提醒失败: %v", err)
+		return
+	}
+	bySymbol := make(map[string][]*model.PriceAlert)
+	for _, alert := range alerts {
+		bySymbol[alert.Symbol] = append(bySymbol[alert.Symbol], alert)
+	}
+	s.alerts = bySymbol
+	s.loadedAt = now
+	s.dirty = false
+}
+
+/**
+ * OnTicks 接收行情代理分发的Tick，放入队列等待处理协程判断
+ *
+ * 在行情接收协程中调用，不加锁、不访问数据库；队列满时丢弃本批Tick
+ *
+ * @param ticks []*model.QuoteTick - 标准化Tick
+ * @return void
+ */
+func (s *PriceAlertService) OnTicks(ticks []*model.QuoteTick) {
+	// 多实例部署时只有主实例判断触发
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		return
+	}
+
+	select {
+	case s.ticks <- ticks:
+	default:
+		if n := atomic.AddInt64(&s.dropped, 1); n == 1 || n%1000 == 0 {
+			log.Printf("[PriceAlert] ⚠️ 提醒处理队列已满，已丢弃 %d 批Tick", n)
+		}
+	}
+}
+
+/**
+ * Run 处理协程：依次判断队列中的Tick（由main以goroutine启动）
+ *
+ * @return void
+ */
+func (s *PriceAlertService) Run() {
+	for ticks := range s.ticks {
+		s.process(ticks)
+	}
+}
+
+/**
+ * process 判断一批Tick是否触发提醒
+ */
+func (s *PriceAlertService) process(ticks []*model.QuoteTick) {
+	buySpread, _ := s.spreadSvc.GetSpread()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.dirty || now.Sub(s.loadedAt) > priceAlertReloadInterval {
+		s.reload(now)
+	}
+
+	for _, tick := range ticks {
+		alerts := s.alerts[tick.Symbol]
+		if len(alerts) == 0 {
+			continue
+		}
+		// 与行情推送给客户的销售价（Ask）一致
+		price := math.Round((tick.Sell+buySpread)*100) / 100
+		kept := alerts[:0]
+		for _, alert := range alerts {
+			if s.evaluate(alert, price, buySpread, now) {
+				kept = append(kept, alert)
+			}
+		}
+		s.alerts[tick.Symbol] = kept
+	}
+}
+
+/**
+ * evaluate 判断单个提醒（调用方持有锁）
+ *
+ * @return bool - 提醒是否仍然启用
+ */
+func (s *PriceAlertService) evaluate(alert *model.PriceAlert, price, buySpread float64, now time.Time) bool {
+	switch alert.Type {
+	case model.PriceAlertTypeAbove:
+		if alert.Armed && price >= alert.TargetPrice {
+			return s.trigger(alert, price, now,
+				fmt.Sprintf("%s 已涨至 %.2f 元/克，达到您设置的目标价 %.2f", alert.Symbol, price, alert.TargetPrice))
+		}
+		if !alert.Armed && price < alert.TargetPrice*(1-priceAlertRearmBand) {
+			s.rearm(alert)
+		}
+
+	case model.PriceAlertTypeBelow:
+		if alert.Armed && price <= alert.TargetPrice {
+			return s.trigger(alert, price, now,
+				fmt.Sprintf("%s 已跌至 %.2f 元/克，达到您设置的目标价 %.2f", alert.Symbol, price, alert.TargetPrice))
+		}
+		if !alert.Armed && price > alert.TargetPrice*(1+priceAlertRearmBand) {
+			s.rearm(alert)
+		}
+
+	case model.PriceAlertTypePercentMove:
+		history := DefaultQuoteHistory()
+		if history == nil {
+			return true
+		}
+		open, ok := history.DayOpen(alert.Symbol)
+		if !ok || open <= 0 {
+			return true
+		}
+		open = math.Round((open+buySpread)*100) / 100
+		if alert.LastTriggeredAt != nil && sameLocalDay(*alert.LastTriggeredAt, now) {
+			return true
+		}
+		move := (price - open) / open * 100
+		if math.Abs(move) >= alert.Percent {
+			direction := "涨幅"
+			if move < 0 {
+				direction = "跌幅"
+			}
+			return s.trigger(alert, price, now,
+				fmt.Sprintf("%s 当日%s %.2f%%（开盘 %.2f，现价 %.2f 元/克），达到您设置的 %.2f%% 提醒",
+					alert.Symbol, direction, math.Abs(move), open, price, alert.Percent))
+		}
+	}
+	return true
+}
+
+/**
+ * trigger 触发提醒：保存触发记录并推送通知
+ *
+ * @return bool - 提醒是否仍然启用（一次性提醒触发后停用）
+ */
+func (s *PriceAlertService) trigger(alert *model.PriceAlert, price float64, now time.Time, content string) bool {
+	alert.TriggerCount++
+	alert.LastTriggeredAt = &now
+	alert.LastTriggerPrice = price
+	alert.Armed = false
+	if !alert.Recurring {
+		alert.Enabled = false
+	}
+
+	if err := s.repo.UpdateFields(alert.ID, map[string]interface{}{
+		"trigger_count":      alert.TriggerCount,
+		"last_triggered_at":  now,
+		"last_trigger_price": price,
+		"armed":              false,
+		"enabled":            alert.Enabled,
+	}); err != nil {
+		log.Printf("[PriceAlert] ❌ 保存提醒触发记录失败 ID=%d: %v", alert.ID, err)
+	}
+
+	if alert.Note != "" {
+		content = fmt.Sprintf("%s（备注：%s）", content, alert.Note)
+	}
+	userID, alertID := alert.UserID, alert.ID
+	go func() {
+		if _, err := s.notiSvc.SendNotification(userID, model.NotifyTypePriceAlert, model.NotifyLevelInfo,
+			"价格提醒", content, alertID, "price_alert"); err != nil {
+			log.Printf("[PriceAlert] ❌ 发送提醒通知失败 ID=%d: %v", alertID, err)
+		}
+	}()
+
+	log.Printf("[PriceAlert] 🔔 价格提醒已触发 ID=%d UserID=%d %s %.2f", alertID, userID, alert.Symbol, price)
+	return alert.Enabled
+}
+
+/**
+ * rearm 重复提醒重新待触发
+ */
+func (s *PriceAlertService) rearm(alert *model.PriceAlert) {
+	alert.Armed = true
+	if err := s.repo.UpdateFields(alert.ID, map[string]interface{}{"armed": true}); err != nil {
+		log.Printf("[PriceAlert] ❌ 更新提醒状态失败 ID=%d: %v", alert.ID, err)
+	}
+}
+
+/**
+ * sameLocalDay 判断两个时间是否为同一自然日（本地时区）
+ */
+func sameLocalDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+	return ay == by && am == bm && ad == bd
+}
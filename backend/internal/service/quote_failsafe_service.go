@@ -0,0 +1,407 @@
+/**
+ * 行情保护（降级模式）服务
+ *
+ * 用途：
+ * - 监控行情新鲜度，行情过期时进入平台级降级模式
+ * - 降级期间禁止下单和结算，风控强平暂停
+ * - 通过API和WebSocket对外暴露当前状态
+ * - 进入/恢复时通知管理员，并记录每一段降级时间
+ *
+ * 说明：
+ * - 降级状态由每个实例按本地行情独立判定；降级记录和管理员通知只由主实例写入，避免多实例重复
+ * - 交易检查除读取降级状态外，同时按最新行情时间即时判断，不依赖定时任务的检查间隔
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+// 默认行情过期阈值（秒）
+const defaultQuoteStaleSeconds = 30
+
+// ErrMarketDegraded 降级模式下拒绝交易操作时返回的错误
+var ErrMarketDegraded = errors.New("行情数据中断，系统已进入保护模式，暂停下单和结算，请稍后再试")
+
+/**
+ * MarketStatusBroadcaster 行情状态广播接口
+ * 由行情WebSocket代理实现，用于向前端推送横幅状态
+ */
+type MarketStatusBroadcaster interface {
+	BroadcastStatus(message []byte)
+}
+
+/**
+ * MarketStatus 平台行情状态
+ */
+type MarketStatus struct {
+	Type             string     `json:"type"`                    // 固定为 market_status
+	Status           string     `json:"status"`                  // normal/degraded
+	Degraded         bool       `json:"degraded"`                // 是否降级
+	Message          string     `json:"message,omitempty"`       // 横幅提示文案
+	Since            *time.Time `json:"since,omitempty"`         // 进入降级时间
+	LastQuoteAt      *time.Time `json:"last_quote_at,omitempty"` // 最后行情时间
+	QuoteSource      string     `json:"quote_source,omitempty"`  // 行情来源（行情源名称）
+	QuoteAgeSeconds  int        `json:"quote_age_seconds"`       // 行情延迟（秒）
+	ThresholdSeconds int        `json:"threshold_seconds"`       // 过期阈值（秒）
+}
+
+/**
+ * QuoteFailsafeService 行情保护服务
+ */
+type QuoteFailsafeService struct {
+	ctx         *appctx.AppContext
+	quoteHub    QuoteHubInterface
+	broadcaster MarketStatusBroadcaster
+	configRepo  *repository.ConfigRepository
+	outageRepo  *repository.QuoteOutageRepository
+	notiSvc     *NotificationService
+
+	mu        sync.RWMutex
+	degraded  bool
+	since     time.Time
+	outage    *model.QuoteOutage // 本实例作为主实例记录的降级记录
+	threshold time.Duration      // 最近一次读取的过期阈值（交易检查使用，避免每次查库）
+	cleaned   bool               // 成为主实例后是否已关闭遗留的降级记录
+	startedAt time.Time
+}
+
+var defaultQuoteFailsafe *QuoteFailsafeService
+
+/**
+ * SetDefaultQuoteFailsafe 设置全局行情保护服务（由main注入）
+ */
+func SetDefaultQuoteFailsafe(f *QuoteFailsafeService) {
+	defaultQuoteFailsafe = f
+}
+
+/**
+ * DefaultQuoteFailsafe 获取全局行情保护服务
+ */
+func DefaultQuoteFailsafe() *QuoteFailsafeService {
+	return defaultQuoteFailsafe
+}
+
+/**
+ * CheckMarketAvailable 检查当前是否允许交易操作
+ *
+ * 未启用行情保护时（如脚本、单元工具）直接放行；
+ * 除降级状态外按最新行情时间即时判断，定时检查尚未切换状态时也不会以过期价格成交
+ *
+ * @return error - 降级模式或行情已过期时返回 ErrMarketDegraded
+ */
+func CheckMarketAvailable() error {
+	if defaultQuoteFailsafe == nil {
+		return nil
+	}
+	if defaultQuoteFailsafe.IsDegraded() || defaultQuoteFailsafe.isQuoteStale() {
+		return ErrMarketDegraded
+	}
+	return nil
+}
+
+/**
+ * NewQuoteFailsafeService 创建行情保护服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @param quoteHub QuoteHubInterface - 行情代理
+ * @param broadcaster MarketStatusBroadcaster - 状态广播（可为nil）
+ * @return *QuoteFailsafeService
+ */
+func NewQuoteFailsafeService(ctx *appctx.AppContext, quoteHub QuoteHubInterface, broadcaster MarketStatusBroadcaster) *QuoteFailsafeService {
+	s := &QuoteFailsafeService{
+		ctx:         ctx,
+		quoteHub:    quoteHub,
+		broadcaster: broadcaster,
+		configRepo:  repository.NewConfigRepository(ctx.DB),
+		outageRepo:  repository.NewQuoteOutageRepository(ctx.DB),
+		notiSvc:     NewNotificationService(ctx),
+		threshold:   defaultQuoteStaleSeconds * time.Second,
+		startedAt:   time.Now(),
+	}
+	return s
+}
+
+/**
+ * isLeader 当前实例是否负责写降级记录和发送通知（未启用选主时为单实例）
+ */
+func (s *QuoteFailsafeService) isLeader() bool {
+	le := DefaultLeaderElection()
+	return le == nil || le.IsLeader()
+}
+
+/**
+ * closeDanglingOutages 关闭未结束的降级记录（主实例执行）
+ *
+ * 用于原主实例在降级期间退出或切换主实例后，由当前主实例收尾
+ *
+ * @param endedAt time.Time - 结束时间
+ * @param suffix string - 追加到降级原因的说明（为空不追加）
+ */
+func (s *QuoteFailsafeService) closeDanglingOutages(endedAt time.Time, suffix string) {
+	outages, err := s.outageRepo.FindOngoing()
+	if err != nil {
+		log.Printf("[QuoteFailsafe] 查询未结束的降级记录失败: %v", err)
+		return
+	}
+	for _, o := range outages {
+		o.Close(endedAt)
+		o.Reason = o.Reason + suffix
+		if err := s.outageRepo.Update(o); err != nil {
+			log.Printf("[QuoteFailsafe] 关闭降级记录 %d 失败: %v", o.ID, err)
+		}
+	}
+}
+
+/**
+ * isQuoteStale 按最新行情时间即时判断行情是否已过期
+ */
+func (s *QuoteFailsafeService) isQuoteStale() bool {
+	var lastUpdate time.Time
+	if s.quoteHub != nil {
+		_, lastUpdate, _, _ = s.quoteHub.GetLatestPrice()
+	}
+	s.mu.RLock()
+	threshold := s.threshold
+	s.mu.RUnlock()
+	return s.staleAt(time.Now(), lastUpdate, threshold)
+}
+
+/**
+ * staleAt 行情在指定时间是否已过期（启动后尚未收到任何行情时，给予一个阈值的连接宽限期）
+ */
+func (s *QuoteFailsafeService) staleAt(now, lastUpdate time.Time, threshold time.Duration) bool {
+	if lastUpdate.IsZero() {
+		return now.Sub(s.startedAt) > threshold
+	}
+	return now.Sub(lastUpdate) > threshold
+}
+
+/**
+ * getStaleThreshold 获取行情过期阈值（默认30秒）
+ */
+func (s *QuoteFailsafeService) getStaleThreshold() time.Duration {
+	seconds := defaultQuoteStaleSeconds
+	config, err := s.configRepo.FindByKey(model.ConfigKeyQuoteStaleSeconds)
+	if err == nil && config != nil {
+		var v int
+		if _, err := fmt.Sscanf(config.Value, "%d", &v); err == nil && v > 0 {
+			seconds = v
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+/**
+ * Evaluate 评估行情新鲜度并切换降级状态（定时任务调用）
+ *
+ * 业务流程：
+ * 1. 读取最新行情时间
+ * 2. 行情延迟超过阈值：进入降级模式
+ * 3. 降级中收到新行情：自动恢复
+ * 4. 主实例正常状态下关闭遗留的降级记录（如原主实例在降级期间退出）
+ *
+ * @return void
+ */
+func (s *QuoteFailsafeService) Evaluate() {
+	threshold := s.getStaleThreshold()
+
+	var price float64
+	var lastUpdate time.Time
+	if s.quoteHub != nil {
+		price, lastUpdate, _, _ = s.quoteHub.GetLatestPrice()
+	}
+
+	now := time.Now()
+	stale := s.staleAt(now, lastUpdate, threshold)
+	leader := s.isLeader()
+
+	s.mu.Lock()
+	s.threshold = threshold
+	degraded := s.degraded
+	cleanup := leader && !s.cleaned && !stale && !degraded
+	if cleanup || !leader {
+		s.cleaned = leader
+	}
+	s.mu.Unlock()
+
+	if stale && !degraded {
+		s.enterDegraded(price, lastUpdate, threshold, leader)
+	} else if !stale && degraded {
+		s.exitDegraded(price, lastUpdate, leader)
+	} else if cleanup {
+		s.closeDanglingOutages(now, "（主实例切换/服务重启时关闭）")
+	}
+}
+
+/**
+ * enterDegraded 进入降级模式（仅主实例记录降级并通知管理员）
+ */
+func (s *QuoteFailsafeService) enterDegraded(lastPrice float64, lastUpdate time.Time, threshold time.Duration, leader bool) {
+	now := time.Now()
+	reason := fmt.Sprintf("行情超过 %d 秒未更新", int(threshold.Seconds()))
+	if lastUpdate.IsZero() {
+		reason = "服务启动后未收到任何行情"
+	}
+
+	var outage *model.QuoteOutage
+	if leader {
+		outage = &model.QuoteOutage{
+			StartedAt:        now,
+			LastPrice:        lastPrice,
+			ThresholdSeconds: int(threshold.Seconds()),
+			Reason:           reason,
+		}
+		if !lastUpdate.IsZero() {
+			t := lastUpdate
+			outage.LastQuoteAt = &t
+		}
+		if err := s.outageRepo.Create(outage); err != nil {
+			log.Printf("[QuoteFailsafe] 记录降级开始失败: %v", err)
+			outage = nil
+		}
+	}
+
+	s.mu.Lock()
+	s.degraded = true
+	s.since = now
+	s.outage = outage
+	s.mu.Unlock()
+
+	log.Printf("[QuoteFailsafe] 🚨 进入降级模式: %s，暂停下单、结算和风控强平", reason)
+	s.broadcast()
+
+	if !leader {
+		return
+	}
+	content := fmt.Sprintf("%s，系统已自动进入保护模式：暂停客户下单和结算，风控强平暂停。\n最后行情时间：%s\n最后价格：%.2f 元/克",
+		reason, formatQuoteTime(lastUpdate), lastPrice)
+	go s.notiSvc.SendSystemNotificationToAdmins("行情中断告警", content, model.NotifyLevelCritical)
+}
+
+/**
+ * exitDegraded 退出降级模式（仅主实例关闭降级记录并通知管理员）
+ */
+func (s *QuoteFailsafeService) exitDegraded(price float64, lastUpdate time.Time, leader bool) {
+	now := time.Now()
+
+	s.mu.Lock()
+	outage := s.outage
+	since := s.since
+	s.degraded = false
+	s.since = time.Time{}
+	s.outage = nil
+	s.mu.Unlock()
+
+	if outage != nil {
+		outage.Close(now)
+		if err := s.outageRepo.Update(outage); err != nil {
+			log.Printf("[QuoteFailsafe] 记录降级结束失败: %v", err)
+		}
+	}
+
+	duration := now.Sub(since).Round(time.Second)
+	log.Printf("[QuoteFailsafe] ✅ 行情恢复，退出降级模式，持续 %v", duration)
+	s.broadcast()
+
+	if !leader {
+		return
+	}
+	// 降级期间切换了主实例时，原主实例的记录由当前主实例一并关闭
+	s.closeDanglingOutages(now, "")
+
+	content := fmt.Sprintf("行情已恢复，系统退出保护模式，交易恢复正常。\n降级持续：%v\n最新行情时间：%s\n最新价格：%.2f 元/克",
+		duration, formatQuoteTime(lastUpdate), price)
+	go s.notiSvc.SendSystemNotificationToAdmins("行情恢复", content, model.NotifyLevelInfo)
+}
+
+/**
+ * IsDegraded 当前是否处于降级模式
+ *
+ * @return bool
+ */
+func (s *QuoteFailsafeService) IsDegraded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.degraded
+}
+
+/**
+ * GetStatus 获取当前行情状态（横幅数据）
+ *
+ * @return *MarketStatus
+ */
+func (s *QuoteFailsafeService) GetStatus() *MarketStatus {
+	status := &MarketStatus{
+		Type:             "market_status",
+		Status:           model.MarketStatusNormal,
+		ThresholdSeconds: int(s.getStaleThreshold().Seconds()),
+	}
+
+	if s.quoteHub != nil {
+		_, lastUpdate, source, _ := s.quoteHub.GetLatestPrice()
+		if !lastUpdate.IsZero() {
+			t := lastUpdate
+			status.LastQuoteAt = &t
+			status.QuoteSource = source
+			status.QuoteAgeSeconds = int(time.Since(lastUpdate).Seconds())
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.degraded {
+		since := s.since
+		status.Status = model.MarketStatusDegraded
+		status.Degraded = true
+		status.Since = &since
+		status.Message = ErrMarketDegraded.Error()
+	}
+	return status
+}
+
+/**
+ * GetOutages 查询历史降级记录
+ *
+ * @param limit int - 查询数量限制
+ * @param offset int - 偏移量
+ * @return ([]*model.QuoteOutage, int64, error)
+ */
+func (s *QuoteFailsafeService) GetOutages(limit, offset int) ([]*model.QuoteOutage, int64, error) {
+	return s.outageRepo.FindRecent(limit, offset)
+}
+
+/**
+ * broadcast 通过行情WebSocket推送当前状态
+ */
+func (s *QuoteFailsafeService) broadcast() {
+	if s.broadcaster == nil {
+		return
+	}
+	data, err := json.Marshal(s.GetStatus())
+	if err != nil {
+		log.Printf("[QuoteFailsafe] 序列化状态失败: %v", err)
+		return
+	}
+	s.broadcaster.BroadcastStatus(data)
+}
+
+func formatQuoteTime(t time.Time) string {
+	if t.IsZero() {
+		return "无"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,362 @@
+/**
+ * 行情历史服务
+ *
+ * 用途：
+ * - 接收行情代理分发的Tick，按品种聚合 1m/5m/15m/1h/1d K线
+ * - 归档价格变动Tick（审计、重建K线）
+ * - 提供历史K线查询，以及从Tick归档重建K线
+ *
+ * 说明：
+ * - Tick先缓存在内存，由定时任务批量落库
+ * - 行情协程只操作内存；新开K线与库中已有数据的合并在落库定时任务中完成，不阻塞行情分发
+ * - 多实例部署时只有主实例落库，避免重复归档
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+const (
+	defaultCandleLimit = 500  // 默认返回K线数量
+	maxCandleLimit     = 2000 // 单次最多返回K线数量
+	rebuildBatchSize   = 5000 // 重建时每批读取的Tick数量
+)
+
+/**
+ * QuoteHistoryService 行情历史服务
+ */
+type QuoteHistoryService struct {
+	ctx        *appctx.AppContext
+	tickRepo   *repository.QuoteTickRepository
+	candleRepo *repository.QuoteCandleRepository
+
+	mu           sync.Mutex
+	lastTicks    map[string]*model.QuoteTick   // 各品种最近一次价格（用于去重）
+	current      map[string]*model.QuoteCandle // 各品种各周期当前K线（键：品种|周期）
+	pendingTicks []*model.QuoteTick            // 待落库Tick
+	dirty        map[*model.QuoteCandle]bool   // 待落库K线
+	unmerged     map[*model.QuoteCandle]bool   // 尚未与库中同周期数据合并的新开K线
+}
+
+var defaultQuoteHistory *QuoteHistoryService
+
+/**
+ * SetDefaultQuoteHistory 设置全局行情历史服务（由main注入）
+ */
+func SetDefaultQuoteHistory(s *QuoteHistoryService) {
+	defaultQuoteHistory = s
+}
+
+/**
+ * DefaultQuoteHistory 获取全局行情历史服务
+ */
+func DefaultQuoteHistory() *QuoteHistoryService {
+	return defaultQuoteHistory
+}
+
+/**
+ * NewQuoteHistoryService 创建行情历史服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *QuoteHistoryService
+ */
+func NewQuoteHistoryService(ctx *appctx.AppContext) *QuoteHistoryService {
+	return &QuoteHistoryService{
+		ctx:        ctx,
+		tickRepo:   repository.NewQuoteTickRepository(ctx.DB),
+		candleRepo: repository.NewQuoteCandleRepository(ctx.DB),
+		lastTicks:  make(map[string]*model.QuoteTick),
+		current:    make(map[string]*model.QuoteCandle),
+		dirty:      make(map[*model.QuoteCandle]bool),
+		unmerged:   make(map[*model.QuoteCandle]bool),
+	}
+}
+
+func candleKey(symbol, interval string) string {
+	return symbol + "|" + interval
+}
+
+/**
+ * OnTicks 接收行情代理分发的Tick
+ *
+ * 价格未变化的Tick直接忽略；变化的Tick进入归档队列并更新各周期K线
+ *
+ * @param ticks []*model.QuoteTick - 标准化Tick
+ * @return void
+ */
+func (s *QuoteHistoryService) OnTicks(ticks []*model.QuoteTick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tick := range ticks {
+		if last, ok := s.lastTicks[tick.Symbol]; ok && last.Buy == tick.Buy && last.Sell == tick.Sell {
+			continue
+		}
+		s.lastTicks[tick.Symbol] = tick
+		s.pendingTicks = append(s.pendingTicks, tick)
+
+		for _, interval := range model.CandleIntervals {
+			openTime := model.CandleOpenTime(tick.TickAt, interval)
+			key := candleKey(tick.Symbol, interval)
+
+			candle := s.current[key]
+			if candle != nil && candle.OpenTime.Equal(openTime) {
+				candle.Apply(tick.Sell)
+			} else {
+				candle = model.NewQuoteCandle(tick.Symbol, interval, openTime, tick.Sell)
+				s.current[key] = candle
+				s.unmerged[candle] = true
+			}
+			s.dirty[candle] = true
+		}
+	}
+}
+
+/**
+ * DayOpen 获取品种当日开盘价（当日第一个Tick的价格，服务重启后在首次落库时从日K线恢复）
+ *
+ * @param symbol string - 品种代码
+ * @return (float64, bool) - 开盘价、当日是否已有行情
+ */
+func (s *QuoteHistoryService) DayOpen(symbol string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candle := s.current[candleKey(symbol, model.CandleInterval1d)]
+	if candle == nil || !candle.OpenTime.Equal(model.CandleOpenTime(time.Now(), model.CandleInterval1d)) {
+		return 0, false
+	}
+	return candle.Open, true
+}
+
+/**
+ * Flush 将缓存的Tick和K线落库（定时任务调用）
+ *
+ * 新开K线先与库中同周期数据合并（服务重启时该周期可能已有落库数据），合并前不落库，避免覆盖
+ *
+ * @return void
+ */
+func (s *QuoteHistoryService) Flush() {
+	s.mergePersisted()
+
+	s.mu.Lock()
+	ticks := s.pendingTicks
+	s.pendingTicks = nil
+	candles := make([]model.QuoteCandle, 0, len(s.dirty))
+	for candle := range s.dirty {
+		if s.unmerged[candle] {
+			continue
+		}
+		candles = append(candles, *candle)
+		delete(s.dirty, candle)
+	}
+	s.mu.Unlock()
+
+	// 多实例部署时只有主实例落库
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		return
+	}
+
+	if err := s.tickRepo.CreateBatch(ticks); err != nil {
+		log.Printf("[QuoteHistory] ❌ 归档Tick失败（%d条）: %v", len(ticks), err)
+	}
+	for i := range candles {
+		candle := candles[i]
+		candle.ID = 0
+		if err := s.candleRepo.Upsert(&candle); err != nil {
+			log.Printf("[QuoteHistory] ❌ 保存K线失败 %s %s %s: %v",
+				candle.Symbol, candle.Interval, candle.OpenTime.Format("2006-01-02 15:04"), err)
+		}
+	}
+}
+
+/**
+ * mergePersisted 将新开K线与库中同周期数据合并（在落库协程中查询，不持锁访问数据库）
+ */
+func (s *QuoteHistoryService) mergePersisted() {
+	s.mu.Lock()
+	keys := make(map[*model.QuoteCandle]model.QuoteCandle, len(s.unmerged))
+	for candle := range s.unmerged {
+		keys[candle] = *candle
+	}
+	s.mu.Unlock()
+
+	persisted := make(map[*model.QuoteCandle]*model.QuoteCandle, len(keys))
+	failed := make(map[*model.QuoteCandle]bool)
+	for candle, key := range keys {
+		existing, err := s.candleRepo.FindOne(key.Symbol, key.Interval, key.OpenTime)
+		if err != nil {
+			// 下次落库时重试，期间该K线不落库
+			log.Printf("[QuoteHistory] ❌ 读取已落库K线失败 %s %s %s: %v",
+				key.Symbol, key.Interval, key.OpenTime.Format("2006-01-02 15:04"), err)
+			failed[candle] = true
+			continue
+		}
+		persisted[candle] = existing
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for candle := range keys {
+		if failed[candle] {
+			continue
+		}
+		if existing := persisted[candle]; existing != nil {
+			candle.Merge(existing)
+		}
+		delete(s.unmerged, candle)
+	}
+}
+
+/**
+ * GetCandles 查询历史K线
+ *
+ * 未落库的当前K线以内存数据为准
+ *
+ * @param symbol string - 品种代码
+ * @param interval string - 周期（1m/5m/15m/1h/1d）
+ * @param from time.Time - 开始时间（零值表示不限）
+ * @param to time.Time - 结束时间（零值表示当前）
+ * @param limit int - 最多返回数量（取最近的K线）
+ * @return ([]*model.QuoteCandle, error)
+ */
+func (s *QuoteHistoryService) GetCandles(symbol, interval string, from, to time.Time, limit int) ([]*model.QuoteCandle, error) {
+	if symbol == "" {
+		return nil, errors.New("请指定品种")
+	}
+	if _, ok := model.CandleDuration(interval); !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+	if limit <= 0 {
+		limit = defaultCandleLimit
+	}
+	if limit > maxCandleLimit {
+		limit = maxCandleLimit
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !from.IsZero() && from.After(to) {
+		return nil, errors.New("开始时间不能晚于结束时间")
+	}
+
+	candles, err := s.candleRepo.FindRange(symbol, interval, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用内存中的当前K线覆盖（或补充）落库数据
+	s.mu.Lock()
+	current := s.current[candleKey(symbol, interval)]
+	var live *model.QuoteCandle
+	unmerged := false
+	if current != nil && !current.OpenTime.Before(from) && !current.OpenTime.After(to) {
+		c := *current
+		live = &c
+		unmerged = s.unmerged[current]
+	}
+	s.mu.Unlock()
+
+	if live != nil {
+		replaced := false
+		for i, c := range candles {
+			if c.OpenTime.Equal(live.OpenTime) {
+				if unmerged {
+					live.Merge(c)
+				}
+				candles[i] = live
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			candles = append(candles, live)
+			sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime.Before(candles[j].OpenTime) })
+			if len(candles) > limit {
+				candles = candles[len(candles)-limit:]
+			}
+		}
+	}
+	return candles, nil
+}
+
+/**
+ * RebuildCandles 从Tick归档重建K线（回填命令调用）
+ *
+ * 时间范围按本地日期对齐，保证日K完整；范围内原有K线会被删除后重新生成
+ *
+ * @param symbol string - 品种代码（为空表示全部品种）
+ * @param from time.Time - 开始日期
+ * @param to time.Time - 结束日期（不含）
+ * @return (int, int, error) - 处理的Tick数、生成的K线数、错误
+ */
+func (s *QuoteHistoryService) RebuildCandles(symbol string, from, to time.Time) (int, int, error) {
+	from = model.CandleOpenTime(from, model.CandleInterval1d)
+	to = model.CandleOpenTime(to, model.CandleInterval1d)
+	if !to.After(from) {
+		return 0, 0, errors.New("结束日期必须晚于开始日期")
+	}
+
+	candles := make(map[string]*model.QuoteCandle)
+	var order []*model.QuoteCandle
+	tickCount := 0
+	var afterID uint
+
+	for {
+		ticks, err := s.tickRepo.FindRange(symbol, from, to, afterID, rebuildBatchSize)
+		if err != nil {
+			return tickCount, 0, fmt.Errorf("读取Tick归档失败: %v", err)
+		}
+		if len(ticks) == 0 {
+			break
+		}
+		for _, tick := range ticks {
+			afterID = tick.ID
+			tickCount++
+			for _, interval := range model.CandleIntervals {
+				openTime := model.CandleOpenTime(tick.TickAt, interval)
+				key := fmt.Sprintf("%s|%d", candleKey(tick.Symbol, interval), openTime.Unix())
+				if candle, ok := candles[key]; ok {
+					candle.Apply(tick.Sell)
+					continue
+				}
+				candle := model.NewQuoteCandle(tick.Symbol, interval, openTime, tick.Sell)
+				candles[key] = candle
+				order = append(order, candle)
+			}
+		}
+	}
+
+	tx := s.ctx.DB.Begin()
+	if err := repository.NewQuoteCandleRepository(tx).DeleteRange(symbol, from, to); err != nil {
+		tx.Rollback()
+		return tickCount, 0, fmt.Errorf("清理旧K线失败: %v", err)
+	}
+	for _, candle := range order {
+		if err := tx.Create(candle).Error; err != nil {
+			tx.Rollback()
+			return tickCount, 0, fmt.Errorf("写入K线失败: %v", err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return tickCount, 0, errors.New("事务提交失败")
+	}
+
+	log.Printf("[QuoteHistory] ✅ K线重建完成: %s ~ %s，Tick %d 条，K线 %d 根",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), tickCount, len(order))
+	return tickCount, len(order), nil
+}
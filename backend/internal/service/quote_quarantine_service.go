@@ -0,0 +1,162 @@
+/**
+ * 异常Tick隔离日志服务
+ *
+ * 用途：
+ * - 接收行情代理拒绝的异常Tick，批量写入隔离日志
+ * - 管理员查询与复核隔离记录
+ *
+ * 说明：
+ * - 与行情历史一样先缓存在内存，由定时任务批量落库；多实例部署时只有主实例落库
+ * - 行情源异常时可能短时间内产生大量记录，内存缓存超过上限后丢弃并计数
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+const maxPendingQuarantine = 1000 // 内存中最多缓存的隔离记录数
+
+/**
+ * QuoteQuarantineService 异常Tick隔离日志服务
+ */
+type QuoteQuarantineService struct {
+	repo *repository.QuoteQuarantineRepository
+
+	mu      sync.Mutex
+	pending []*model.QuoteQuarantine
+	dropped int // 因缓存已满丢弃的记录数
+}
+
+var defaultQuoteQuarantine *QuoteQuarantineService
+
+/**
+ * SetDefaultQuoteQuarantine 设置全局隔离日志服务（由main注入）
+ */
+func SetDefaultQuoteQuarantine(s *QuoteQuarantineService) {
+	defaultQuoteQuarantine = s
+}
+
+/**
+ * DefaultQuoteQuarantine 获取全局隔离日志服务
+ */
+func DefaultQuoteQuarantine() *QuoteQuarantineService {
+	return defaultQuoteQuarantine
+}
+
+/**
+ * NewQuoteQuarantineService 创建隔离日志服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *QuoteQuarantineService
+ */
+func NewQuoteQuarantineService(ctx *appctx.AppContext) *QuoteQuarantineService {
+	return &QuoteQuarantineService{
+		repo: repository.NewQuoteQuarantineRepository(ctx.DB),
+	}
+}
+
+/**
+ * OnQuarantine 接收行情代理拒绝的Tick
+ *
+ * @param items []*model.QuoteQuarantine - 被拒绝的Tick
+ * @return void
+ */
+func (s *QuoteQuarantineService) OnQuarantine(items []*model.QuoteQuarantine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		if len(s.pending) >= maxPendingQuarantine {
+			s.dropped++
+			continue
+		}
+		s.pending = append(s.pending, item)
+	}
+}
+
+/**
+ * Flush 将缓存的隔离记录落库（定时任务调用）
+ *
+ * @return void
+ */
+func (s *QuoteQuarantineService) Flush() {
+	s.mu.Lock()
+	items := s.pending
+	dropped := s.dropped
+	s.pending = nil
+	s.dropped = 0
+	s.mu.Unlock()
+
+	// 多实例部署时只有主实例落库
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		return
+	}
+
+	if dropped > 0 {
+		log.Printf("[QuoteQuarantine] ⚠️ 异常Tick过多，已丢弃 %d 条隔离记录", dropped)
+	}
+	if err := s.repo.CreateBatch(items); err != nil {
+		log.Printf("[QuoteQuarantine] ❌ 写入隔离记录失败（%d条）: %v", len(items), err)
+	}
+}
+
+/**
+ * GetList 分页查询隔离记录
+ *
+ * @param symbol string - 品种（可选）
+ * @param source string - 行情源（可选）
+ * @param reason string - 隔离原因（可选）
+ * @param reviewed *bool - 是否已复核（nil表示不限）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.QuoteQuarantine, int64, error)
+ */
+func (s *QuoteQuarantineService) GetList(symbol, source, reason string, reviewed *bool, limit, offset int) ([]*model.QuoteQuarantine, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.FindList(symbol, source, reason, reviewed, limit, offset)
+}
+
+/**
+ * Review 复核隔离记录
+ *
+ * @param id uint - 记录ID
+ * @param adminID uint - 复核管理员ID
+ * @param note string - 复核备注
+ * @return (*model.QuoteQuarantine, error)
+ */
+func (s *QuoteQuarantineService) Review(id, adminID uint, note string) (*model.QuoteQuarantine, error) {
+	item, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("隔离记录不存在")
+	}
+	if item.Reviewed {
+		return nil, errors.New("该记录已复核")
+	}
+
+	now := time.Now()
+	item.Reviewed = true
+	item.ReviewedBy = adminID
+	item.ReviewedAt = &now
+	item.ReviewNote = note
+	if err := s.repo.Update(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
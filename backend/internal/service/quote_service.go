@@ -4,10 +4,11 @@
  * 用途：
  * - 从WebSocket行情代理获取实时黄金价格
  * - 提供价格查询接口
+ * - 提供各品种标准化报价快照和推送订阅（REST / SSE 接口）
  * 
  * 数据源：
  * - 上海黄金交易所 (通过WebSocket代理)
- * - wss://push143.jtd9999.vip/ws
+ * - 行情源按配置接入，代理中心负责主备切换（configs/config.*.yaml 中的 quote.sources）
  * 
  * 作者：速金盈技术团队
  * 日期：2025-11
@@ -18,13 +19,36 @@ package service
 import (
 	"fmt"
 	"time"
+
+	"suxin/internal/model"
 )
 
+// quoteStaleAfter 报价超过该时长未更新视为过期
+const quoteStaleAfter = 5 * time.Minute
+
 /**
  * QuoteHubInterface WebSocket行情代理接口
+ * 
+ * GetLatestPrice 返回：价格、更新时间、当前价格来源的行情源名称、是否有效
+ * GetLatestQuotes 返回：各品种最新标准化报价
+ * SubscribeQuotes 返回：推送消息通道（与WebSocket消息格式一致）、取消订阅函数
+ * GetSourceStatuses 返回：各上游行情源连接状态和计数
  */
 type QuoteHubInterface interface {
-	GetLatestPrice() (float64, time.Time, bool)
+	GetLatestPrice() (float64, time.Time, string, bool)
+	GetLatestQuotes() []*model.QuoteUpdate
+	SubscribeQuotes(symbols []string) (<-chan []byte, func())
+	GetSourceStatuses() []model.QuoteSourceStatus
+}
+
+/**
+ * LatestQuote 品种最新报价（含时效信息）
+ */
+type LatestQuote struct {
+	model.QuoteUpdate
+	UpdatedAt  string `json:"updated_at"`  // 更新时间
+	AgeSeconds int    `json:"age_seconds"` // 距今秒数
+	Stale      bool   `json:"stale"`       // 是否已过期
 }
 
 /**
@@ -34,6 +58,22 @@ type QuoteService struct {
 	quoteHub QuoteHubInterface
 }
 
+var defaultQuoteService *QuoteService
+
+/**
+ * SetDefaultQuoteService 设置全局行情服务（由main注入）
+ */
+func SetDefaultQuoteService(s *QuoteService) {
+	defaultQuoteService = s
+}
+
+/**
+ * DefaultQuoteService 获取全局行情服务
+ */
+func DefaultQuoteService() *QuoteService {
+	return defaultQuoteService
+}
+
 /**
  * NewQuoteService 创建行情服务实例
  * 
@@ -58,7 +98,7 @@ func (s *QuoteService) GetCurrentPrice() (float64, error) {
 		return 0, fmt.Errorf("WebSocket行情代理未初始化")
 	}
 	
-	price, lastUpdate, valid := s.quoteHub.GetLatestPrice()
+	price, lastUpdate, _, valid := s.quoteHub.GetLatestPrice()
 	
 	if !valid {
 		return 0, fmt.Errorf("WebSocket价格数据无效，请检查行情连接")
@@ -91,16 +131,19 @@ func (s *QuoteService) GetPriceInfo() map[string]interface{} {
 		}
 	}
 	
-	price, lastUpdate, valid := s.quoteHub.GetLatestPrice()
+	price, lastUpdate, source, valid := s.quoteHub.GetLatestPrice()
 	
 	info := map[string]interface{}{
 		"price":       price,
 		"last_update": lastUpdate.Format("2006-01-02 15:04:05"),
 		"age_seconds": int(time.Since(lastUpdate).Seconds()),
-		"source":      "websocket",
+		"source":      source,
 		"valid":       valid,
 	}
 	
+	// 各品种标准化报价
+	info["quotes"] = s.GetLatestQuotes(nil)
+	
 	// 如果价格无效，添加错误信息
 	if !valid {
 		info["error"] = "价格数据无效"
@@ -114,3 +157,62 @@ func (s *QuoteService) GetPriceInfo() map[string]interface{} {
 	
 	return info
 }
+
+/**
+ * GetLatestQuotes 获取各品种最新报价
+ * 
+ * @param symbols []string - 品种代码（为空表示全部）
+ * @return []*LatestQuote
+ */
+func (s *QuoteService) GetLatestQuotes(symbols []string) []*LatestQuote {
+	if s.quoteHub == nil {
+		return []*LatestQuote{}
+	}
+	
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+	
+	now := time.Now()
+	quotes := make([]*LatestQuote, 0)
+	for _, q := range s.quoteHub.GetLatestQuotes() {
+		if len(wanted) > 0 && !wanted[q.Symbol] {
+			continue
+		}
+		updatedAt := time.UnixMilli(q.Timestamp)
+		quotes = append(quotes, &LatestQuote{
+			QuoteUpdate: *q,
+			UpdatedAt:   updatedAt.Format("2006-01-02 15:04:05"),
+			AgeSeconds:  int(now.Sub(updatedAt).Seconds()),
+			Stale:       now.Sub(updatedAt) > quoteStaleAfter,
+		})
+	}
+	return quotes
+}
+
+/**
+ * Subscribe 订阅报价推送（与WebSocket共用行情代理的分发和合并限频）
+ * 
+ * @param symbols []string - 品种代码（"*" 表示全部）
+ * @return (<-chan []byte, func(), error) - 消息通道、取消订阅函数、错误
+ */
+func (s *QuoteService) Subscribe(symbols []string) (<-chan []byte, func(), error) {
+	if s.quoteHub == nil {
+		return nil, nil, fmt.Errorf("WebSocket行情代理未初始化")
+	}
+	messages, cancel := s.quoteHub.SubscribeQuotes(symbols)
+	return messages, cancel, nil
+}
+
+/**
+ * GetSourceStatuses 获取上游行情源状态（连接状态、重连次数、消息速率等）
+ * 
+ * @return []model.QuoteSourceStatus
+ */
+func (s *QuoteService) GetSourceStatuses() []model.QuoteSourceStatus {
+	if s.quoteHub == nil {
+		return []model.QuoteSourceStatus{}
+	}
+	return s.quoteHub.GetSourceStatuses()
+}
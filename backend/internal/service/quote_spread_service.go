@@ -0,0 +1,79 @@
+/**
+ * 行情点差服务
+ *
+ * 用途：
+ * - 读取系统配置中的买入/卖出点差，供行情推送计算平台买卖价
+ * - 点差配置缓存10秒，避免每条行情都查询数据库
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+const spreadCacheTTL = 10 * time.Second
+
+/**
+ * QuoteSpreadService 行情点差服务
+ */
+type QuoteSpreadService struct {
+	configRepo *repository.ConfigRepository
+
+	mu         sync.Mutex
+	buySpread  float64
+	sellSpread float64
+	loadedAt   time.Time
+}
+
+/**
+ * NewQuoteSpreadService 创建行情点差服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *QuoteSpreadService
+ */
+func NewQuoteSpreadService(ctx *appctx.AppContext) *QuoteSpreadService {
+	return &QuoteSpreadService{
+		configRepo: repository.NewConfigRepository(ctx.DB),
+	}
+}
+
+/**
+ * GetSpread 获取当前点差（未配置时为0）
+ *
+ * @return (float64, float64) - 买入点差、卖出点差（元/克）
+ */
+func (s *QuoteSpreadService) GetSpread() (float64, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.loadedAt) < spreadCacheTTL {
+		return s.buySpread, s.sellSpread
+	}
+
+	s.buySpread = s.readSpread(model.ConfigKeyBuySpread)
+	s.sellSpread = s.readSpread(model.ConfigKeySellSpread)
+	s.loadedAt = time.Now()
+	return s.buySpread, s.sellSpread
+}
+
+func (s *QuoteSpreadService) readSpread(key string) float64 {
+	config, err := s.configRepo.FindByKey(key)
+	if err != nil || config == nil {
+		return 0
+	}
+	var v float64
+	if _, err := fmt.Sscanf(config.Value, "%f", &v); err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,426 @@
+/**
+ * 资金对账服务
+ *
+ * 用途：
+ * - 每日定时对全部客户对账，管理员也可对单个客户手动对账
+ * - 不一致写入对账报告，并通知客服/管理员
+ *
+ * 检查项：
+ * 1. used_deposit：已用定金 = 持仓订单定金合计
+ * 2. fund_log：最近一条资金流水的变动后余额（可用、已用、提现冻结）= 当前余额
+ * 3. equity：已入账充值 - 已扣款提现 + 已实现盈亏 + 余额调整 = 可用定金 + 已用定金 + 提现冻结
+ * 4. ledger：客户余额与总账分户科目一致；全量对账时还核对总账试算平衡、科目余额与分录一致
+ *
+ * 说明：
+ * - 多实例部署时只有主实例执行每日对账；每天只执行一次（以当天是否已有定时任务记录为准）
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+const (
+	defaultReconciliationHour = 2     // 默认每日对账时间（凌晨2点）
+	maxMismatchNotifyLines    = 10    // 通知中最多列出的不一致条数
+	reconcileTolerance        = 0.005 // 金额比较容差（不足1分视为一致）
+)
+
+/**
+ * ReconciliationService 资金对账服务
+ */
+type ReconciliationService struct {
+	ctx        *appctx.AppContext
+	repo       *repository.ReconciliationRepository
+	userRepo   *repository.UserRepository
+	configRepo *repository.ConfigRepository
+	ledgerSvc  *LedgerService
+	notiSvc    *NotificationService
+}
+
+/**
+ * NewReconciliationService 创建资金对账服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *ReconciliationService
+ */
+func NewReconciliationService(ctx *appctx.AppContext) *ReconciliationService {
+	return &ReconciliationService{
+		ctx:        ctx,
+		repo:       repository.NewReconciliationRepository(ctx.DB),
+		userRepo:   repository.NewUserRepository(ctx.DB),
+		configRepo: repository.NewConfigRepository(ctx.DB),
+		ledgerSvc:  NewLedgerService(ctx),
+		notiSvc:    NewNotificationService(ctx),
+	}
+}
+
+/**
+ * GetScheduleHour 读取每日对账时间（点）
+ *
+ * @return int
+ */
+func (s *ReconciliationService) GetScheduleHour() int {
+	hour := defaultReconciliationHour
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyReconciliationHour); err == nil && config != nil {
+		var v int
+		if _, err := fmt.Sscanf(config.Value, "%d", &v); err == nil && v >= 0 && v <= 23 {
+			hour = v
+		}
+	}
+	return hour
+}
+
+/**
+ * RunScheduled 到达每日对账时间且当天尚未对账时执行全量对账（由定时任务调用）
+ *
+ * @return void
+ */
+func (s *ReconciliationService) RunScheduled() {
+	// 多实例部署时只有主实例对账
+	if le := DefaultLeaderElection(); le != nil && !le.IsLeader() {
+		return
+	}
+
+	now := time.Now()
+	if now.Hour() < s.GetScheduleHour() {
+		return
+	}
+	if last, err := s.repo.FindLatestRun(model.ReconciliationTriggerScheduled); err == nil && sameLocalDay(last.StartedAt, now) {
+		return
+	}
+
+	if _, _, err := s.Run(model.ReconciliationTriggerScheduled, 0, 0); err != nil {
+		log.Printf("[Reconcile] ❌ 每日对账失败: %v", err)
+	}
+}
+
+/**
+ * Run 执行一次对账
+ *
+ * @param triggerType string - 触发方式（model.ReconciliationTrigger*）
+ * @param userID uint - 对账客户（0表示全部客户）
+ * @param operatorID uint - 手动触发的管理员（定时任务为0）
+ * @return (*model.ReconciliationRun, []*model.ReconciliationMismatch, error)
+ */
+func (s *ReconciliationService) Run(triggerType string, userID, operatorID uint) (*model.ReconciliationRun, []*model.ReconciliationMismatch, error) {
+	var users []*model.User
+	if userID > 0 {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			return nil, nil, errors.New("用户不存在")
+		}
+		users = []*model.User{user}
+	}
+
+	run := &model.ReconciliationRun{
+		TriggerType: triggerType,
+		UserID:      userID,
+		OperatorID:  operatorID,
+		Status:      model.ReconciliationStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := s.repo.CreateRun(run); err != nil {
+		return nil, nil, fmt.Errorf("创建对账任务失败: %v", err)
+	}
+	log.Printf("[Reconcile] 🔍 开始对账 任务ID=%d 客户=%d（0为全部）", run.ID, userID)
+
+	mismatches, checked, err := s.reconcile(users, userID)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.CheckedUsers = checked
+	run.MismatchCount = len(mismatches)
+	switch {
+	case err != nil:
+		run.Status = model.ReconciliationStatusFailed
+		run.Error = err.Error()
+	case len(mismatches) > 0:
+		run.Status = model.ReconciliationStatusMismatch
+	default:
+		run.Status = model.ReconciliationStatusOK
+	}
+
+	if err == nil {
+		for _, m := range mismatches {
+			m.RunID = run.ID
+		}
+		if saveErr := s.repo.CreateMismatches(mismatches); saveErr != nil {
+			run.Status = model.ReconciliationStatusFailed
+			run.Error = fmt.Sprintf("保存对账报告失败: %v", saveErr)
+			err = saveErr
+		}
+	}
+	if updateErr := s.repo.UpdateRun(run); updateErr != nil {
+		log.Printf("[Reconcile] ❌ 更新对账任务失败 ID=%d: %v", run.ID, updateErr)
+	}
+	if err != nil {
+		s.notifyFailed(run)
+		return run, nil, err
+	}
+
+	if len(mismatches) > 0 {
+		log.Printf("[Reconcile] ⚠️ 对账完成 任务ID=%d：核对 %d 个客户，发现 %d 处不一致", run.ID, checked, len(mismatches))
+		s.notifyMismatches(run, mismatches)
+	} else {
+		log.Printf("[Reconcile] ✅ 对账完成 任务ID=%d：核对 %d 个客户，全部一致", run.ID, checked)
+	}
+	return run, mismatches, nil
+}
+
+/**
+ * reconcile 核对客户资金
+ *
+ * @param users []*model.User - 待核对客户（userID为0时加载全部用户）
+ * @param userID uint - 对账客户（0表示全部）
+ * @return ([]*model.ReconciliationMismatch, int, error) - 不一致记录、核对客户数
+ */
+func (s *ReconciliationService) reconcile(users []*model.User, userID uint) ([]*model.ReconciliationMismatch, int, error) {
+	if userID == 0 {
+		var err error
+		if users, err = s.userRepo.FindAllBalances(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	holding, err := s.repo.SumHoldingDeposits(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	deposits, err := s.repo.SumApprovedDeposits(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	withdrawals, err := s.repo.SumWithdrawals(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	realized, err := s.repo.SumRealizedPnL(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	adjustments, err := s.repo.SumAdjustments(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	latestLogs, err := s.repo.FindLatestFundLogs(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	mismatches := make([]*model.ReconciliationMismatch, 0)
+	add := func(uid uint, check string, expected, actual float64, detail string) {
+		mismatches = append(mismatches, &model.ReconciliationMismatch{
+			UserID:     uid,
+			CheckItem:  check,
+			Expected:   roundMoney(expected),
+			Actual:     roundMoney(actual),
+			Difference: roundMoney(actual - expected),
+			Detail:     detail,
+		})
+	}
+	differs := func(a, b float64) bool {
+		return math.Abs(a-b) >= reconcileTolerance
+	}
+
+	for _, user := range users {
+		// 1. 已用定金 = 持仓订单定金合计
+		if expected := holding[user.ID]; differs(user.UsedDeposit, expected) {
+			add(user.ID, model.ReconcileCheckUsedDeposit, expected, user.UsedDeposit,
+				fmt.Sprintf("已用定金 %.2f，持仓订单定金合计 %.2f", user.UsedDeposit, expected))
+		}
+
+		// 2. 最近一条资金流水的变动后余额 = 当前余额
+		if fundLog := latestLogs[user.ID]; fundLog != nil {
+			if differs(user.AvailableDeposit, fundLog.AvailableAfter) {
+				add(user.ID, model.ReconcileCheckFundLog, fundLog.AvailableAfter, user.AvailableDeposit,
+					fmt.Sprintf("可用定金 %.2f，最近资金流水(ID=%d)变动后可用 %.2f", user.AvailableDeposit, fundLog.ID, fundLog.AvailableAfter))
+			}
+			if differs(user.UsedDeposit, fundLog.UsedAfter) {
+				add(user.ID, model.ReconcileCheckFundLog, fundLog.UsedAfter, user.UsedDeposit,
+					fmt.Sprintf("已用定金 %.2f，最近资金流水(ID=%d)变动后已用 %.2f", user.UsedDeposit, fundLog.ID, fundLog.UsedAfter))
+			}
+			if differs(user.WithdrawFrozen, fundLog.FrozenAfter) {
+				add(user.ID, model.ReconcileCheckFundLog, fundLog.FrozenAfter, user.WithdrawFrozen,
+					fmt.Sprintf("提现冻结 %.2f，最近资金流水(ID=%d)变动后冻结 %.2f", user.WithdrawFrozen, fundLog.ID, fundLog.FrozenAfter))
+			}
+		} else if user.AvailableDeposit != 0 || user.UsedDeposit != 0 || user.WithdrawFrozen != 0 {
+			add(user.ID, model.ReconcileCheckFundLog, 0, user.AvailableDeposit+user.UsedDeposit+user.WithdrawFrozen,
+				fmt.Sprintf("有余额（可用 %.2f，已用 %.2f，提现冻结 %.2f）但没有任何资金流水",
+					user.AvailableDeposit, user.UsedDeposit, user.WithdrawFrozen))
+		}
+
+		// 3. 充值 - 提现 + 已实现盈亏 + 余额调整 = 可用 + 已用 + 提现冻结
+		equity := user.AvailableDeposit + user.UsedDeposit + user.WithdrawFrozen
+		expected := deposits[user.ID] - withdrawals[user.ID] + realized[user.ID] + adjustments[user.ID]
+		if differs(equity, expected) {
+			add(user.ID, model.ReconcileCheckEquity, expected, equity,
+				fmt.Sprintf("权益 %.2f，充值 %.2f - 提现 %.2f + 已实现盈亏 %.2f + 余额调整 %.2f = %.2f",
+					equity, deposits[user.ID], withdrawals[user.ID], realized[user.ID], adjustments[user.ID], expected))
+		}
+	}
+
+	// 4. 总账
+	if userID > 0 {
+		for _, user := range users {
+			s.reconcileUserLedger(user, add)
+		}
+	} else {
+		check, err := s.ledgerSvc.Check()
+		if err != nil {
+			return nil, 0, fmt.Errorf("核对总账失败: %v", err)
+		}
+		if !check.Balanced {
+			add(0, model.ReconcileCheckLedger, check.TotalDebit, check.TotalCredit,
+				fmt.Sprintf("总账试算不平衡：借方合计 %.2f，贷方合计 %.2f", check.TotalDebit, check.TotalCredit))
+		}
+		for _, m := range check.AccountMismatches {
+			add(m.UserID, model.ReconcileCheckLedger, m.LineTotal, m.Balance,
+				fmt.Sprintf("科目 %s(ID=%d) 余额 %.2f，分录汇总 %.2f", m.Code, m.AccountID, m.Balance, m.LineTotal))
+		}
+		for _, m := range check.UserMismatches {
+			add(m.UserID, model.ReconcileCheckLedger, m.LedgerAvailable+m.LedgerMargin+m.LedgerFrozen,
+				m.AvailableDeposit+m.UsedDeposit+m.WithdrawFrozen,
+				fmt.Sprintf("可用/已用定金/提现冻结 %.2f/%.2f/%.2f，总账 %.2f/%.2f/%.2f",
+					m.AvailableDeposit, m.UsedDeposit, m.WithdrawFrozen, m.LedgerAvailable, m.LedgerMargin, m.LedgerFrozen))
+		}
+	}
+
+	return mismatches, len(users), nil
+}
+
+/**
+ * reconcileUserLedger 核对单个客户余额与总账分户科目（未建账的客户跳过）
+ */
+func (s *ReconciliationService) reconcileUserLedger(user *model.User, add func(uint, string, float64, float64, string)) {
+	accounts, err := s.ledgerSvc.GetUserAccounts(user.ID)
+	if err != nil || len(accounts) == 0 {
+		return
+	}
+	var ledgerAvailable, ledgerMargin, ledgerFrozen float64
+	for _, account := range accounts {
+		switch account.Code {
+		case model.LedgerAccountCustomerAvailable:
+			ledgerAvailable = account.Balance
+		case model.LedgerAccountCustomerMargin:
+			ledgerMargin = account.Balance
+		case model.LedgerAccountCustomerFrozen:
+			ledgerFrozen = account.Balance
+		}
+	}
+	if math.Abs(user.AvailableDeposit-ledgerAvailable) >= reconcileTolerance ||
+		math.Abs(user.UsedDeposit-ledgerMargin) >= reconcileTolerance ||
+		math.Abs(user.WithdrawFrozen-ledgerFrozen) >= reconcileTolerance {
+		add(user.ID, model.ReconcileCheckLedger, ledgerAvailable+ledgerMargin+ledgerFrozen,
+			user.AvailableDeposit+user.UsedDeposit+user.WithdrawFrozen,
+			fmt.Sprintf("可用/已用定金/提现冻结 %.2f/%.2f/%.2f，总账 %.2f/%.2f/%.2f",
+				user.AvailableDeposit, user.UsedDeposit, user.WithdrawFrozen, ledgerAvailable, ledgerMargin, ledgerFrozen))
+	}
+}
+
+/**
+ * notifyMismatches 通知客服/管理员对账不一致
+ */
+func (s *ReconciliationService) notifyMismatches(run *model.ReconciliationRun, mismatches []*model.ReconciliationMismatch) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "对账任务 #%d 发现 %d 处资金不一致，请在对账报告中核查：", run.ID, len(mismatches))
+	for i, m := range mismatches {
+		if i >= maxMismatchNotifyLines {
+			fmt.Fprintf(&b, "\n……等共 %d 处", len(mismatches))
+			break
+		}
+		fmt.Fprintf(&b, "\n客户ID %d [%s] 差额 %.2f", m.UserID, m.CheckItem, m.Difference)
+	}
+	if err := s.notiSvc.SendSystemNotificationToAdmins("资金对账不一致", b.String(), model.NotifyLevelCritical); err != nil {
+		log.Printf("[Reconcile] 发送对账通知失败: %v", err)
+	}
+}
+
+/**
+ * notifyFailed 通知客服/管理员对账执行失败
+ */
+func (s *ReconciliationService) notifyFailed(run *model.ReconciliationRun) {
+	content := fmt.Sprintf("对账任务 #%d 执行失败：%s", run.ID, run.Error)
+	if err := s.notiSvc.SendSystemNotificationToAdmins("资金对账失败", content, model.NotifyLevelWarning); err != nil {
+		log.Printf("[Reconcile] 发送对账通知失败: %v", err)
+	}
+}
+
+/**
+ * GetRuns 分页查询对账任务
+ *
+ * @param userID uint - 对账客户（0表示不筛选）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.ReconciliationRun, int64, error)
+ */
+func (s *ReconciliationService) GetRuns(userID uint, limit, offset int) ([]*model.ReconciliationRun, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.FindRuns(userID, limit, offset)
+}
+
+/**
+ * GetMismatches 分页查询对账不一致记录
+ *
+ * @param runID uint - 对账任务（0表示不筛选）
+ * @param userID uint - 客户（0表示不筛选）
+ * @param resolved *bool - 是否已处理（nil表示不筛选）
+ * @param limit int - 每页数量
+ * @param offset int - 偏移量
+ * @return ([]*model.ReconciliationMismatch, int64, error)
+ */
+func (s *ReconciliationService) GetMismatches(runID, userID uint, resolved *bool, limit, offset int) ([]*model.ReconciliationMismatch, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.FindMismatches(runID, userID, resolved, limit, offset)
+}
+
+/**
+ * ResolveMismatch 标记对账不一致已处理
+ *
+ * @param id uint - 不一致记录ID
+ * @param adminID uint - 处理管理员
+ * @param note string - 处理备注
+ * @return (*model.ReconciliationMismatch, error)
+ */
+func (s *ReconciliationService) ResolveMismatch(id, adminID uint, note string) (*model.ReconciliationMismatch, error) {
+	mismatch, err := s.repo.FindMismatchByID(id)
+	if err != nil {
+		return nil, errors.New("对账记录不存在")
+	}
+	if mismatch.Resolved {
+		return nil, errors.New("该记录已处理")
+	}
+
+	now := time.Now()
+	mismatch.Resolved = true
+	mismatch.ResolvedBy = adminID
+	mismatch.ResolvedAt = &now
+	mismatch.ResolveNote = note
+	if err := s.repo.UpdateMismatch(mismatch); err != nil {
+		return nil, err
+	}
+	return mismatch, nil
+}
@@ -16,6 +16,7 @@ package service
 import (
 	"fmt"
 	"log"
+	"math"
 	"time"
 
 	"suxin/internal/appctx"
@@ -27,10 +28,11 @@ import (
  * RiskService 风控引擎服务
  */
 type RiskService struct {
-	ctx       *appctx.AppContext
-	orderRepo *repository.OrderRepository
-	userRepo  *repository.UserRepository
-	notiSvc   *NotificationService
+	ctx        *appctx.AppContext
+	orderRepo  *repository.OrderRepository
+	userRepo   *repository.UserRepository
+	configRepo *repository.ConfigRepository
+	notiSvc    *NotificationService
 }
 
 /**
@@ -41,10 +43,11 @@ type RiskService struct {
  */
 func NewRiskService(ctx *appctx.AppContext) *RiskService {
 	return &RiskService{
-		ctx:       ctx,
-		orderRepo: repository.NewOrderRepository(ctx.DB),
-		userRepo:  repository.NewUserRepository(ctx.DB),
-		notiSvc:   NewNotificationService(ctx),
+		ctx:        ctx,
+		orderRepo:  repository.NewOrderRepository(ctx.DB),
+		userRepo:   repository.NewUserRepository(ctx.DB),
+		configRepo: repository.NewConfigRepository(ctx.DB),
+		notiSvc:    NewNotificationService(ctx),
 	}
 }
 
@@ -53,9 +56,11 @@ func NewRiskService(ctx *appctx.AppContext) *RiskService {
  */
 type RiskCheckResult struct {
 	TotalOrders      int                // 总订单数
-	NeedForceClose   []*model.Order     // 需要强平的订单
+	NeedForceClose   []*model.Order     // 跌破强平底线、需要立即强平的订单
+	MarginCall       []*model.Order     // 低于追保线的订单
 	HighRisk         []*model.Order     // 高风险订单
 	Warning          []*model.Order     // 需要预警的订单
+	Policy           MarginCallPolicy   // 本次检查使用的追保阈值
 	CheckTime        time.Time          // 检查时间
 }
 
@@ -65,8 +70,8 @@ type RiskCheckResult struct {
  * 业务流程：
  * 1. 获取所有持仓订单
  * 2. 使用当前价格更新每个订单的盈亏和定金率
- * 3. 分类订单：强平/高风险/预警
- * 4. 保存更新后的订单数据
+ * 3. 保存更新后的订单数据
+ * 4. 分类订单：强平（跌破强平底线）/追保（低于追保线）/高风险/预警
  * 
  * @param currentPrice float64 - 当前市场价格（元/克）
  * @return (*RiskCheckResult, error)
@@ -78,11 +83,15 @@ func (s *RiskService) CheckAllOrders(currentPrice float64) (*RiskCheckResult, er
 		return nil, fmt.Errorf("获取持仓订单失败: %v", err)
 	}
 
+	policy := NewMarginCallService(s.ctx).GetPolicy()
+
 	result := &RiskCheckResult{
 		TotalOrders:    len(orders),
 		NeedForceClose: make([]*model.Order, 0),
+		MarginCall:     make([]*model.Order, 0),
 		HighRisk:       make([]*model.Order, 0),
 		Warning:        make([]*model.Order, 0),
+		Policy:         policy,
 		CheckTime:      time.Now(),
 	}
 
@@ -98,11 +107,16 @@ func (s *RiskService) CheckAllOrders(currentPrice float64) (*RiskCheckResult, er
 		}
 
 		// 4. 根据定金率分类订单
-		if order.IsNeedForceClose() {
-			// 定金率 ≤ 20%：需要强制平仓
+		if order.MarginRate <= policy.FloorRate {
+			// 定金率 ≤ 强平底线：不等追保期限，立即强制平仓
 			result.NeedForceClose = append(result.NeedForceClose, order)
-			log.Printf("[Risk] ⚠️ 订单 %s 定金率 %.2f%% ≤ 20%%，需要强制平仓", 
-				order.OrderID, order.MarginRate)
+			log.Printf("[Risk] ⚠️ 订单 %s 定金率 %.2f%% ≤ 强平底线 %.2f%%，需要强制平仓", 
+				order.OrderID, order.MarginRate, policy.FloorRate)
+		} else if order.MarginRate <= policy.CallRate {
+			// 定金率 ≤ 追保线：发起追保，期限内未补足再强平
+			result.MarginCall = append(result.MarginCall, order)
+			log.Printf("[Risk] ⚠️ 订单 %s 定金率 %.2f%% ≤ 追保线 %.2f%%，进入追保", 
+				order.OrderID, order.MarginRate, policy.CallRate)
 		} else if order.IsHighRisk() {
 			// 20% < 定金率 < 25%：高风险预警
 			result.HighRisk = append(result.HighRisk, order)
@@ -116,9 +130,10 @@ func (s *RiskService) CheckAllOrders(currentPrice float64) (*RiskCheckResult, er
 		}
 	}
 
-	log.Printf("[Risk] ✅ 风控检查完成：总计 %d 单，强平 %d 单，高风险 %d 单，预警 %d 单",
+	log.Printf("[Risk] ✅ 风控检查完成：总计 %d 单，强平 %d 单，追保 %d 单，高风险 %d 单，预警 %d 单",
 		result.TotalOrders, 
 		len(result.NeedForceClose), 
+		len(result.MarginCall), 
 		len(result.HighRisk), 
 		len(result.Warning))
 
@@ -130,9 +145,10 @@ func (s *RiskService) CheckAllOrders(currentPrice float64) (*RiskCheckResult, er
  * 
  * 业务流程：
  * 1. 获取需要强平的订单列表
- * 2. 对每个订单执行强平操作
- * 3. 更新用户资金（释放定金 + 结算盈亏）
- * 4. 发送强平通知
+ * 2. 大额订单优先部分平仓，只平掉恢复定金率所需的最少克重
+ * 3. 无法部分平仓的订单执行全部强平
+ * 4. 更新用户资金（释放定金 + 结算盈亏）
+ * 5. 发送强平通知
  * 
  * @param orders []*model.Order - 需要强平的订单列表
  * @param closePrice float64 - 平仓价格
@@ -141,75 +157,325 @@ func (s *RiskService) CheckAllOrders(currentPrice float64) (*RiskCheckResult, er
 func (s *RiskService) AutoForceClose(orders []*model.Order, closePrice float64) (int, error) {
 	successCount := 0
 
+	partialPolicy := s.GetPartialClosePolicy()
+
 	for _, order := range orders {
-		// 开启事务
-		tx := s.ctx.DB.Begin()
-		defer func() {
-			if r := recover(); r != nil {
-				tx.Rollback()
-			}
-		}()
+		// 大额订单先尝试部分平仓
+		if partial, err := s.PartialLiquidate(order, closePrice, partialPolicy); err != nil {
+			log.Printf("[Risk] ⚠️ 订单 %s 部分平仓失败，改为全部强平: %v", order.OrderID, err)
+		} else if partial != nil {
+			successCount++
+			continue
+		}
 
-		// 1. 获取用户信息
-		user, err := s.userRepo.FindByID(order.UserID)
+		fundLog, finalPnL, err := s.forceCloseOrder(order, closePrice)
 		if err != nil {
-			log.Printf("[Risk] 获取用户 %d 信息失败: %v", order.UserID, err)
+			log.Printf("[Risk] ⚠️ 订单 %s 强平失败，跳过: %v", order.OrderID, err)
 			continue
 		}
 
-		// 2. 计算最终盈亏
-		finalPnL := order.CalculatePnL(closePrice)
+		successCount++
+		log.Printf("[Risk] ✅ 订单 %s 强制平仓成功，平仓价 %.2f，最终盈亏 %.2f",
+			order.OrderID, closePrice, finalPnL)
+
+		// 发送强平通知
+		notifyMsg := fmt.Sprintf("您的订单已触发强制平仓\n平仓价格：%.2f 元/克\n最终盈亏：%.2f 元\n账户可用定金：%.2f 元",
+			closePrice, finalPnL, fundLog.AvailableAfter)
+		s.notiSvc.SendRiskNotification(order.UserID, order.OrderID, notifyMsg, true)
+	}
+
+	log.Printf("[Risk] 🎯 自动强平完成：成功 %d/%d 单", successCount, len(orders))
+	return successCount, nil
+}
+
+/**
+ * PartialClosePolicy 部分平仓参数
+ */
+type PartialClosePolicy struct {
+	MinWeightG  float64 // 启用部分平仓的最小持仓克重（0表示关闭）
+	RestoreRate float64 // 平仓后恢复到的定金率（%）
+	StepGrams   float64 // 平仓克重步长（克）
+}
 
-		// 3. 执行强平操作
-		order.ForceClose(closePrice)
+/**
+ * PartialCloseResult 部分平仓结果
+ */
+type PartialCloseResult struct {
+	ClosedWeightG    float64 // 本次平仓克重
+	RealizedPnL      float64 // 本次已实现盈亏
+	RemainingWeightG float64 // 剩余持仓克重
+	MarginRate       float64 // 平仓后定金率（%）
+}
 
-		// 4. 更新用户资金
-		// 释放已用定金，结算金额（定金 + 盈亏）加回可用定金
-		newAvailable := user.AvailableDeposit + order.Deposit + finalPnL
-		newUsed := user.UsedDeposit - order.Deposit
+// 部分平仓默认参数
+const (
+	defaultPartialCloseMinWeight   = 1000.0
+	defaultPartialCloseRestoreRate = 30.0
+	defaultPartialCloseStepGrams   = 1.0
+)
 
-		// 防止资金为负
-		if newAvailable < 0 {
-			log.Printf("[Risk] ⚠️ 订单 %s 强平后资金异常（可用定金为负: %.2f），跳过", 
-				order.OrderID, newAvailable)
-			tx.Rollback()
-			continue
+/**
+ * GetPartialClosePolicy 读取部分平仓参数
+ *
+ * 恢复定金率必须高于追保线，否则平仓后会立即再次进入追保，此时按追保线 + 10% 处理
+ *
+ * @return PartialClosePolicy
+ */
+func (s *RiskService) GetPartialClosePolicy() PartialClosePolicy {
+	policy := PartialClosePolicy{
+		MinWeightG:  defaultPartialCloseMinWeight,
+		RestoreRate: defaultPartialCloseRestoreRate,
+		StepGrams:   defaultPartialCloseStepGrams,
+	}
+
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyPartialCloseMinWeight); err == nil && config != nil {
+		var weight float64
+		if _, err := fmt.Sscanf(config.Value, "%f", &weight); err == nil && weight >= 0 {
+			policy.MinWeightG = weight
+		}
+	}
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyPartialCloseRestoreRate); err == nil && config != nil {
+		var rate float64
+		fmt.Sscanf(config.Value, "%f", &rate)
+		if rate > 0 && rate < 1000 {
+			policy.RestoreRate = rate
+		}
+	}
+	if config, err := s.configRepo.FindByKey(model.ConfigKeyPartialCloseStepGrams); err == nil && config != nil {
+		var step float64
+		fmt.Sscanf(config.Value, "%f", &step)
+		if step >= 0.001 {
+			policy.StepGrams = step
 		}
+	}
+
+	callRate := NewMarginCallService(s.ctx).GetPolicy().CallRate
+	if policy.RestoreRate <= callRate {
+		policy.RestoreRate = callRate + 10
+	}
+
+	return policy
+}
+
+/**
+ * CalculatePartialCloseWeight 计算恢复定金率所需平仓的最少克重
+ *
+ * 计算逻辑：
+ * - 平仓部分的盈亏计入定金，权益（定金 + 浮动盈亏）在平仓前后不变
+ * - 剩余克重为 W' 时，定金率 = 权益 / (W' × 10) × 100%
+ * - 要求定金率 ≥ 恢复定金率 R：W' ≤ 权益 × 10 / R
+ * - 最少平仓克重 = W - 权益 × 10 / R，按步长向上取整
+ *
+ * @param order *model.Order - 订单
+ * @param closePrice float64 - 平仓价格
+ * @param policy PartialClosePolicy - 部分平仓参数
+ * @return (float64, bool) - 平仓克重；false 表示无法部分平仓（需全部强平）
+ */
+func (s *RiskService) CalculatePartialCloseWeight(order *model.Order, closePrice float64, policy PartialClosePolicy) (float64, bool) {
+	if policy.MinWeightG <= 0 || order.WeightG < policy.MinWeightG {
+		return 0, false
+	}
+
+	equity := order.Deposit + order.CalculatePnL(closePrice)
+	if equity <= 0 {
+		return 0, false
+	}
+
+	maxRemaining := equity * 10 / policy.RestoreRate
+	closeWeight := order.WeightG - maxRemaining
+	if closeWeight <= 0 {
+		return 0, false
+	}
 
-		if err := tx.Model(&model.User{}).Where("id = ?", order.UserID).Updates(map[string]interface{}{
-			"available_deposit": newAvailable,
-			"used_deposit":      newUsed,
-		}).Error; err != nil {
-			log.Printf("[Risk] 更新用户 %d 资金失败: %v", order.UserID, err)
+	// 按步长向上取整（减去微小误差，避免浮点误差多平一个步长）
+	steps := math.Ceil(closeWeight/policy.StepGrams - 1e-9)
+	closeWeight = math.Round(steps*policy.StepGrams*1000) / 1000
+
+	// 剩余不足一个步长则全部平仓
+	if order.WeightG-closeWeight < policy.StepGrams {
+		return 0, false
+	}
+
+	return closeWeight, true
+}
+
+/**
+ * PartialLiquidate 部分平仓
+ *
+ * 业务流程：
+ * 1. 锁定订单并确认仍为持仓，按锁定后的数据计算最少平仓克重
+ * 2. 平掉该部分克重，已实现盈亏计入订单定金，剩余持仓继续持有
+ * 3. 同步用户已用定金并记录资金流水
+ * 4. 定金率恢复后解除进行中的追保
+ * 5. 通知客户平仓克重
+ *
+ * @param order *model.Order - 待强平订单
+ * @param closePrice float64 - 平仓价格
+ * @param policy PartialClosePolicy - 部分平仓参数
+ * @return (*PartialCloseResult, error) - 不满足部分平仓条件时返回 nil, nil
+ */
+func (s *RiskService) PartialLiquidate(order *model.Order, closePrice float64, policy PartialClosePolicy) (*PartialCloseResult, error) {
+	if _, ok := s.CalculatePartialCloseWeight(order, closePrice, policy); !ok {
+		return nil, nil
+	}
+
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
 			tx.Rollback()
-			continue
 		}
+	}()
+
+	// 1. 锁定订单并确认状态
+	locked, err := repository.NewOrderRepository(tx).LockByID(order.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("订单不存在: %v", err)
+	}
+	if !locked.CanSettle() {
+		tx.Rollback()
+		return nil, fmt.Errorf("订单状态已变更（当前状态: %s）", locked.Status)
+	}
+
+	closeWeight, ok := s.CalculatePartialCloseWeight(locked, closePrice, policy)
+	if !ok {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	// 2. 平掉部分克重
+	realized := locked.PartialClose(closeWeight, closePrice)
+
+	// 3. 已实现盈亏计入订单定金，同步用户已用定金
+	if _, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:      locked.UserID,
+		Type:        model.FundLogTypePartialClose,
+		UsedDelta:   realized,
+		RelatedID:   locked.ID,
+		RelatedType: "order",
+		Note: fmt.Sprintf("风控部分平仓: 平仓%.3f克，平仓价%.2f，实现盈亏%.2f元 (订单%s)",
+			closeWeight, closePrice, realized, locked.OrderID),
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-		// 5. 保存订单状态
-		if err := tx.Save(order).Error; err != nil {
-			log.Printf("[Risk] 保存订单 %s 状态失败: %v", order.OrderID, err)
+	if err := tx.Save(locked).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("保存订单失败: %v", err)
+	}
+
+	// 4. 解除追保
+	marginCallSvc := NewMarginCallService(s.ctx)
+	resolvedCall, err := marginCallSvc.ResolveOnPartialClose(tx, locked, closeWeight)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("更新追保状态失败: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("事务提交失败: %v", err)
+	}
+
+	*order = *locked
+	result := &PartialCloseResult{
+		ClosedWeightG:    closeWeight,
+		RealizedPnL:      realized,
+		RemainingWeightG: locked.WeightG,
+		MarginRate:       locked.MarginRate,
+	}
+
+	log.Printf("[Risk] ✅ 订单 %s 部分平仓成功：平仓 %.3f 克，平仓价 %.2f，实现盈亏 %.2f，剩余 %.3f 克，定金率恢复至 %.2f%%",
+		locked.OrderID, closeWeight, closePrice, realized, locked.WeightG, locked.MarginRate)
+
+	// 5. 通知客户
+	notifyMsg := fmt.Sprintf("您的订单定金率不足，系统已执行部分平仓\n平仓克重：%.3f 克\n平仓价格：%.2f 元/克\n实现盈亏：%.2f 元\n剩余持仓：%.3f 克\n当前定金率：%.2f%%",
+		closeWeight, closePrice, realized, locked.WeightG, locked.MarginRate)
+	if _, err := s.notiSvc.SendNotification(
+		locked.UserID,
+		model.NotifyTypeRisk,
+		model.NotifyLevelCritical,
+		"部分平仓通知",
+		fmt.Sprintf("订单号：%s\n%s", locked.OrderID, notifyMsg),
+		locked.ID,
+		"order",
+	); err != nil {
+		log.Printf("[Risk] 发送部分平仓通知失败: %v", err)
+	}
+	if resolvedCall != nil {
+		marginCallSvc.NotifyResolved(resolvedCall)
+	}
+
+	return result, nil
+}
+
+/**
+ * forceCloseOrder 在单个事务中强平一个订单
+ *
+ * 锁定订单行并确认仍为持仓，再通过资金服务锁定用户行结算，
+ * 避免与客户结算、补定金等操作并发时重复结算或丢失资金更新
+ *
+ * @param order *model.Order - 待强平订单
+ * @param closePrice float64 - 平仓价格
+ * @return (*model.FundLog, float64, error) - 资金流水、订单总盈亏（含部分平仓已实现盈亏）
+ */
+func (s *RiskService) forceCloseOrder(order *model.Order, closePrice float64) (*model.FundLog, float64, error) {
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
 			tx.Rollback()
-			continue
 		}
+	}()
 
-		// 6. 提交事务
-		if err := tx.Commit().Error; err != nil {
-			log.Printf("[Risk] 订单 %s 强平事务提交失败: %v", order.OrderID, err)
-			continue
-		}
+	// 1. 锁定订单并确认状态
+	locked, err := repository.NewOrderRepository(tx).LockByID(order.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("订单不存在: %v", err)
+	}
+	if !locked.CanSettle() {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("订单状态已变更（当前状态: %s）", locked.Status)
+	}
 
-		successCount++
-		log.Printf("[Risk] ✅ 订单 %s 强制平仓成功，平仓价 %.2f，最终盈亏 %.2f",
-			order.OrderID, closePrice, finalPnL)
+	// 2. 计算最终盈亏
+	finalPnL := locked.CalculatePnL(closePrice)
+
+	// 3. 更新用户资金：释放已用定金，结算金额（定金 + 盈亏）加回可用定金
+	fundLog, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:         locked.UserID,
+		Type:           model.FundLogTypeForceClose,
+		AvailableDelta: locked.Deposit + finalPnL,
+		UsedDelta:      -locked.Deposit,
+		RelatedID:      locked.ID,
+		RelatedType:    "order",
+		Note:           fmt.Sprintf("风控强平: 平仓价%.2f，盈亏%.2f元 (订单%s)", closePrice, finalPnL, locked.OrderID),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, err
+	}
 
-		// 7. 发送强平通知
-		notifyMsg := fmt.Sprintf("您的订单已触发强制平仓\n平仓价格：%.2f 元/克\n最终盈亏：%.2f 元\n账户可用定金：%.2f 元",
-			closePrice, finalPnL, newAvailable)
-		s.notiSvc.SendRiskNotification(order.UserID, order.OrderID, notifyMsg, true)
+	// 4. 保存订单状态
+	locked.ForceClose(closePrice)
+	if err := tx.Save(locked).Error; err != nil {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("保存订单状态失败: %v", err)
 	}
 
-	log.Printf("[Risk] 🎯 自动强平完成：成功 %d/%d 单", successCount, len(orders))
-	return successCount, nil
+	// 5. 关闭进行中的追保
+	if err := NewMarginCallService(s.ctx).CloseOnOrderClosed(tx, locked); err != nil {
+		tx.Rollback()
+		return nil, 0, fmt.Errorf("关闭追保失败: %v", err)
+	}
+
+	// 6. 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return nil, 0, fmt.Errorf("事务提交失败: %v", err)
+	}
+
+	*order = *locked
+	return fundLog, locked.SettledPnL, nil
 }
 
 /**
@@ -218,8 +484,9 @@ func (s *RiskService) AutoForceClose(orders []*model.Order, closePrice float64)
  * 业务流程：
  * 1. 获取当前市场价格
  * 2. 检查所有持仓订单
- * 3. 自动执行强平
- * 4. 发送预警通知
+ * 3. 处理追保：发起新追保、解除已恢复的追保、找出逾期未补足的订单
+ * 4. 对跌破强平底线和追保逾期的订单执行强平
+ * 5. 发送预警通知
  * 
  * @param currentPrice float64 - 当前市场价格
  * @return error
@@ -233,21 +500,36 @@ func (s *RiskService) RunRiskCheck(currentPrice float64) error {
 		return fmt.Errorf("风控检查失败: %v", err)
 	}
 
-	// 2. 自动强平
-	if len(result.NeedForceClose) > 0 {
-		log.Printf("[Risk] 🚨 发现 %d 单需要强制平仓", len(result.NeedForceClose))
-		_, err := s.AutoForceClose(result.NeedForceClose, currentPrice)
+	// 2. 处理追保（逾期未补足的订单加入强平列表）
+	overdue := NewMarginCallService(s.ctx).ReviewOrders(result.MarginCall, result.NeedForceClose, result.Policy)
+
+	// 3. 自动强平：跌破强平底线 + 追保逾期
+	forceCloseOrders := append(result.NeedForceClose, overdue...)
+	if len(forceCloseOrders) > 0 {
+		log.Printf("[Risk] 🚨 发现 %d 单需要强制平仓（跌破底线 %d 单，追保逾期 %d 单）",
+			len(forceCloseOrders), len(result.NeedForceClose), len(overdue))
+		_, err := s.AutoForceClose(forceCloseOrders, currentPrice)
 		if err != nil {
 			log.Printf("[Risk] 自动强平失败: %v", err)
 		}
 	}
 
-	// 3. 尝试自动补定金（针对所有预警订单）
+	// 4. 尝试自动补定金（针对追保中及所有预警订单，补足后自动解除追保）
 	autoSupplementSvc := NewAutoSupplementService(s.ctx)
 	autoSupplementCount := 0
 	
-	// 合并高风险和一般预警订单
-	allWarningOrders := append(result.HighRisk, result.Warning...)
+	overdueMap := make(map[uint]bool, len(overdue))
+	for _, order := range overdue {
+		overdueMap[order.ID] = true
+	}
+	allWarningOrders := make([]*model.Order, 0, len(result.MarginCall)+len(result.HighRisk)+len(result.Warning))
+	for _, order := range result.MarginCall {
+		if !overdueMap[order.ID] {
+			allWarningOrders = append(allWarningOrders, order)
+		}
+	}
+	allWarningOrders = append(allWarningOrders, result.HighRisk...)
+	allWarningOrders = append(allWarningOrders, result.Warning...)
 	
 	if len(allWarningOrders) > 0 {
 		log.Printf("[Risk] 🔄 检查自动补定金: %d 单订单", len(allWarningOrders))
@@ -261,7 +543,7 @@ func (s *RiskService) RunRiskCheck(currentPrice float64) error {
 		}
 	}
 
-	// 4. 发送高风险预警（只对未自动补定金的订单）
+	// 5. 发送高风险预警（只对未自动补定金的订单）
 	if len(result.HighRisk) > 0 {
 		log.Printf("[Risk] ⚠️ 发现 %d 单高风险订单", len(result.HighRisk))
 		for _, order := range result.HighRisk {
@@ -271,7 +553,7 @@ func (s *RiskService) RunRiskCheck(currentPrice float64) error {
 		}
 	}
 
-	// 5. 发送一般预警（只对未自动补定金的订单）
+	// 6. 发送一般预警（只对未自动补定金的订单）
 	if len(result.Warning) > 0 {
 		log.Printf("[Risk] ⚠️ 发现 %d 单需要预警", len(result.Warning))
 		for _, order := range result.Warning {
@@ -281,14 +563,14 @@ func (s *RiskService) RunRiskCheck(currentPrice float64) error {
 		}
 	}
 
-	// 6. 向客服/管理员发送风控汇总通知，便于管理员在“消息通知”中查看整体风险情况
-	if len(result.NeedForceClose) > 0 || len(result.HighRisk) > 0 || len(result.Warning) > 0 {
+	// 7. 向客服/管理员发送风控汇总通知，便于管理员在“消息通知”中查看整体风险情况
+	if len(forceCloseOrders) > 0 || len(result.MarginCall) > 0 || len(result.HighRisk) > 0 || len(result.Warning) > 0 {
 		summary := fmt.Sprintf(
-			"风控检查完成：强平 %d 单，高风险 %d 单，预警 %d 单",
-			len(result.NeedForceClose), len(result.HighRisk), len(result.Warning),
+			"风控检查完成：强平 %d 单，追保 %d 单，高风险 %d 单，预警 %d 单",
+			len(forceCloseOrders), len(result.MarginCall), len(result.HighRisk), len(result.Warning),
 		)
 		level := model.NotifyLevelInfo
-		if len(result.NeedForceClose) > 0 || len(result.HighRisk) > 0 {
+		if len(forceCloseOrders) > 0 || len(result.MarginCall) > 0 || len(result.HighRisk) > 0 {
 			level = model.NotifyLevelWarning
 		}
 		// 异步发送，避免阻塞风控流程
@@ -313,9 +595,10 @@ func (s *RiskService) GetRiskStatistics(currentPrice float64) (map[string]interf
 	stats := map[string]interface{}{
 		"total_orders":       result.TotalOrders,
 		"force_close_count":  len(result.NeedForceClose),
+		"margin_call_count":  len(result.MarginCall),
 		"high_risk_count":    len(result.HighRisk),
 		"warning_count":      len(result.Warning),
-		"safe_count":         result.TotalOrders - len(result.NeedForceClose) - len(result.HighRisk) - len(result.Warning),
+		"safe_count":         result.TotalOrders - len(result.NeedForceClose) - len(result.MarginCall) - len(result.HighRisk) - len(result.Warning),
 		"check_time":         result.CheckTime,
 		"current_price":      currentPrice,
 	}
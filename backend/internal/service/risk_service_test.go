@@ -0,0 +1,170 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"suxin/internal/model"
+)
+
+func TestCalculatePartialCloseWeight(t *testing.T) {
+	policy := PartialClosePolicy{MinWeightG: 1000, RestoreRate: 30, StepGrams: 1}
+
+	tests := []struct {
+		name       string
+		order      model.Order
+		closePrice float64
+		policy     PartialClosePolicy
+		wantWeight float64
+		wantOK     bool
+	}{
+		{
+			// 权益 20000 - 16000 = 4000，剩余克重上限 4000×10/30 = 1333.33，平仓 666.67 向上取整
+			name:       "long restores to target rate",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 2000, Deposit: 20000},
+			closePrice: 492, policy: policy,
+			wantWeight: 667, wantOK: true,
+		},
+		{
+			name:       "short restores to target rate",
+			order:      model.Order{Type: model.OrderTypeShortSell, LockedPrice: 500, WeightG: 2000, Deposit: 20000},
+			closePrice: 508, policy: policy,
+			wantWeight: 667, wantOK: true,
+		},
+		{
+			name:       "rounds up to step",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 2000, Deposit: 20000},
+			closePrice: 492, policy: PartialClosePolicy{MinWeightG: 1000, RestoreRate: 30, StepGrams: 10},
+			wantWeight: 670, wantOK: true,
+		},
+		{
+			name:       "below minimum weight",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 500, Deposit: 5000},
+			closePrice: 492, policy: policy,
+		},
+		{
+			name:       "partial close disabled",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 2000, Deposit: 20000},
+			closePrice: 492, policy: PartialClosePolicy{MinWeightG: 0, RestoreRate: 30, StepGrams: 1},
+		},
+		{
+			name:       "equity exhausted",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 2000, Deposit: 20000},
+			closePrice: 489, policy: policy,
+		},
+		{
+			name:       "already above restore rate",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 2000, Deposit: 20000},
+			closePrice: 499, policy: policy,
+		},
+		{
+			name:       "remainder smaller than one step",
+			order:      model.Order{Type: model.OrderTypeLongBuy, LockedPrice: 500, WeightG: 1000, Deposit: 10000},
+			closePrice: 492, policy: PartialClosePolicy{MinWeightG: 1000, RestoreRate: 30, StepGrams: 700},
+		},
+	}
+
+	svc := &RiskService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weight, ok := svc.CalculatePartialCloseWeight(&tt.order, tt.closePrice, tt.policy)
+			if ok != tt.wantOK || weight != tt.wantWeight {
+				t.Errorf("got (%.3f, %v), want (%.3f, %v)", weight, ok, tt.wantWeight, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			remaining := tt.order
+			remaining.PartialClose(weight, tt.closePrice)
+			if remaining.MarginRate < tt.policy.RestoreRate {
+				t.Errorf("margin rate after close = %.2f%%, want >= %.2f%%", remaining.MarginRate, tt.policy.RestoreRate)
+			}
+		})
+	}
+}
+
+func TestPartialLiquidate(t *testing.T) {
+	policy := PartialClosePolicy{MinWeightG: 1000, RestoreRate: 30, StepGrams: 1}
+
+	tests := []struct {
+		name       string
+		status     string
+		closePrice float64
+		wantResult bool
+		wantErr    bool
+		// 期望平仓后的订单和客户数据
+		closed, realized, remaining, deposit float64
+	}{
+		{
+			name: "closes minimum weight", status: model.OrderStatusHolding, closePrice: 492,
+			wantResult: true, closed: 667, realized: -5336, remaining: 1333, deposit: 14664,
+		},
+		{
+			name: "not eligible", status: model.OrderStatusHolding, closePrice: 499,
+			remaining: 2000, deposit: 20000,
+		},
+		{
+			name: "order no longer holding", status: model.OrderStatusSettled, closePrice: 492,
+			wantErr: true, remaining: 2000, deposit: 20000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			user := createTestUser(t, ctx, &model.User{AvailableDeposit: 1000, UsedDeposit: 20000})
+			order := &model.Order{
+				OrderID: "T" + tt.status, UserID: user.ID, Type: model.OrderTypeLongBuy,
+				LockedPrice: 500, WeightG: 2000, Deposit: 20000, Status: model.OrderStatusHolding,
+			}
+			if err := ctx.DB.Create(order).Error; err != nil {
+				t.Fatalf("create order: %v", err)
+			}
+			// 调用方持有的是状态变更前的订单快照
+			snapshot := *order
+			if tt.status != model.OrderStatusHolding {
+				ctx.DB.Model(order).Update("status", tt.status)
+			}
+
+			result, err := NewRiskService(ctx).PartialLiquidate(&snapshot, tt.closePrice, policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (result != nil) != tt.wantResult {
+				t.Fatalf("result = %+v, wantResult %v", result, tt.wantResult)
+			}
+			if result != nil {
+				if result.ClosedWeightG != tt.closed || result.RealizedPnL != tt.realized || result.RemainingWeightG != tt.remaining {
+					t.Errorf("result = %+v", result)
+				}
+				if result.MarginRate < policy.RestoreRate {
+					t.Errorf("margin rate = %.2f%%, want >= %.2f%%", result.MarginRate, policy.RestoreRate)
+				}
+			}
+
+			var stored model.Order
+			ctx.DB.First(&stored, order.ID)
+			if stored.WeightG != tt.remaining || stored.Deposit != tt.deposit {
+				t.Errorf("order weight/deposit = %.3f/%.2f, want %.3f/%.2f", stored.WeightG, stored.Deposit, tt.remaining, tt.deposit)
+			}
+			var storedUser model.User
+			ctx.DB.First(&storedUser, user.ID)
+			if storedUser.UsedDeposit != tt.deposit || storedUser.AvailableDeposit != 1000 {
+				t.Errorf("user available/used = %.2f/%.2f, want 1000/%.2f", storedUser.AvailableDeposit, storedUser.UsedDeposit, tt.deposit)
+			}
+
+			var logs []model.FundLog
+			ctx.DB.Where("user_id = ? AND type = ?", user.ID, model.FundLogTypePartialClose).Find(&logs)
+			wantLogs := 0
+			if tt.wantResult {
+				wantLogs = 1
+			}
+			if len(logs) != wantLogs {
+				t.Fatalf("partial close fund logs = %d, want %d", len(logs), wantLogs)
+			}
+			if wantLogs == 1 && math.Abs(logs[0].UsedAfter-logs[0].UsedBefore-tt.realized) > 0.001 {
+				t.Errorf("fund log used change = %.2f, want %.2f", logs[0].UsedAfter-logs[0].UsedBefore, tt.realized)
+			}
+		})
+	}
+}
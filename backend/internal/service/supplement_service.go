@@ -5,6 +5,7 @@
  * - 处理补定金申请
  * - 审核补定金
  * - 增加订单定金
+ * - 定金率恢复后解除追保
  * 
  * 作者：速金盈技术团队
  * 日期：2025-11
@@ -47,9 +48,9 @@ func NewSupplementService(ctx *appctx.AppContext) *SupplementService {
  * 
  * 流程：
  * 1. 验证订单和金额
- * 2. 检查可用定金是否充足
+ * 2. 事务内锁定订单和用户资金，检查可用定金是否充足
  * 3. 如果充足：直接扣减可用定金，增加订单定金
- * 4. 如果不足：返回错误提示充值
+ * 4. 如果不足：回滚并返回错误提示充值
  * 
  * @param userID uint - 用户ID
  * @param orderID uint - 订单ID
@@ -78,19 +79,7 @@ func (s *SupplementService) SubmitSupplement(userID, orderID uint, amount float6
 		return nil, errors.New("只能为持仓订单补充定金")
 	}
 
-	// 5. 查询用户
-	user, err := s.userRepo.FindByID(userID)
-	if err != nil {
-		return nil, errors.New("用户不存在")
-	}
-
-	// 6. 检查可用定金是否充足
-	if user.AvailableDeposit < amount {
-		return nil, fmt.Errorf("可用定金不足，当前可用: %.2f 元，需要: %.2f 元，请先充值", 
-			user.AvailableDeposit, amount)
-	}
-
-	// 7. 开启事务
+	// 5. 开启事务
 	tx := s.ctx.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -98,7 +87,18 @@ func (s *SupplementService) SubmitSupplement(userID, orderID uint, amount float6
 		}
 	}()
 
-	// 8. 创建补定金记录
+	// 6. 锁定订单并再次确认状态，防止与结算/强平并发
+	order, err = repository.NewOrderRepository(tx).LockByID(orderID)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("订单不存在")
+	}
+	if order.Status != model.OrderStatusHolding {
+		tx.Rollback()
+		return nil, errors.New("只能为持仓订单补充定金")
+	}
+
+	// 7. 创建补定金记录
 	supplement := &model.SupplementDeposit{
 		UserID:     userID,
 		OrderID:    orderID,
@@ -116,23 +116,30 @@ func (s *SupplementService) SubmitSupplement(userID, orderID uint, amount float6
 		return nil, fmt.Errorf("创建补定金记录失败: %v", err)
 	}
 
-	// 9. 扣减用户可用定金，增加已用定金
-	oldDeposit := order.Deposit
-	newAvailable := user.AvailableDeposit - amount
-	newUsed := user.UsedDeposit + amount
-	
-	if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
-		"available_deposit": newAvailable,
-		"used_deposit":      newUsed,
-	}).Error; err != nil {
+	// 8. 扣减用户可用定金，增加已用定金，并记录资金流水
+	if _, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:         userID,
+		Type:           model.FundLogTypeSupplement,
+		AvailableDelta: -amount,
+		UsedDelta:      amount,
+		RelatedID:      order.ID,
+		RelatedType:    "order",
+		Note:           fmt.Sprintf("补充定金: %.2f元 (订单%s)", amount, order.OrderID),
+	}); err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("扣减可用定金失败: %v", err)
+		var insufficient *InsufficientBalanceError
+		if errors.As(err, &insufficient) {
+			return nil, fmt.Errorf("可用定金不足，当前可用: %.2f 元，需要: %.2f 元，请先充值", 
+				insufficient.Available, amount)
+		}
+		return nil, err
 	}
 
-	// 10. 增加订单定金
+	// 9. 增加订单定金
+	oldDeposit := order.Deposit
 	order.Deposit += amount
 	
-	// 11. 重新计算定金率
+	// 10. 重新计算定金率
 	if order.CurrentPrice > 0 {
 		order.UpdatePnLAndMargin(order.CurrentPrice)
 	}
@@ -142,33 +149,26 @@ func (s *SupplementService) SubmitSupplement(userID, orderID uint, amount float6
 		return nil, fmt.Errorf("更新订单定金失败: %v", err)
 	}
 
-	// 12. 记录资金流水
-	fundLog := &model.FundLog{
-		UserID:          user.ID,
-		Type:            "supplement",
-		Amount:          -amount,
-		AvailableBefore: user.AvailableDeposit,
-		AvailableAfter:  newAvailable,
-		UsedBefore:      user.UsedDeposit,
-		UsedAfter:       newUsed,
-		RelatedID:       order.ID,
-		RelatedType:     "order",
-		Note:            fmt.Sprintf("补充定金: %.2f元 (订单%s)", amount, order.OrderID),
-	}
-	if err := tx.Create(fundLog).Error; err != nil {
+	// 11. 定金率回到追保线以上则解除追保
+	marginCallSvc := NewMarginCallService(s.ctx)
+	resolvedCall, err := marginCallSvc.ResolveOnSupplement(tx, order, supplement.ID)
+	if err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("记录流水失败: %v", err)
+		return nil, fmt.Errorf("更新追保状态失败: %v", err)
 	}
 
-	// 13. 提交事务
+	// 12. 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("事务提交失败: %v", err)
 	}
 
-	// 14. 发送通知
+	// 13. 发送通知
 	notifyMsg := fmt.Sprintf("补定金成功\n订单号：%s\n补充金额：%.2f 元\n订单定金：%.2f → %.2f 元\n定金率：%.2f%%",
 		order.OrderID, amount, oldDeposit, order.Deposit, order.MarginRate)
-	s.notiSvc.SendFundNotification(user.ID, "补定金成功", notifyMsg)
+	s.notiSvc.SendFundNotification(userID, "补定金成功", notifyMsg)
+	if resolvedCall != nil {
+		marginCallSvc.NotifyResolved(resolvedCall)
+	}
 
 	return supplement, nil
 }
@@ -193,27 +193,55 @@ func (s *SupplementService) ApproveSupplement(supplementID, reviewerID uint, not
 		return fmt.Errorf("补定金状态不允许审核（当前状态: %s）", supplement.Status)
 	}
 
-	// 3. 查询订单
-	order, err := s.orderRepo.FindByID(supplement.OrderID)
+	// 3. 开启事务
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 4. 锁定补定金申请并再次确认状态，防止重复审核
+	supplement, err = repository.NewSupplementRepository(tx).LockByID(supplementID)
 	if err != nil {
-		return errors.New("订单不存在")
+		tx.Rollback()
+		return errors.New("补定金申请不存在")
+	}
+	if !supplement.IsPending() {
+		tx.Rollback()
+		return fmt.Errorf("补定金状态不允许审核（当前状态: %s）", supplement.Status)
 	}
 
-	// 4. 查询用户
-	user, err := s.userRepo.FindByID(supplement.UserID)
+	// 5. 锁定订单
+	order, err := repository.NewOrderRepository(tx).LockByID(supplement.OrderID)
 	if err != nil {
-		return errors.New("用户不存在")
+		tx.Rollback()
+		return errors.New("订单不存在")
+	}
+	if order.Status != model.OrderStatusHolding {
+		tx.Rollback()
+		return errors.New("只能为持仓订单补充定金")
 	}
 
-	// 5. 开启事务
-	tx := s.ctx.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// 6. 扣减用户可用定金，增加已用定金，并记录资金流水
+	if _, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:         supplement.UserID,
+		Type:           model.FundLogTypeSupplement,
+		AvailableDelta: -supplement.Amount,
+		UsedDelta:      supplement.Amount,
+		RelatedID:      order.ID,
+		RelatedType:    "order",
+		Note:           fmt.Sprintf("补充定金: %.2f元 (订单%s)", supplement.Amount, order.OrderID),
+	}); err != nil {
+		tx.Rollback()
+		var insufficient *InsufficientBalanceError
+		if errors.As(err, &insufficient) {
+			return errors.New("用户可用定金不足")
 		}
-	}()
+		return err
+	}
 
-	// 6. 增加订单定金
+	// 7. 增加订单定金
 	oldDeposit := order.Deposit
 	order.Deposit += supplement.Amount
 	
@@ -227,57 +255,33 @@ func (s *SupplementService) ApproveSupplement(supplementID, reviewerID uint, not
 		return fmt.Errorf("更新订单定金失败: %v", err)
 	}
 
-	// 7. 扣减用户可用定金，增加已用定金
-	newAvailable := user.AvailableDeposit - supplement.Amount
-	newUsed := user.UsedDeposit + supplement.Amount
-	
-	if newAvailable < 0 {
-		tx.Rollback()
-		return errors.New("用户可用定金不足")
-	}
-	
-	if err := tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
-		"available_deposit": newAvailable,
-		"used_deposit":      newUsed,
-	}).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("更新用户资金失败: %v", err)
-	}
-
-	// 8. 记录资金流水
-	fundLog := &model.FundLog{
-		UserID:          user.ID,
-		Type:            "supplement",
-		Amount:          -supplement.Amount,
-		AvailableBefore: user.AvailableDeposit,
-		AvailableAfter:  newAvailable,
-		UsedBefore:      user.UsedDeposit,
-		UsedAfter:       newUsed,
-		RelatedID:       order.ID,
-		RelatedType:     "order",
-		Note:            fmt.Sprintf("补充定金: %.2f元 (订单%s)", supplement.Amount, order.OrderID),
-	}
-	if err := tx.Create(fundLog).Error; err != nil {
+	// 定金率回到追保线以上则解除追保
+	marginCallSvc := NewMarginCallService(s.ctx)
+	resolvedCall, err := marginCallSvc.ResolveOnSupplement(tx, order, supplement.ID)
+	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("记录流水失败: %v", err)
+		return fmt.Errorf("更新追保状态失败: %v", err)
 	}
 
-	// 9. 更新补定金状态
+	// 8. 更新补定金状态
 	supplement.Approve(reviewerID, note)
 	if err := tx.Save(supplement).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("更新补定金状态失败: %v", err)
 	}
 
-	// 10. 提交事务
+	// 9. 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return fmt.Errorf("事务提交失败: %v", err)
 	}
 
-	// 11. 发送通知
+	// 10. 发送通知
 	notifyMsg := fmt.Sprintf("您的补定金申请已通过\n订单号：%s\n补充金额：%.2f 元\n订单定金：%.2f → %.2f 元\n定金率：%.2f%%",
 		order.OrderID, supplement.Amount, oldDeposit, order.Deposit, order.MarginRate)
-	s.notiSvc.SendFundNotification(user.ID, "补定金成功", notifyMsg)
+	s.notiSvc.SendFundNotification(supplement.UserID, "补定金成功", notifyMsg)
+	if resolvedCall != nil {
+		marginCallSvc.NotifyResolved(resolvedCall)
+	}
 
 	return nil
 }
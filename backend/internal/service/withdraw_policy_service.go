@@ -0,0 +1,345 @@
+/**
+ * 提现策略服务
+ *
+ * 用途：
+ * - 按系统配置计算提现手续费（固定 + 比例，受上下限约束）、每月免手续费次数
+ * - 校验单笔最低金额、每日/每月提现次数与金额上限
+ * - 支持为个别客户（VIP等）覆盖全局策略
+ *
+ * 说明：
+ * - 次数与金额按有效提现（待审核、待复核、已通过、已打款）统计，驳回和取消的不计入
+ * - 每月免手续费次数按当月有效提现次数计算，前 N 笔免手续费
+ * - 到账金额 = 提现金额 - 手续费
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"suxin/internal/appctx"
+	"suxin/internal/model"
+	"suxin/internal/repository"
+)
+
+/**
+ * WithdrawPolicy 提现策略（限额类为0表示不限制）
+ */
+type WithdrawPolicy struct {
+	MinAmount     float64 `json:"min_amount"`     // 单笔最低金额（元）
+	FeeFixed      float64 `json:"fee_fixed"`      // 单笔固定手续费（元）
+	FeeRate       float64 `json:"fee_rate"`       // 手续费比例（%）
+	FeeMin        float64 `json:"fee_min"`        // 手续费下限（元）
+	FeeMax        float64 `json:"fee_max"`        // 手续费上限（元）
+	DailyCount    int     `json:"daily_count"`    // 每日次数上限
+	DailyAmount   float64 `json:"daily_amount"`   // 每日金额上限（元）
+	MonthlyCount  int     `json:"monthly_count"`  // 每月次数上限
+	MonthlyAmount float64 `json:"monthly_amount"` // 每月金额上限（元）
+	FreePerMonth  int     `json:"free_per_month"` // 每月免手续费次数
+	Overridden    bool    `json:"overridden"`     // 是否使用了客户专属策略
+	Note          string  `json:"note,omitempty"` // 客户专属策略备注
+}
+
+/**
+ * WithdrawQuote 提现试算结果（手续费明细与额度使用情况）
+ */
+type WithdrawQuote struct {
+	Amount            float64        `json:"amount"`              // 提现金额
+	FeeFixed          float64        `json:"fee_fixed"`           // 固定手续费
+	FeeRateAmount     float64        `json:"fee_rate_amount"`     // 比例手续费
+	FeeBeforeWaiver   float64        `json:"fee_before_waiver"`   // 按上下限调整后的手续费
+	Fee               float64        `json:"fee"`                 // 实收手续费
+	FeeWaived         bool           `json:"fee_waived"`          // 是否使用了免手续费次数
+	FreeRemaining     int            `json:"free_remaining"`      // 本次之前剩余免手续费次数
+	ActualAmount      float64        `json:"actual_amount"`       // 预计到账
+	DailyCountUsed    int64          `json:"daily_count_used"`    // 今日已提现次数
+	DailyAmountUsed   float64        `json:"daily_amount_used"`   // 今日已提现金额
+	MonthlyCountUsed  int64          `json:"monthly_count_used"`  // 本月已提现次数
+	MonthlyAmountUsed float64        `json:"monthly_amount_used"` // 本月已提现金额
+	Policy            WithdrawPolicy `json:"policy"`              // 适用策略
+}
+
+/**
+ * FeeDescription 手续费明细说明（用于资金流水备注）
+ *
+ * @return string
+ */
+func (q *WithdrawQuote) FeeDescription() string {
+	if q.FeeWaived {
+		return "本月免手续费"
+	}
+	parts := make([]string, 0, 3)
+	if q.FeeFixed > 0 {
+		parts = append(parts, fmt.Sprintf("固定%.2f元", q.FeeFixed))
+	}
+	if q.FeeRateAmount > 0 {
+		parts = append(parts, fmt.Sprintf("%.4g%%计%.2f元", q.Policy.FeeRate, q.FeeRateAmount))
+	}
+	desc := strings.Join(parts, " + ")
+	if q.FeeBeforeWaiver != roundMoney(q.FeeFixed+q.FeeRateAmount) {
+		desc += fmt.Sprintf("，按上下限调整为%.2f元", q.FeeBeforeWaiver)
+	}
+	return desc
+}
+
+/**
+ * WithdrawPolicyService 提现策略服务
+ */
+type WithdrawPolicyService struct {
+	ctx        *appctx.AppContext
+	repo       *repository.WithdrawPolicyRepository
+	configRepo *repository.ConfigRepository
+}
+
+/**
+ * NewWithdrawPolicyService 创建提现策略服务实例
+ *
+ * @param ctx *appctx.AppContext - 应用上下文
+ * @return *WithdrawPolicyService
+ */
+func NewWithdrawPolicyService(ctx *appctx.AppContext) *WithdrawPolicyService {
+	return &WithdrawPolicyService{
+		ctx:        ctx,
+		repo:       repository.NewWithdrawPolicyRepository(ctx.DB),
+		configRepo: repository.NewConfigRepository(ctx.DB),
+	}
+}
+
+/**
+ * configFloat 读取非负数值配置（按顺序取第一个存在的键，缺失或不合法返回0）
+ */
+func (s *WithdrawPolicyService) configFloat(keys ...string) float64 {
+	for _, key := range keys {
+		config, err := s.configRepo.FindByKey(key)
+		if err != nil || config == nil || strings.TrimSpace(config.Value) == "" {
+			continue
+		}
+		var v float64
+		if _, err := fmt.Sscanf(config.Value, "%f", &v); err == nil && v >= 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+/**
+ * GetGlobalPolicy 读取全局提现策略
+ *
+ * @return WithdrawPolicy
+ */
+func (s *WithdrawPolicyService) GetGlobalPolicy() WithdrawPolicy {
+	return WithdrawPolicy{
+		MinAmount:     s.configFloat(model.ConfigKeyMinWithdrawAmount, model.ConfigKeyMinWithdraw),
+		FeeFixed:      s.configFloat(model.ConfigKeyWithdrawFeeFixed),
+		FeeRate:       s.configFloat(model.ConfigKeyWithdrawFeeRate),
+		FeeMin:        s.configFloat(model.ConfigKeyWithdrawFeeMin),
+		FeeMax:        s.configFloat(model.ConfigKeyWithdrawFeeMax),
+		DailyCount:    int(s.configFloat(model.ConfigKeyWithdrawDailyCount)),
+		DailyAmount:   s.configFloat(model.ConfigKeyWithdrawDailyAmount),
+		MonthlyCount:  int(s.configFloat(model.ConfigKeyWithdrawMonthlyCount)),
+		MonthlyAmount: s.configFloat(model.ConfigKeyWithdrawMonthlyAmount),
+		FreePerMonth:  int(s.configFloat(model.ConfigKeyWithdrawFreePerMonth)),
+	}
+}
+
+/**
+ * GetPolicy 读取客户适用的提现策略（全局策略 + 客户专属覆盖）
+ *
+ * @param userID uint - 客户ID
+ * @return WithdrawPolicy
+ */
+func (s *WithdrawPolicyService) GetPolicy(userID uint) WithdrawPolicy {
+	policy := s.GetGlobalPolicy()
+
+	override, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return policy
+	}
+	policy.Overridden = true
+	policy.Note = override.Note
+	if override.MinAmount != nil {
+		policy.MinAmount = *override.MinAmount
+	}
+	if override.FeeFixed != nil {
+		policy.FeeFixed = *override.FeeFixed
+	}
+	if override.FeeRate != nil {
+		policy.FeeRate = *override.FeeRate
+	}
+	if override.FeeMin != nil {
+		policy.FeeMin = *override.FeeMin
+	}
+	if override.FeeMax != nil {
+		policy.FeeMax = *override.FeeMax
+	}
+	if override.DailyCount != nil {
+		policy.DailyCount = *override.DailyCount
+	}
+	if override.DailyAmount != nil {
+		policy.DailyAmount = *override.DailyAmount
+	}
+	if override.MonthlyCount != nil {
+		policy.MonthlyCount = *override.MonthlyCount
+	}
+	if override.MonthlyAmount != nil {
+		policy.MonthlyAmount = *override.MonthlyAmount
+	}
+	if override.FreePerMonth != nil {
+		policy.FreePerMonth = *override.FreePerMonth
+	}
+	return policy
+}
+
+/**
+ * Quote 提现试算：计算手续费明细并校验最低金额与限额
+ *
+ * 校验不通过时同时返回试算结果和错误，便于前端展示额度使用情况
+ *
+ * @param db *gorm.DB - 数据库连接（提交提现时传入事务，在锁定用户行后统计）
+ * @param userID uint - 客户ID
+ * @param amount float64 - 提现金额
+ * @return (*WithdrawQuote, error)
+ */
+func (s *WithdrawPolicyService) Quote(db *gorm.DB, userID uint, amount float64) (*WithdrawQuote, error) {
+	if amount <= 0 {
+		return nil, errors.New("提现金额必须大于0")
+	}
+	amount = roundMoney(amount)
+	policy := s.GetPolicy(userID)
+	quote := &WithdrawQuote{Amount: amount, Policy: policy}
+
+	// 1. 额度使用情况
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	withdrawRepo := repository.NewWithdrawRepository(db)
+	var err error
+	if quote.DailyCountUsed, quote.DailyAmountUsed, err = withdrawRepo.SumActiveSince(userID, dayStart); err != nil {
+		return nil, err
+	}
+	if quote.MonthlyCountUsed, quote.MonthlyAmountUsed, err = withdrawRepo.SumActiveSince(userID, monthStart); err != nil {
+		return nil, err
+	}
+
+	// 2. 手续费：固定 + 比例，再按上下限调整，本月免手续费次数内免收
+	quote.FeeFixed = roundMoney(policy.FeeFixed)
+	quote.FeeRateAmount = roundMoney(amount * policy.FeeRate / 100)
+	fee := roundMoney(quote.FeeFixed + quote.FeeRateAmount)
+	if policy.FeeMin > 0 && fee < policy.FeeMin {
+		fee = roundMoney(policy.FeeMin)
+	}
+	if policy.FeeMax > 0 && fee > policy.FeeMax {
+		fee = roundMoney(policy.FeeMax)
+	}
+	quote.FeeBeforeWaiver = fee
+	if remaining := policy.FreePerMonth - int(quote.MonthlyCountUsed); remaining > 0 {
+		quote.FreeRemaining = remaining
+		if fee > 0 {
+			quote.FeeWaived = true
+			fee = 0
+		}
+	}
+	quote.Fee = fee
+	quote.ActualAmount = roundMoney(amount - fee)
+
+	// 3. 校验
+	if policy.MinAmount > 0 && amount < policy.MinAmount {
+		return quote, fmt.Errorf("单笔提现金额不能低于 %.2f 元", policy.MinAmount)
+	}
+	if quote.ActualAmount <= 0 {
+		return quote, fmt.Errorf("提现金额不足以支付手续费（手续费 %.2f 元）", fee)
+	}
+	if policy.DailyCount > 0 && quote.DailyCountUsed >= int64(policy.DailyCount) {
+		return quote, fmt.Errorf("已达每日提现次数上限（%d 次）", policy.DailyCount)
+	}
+	if policy.DailyAmount > 0 && quote.DailyAmountUsed+amount > policy.DailyAmount+0.005 {
+		return quote, fmt.Errorf("超过每日提现金额上限 %.2f 元（今日已提 %.2f 元）", policy.DailyAmount, quote.DailyAmountUsed)
+	}
+	if policy.MonthlyCount > 0 && quote.MonthlyCountUsed >= int64(policy.MonthlyCount) {
+		return quote, fmt.Errorf("已达每月提现次数上限（%d 次）", policy.MonthlyCount)
+	}
+	if policy.MonthlyAmount > 0 && quote.MonthlyAmountUsed+amount > policy.MonthlyAmount+0.005 {
+		return quote, fmt.Errorf("超过每月提现金额上限 %.2f 元（本月已提 %.2f 元）", policy.MonthlyAmount, quote.MonthlyAmountUsed)
+	}
+
+	return quote, nil
+}
+
+/**
+ * GetOverride 查询客户专属提现策略
+ *
+ * @param userID uint - 客户ID
+ * @return (*model.WithdrawPolicyOverride, error)
+ */
+func (s *WithdrawPolicyService) GetOverride(userID uint) (*model.WithdrawPolicyOverride, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+/**
+ * ListOverrides 查询全部客户专属提现策略
+ *
+ * @return ([]*model.WithdrawPolicyOverride, error)
+ */
+func (s *WithdrawPolicyService) ListOverrides() ([]*model.WithdrawPolicyOverride, error) {
+	return s.repo.FindAll()
+}
+
+/**
+ * SaveOverride 设置客户专属提现策略（整体覆盖，未填写的字段沿用全局配置）
+ *
+ * @param userID uint - 客户ID
+ * @param input model.WithdrawPolicyOverride - 策略内容
+ * @param operatorID uint - 操作管理员
+ * @return (*model.WithdrawPolicyOverride, error)
+ */
+func (s *WithdrawPolicyService) SaveOverride(userID uint, input model.WithdrawPolicyOverride, operatorID uint) (*model.WithdrawPolicyOverride, error) {
+	if _, err := repository.NewUserRepository(s.ctx.DB).FindByID(userID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	for _, v := range []*float64{input.FeeFixed, input.FeeRate, input.FeeMin, input.FeeMax, input.MinAmount, input.DailyAmount, input.MonthlyAmount} {
+		if v != nil && *v < 0 {
+			return nil, errors.New("金额和比例不能为负数")
+		}
+	}
+	for _, v := range []*int{input.DailyCount, input.MonthlyCount, input.FreePerMonth} {
+		if v != nil && *v < 0 {
+			return nil, errors.New("次数不能为负数")
+		}
+	}
+	if input.FeeRate != nil && *input.FeeRate >= 100 {
+		return nil, errors.New("手续费比例必须小于100%")
+	}
+	if input.FeeMin != nil && input.FeeMax != nil && *input.FeeMax > 0 && *input.FeeMin > *input.FeeMax {
+		return nil, errors.New("手续费下限不能高于上限")
+	}
+
+	override := &input
+	if existing, err := s.repo.FindByUserID(userID); err == nil {
+		override.ID = existing.ID
+		override.CreatedAt = existing.CreatedAt
+	}
+	override.UserID = userID
+	override.OperatorID = operatorID
+	if err := s.repo.Save(override); err != nil {
+		return nil, fmt.Errorf("保存提现策略失败: %v", err)
+	}
+	return override, nil
+}
+
+/**
+ * DeleteOverride 删除客户专属提现策略（恢复使用全局配置）
+ *
+ * @param userID uint - 客户ID
+ * @return error
+ */
+func (s *WithdrawPolicyService) DeleteOverride(userID uint) error {
+	return s.repo.DeleteByUserID(userID)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"suxin/internal/model"
+)
+
+func TestWithdrawPolicyQuote(t *testing.T) {
+	floatPtr := func(v float64) *float64 { return &v }
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name     string
+		configs  map[string]string
+		override *model.WithdrawPolicyOverride
+		previous []model.WithdrawRequest // 本月已有的提现申请
+		amount   float64
+
+		wantFee, wantActual float64
+		wantWaived          bool
+		wantErr             string // 错误信息包含的内容（为空表示通过）
+	}{
+		{
+			name:    "fixed plus rate",
+			configs: map[string]string{model.ConfigKeyWithdrawFeeFixed: "2", model.ConfigKeyWithdrawFeeRate: "0.5"},
+			amount:  1000, wantFee: 7, wantActual: 993,
+		},
+		{
+			name:    "fee raised to minimum",
+			configs: map[string]string{model.ConfigKeyWithdrawFeeRate: "0.1", model.ConfigKeyWithdrawFeeMin: "5"},
+			amount:  1000, wantFee: 5, wantActual: 995,
+		},
+		{
+			name:    "fee capped at maximum",
+			configs: map[string]string{model.ConfigKeyWithdrawFeeRate: "1", model.ConfigKeyWithdrawFeeMax: "50"},
+			amount:  10000, wantFee: 50, wantActual: 9950,
+		},
+		{
+			name:     "free withdrawal remaining this month",
+			configs:  map[string]string{model.ConfigKeyWithdrawFeeFixed: "3", model.ConfigKeyWithdrawFreePerMonth: "2"},
+			previous: []model.WithdrawRequest{{Amount: 100, Status: model.WithdrawStatusPaid}},
+			amount:   500, wantFee: 0, wantActual: 500, wantWaived: true,
+		},
+		{
+			name:     "free withdrawals used up",
+			configs:  map[string]string{model.ConfigKeyWithdrawFeeFixed: "3", model.ConfigKeyWithdrawFreePerMonth: "1"},
+			previous: []model.WithdrawRequest{{Amount: 100, Status: model.WithdrawStatusApproved}},
+			amount:   500, wantFee: 3, wantActual: 497,
+		},
+		{
+			name:     "rejected withdrawals do not count",
+			configs:  map[string]string{model.ConfigKeyWithdrawFeeFixed: "3", model.ConfigKeyWithdrawFreePerMonth: "1"},
+			previous: []model.WithdrawRequest{{Amount: 100, Status: model.WithdrawStatusRejected}},
+			amount:   500, wantFee: 0, wantActual: 500, wantWaived: true,
+		},
+		{
+			name:    "below minimum amount",
+			configs: map[string]string{model.ConfigKeyMinWithdrawAmount: "100"},
+			amount:  50, wantActual: 50, wantErr: "不能低于",
+		},
+		{
+			name:    "legacy minimum key",
+			configs: map[string]string{model.ConfigKeyMinWithdraw: "100"},
+			amount:  50, wantActual: 50, wantErr: "不能低于",
+		},
+		{
+			name:    "amount does not cover fee",
+			configs: map[string]string{model.ConfigKeyWithdrawFeeFixed: "10"},
+			amount:  10, wantFee: 10, wantErr: "不足以支付手续费",
+		},
+		{
+			name:     "daily count reached",
+			configs:  map[string]string{model.ConfigKeyWithdrawDailyCount: "1"},
+			previous: []model.WithdrawRequest{{Amount: 100, Status: model.WithdrawStatusPending}},
+			amount:   100, wantActual: 100, wantErr: "每日提现次数",
+		},
+		{
+			name:     "daily amount exceeded",
+			configs:  map[string]string{model.ConfigKeyWithdrawDailyAmount: "1000"},
+			previous: []model.WithdrawRequest{{Amount: 800, Status: model.WithdrawStatusReviewing}},
+			amount:   300, wantActual: 300, wantErr: "每日提现金额",
+		},
+		{
+			name:     "daily amount exactly reached",
+			configs:  map[string]string{model.ConfigKeyWithdrawDailyAmount: "1000"},
+			previous: []model.WithdrawRequest{{Amount: 800, Status: model.WithdrawStatusReviewing}},
+			amount:   200, wantActual: 200,
+		},
+		{
+			name:    "monthly count reached",
+			configs: map[string]string{model.ConfigKeyWithdrawMonthlyCount: "2"},
+			previous: []model.WithdrawRequest{
+				{Amount: 100, Status: model.WithdrawStatusPaid},
+				{Amount: 100, Status: model.WithdrawStatusPaid},
+			},
+			amount: 100, wantActual: 100, wantErr: "每月提现次数",
+		},
+		{
+			name:     "monthly amount exceeded",
+			configs:  map[string]string{model.ConfigKeyWithdrawMonthlyAmount: "500"},
+			previous: []model.WithdrawRequest{{Amount: 400, Status: model.WithdrawStatusPaid}},
+			amount:   200, wantActual: 200, wantErr: "每月提现金额",
+		},
+		{
+			name:     "customer override replaces global policy",
+			configs:  map[string]string{model.ConfigKeyWithdrawFeeFixed: "5", model.ConfigKeyWithdrawDailyCount: "1"},
+			override: &model.WithdrawPolicyOverride{FeeFixed: floatPtr(0), DailyCount: intPtr(3)},
+			previous: []model.WithdrawRequest{{Amount: 100, Status: model.WithdrawStatusPaid}},
+			amount:   100, wantFee: 0, wantActual: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			user := createTestUser(t, ctx, &model.User{AvailableDeposit: 100000})
+			for key, value := range tt.configs {
+				setTestConfig(t, ctx, key, value)
+			}
+			for i := range tt.previous {
+				w := tt.previous[i]
+				w.UserID = user.ID
+				w.BankCardID = 1
+				w.ActualAmount = w.Amount
+				if err := ctx.DB.Create(&w).Error; err != nil {
+					t.Fatalf("create withdraw: %v", err)
+				}
+			}
+			svc := NewWithdrawPolicyService(ctx)
+			if tt.override != nil {
+				if _, err := svc.SaveOverride(user.ID, *tt.override, 1); err != nil {
+					t.Fatalf("save override: %v", err)
+				}
+			}
+
+			quote, err := svc.Quote(ctx.DB, user.ID, tt.amount)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Quote: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+			if quote.Fee != tt.wantFee || quote.FeeWaived != tt.wantWaived {
+				t.Errorf("fee = %.2f (waived %v), want %.2f (waived %v)", quote.Fee, quote.FeeWaived, tt.wantFee, tt.wantWaived)
+			}
+			if tt.wantErr == "" || tt.wantActual != 0 {
+				if quote.ActualAmount != tt.wantActual {
+					t.Errorf("actual amount = %.2f, want %.2f", quote.ActualAmount, tt.wantActual)
+				}
+			}
+		})
+	}
+}
+
+func TestWithdrawPolicyQuoteRejectsNonPositive(t *testing.T) {
+	ctx := newTestContext(t)
+	if _, err := NewWithdrawPolicyService(ctx).Quote(ctx.DB, 1, 0); err == nil {
+		t.Fatal("expected error for zero amount")
+	}
+}
@@ -6,6 +6,15 @@
  * - 处理提现申请和审核
  * - 管理资金扣减和流水
  * 
+ * 资金流程：
+ * - 提交申请：可用定金转入提现冻结（withdraw_freeze），冻结部分不能再用于下单
+ * - 审核通过：扣减提现冻结，到账金额记提现（withdraw），手续费单独记一笔（withdraw_fee）
+ * - 驳回/用户取消：提现冻结退回可用定金（withdraw_release）
+ * 
+ * 审批流程：
+ * - 超过双人审批阈值的提现，初审通过后进入待复核（reviewing），由另一位超级管理员复核后才扣款
+ * - 每一步审批记录在审批链中，客户本人不能审批，同一审核人不能既初审又复核
+ * 
  * 作者：速金盈技术团队
  * 日期：2025-11
  */
@@ -16,6 +25,8 @@ import (
 	"errors"
 	"fmt"
 
+	"gorm.io/gorm"
+
 	"suxin/internal/appctx"
 	"suxin/internal/model"
 	"suxin/internal/repository"
@@ -28,6 +39,9 @@ type WithdrawService struct {
 	fundLogRepo  *repository.FundLogRepository
 	cardRepo     *repository.BankCardRepository
 	notiSvc      *NotificationService
+	policySvc    *WithdrawPolicyService
+	approvalSvc  *ApprovalService
+	fileSvc      *FileService
 }
 
 func NewWithdrawService(ctx *appctx.AppContext) *WithdrawService {
@@ -38,11 +52,14 @@ func NewWithdrawService(ctx *appctx.AppContext) *WithdrawService {
 		fundLogRepo:  repository.NewFundLogRepository(ctx.DB),
 		cardRepo:     repository.NewBankCardRepository(ctx.DB),
 		notiSvc:      NewNotificationService(ctx),
+		policySvc:    NewWithdrawPolicyService(ctx),
+		approvalSvc:  NewApprovalService(ctx),
+		fileSvc:      NewFileService(ctx),
 	}
 }
 
 /**
- * SubmitWithdraw 提交提现申请
+ * SubmitWithdraw 提交提现申请（同时冻结提现金额）
  * 
  * @param userID uint - 用户ID
  * @param bankCardID uint - 银行卡ID
@@ -71,65 +88,97 @@ func (s *WithdrawService) SubmitWithdraw(userID, bankCardID uint, amount float64
 		return nil, errors.New("银行卡不属于当前用户")
 	}
 
-	// 4. 计算手续费（可配置，这里设为0）
-	fee := 0.0
-	actualAmount := amount - fee
-
-	// 5. 验证余额是否充足
+	// 4. 验证余额是否充足（提交时以锁定后的余额为准，这里提前给出友好提示）
 	if user.AvailableDeposit < amount {
 		return nil, fmt.Errorf("可用余额不足，当前可用: %.2f", user.AvailableDeposit)
 	}
 
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 5. 锁定用户行后按提现策略计算手续费并校验限额，避免并发提交绕过限额
+	if _, err := repository.NewUserRepository(tx).LockByID(userID); err != nil {
+		tx.Rollback()
+		return nil, errors.New("用户不存在")
+	}
+	quote, err := s.policySvc.Quote(tx, userID, amount)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// 6. 创建提现申请
 	withdraw := &model.WithdrawRequest{
 		UserID:       userID,
 		BankCardID:   bankCardID,
-		Amount:       amount,
-		Fee:          fee,
-		ActualAmount: actualAmount,
+		Amount:       quote.Amount,
+		Fee:          quote.Fee,
+		ActualAmount: quote.ActualAmount,
+		FeeDetail:    quote.FeeDescription(),
 		UserNote:     note,
+		Frozen:       true,
 		Status:       model.WithdrawStatusPending,
+		ApprovalChain: model.ApprovalChain{
+			RequiredApprovals: s.approvalSvc.RequiredApprovals(model.ApprovalTargetWithdraw, quote.Amount),
+		},
 	}
-
-	if err := s.withdrawRepo.Create(withdraw); err != nil {
+	if err := repository.NewWithdrawRepository(tx).Create(withdraw); err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("创建提现申请失败: %v", err)
 	}
 
+	// 7. 冻结提现金额并记录资金流水
+	if _, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+		UserID:         userID,
+		Type:           model.FundLogTypeWithdrawFreeze,
+		AvailableDelta: -withdraw.Amount,
+		FrozenDelta:    withdraw.Amount,
+		RelatedID:      withdraw.ID,
+		RelatedType:    "withdraw",
+		Note:           fmt.Sprintf("提现冻结: %.2f元", withdraw.Amount),
+	}); err != nil {
+		tx.Rollback()
+		var insufficient *InsufficientBalanceError
+		if errors.As(err, &insufficient) {
+			return nil, fmt.Errorf("可用余额不足，当前可用: %.2f", insufficient.Available)
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("事务提交失败: %v", err)
+	}
+
 	return withdraw, nil
 }
 
 /**
  * ApproveWithdraw 审核通过提现
  * 
+ * 需要多级审批时，未到最后一级只记录审批并转为待复核，最后一级通过后才扣款
+ * 
  * @param withdrawID uint - 提现ID
  * @param reviewerID uint - 审核人ID
  * @param note string - 审核备注
- * @return error
+ * @return (*model.WithdrawRequest, error) - 审批后的提现申请
  */
-func (s *WithdrawService) ApproveWithdraw(withdrawID, reviewerID uint, note string) error {
+func (s *WithdrawService) ApproveWithdraw(withdrawID, reviewerID uint, note string) (*model.WithdrawRequest, error) {
 	// 1. 查找提现申请
 	withdraw, err := s.withdrawRepo.FindByID(withdrawID)
 	if err != nil {
-		return errors.New("提现申请不存在")
+		return nil, errors.New("提现申请不存在")
 	}
 
 	// 2. 验证状态
-	if !withdraw.IsPending() {
-		return fmt.Errorf("提现状态不允许审核（当前状态: %s）", withdraw.Status)
+	if !withdraw.IsAwaitingApproval() {
+		return nil, fmt.Errorf("提现状态不允许审核（当前状态: %s）", withdraw.Status)
 	}
 
-	// 3. 查询用户
-	user, err := s.userRepo.FindByID(withdraw.UserID)
-	if err != nil {
-		return errors.New("用户不存在")
-	}
-
-	// 4. 再次验证余额
-	if user.AvailableDeposit < withdraw.Amount {
-		return errors.New("用户余额不足")
-	}
-
-	// 5. 开启事务
+	// 3. 开启事务
 	tx := s.ctx.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -137,57 +186,95 @@ func (s *WithdrawService) ApproveWithdraw(withdrawID, reviewerID uint, note stri
 		}
 	}()
 
-	// 6. 扣减用户余额
-	newAvailable := user.AvailableDeposit - withdraw.Amount
-	if err := tx.Model(&model.User{}).Where("id = ?", user.ID).
-		Update("available_deposit", newAvailable).Error; err != nil {
+	// 4. 锁定提现申请并再次确认状态，防止重复扣款
+	withdraw, err = repository.NewWithdrawRepository(tx).LockByID(withdrawID)
+	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("扣减余额失败: %v", err)
-	}
-
-	// 7. 记录资金流水
-	fundLog := &model.FundLog{
-		UserID:          user.ID,
-		Type:            model.FundLogTypeWithdraw,
-		Amount:          -withdraw.Amount,
-		AvailableBefore: user.AvailableDeposit,
-		AvailableAfter:  newAvailable,
-		UsedBefore:      user.UsedDeposit,
-		UsedAfter:       user.UsedDeposit,
-		RelatedID:       withdraw.ID,
-		RelatedType:     "withdraw",
-		Note:            fmt.Sprintf("提现: %.2f元", withdraw.Amount),
-	}
-	if err := tx.Create(fundLog).Error; err != nil {
+		return nil, errors.New("提现申请不存在")
+	}
+	if !withdraw.IsAwaitingApproval() {
+		tx.Rollback()
+		return nil, fmt.Errorf("提现状态不允许审核（当前状态: %s）", withdraw.Status)
+	}
+
+	// 5. 记录审批步骤，未到最后一级时转为待复核
+	final, err := s.approvalSvc.Approve(tx, s.approvalTarget(withdraw), reviewerID, note)
+	if err != nil {
 		tx.Rollback()
-		return fmt.Errorf("记录流水失败: %v", err)
+		return nil, err
+	}
+	if !final {
+		withdraw.PassStep()
+		if err := tx.Save(withdraw).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("更新提现状态失败: %v", err)
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, fmt.Errorf("事务提交失败: %v", err)
+		}
+
+		s.notiSvc.SendSystemNotificationToAdmins("大额提现待复核",
+			fmt.Sprintf("提现申请 #%d（%.2f 元）已初审通过，需超级管理员复核", withdraw.ID, withdraw.Amount), "")
+		return withdraw, nil
 	}
 
-	// 8. 更新提现状态
+	// 6. 扣减提现冻结并记录资金流水：到账金额、手续费各记一笔（提交时未冻结的早期申请直接扣减可用定金）
+	changes := []BalanceChange{{
+		UserID:      withdraw.UserID,
+		Type:        model.FundLogTypeWithdraw,
+		RelatedID:   withdraw.ID,
+		RelatedType: "withdraw",
+		Note:        fmt.Sprintf("提现: %.2f元", withdraw.ActualAmount),
+	}}
+	setWithdrawDeduction(&changes[0], withdraw.Frozen, withdraw.ActualAmount)
+	if withdraw.Fee > 0 {
+		feeChange := BalanceChange{
+			UserID:      withdraw.UserID,
+			Type:        model.FundLogTypeWithdrawFee,
+			Fee:         withdraw.Fee,
+			RelatedID:   withdraw.ID,
+			RelatedType: "withdraw",
+			Note:        fmt.Sprintf("提现手续费: %.2f元（%s）", withdraw.Fee, withdraw.FeeDetail),
+		}
+		setWithdrawDeduction(&feeChange, withdraw.Frozen, withdraw.Fee)
+		changes = append(changes, feeChange)
+	}
+	for _, change := range changes {
+		if _, err := NewBalanceService(s.ctx).Apply(tx, change); err != nil {
+			tx.Rollback()
+			var insufficient *InsufficientBalanceError
+			if errors.As(err, &insufficient) {
+				return nil, errors.New("用户余额不足")
+			}
+			return nil, err
+		}
+	}
+
+	// 7. 更新提现状态
 	withdraw.Approve(reviewerID, note)
 	if err := tx.Save(withdraw).Error; err != nil {
 		tx.Rollback()
-		return fmt.Errorf("更新提现状态失败: %v", err)
+		return nil, fmt.Errorf("更新提现状态失败: %v", err)
 	}
 
-	// 9. 提交事务
+	// 8. 提交事务
 	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("事务提交失败: %v", err)
+		return nil, fmt.Errorf("事务提交失败: %v", err)
 	}
 
-	// 10. 发送通知
+	// 9. 发送通知
 	notifyMsg := fmt.Sprintf("您的提现申请已通过\n提现金额：%.2f 元\n预计到账：%.2f 元",
 		withdraw.Amount, withdraw.ActualAmount)
-	s.notiSvc.SendFundNotification(user.ID, "提现通过", notifyMsg)
+	s.notiSvc.SendFundNotification(withdraw.UserID, "提现通过", notifyMsg)
 
-	return nil
+	return withdraw, nil
 }
 
 /**
  * MarkWithdrawPaid 标记提现已打款并保存打款凭证
  *
  * @param withdrawID uint - 提现ID
- * @param voucherURL string - 打款凭证（Base64或URL）
+ * @param voucherURL string - 打款凭证（文件引用、Base64或URL）
  * @return error
  */
 func (s *WithdrawService) MarkWithdrawPaid(withdrawID uint, voucherURL string) error {
@@ -200,6 +287,12 @@ func (s *WithdrawService) MarkWithdrawPaid(withdrawID uint, voucherURL string) e
 		return errors.New("只有已通过的提现才能标记为已打款")
 	}
 
+	// 打款凭证转存到文件存储，申请中只保存文件引用
+	voucherURL, err = s.fileSvc.Ingest(voucherURL, model.FileCategoryWithdrawVoucher, 0)
+	if err != nil {
+		return fmt.Errorf("保存打款凭证失败: %v", err)
+	}
+
 	withdraw.MarkAsPaid(voucherURL)
 	if err := s.withdrawRepo.Update(withdraw); err != nil {
 		return fmt.Errorf("更新提现状态失败: %v", err)
@@ -212,7 +305,7 @@ func (s *WithdrawService) MarkWithdrawPaid(withdrawID uint, voucherURL string) e
 }
 
 /**
- * RejectWithdraw 驳回提现
+ * RejectWithdraw 驳回提现（退回冻结金额）
  * 
  * @param withdrawID uint - 提现ID
  * @param reviewerID uint - 审核人ID
@@ -220,17 +313,14 @@ func (s *WithdrawService) MarkWithdrawPaid(withdrawID uint, voucherURL string) e
  * @return error
  */
 func (s *WithdrawService) RejectWithdraw(withdrawID, reviewerID uint, note string) error {
-	withdraw, err := s.withdrawRepo.FindByID(withdrawID)
+	withdraw, err := s.closePending(withdrawID, func(tx *gorm.DB, withdraw *model.WithdrawRequest) error {
+		if err := s.approvalSvc.Reject(tx, s.approvalTarget(withdraw), reviewerID, note); err != nil {
+			return err
+		}
+		withdraw.Reject(reviewerID, note)
+		return nil
+	}, "提现驳回，退回冻结金额")
 	if err != nil {
-		return errors.New("提现申请不存在")
-	}
-
-	if !withdraw.IsPending() {
-		return errors.New("提现状态不允许审核")
-	}
-
-	withdraw.Reject(reviewerID, note)
-	if err := s.withdrawRepo.Update(withdraw); err != nil {
 		return err
 	}
 
@@ -241,6 +331,84 @@ func (s *WithdrawService) RejectWithdraw(withdrawID, reviewerID uint, note strin
 	return nil
 }
 
+/**
+ * CancelWithdraw 用户取消自己审批中的提现申请（退回冻结金额）
+ * 
+ * @param withdrawID uint - 提现ID
+ * @param userID uint - 当前用户ID
+ * @return error
+ */
+func (s *WithdrawService) CancelWithdraw(withdrawID, userID uint) error {
+	withdraw, err := s.withdrawRepo.FindByID(withdrawID)
+	if err != nil || withdraw.UserID != userID {
+		return errors.New("提现申请不存在")
+	}
+
+	_, err = s.closePending(withdrawID, func(tx *gorm.DB, withdraw *model.WithdrawRequest) error {
+		withdraw.Cancel()
+		return nil
+	}, "取消提现，退回冻结金额")
+	return err
+}
+
+/**
+ * closePending 在事务中结束一笔审批中的提现（驳回/取消），并退回提交时冻结的金额
+ * 
+ * @param withdrawID uint - 提现ID
+ * @param update func(*gorm.DB, *model.WithdrawRequest) error - 在事务中更新申请状态
+ * @param releaseNote string - 解冻流水备注
+ * @return (*model.WithdrawRequest, error)
+ */
+func (s *WithdrawService) closePending(withdrawID uint, update func(*gorm.DB, *model.WithdrawRequest) error, releaseNote string) (*model.WithdrawRequest, error) {
+	tx := s.ctx.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定提现申请并确认状态，防止与审核通过并发
+	withdraw, err := repository.NewWithdrawRepository(tx).LockByID(withdrawID)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("提现申请不存在")
+	}
+	if !withdraw.IsAwaitingApproval() {
+		tx.Rollback()
+		return nil, fmt.Errorf("提现状态不允许操作（当前状态: %s）", withdraw.Status)
+	}
+	if err := update(tx, withdraw); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Save(withdraw).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("更新提现状态失败: %v", err)
+	}
+
+	// 提交时未冻结的早期申请无需解冻
+	if withdraw.Frozen {
+		if _, err := NewBalanceService(s.ctx).Apply(tx, BalanceChange{
+			UserID:         withdraw.UserID,
+			Type:           model.FundLogTypeWithdrawRelease,
+			AvailableDelta: withdraw.Amount,
+			FrozenDelta:    -withdraw.Amount,
+			RelatedID:      withdraw.ID,
+			RelatedType:    "withdraw",
+			Note:           fmt.Sprintf("%s: %.2f元", releaseNote, withdraw.Amount),
+		}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("事务提交失败: %v", err)
+	}
+
+	return withdraw, nil
+}
+
 /**
  * GetUserWithdraws 查询用户提现记录
  */
@@ -265,3 +433,36 @@ func (s *WithdrawService) GetPendingWithdraws(limit int) ([]*model.WithdrawReque
 func (s *WithdrawService) GetWithdrawsByStatus(status string, limit int) ([]*model.WithdrawRequest, error) {
 	return s.withdrawRepo.FindByStatus(status, limit)
 }
+
+/**
+ * GetWithdrawApprovals 查询提现申请的审批记录
+ *
+ * @param withdrawID uint - 提现ID
+ * @return ([]*model.ApprovalStep, error)
+ */
+func (s *WithdrawService) GetWithdrawApprovals(withdrawID uint) ([]*model.ApprovalStep, error) {
+	return s.approvalSvc.ListSteps(model.ApprovalTargetWithdraw, withdrawID)
+}
+
+/**
+ * approvalTarget 提现申请对应的审批对象（发起人为提现客户）
+ */
+func (s *WithdrawService) approvalTarget(withdraw *model.WithdrawRequest) ApprovalTarget {
+	return ApprovalTarget{
+		Type:        model.ApprovalTargetWithdraw,
+		ID:          withdraw.ID,
+		RequesterID: withdraw.UserID,
+		Chain:       &withdraw.ApprovalChain,
+	}
+}
+
+/**
+ * setWithdrawDeduction 设置提现扣款金额：已冻结的申请扣提现冻结，早期未冻结的申请扣可用定金（两种方式流水金额均为扣款金额，见 fundLogAmount）
+ */
+func setWithdrawDeduction(change *BalanceChange, frozen bool, amount float64) {
+	if frozen {
+		change.FrozenDelta = -amount
+	} else {
+		change.AvailableDelta = -amount
+	}
+}
@@ -0,0 +1,244 @@
+/**
+ * 行情Tick校验
+ *
+ * 用途：
+ * - 在Tick进入风控、K线和前端推送之前拦截异常报价
+ * - 被拒绝的Tick写入隔离日志，供管理员复核
+ *
+ * 校验规则（按顺序）：
+ * 1. 回购价高于销售价：拒绝
+ * 2. 同一行情源同一品种推送间隔小于最小间隔：拒绝（需显式配置最小间隔，默认不限制）
+ * 3. 配置多个行情源时，与其他行情源最新报价的中位数偏差过大：拒绝；一致则直接通过
+ *    （多源一致说明是真实行情变化，即使偏离本地中位数也接受）
+ * 4. 偏离滚动中位数过大：拒绝；连续多个彼此一致的偏离Tick视为真实跳变，重置窗口后接受
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package websocket
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/config"
+)
+
+const (
+	defaultFilterMaxDeviation  = 0.02  // 默认偏离滚动中位数的最大比例
+	defaultFilterMedianWindow  = 20    // 默认滚动中位数窗口
+	defaultFilterConfirmTicks  = 5     // 默认跳变确认Tick数
+	defaultFilterMaxSourceDiff = 0.005 // 默认多源最大偏差比例
+	minMedianSamples           = 5     // 窗口内少于该数量时不做中位数校验
+)
+
+/**
+ * quoteFilter 行情Tick校验器
+ */
+type quoteFilter struct {
+	disabled      bool
+	maxDeviation  float64
+	window        int
+	confirmTicks  int
+	minInterval   time.Duration
+	maxSourceDiff float64
+
+	mu      sync.Mutex
+	symbols map[string]*symbolFilterState
+}
+
+/**
+ * symbolFilterState 单个品种的校验状态
+ */
+type symbolFilterState struct {
+	prices     []float64 // 最近接受的销售价（滚动窗口）
+	suspects   []float64 // 连续偏离的销售价（跳变确认）
+	lastSource string    // 最近一次Tick的行情源
+	lastAt     time.Time // 最近一次Tick的时间
+}
+
+/**
+ * newQuoteFilter 按配置创建校验器
+ *
+ * @param cfg config.QuoteFilterConfig - 过滤配置
+ * @return *quoteFilter
+ */
+func newQuoteFilter(cfg config.QuoteFilterConfig) *quoteFilter {
+	f := &quoteFilter{
+		disabled:      cfg.Disabled,
+		maxDeviation:  cfg.MaxDeviation,
+		window:        cfg.MedianWindow,
+		confirmTicks:  cfg.ConfirmTicks,
+		minInterval:   time.Duration(cfg.MinTickIntervalMs) * time.Millisecond,
+		maxSourceDiff: cfg.MaxSourceDiff,
+		symbols:       make(map[string]*symbolFilterState),
+	}
+	if f.maxDeviation <= 0 {
+		f.maxDeviation = defaultFilterMaxDeviation
+	}
+	if f.window <= 0 {
+		f.window = defaultFilterMedianWindow
+	}
+	if f.confirmTicks <= 0 {
+		f.confirmTicks = defaultFilterConfirmTicks
+	}
+	if f.maxSourceDiff <= 0 {
+		f.maxSourceDiff = defaultFilterMaxSourceDiff
+	}
+	return f
+}
+
+/**
+ * medianOf 计算中位数（不修改入参）
+ */
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+/**
+ * deviation 计算相对偏差比例
+ */
+func deviation(price, reference float64) float64 {
+	if reference <= 0 {
+		return 0
+	}
+	return math.Abs(price-reference) / reference
+}
+
+/**
+ * Check 校验一批Tick
+ *
+ * @param source string - 行情源名称
+ * @param quotes []vendorQuote - 待校验报价
+ * @param references map[string][]float64 - 其他行情源的最新销售价（按品种）
+ * @param now time.Time - 收到时间
+ * @return ([]vendorQuote, []*model.QuoteQuarantine) - 通过的报价、被拒绝的Tick
+ */
+func (f *quoteFilter) Check(source string, quotes []vendorQuote, references map[string][]float64, now time.Time) ([]vendorQuote, []*model.QuoteQuarantine) {
+	if f.disabled {
+		return quotes, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	accepted := make([]vendorQuote, 0, len(quotes))
+	var rejected []*model.QuoteQuarantine
+	for _, q := range quotes {
+		reason, reference, detail := f.checkOne(source, q, references[q.Symbol], now)
+		if reason == "" {
+			accepted = append(accepted, q)
+			continue
+		}
+		rejected = append(rejected, &model.QuoteQuarantine{
+			Symbol:    q.Symbol,
+			Source:    source,
+			Buy:       q.Buy,
+			Sell:      q.Sell,
+			Reference: reference,
+			Reason:    reason,
+			Detail:    detail,
+			TickAt:    now,
+		})
+	}
+	return accepted, rejected
+}
+
+/**
+ * checkOne 校验单个Tick（调用方持有锁）
+ *
+ * @return (string, float64, string) - 拒绝原因（空表示通过）、参考价、说明
+ */
+func (f *quoteFilter) checkOne(source string, q vendorQuote, references []float64, now time.Time) (string, float64, string) {
+	state := f.symbols[q.Symbol]
+	if state == nil {
+		state = &symbolFilterState{}
+		f.symbols[q.Symbol] = state
+	}
+
+	if q.Buy > q.Sell {
+		return model.QuarantineReasonCrossed, 0, fmt.Sprintf("回购价 %.2f 高于销售价 %.2f", q.Buy, q.Sell)
+	}
+
+	if f.minInterval > 0 && state.lastSource == source && now.Sub(state.lastAt) < f.minInterval {
+		return model.QuarantineReasonTooFrequent, 0,
+			fmt.Sprintf("距上一Tick %dms，小于最小间隔 %dms", now.Sub(state.lastAt).Milliseconds(), f.minInterval.Milliseconds())
+	}
+	state.lastSource = source
+	state.lastAt = now
+
+	// 多源交叉校验：一致则视为真实行情
+	if len(references) > 0 {
+		reference := medianOf(references)
+		if d := deviation(q.Sell, reference); d > f.maxSourceDiff {
+			return model.QuarantineReasonSourceDisagree, reference,
+				fmt.Sprintf("与其他%d个行情源中位数偏差 %.2f%%，上限 %.2f%%", len(references), d*100, f.maxSourceDiff*100)
+		}
+		if len(state.prices) >= minMedianSamples && deviation(q.Sell, medianOf(state.prices)) > f.maxDeviation {
+			state.prices = state.prices[:0]
+		}
+		f.accept(state, q.Sell)
+		return "", 0, ""
+	}
+
+	if len(state.prices) < minMedianSamples {
+		f.accept(state, q.Sell)
+		return "", 0, ""
+	}
+
+	median := medianOf(state.prices)
+	d := deviation(q.Sell, median)
+	if d <= f.maxDeviation {
+		f.accept(state, q.Sell)
+		return "", 0, ""
+	}
+
+	// 连续偏离且彼此一致：视为真实跳变
+	state.suspects = append(state.suspects, q.Sell)
+	if len(state.suspects) >= f.confirmTicks {
+		suspects := state.suspects[len(state.suspects)-f.confirmTicks:]
+		level := medianOf(suspects)
+		consistent := true
+		for _, p := range suspects {
+			if deviation(p, level) > f.maxDeviation {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			log.Printf("[QuoteProxy] ⚠️ %s 价格跳变已确认: %.2f → %.2f（连续%d个Tick）", q.Symbol, median, level, f.confirmTicks)
+			state.prices = append(state.prices[:0], suspects...)
+			state.suspects = nil
+			return "", 0, ""
+		}
+		state.suspects = append(state.suspects[:0], suspects...)
+	}
+	return model.QuarantineReasonDeviation, median,
+		fmt.Sprintf("偏离滚动中位数 %.2f%%，上限 %.2f%%", d*100, f.maxDeviation*100)
+}
+
+/**
+ * accept 记录通过的价格（调用方持有锁）
+ */
+func (f *quoteFilter) accept(state *symbolFilterState, price float64) {
+	state.suspects = nil
+	state.prices = append(state.prices, price)
+	if len(state.prices) > f.window {
+		state.prices = state.prices[len(state.prices)-f.window:]
+	}
+}
@@ -0,0 +1,129 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/config"
+)
+
+func TestQuoteFilterCheck(t *testing.T) {
+	type tick struct {
+		source string
+		buy    float64
+		sell   float64
+		after  time.Duration // 距上一Tick的时间
+		refs   []float64     // 其他行情源最新销售价
+		want   string        // 期望的拒绝原因（空表示通过）
+	}
+	// 预热：5个500附近的Tick，使滚动中位数生效
+	warmup := []tick{
+		{sell: 500, after: time.Second}, {sell: 500.2, after: time.Second}, {sell: 499.8, after: time.Second},
+		{sell: 500.1, after: time.Second}, {sell: 499.9, after: time.Second},
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.QuoteFilterConfig
+		ticks []tick
+	}{
+		{
+			name:  "normal ticks pass",
+			ticks: append(warmup, tick{sell: 501, after: time.Second}),
+		},
+		{
+			name:  "crossed quote rejected",
+			ticks: []tick{{buy: 501, sell: 500, want: model.QuarantineReasonCrossed}},
+		},
+		{
+			name: "fast ticks pass by default",
+			ticks: []tick{
+				{sell: 500, after: time.Second},
+				{sell: 500.1, after: 10 * time.Millisecond},
+				{sell: 500.2, after: 10 * time.Millisecond},
+			},
+		},
+		{
+			name: "fast ticks rejected when interval configured",
+			cfg:  config.QuoteFilterConfig{MinTickIntervalMs: 100},
+			ticks: []tick{
+				{sell: 500, after: time.Second},
+				{sell: 500.1, after: 50 * time.Millisecond, want: model.QuarantineReasonTooFrequent},
+				{sell: 500.1, after: 200 * time.Millisecond},
+			},
+		},
+		{
+			name: "interval is tracked per source",
+			cfg:  config.QuoteFilterConfig{MinTickIntervalMs: 100},
+			ticks: []tick{
+				{source: "a", sell: 500, after: time.Second},
+				{source: "b", sell: 500.1, after: 10 * time.Millisecond},
+			},
+		},
+		{
+			name:  "spike rejected against rolling median",
+			ticks: append(warmup, tick{sell: 520, after: time.Second, want: model.QuarantineReasonDeviation}),
+		},
+		{
+			name: "consistent jump confirmed after confirm ticks",
+			cfg:  config.QuoteFilterConfig{ConfirmTicks: 3},
+			ticks: append(warmup,
+				tick{sell: 520, after: time.Second, want: model.QuarantineReasonDeviation},
+				tick{sell: 520.5, after: time.Second, want: model.QuarantineReasonDeviation},
+				tick{sell: 520.2, after: time.Second},
+				tick{sell: 520.4, after: time.Second},
+			),
+		},
+		{
+			name: "other sources confirm the move",
+			ticks: append(warmup,
+				tick{sell: 520, after: time.Second, refs: []float64{520.1, 519.9}},
+				tick{sell: 520.3, after: time.Second},
+			),
+		},
+		{
+			name:  "other sources disagree",
+			ticks: append(warmup, tick{sell: 505, after: time.Second, refs: []float64{500, 500.1}, want: model.QuarantineReasonSourceDisagree}),
+		},
+		{
+			name:  "disabled filter accepts everything",
+			cfg:   config.QuoteFilterConfig{Disabled: true},
+			ticks: append(warmup, tick{buy: 600, sell: 520, after: time.Second}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newQuoteFilter(tt.cfg)
+			now := time.Date(2025, 11, 3, 10, 0, 0, 0, time.Local)
+			for i, tk := range tt.ticks {
+				now = now.Add(tk.after)
+				source := tk.source
+				if source == "" {
+					source = "primary"
+				}
+				q := vendorQuote{Symbol: "AU9999", Buy: tk.buy, Sell: tk.sell}
+				if q.Buy == 0 {
+					q.Buy = tk.sell - 1
+				}
+				var refs map[string][]float64
+				if tk.refs != nil {
+					refs = map[string][]float64{"AU9999": tk.refs}
+				}
+
+				accepted, rejected := f.Check(source, []vendorQuote{q}, refs, now)
+				got := ""
+				if len(rejected) > 0 {
+					got = rejected[0].Reason
+				}
+				if got != tk.want {
+					t.Fatalf("tick %d (%.2f): reason = %q, want %q", i, tk.sell, got, tk.want)
+				}
+				if (len(accepted) == 1) != (tk.want == "") {
+					t.Fatalf("tick %d: accepted %d, rejected %d", i, len(accepted), len(rejected))
+				}
+			}
+		})
+	}
+}
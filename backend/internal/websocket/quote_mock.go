@@ -0,0 +1,340 @@
+/**
+ * 行情回放与模拟
+ *
+ * 用途：
+ * - QuoteGenerator：脚本化行情生成器（随机游走 / 价格跳变 / 断流）
+ * - QuoteReplayer：按原始节奏回放录制的行情文件（JSON lines）
+ * - quoteRecorder：录制当前行情源消息，供回放使用
+ *
+ * 生成的消息与上游推送格式一致，可直接被行情代理解析
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package websocket
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"suxin/internal/pkg/config"
+)
+
+const (
+	mockDefaultSymbol     = "AU9999"
+	mockDefaultStartPrice = 500.0
+	mockDefaultIntervalMs = 1000
+	mockDefaultVolatility = 0.3
+	mockSpread            = 0.4 // 模拟买卖价差（元/克）
+)
+
+/**
+ * QuoteGenerator 行情生成器
+ */
+type QuoteGenerator struct {
+	cfg  config.QuoteSourceConfig
+	rnd  *rand.Rand
+	open float64
+	high float64
+	low  float64
+
+	price     float64
+	stepIndex int // 当前脚本步骤
+	stepTicks int // 当前步骤已执行跳数
+}
+
+/**
+ * NewQuoteGenerator 创建行情生成器（未配置的参数使用默认值）
+ *
+ * @param cfg config.QuoteSourceConfig - 行情源配置
+ * @return *QuoteGenerator
+ */
+func NewQuoteGenerator(cfg config.QuoteSourceConfig) *QuoteGenerator {
+	if cfg.Symbol == "" {
+		cfg.Symbol = mockDefaultSymbol
+	}
+	if cfg.StartPrice <= 0 {
+		cfg.StartPrice = mockDefaultStartPrice
+	}
+	if cfg.IntervalMs <= 0 {
+		cfg.IntervalMs = mockDefaultIntervalMs
+	}
+	if cfg.Volatility <= 0 {
+		cfg.Volatility = mockDefaultVolatility
+	}
+
+	return &QuoteGenerator{
+		cfg:   cfg,
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		open:  cfg.StartPrice,
+		high:  cfg.StartPrice,
+		low:   cfg.StartPrice,
+		price: cfg.StartPrice,
+	}
+}
+
+/**
+ * Interval 推送间隔
+ */
+func (g *QuoteGenerator) Interval() time.Duration {
+	return time.Duration(g.cfg.IntervalMs) * time.Millisecond
+}
+
+/**
+ * Next 生成下一条行情
+ *
+ * @return ([]byte, time.Duration) - 行情消息、推送前需要断流的时长（0表示不断流）
+ */
+func (g *QuoteGenerator) Next() ([]byte, time.Duration) {
+	var pause time.Duration
+	if len(g.cfg.Script) > 0 {
+		pause = g.runScript()
+	} else {
+		g.walk()
+		if g.cfg.JumpProbability > 0 && g.rnd.Float64() < g.cfg.JumpProbability {
+			delta := g.cfg.JumpSize
+			if g.rnd.Intn(2) == 0 {
+				delta = -delta
+			}
+			g.move(delta)
+		}
+		if g.cfg.GapProbability > 0 && g.cfg.GapSeconds > 0 && g.rnd.Float64() < g.cfg.GapProbability {
+			pause = time.Duration(g.cfg.GapSeconds) * time.Second
+		}
+	}
+	return BuildVendorQuoteFrame(g.cfg.Symbol, g.price-mockSpread, g.price, g.high, g.low, g.price-g.open), pause
+}
+
+/**
+ * runScript 执行一跳脚本
+ *
+ * @return time.Duration - 断流时长
+ */
+func (g *QuoteGenerator) runScript() time.Duration {
+	step := g.cfg.Script[g.stepIndex]
+	switch step.Action {
+	case "jump":
+		g.move(step.Delta)
+		g.nextStep()
+		return 0
+	case "gap":
+		g.nextStep()
+		return time.Duration(step.Seconds) * time.Second
+	default:
+		// walk：按持续时长换算跳数
+		ticks := step.Seconds * 1000 / g.cfg.IntervalMs
+		if ticks < 1 {
+			ticks = 1
+		}
+		g.walk()
+		g.stepTicks++
+		if g.stepTicks >= ticks {
+			g.nextStep()
+		}
+		return 0
+	}
+}
+
+func (g *QuoteGenerator) nextStep() {
+	g.stepIndex = (g.stepIndex + 1) % len(g.cfg.Script)
+	g.stepTicks = 0
+}
+
+func (g *QuoteGenerator) walk() {
+	g.move((g.rnd.Float64()*2 - 1) * g.cfg.Volatility)
+}
+
+func (g *QuoteGenerator) move(delta float64) {
+	g.price = math.Round((g.price+delta)*100) / 100
+	if g.price < 1 {
+		g.price = 1
+	}
+	g.high = math.Max(g.high, g.price)
+	g.low = math.Min(g.low, g.price)
+}
+
+/**
+ * BuildVendorQuoteFrame 按上游推送格式构造行情消息
+ *
+ * 格式：{"type":"messageevent","content":"{\"items\":{\"AU9999\":{\"Buy\":..,\"Sell\":..,...}}}"}
+ *
+ * @param symbol string - 品种代码
+ * @param buy, sell, high, low, gap float64 - 回购价、销售价、最高、最低、涨跌
+ * @return []byte
+ */
+func BuildVendorQuoteFrame(symbol string, buy, sell, high, low, gap float64) []byte {
+	format := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+	content, _ := json.Marshal(map[string]interface{}{
+		"items": map[string]interface{}{
+			symbol: map[string]string{
+				"Buy":  format(buy),
+				"Sell": format(sell),
+				"H":    format(high),
+				"L":    format(low),
+				"Gap":  format(gap),
+			},
+		},
+	})
+	frame, _ := json.Marshal(map[string]interface{}{
+		"type":    "messageevent",
+		"content": string(content),
+	})
+	return frame
+}
+
+/**
+ * quoteRecord 录制文件中的一行
+ */
+type quoteRecord struct {
+	Ts      int64           `json:"ts"`               // 收到时间（毫秒时间戳）
+	Source  string          `json:"source,omitempty"` // 行情源名称
+	Message json.RawMessage `json:"message"`          // 原始消息
+}
+
+/**
+ * QuoteReplayer 行情回放器
+ *
+ * 每行为 {"ts":毫秒时间戳,"source":"...","message":{原始消息}}；
+ * 也兼容每行直接是一条原始消息（按1秒间隔回放）
+ */
+type QuoteReplayer struct {
+	path    string
+	speed   float64
+	loop    bool
+	file    *os.File
+	scanner *bufio.Scanner
+	prevTs  int64
+}
+
+/**
+ * NewQuoteReplayer 创建行情回放器
+ *
+ * @param path string - 回放文件
+ * @param speed float64 - 回放倍速（<=0 按1倍速）
+ * @param loop bool - 结束后是否从头开始
+ * @return (*QuoteReplayer, error)
+ */
+func NewQuoteReplayer(path string, speed float64, loop bool) (*QuoteReplayer, error) {
+	if speed <= 0 {
+		speed = 1
+	}
+	r := &QuoteReplayer{path: path, speed: speed, loop: loop}
+	if err := r.rewind(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *QuoteReplayer) rewind() error {
+	if r.file != nil {
+		r.file.Close()
+	}
+	f, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("打开回放文件失败: %v", err)
+	}
+	r.file = f
+	r.scanner = bufio.NewScanner(f)
+	r.scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	r.prevTs = 0
+	return nil
+}
+
+/**
+ * Next 读取下一条行情
+ *
+ * @return ([]byte, time.Duration, error) - 原始消息、距上一条的等待时长（已按倍速换算）、错误（回放结束为 io.EOF）
+ */
+func (r *QuoteReplayer) Next() ([]byte, time.Duration, error) {
+	for {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return nil, 0, err
+			}
+			if !r.loop {
+				return nil, 0, io.EOF
+			}
+			if err := r.rewind(); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record quoteRecord
+		if err := json.Unmarshal(line, &record); err != nil || len(record.Message) == 0 {
+			// 原始消息行
+			message := append([]byte(nil), line...)
+			return message, time.Duration(float64(time.Second) / r.speed), nil
+		}
+
+		var wait time.Duration
+		if r.prevTs > 0 && record.Ts > r.prevTs {
+			wait = time.Duration(float64(record.Ts-r.prevTs) * float64(time.Millisecond) / r.speed)
+		}
+		r.prevTs = record.Ts
+		return []byte(record.Message), wait, nil
+	}
+}
+
+/**
+ * Close 关闭回放文件
+ */
+func (r *QuoteReplayer) Close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+/**
+ * quoteRecorder 行情录制器
+ */
+type quoteRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+/**
+ * newQuoteRecorder 打开录制文件（追加写入）
+ */
+func newQuoteRecorder(path string) (*quoteRecorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开行情录制文件失败: %v", err)
+	}
+	return &quoteRecorder{file: f}, nil
+}
+
+/**
+ * Record 录制一条消息（非JSON消息忽略）
+ */
+func (r *quoteRecorder) Record(source string, message []byte) {
+	if !json.Valid(message) {
+		return
+	}
+	line, err := json.Marshal(quoteRecord{
+		Ts:      time.Now().UnixMilli(),
+		Source:  source,
+		Message: json.RawMessage(message),
+	})
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.file.Write(append(line, '\n'))
+}
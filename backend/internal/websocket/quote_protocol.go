@@ -0,0 +1,415 @@
+/**
+ * 行情推送协议
+ *
+ * 用途：
+ * - 将各行情源的原始消息解析为统一的品种报价，不再向前端透传上游格式
+ * - 按平台点差计算买卖价
+ * - 客户端按品种订阅/退订，推送按客户端合并限频（同一品种只保留最新报价）
+ *
+ * 客户端 → 服务端：
+ *   {"action": "subscribe", "symbols": ["AU", "AU9999"]}   （"*" 表示全部品种）
+ *   {"action": "unsubscribe", "symbols": ["AU9999"]}
+ *
+ * 服务端 → 客户端：
+ *   {"type": "subscribed", "symbols": ["AU", "AU9999"]}
+ *   {"type": "quote", "data": [{"symbol": "AU", "bid": 499.6, "ask": 500.3, "last": 500.1, "source": "jtd-primary", "ts": 1732000000000}]}
+ *   {"type": "market_status", ...}                         （平台行情状态，见行情保护服务）
+ *
+ * SSE 订阅（/quotes/stream）复用同一客户端结构和合并推送，只是不经过WebSocket连接
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"suxin/internal/model"
+)
+
+const (
+	defaultClientMaxRate = 2   // 默认每个客户端每秒最多推送次数
+	subscribeAll         = "*" // 订阅全部品种
+	maxClientSymbols     = 100 // 单个客户端最多订阅品种数
+)
+
+/**
+ * vendorQuote 上游原始报价（解析自各行情源消息）
+ */
+type vendorQuote struct {
+	Symbol string
+	Buy    float64
+	Sell   float64
+	High   float64
+	Low    float64
+	Change float64
+}
+
+/**
+ * parseVendorQuotes 从行情消息中解析所有品种的报价
+ *
+ * 支持 data.au9999.currentPrice 格式，以及 content/items 中带 Sell 字段的品种
+ *
+ * @param message []byte - 行情消息
+ * @return []vendorQuote
+ */
+func parseVendorQuotes(message []byte) []vendorQuote {
+	var data map[string]interface{}
+	if err := json.Unmarshal(message, &data); err != nil {
+		return nil
+	}
+
+	var quotes []vendorQuote
+	if dataObj, ok := data["data"].(map[string]interface{}); ok {
+		if au9999, ok := dataObj["au9999"].(map[string]interface{}); ok {
+			if price := parseQuoteNumber(au9999["currentPrice"]); price > 0 {
+				quotes = append(quotes, vendorQuote{Symbol: "AU9999", Buy: price, Sell: price})
+			}
+		}
+	}
+
+	raw := data
+	if content, ok := raw["content"].(string); ok {
+		var inner map[string]interface{}
+		if err := json.Unmarshal([]byte(content), &inner); err == nil {
+			raw = inner
+		}
+	}
+	if items, ok := raw["items"].(map[string]interface{}); ok {
+		raw = items
+	}
+
+	for symbol, v := range raw {
+		item, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sell := parseQuoteNumber(item["Sell"])
+		if sell <= 0 {
+			continue
+		}
+		buy := parseQuoteNumber(item["Buy"])
+		if buy <= 0 {
+			buy = sell
+		}
+		quotes = append(quotes, vendorQuote{
+			Symbol: strings.ToUpper(symbol),
+			Buy:    buy,
+			Sell:   sell,
+			High:   parseQuoteNumber(item["H"]),
+			Low:    parseQuoteNumber(item["L"]),
+			Change: parseQuoteChange(item["Gap"]),
+		})
+	}
+
+	// 保证输出顺序稳定
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Symbol < quotes[j].Symbol })
+	return quotes
+}
+
+/**
+ * parseQuoteChange 解析涨跌字段（可为负数）
+ */
+func parseQuoteChange(v interface{}) float64 {
+	switch t := v.(type) {
+	case string:
+		if p, err := strconv.ParseFloat(strings.TrimSpace(t), 64); err == nil {
+			return p
+		}
+	case float64:
+		return t
+	}
+	return 0
+}
+
+/**
+ * tick 转换为归档Tick
+ */
+func (q vendorQuote) tick(source string, at time.Time) *model.QuoteTick {
+	return &model.QuoteTick{
+		Symbol: q.Symbol,
+		Buy:    q.Buy,
+		Sell:   q.Sell,
+		Source: source,
+		TickAt: at,
+	}
+}
+
+/**
+ * SetSpreadProvider 设置平台点差来源
+ *
+ * @param provider func() (float64, float64) - 返回买入点差、卖出点差（元/克）
+ * @return void
+ */
+func (h *QuoteProxyHub) SetSpreadProvider(provider func() (float64, float64)) {
+	h.quotesMu.Lock()
+	defer h.quotesMu.Unlock()
+	h.spreadProvider = provider
+}
+
+/**
+ * publishQuotes 将上游报价标准化后推送给订阅的客户端
+ *
+ * @param source string - 行情源名称
+ * @param quotes []vendorQuote - 上游报价
+ * @param at time.Time - 收到时间
+ * @return void
+ */
+func (h *QuoteProxyHub) publishQuotes(source string, quotes []vendorQuote, at time.Time) {
+	h.quotesMu.Lock()
+	provider := h.spreadProvider
+	h.quotesMu.Unlock()
+
+	var buySpread, sellSpread float64
+	if provider != nil {
+		buySpread, sellSpread = provider()
+	}
+
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	updates := make([]*model.QuoteUpdate, 0, len(quotes))
+	for _, q := range quotes {
+		updates = append(updates, &model.QuoteUpdate{
+			Symbol:    q.Symbol,
+			Bid:       round(q.Buy - sellSpread),
+			Ask:       round(q.Sell + buySpread),
+			Last:      q.Sell,
+			High:      q.High,
+			Low:       q.Low,
+			Change:    q.Change,
+			Source:    source,
+			Timestamp: at.UnixMilli(),
+		})
+	}
+
+	h.quotesMu.Lock()
+	for _, u := range updates {
+		h.latestQuotes[u.Symbol] = u
+	}
+	h.quotesMu.Unlock()
+
+	h.mu.RLock()
+	for client := range h.clients {
+		client.enqueue(updates)
+	}
+	h.mu.RUnlock()
+}
+
+/**
+ * GetLatestQuotes 获取各品种最新报价（按品种排序）
+ *
+ * @return []*model.QuoteUpdate
+ */
+func (h *QuoteProxyHub) GetLatestQuotes() []*model.QuoteUpdate {
+	h.quotesMu.Lock()
+	quotes := make([]*model.QuoteUpdate, 0, len(h.latestQuotes))
+	for _, u := range h.latestQuotes {
+		quotes = append(quotes, u)
+	}
+	h.quotesMu.Unlock()
+
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Symbol < quotes[j].Symbol })
+	return quotes
+}
+
+/**
+ * SubscribeQuotes 订阅报价推送（SSE等非WebSocket通道使用）
+ *
+ * 与WebSocket客户端共用注册、合并限频和状态消息补发，返回的消息格式与WebSocket一致
+ *
+ * @param symbols []string - 品种代码（"*" 表示全部）
+ * @return (<-chan []byte, func()) - 消息通道（被注销时关闭）、取消订阅函数
+ */
+func (h *QuoteProxyHub) SubscribeQuotes(symbols []string) (<-chan []byte, func()) {
+	client := &Client{
+		hub:     h,
+		send:    make(chan []byte, 256),
+		subs:    make(map[string]bool),
+		pending: make(map[string]*model.QuoteUpdate),
+	}
+	h.register <- client
+	client.subscribe(normalizeSymbols(symbols))
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(h.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if message := client.flushPending(); message != nil {
+					client.trySend(message)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			h.unregister <- client
+		})
+	}
+	return client.send, cancel
+}
+
+/**
+ * clientMessage 客户端消息
+ */
+type clientMessage struct {
+	Action  string   `json:"action"`
+	Symbols []string `json:"symbols"`
+}
+
+/**
+ * handleMessage 处理客户端订阅/退订消息
+ *
+ * 订阅后立即补发这些品种的最新报价
+ *
+ * @param data []byte - 客户端消息
+ * @return void
+ */
+func (c *Client) handleMessage(data []byte) {
+	var msg clientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	symbols := normalizeSymbols(msg.Symbols)
+
+	switch msg.Action {
+	case "subscribe":
+		c.subscribe(symbols)
+
+	case "unsubscribe":
+		c.mu.Lock()
+		for _, s := range symbols {
+			delete(c.subs, s)
+			delete(c.pending, s)
+		}
+		if len(symbols) == 1 && symbols[0] == subscribeAll {
+			c.subs = make(map[string]bool)
+			c.pending = make(map[string]*model.QuoteUpdate)
+		}
+		c.mu.Unlock()
+
+	default:
+		return
+	}
+
+	c.mu.Lock()
+	current := make([]string, 0, len(c.subs))
+	for s := range c.subs {
+		current = append(current, s)
+	}
+	c.mu.Unlock()
+	sort.Strings(current)
+
+	reply, _ := json.Marshal(map[string]interface{}{
+		"type":    "subscribed",
+		"symbols": current,
+	})
+	c.trySend(reply)
+}
+
+/**
+ * normalizeSymbols 规范化品种代码（去空白、转大写）
+ */
+func normalizeSymbols(raw []string) []string {
+	symbols := make([]string, 0, len(raw))
+	for _, s := range raw {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	return symbols
+}
+
+/**
+ * subscribe 订阅品种，并立即补发这些品种的最新报价
+ */
+func (c *Client) subscribe(symbols []string) {
+	c.mu.Lock()
+	for _, s := range symbols {
+		if len(c.subs) >= maxClientSymbols {
+			break
+		}
+		c.subs[s] = true
+	}
+	c.mu.Unlock()
+
+	c.enqueue(c.hub.GetLatestQuotes())
+}
+
+/**
+ * enqueue 将报价放入待推送队列（同一品种只保留最新一条）
+ */
+func (c *Client) enqueue(updates []*model.QuoteUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	all := c.subs[subscribeAll]
+	for _, u := range updates {
+		if all || c.subs[u.Symbol] {
+			c.pending[u.Symbol] = u
+		}
+	}
+}
+
+/**
+ * flushPending 取出待推送报价并组装为一条消息（无待推送时返回nil）
+ */
+func (c *Client) flushPending() []byte {
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	updates := make([]*model.QuoteUpdate, 0, len(c.pending))
+	for _, u := range c.pending {
+		updates = append(updates, u)
+	}
+	c.pending = make(map[string]*model.QuoteUpdate)
+	c.mu.Unlock()
+
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Symbol < updates[j].Symbol })
+	message, err := json.Marshal(map[string]interface{}{
+		"type": "quote",
+		"data": updates,
+	})
+	if err != nil {
+		log.Printf("[QuoteProxy] 序列化报价失败: %v", err)
+		return nil
+	}
+	return message
+}
+
+/**
+ * trySend 非阻塞发送（队列已满时丢弃）
+ *
+ * send 只会在 hub 持写锁时从 clients 中移除后关闭，
+ * 这里持读锁并确认客户端仍在册，保证不会向已关闭的通道发送
+ */
+func (c *Client) trySend(message []byte) {
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	if _, ok := c.hub.clients[c]; !ok {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+	}
+}
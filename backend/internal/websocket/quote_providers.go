@@ -0,0 +1,318 @@
+/**
+ * 行情源实现
+ *
+ * 用途：
+ * - wsQuoteSource：WebSocket推送行情（jtd协议 / 通用订阅消息）
+ * - httpQuoteSource：HTTP轮询行情
+ * - replayQuoteSource：回放录制的行情文件
+ * - simulateQuoteSource：脚本化模拟行情
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"suxin/internal/pkg/config"
+)
+
+const (
+	sourceReadTimeout  = 90 * time.Second // 推送连接读超时（超时视为断线）
+	defaultPollSeconds = 3                // HTTP默认轮询间隔（秒）
+	httpPollTimeout    = 10 * time.Second // HTTP请求超时
+)
+
+/**
+ * wsQuoteSource WebSocket推送行情源
+ */
+type wsQuoteSource struct {
+	cfg config.QuoteSourceConfig
+}
+
+func newWSQuoteSource(cfg config.QuoteSourceConfig) *wsQuoteSource {
+	return &wsQuoteSource{cfg: cfg}
+}
+
+func (s *wsQuoteSource) Name() string  { return s.cfg.Name }
+func (s *wsQuoteSource) Type() string  { return s.cfg.Type }
+func (s *wsQuoteSource) Priority() int { return s.cfg.Priority }
+
+/**
+ * Run 连接上游并持续读取，断线后按退避策略自动重连
+ *
+ * 连接期间由看门狗发送心跳并检测行情停滞（见 quote_supervisor.go）
+ *
+ * @param sink QuoteSink - 行情回调
+ * @return void
+ */
+func (s *wsQuoteSource) Run(sink QuoteSink) {
+	backoff := &reconnectBackoff{}
+	for {
+		sink.OnConnecting()
+		log.Printf("[QuoteProxy] 正在连接行情源 %s: %s", s.cfg.Name, s.cfg.URL)
+
+		conn, _, err := websocket.DefaultDialer.Dial(s.cfg.URL, nil)
+		if err != nil {
+			sink.OnDisconnected(fmt.Errorf("连接失败: %v", err))
+			waitRetry(sink, backoff)
+			continue
+		}
+
+		if err := s.subscribe(conn); err != nil {
+			conn.Close()
+			sink.OnDisconnected(fmt.Errorf("发送订阅消息失败: %v", err))
+			waitRetry(sink, backoff)
+			continue
+		}
+		sink.OnConnected()
+
+		sink.OnDisconnected(s.read(conn, sink, backoff))
+		conn.Close()
+		waitRetry(sink, backoff)
+	}
+}
+
+/**
+ * read 持续读取上游消息，直到连接失败或被看门狗断开
+ *
+ * 本次连接收到行情后重置退避，下次断线立即重连
+ *
+ * @return error - 断开原因
+ */
+func (s *wsQuoteSource) read(conn *websocket.Conn, sink QuoteSink, backoff *reconnectBackoff) error {
+	watchdog := newConnectionWatchdog(s.cfg, conn, sink)
+	watchdog.Start()
+
+	healthy := false
+	for {
+		conn.SetReadDeadline(time.Now().Add(sourceReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if reason := watchdog.Stop(); reason != nil {
+				return reason
+			}
+			return fmt.Errorf("读取数据失败: %v", err)
+		}
+		sink.OnMessage(message)
+
+		if !healthy && watchdog.Healthy() {
+			healthy = true
+			backoff.Reset()
+		}
+	}
+}
+
+/**
+ * subscribe 发送订阅消息
+ *
+ * jtd协议发送带 dempCode/secret 的订阅包；通用WebSocket发送配置中的原文（可为空）
+ */
+func (s *wsQuoteSource) subscribe(conn *websocket.Conn) error {
+	var data []byte
+	switch s.cfg.Type {
+	case QuoteSourceTypeJTD:
+		subscribeMsg := map[string]interface{}{
+			"userid":           0,
+			"dempCode":         s.cfg.DempCode,
+			"channel":          "channel",
+			"clientIp":         "127.0.0.1",
+			"secret":           s.cfg.Secret,
+			"sessionId":        generateSessionID(),
+			"subscriptionType": "all",
+			"time":             time.Now().Format("2006-01-02 15:04:05"),
+		}
+		b, err := json.Marshal(subscribeMsg)
+		if err != nil {
+			return err
+		}
+		data = b
+	default:
+		if s.cfg.Subscribe == "" {
+			return nil
+		}
+		data = []byte(s.cfg.Subscribe)
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+/**
+ * httpQuoteSource HTTP轮询行情源
+ */
+type httpQuoteSource struct {
+	cfg    config.QuoteSourceConfig
+	client *http.Client
+}
+
+func newHTTPQuoteSource(cfg config.QuoteSourceConfig) *httpQuoteSource {
+	return &httpQuoteSource{
+		cfg:    cfg,
+		client: &http.Client{Timeout: httpPollTimeout},
+	}
+}
+
+func (s *httpQuoteSource) Name() string  { return s.cfg.Name }
+func (s *httpQuoteSource) Type() string  { return s.cfg.Type }
+func (s *httpQuoteSource) Priority() int { return s.cfg.Priority }
+
+/**
+ * Run 按间隔轮询行情接口
+ *
+ * @param sink QuoteSink - 行情回调
+ * @return void
+ */
+func (s *httpQuoteSource) Run(sink QuoteSink) {
+	interval := s.cfg.PollSeconds
+	if interval <= 0 {
+		interval = defaultPollSeconds
+	}
+	pollInterval := time.Duration(interval) * time.Second
+	backoff := &reconnectBackoff{}
+
+	for {
+		body, err := s.poll()
+		if err != nil {
+			sink.OnDisconnected(err)
+			// 连续失败时按退避延长间隔（不短于轮询间隔）
+			if wait := backoff.Next(); wait > pollInterval {
+				sink.OnRetry(wait)
+				time.Sleep(wait)
+				continue
+			}
+		} else {
+			backoff.Reset()
+			sink.OnConnected()
+			sink.OnMessage(body)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+/**
+ * poll 请求一次行情接口
+ */
+func (s *httpQuoteSource) poll() ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, s.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("响应状态异常: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMessageSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	return body, nil
+}
+
+/**
+ * replayQuoteSource 回放行情源
+ */
+type replayQuoteSource struct {
+	cfg config.QuoteSourceConfig
+}
+
+func newReplayQuoteSource(cfg config.QuoteSourceConfig) *replayQuoteSource {
+	return &replayQuoteSource{cfg: cfg}
+}
+
+func (s *replayQuoteSource) Name() string  { return s.cfg.Name }
+func (s *replayQuoteSource) Type() string  { return s.cfg.Type }
+func (s *replayQuoteSource) Priority() int { return s.cfg.Priority }
+
+/**
+ * Run 按录制节奏回放行情，未开启循环时回放结束后返回（行情源保持断开状态）
+ *
+ * @param sink QuoteSink - 行情回调
+ * @return void
+ */
+func (s *replayQuoteSource) Run(sink QuoteSink) {
+	backoff := &reconnectBackoff{}
+	for {
+		replayer, err := NewQuoteReplayer(s.cfg.Path, s.cfg.Speed, s.cfg.Loop)
+		if err != nil {
+			sink.OnDisconnected(err)
+			waitRetry(sink, backoff)
+			continue
+		}
+		backoff.Reset()
+		log.Printf("[QuoteProxy] 开始回放行情 %s: %s（%.1f倍速）", s.cfg.Name, s.cfg.Path, replayer.speed)
+		sink.OnConnected()
+
+		for {
+			message, wait, err := replayer.Next()
+			if err != nil {
+				replayer.Close()
+				if err == io.EOF {
+					sink.OnDisconnected(fmt.Errorf("回放结束"))
+					log.Printf("[QuoteProxy] 行情回放结束 %s", s.cfg.Name)
+					return
+				}
+				sink.OnDisconnected(fmt.Errorf("读取回放文件失败: %v", err))
+				break
+			}
+			time.Sleep(wait)
+			sink.OnMessage(message)
+		}
+		waitRetry(sink, backoff)
+	}
+}
+
+/**
+ * simulateQuoteSource 模拟行情源
+ */
+type simulateQuoteSource struct {
+	cfg config.QuoteSourceConfig
+}
+
+func newSimulateQuoteSource(cfg config.QuoteSourceConfig) *simulateQuoteSource {
+	return &simulateQuoteSource{cfg: cfg}
+}
+
+func (s *simulateQuoteSource) Name() string  { return s.cfg.Name }
+func (s *simulateQuoteSource) Type() string  { return s.cfg.Type }
+func (s *simulateQuoteSource) Priority() int { return s.cfg.Priority }
+
+/**
+ * Run 按间隔推送模拟行情，遇到断流步骤时暂停推送
+ *
+ * @param sink QuoteSink - 行情回调
+ * @return void
+ */
+func (s *simulateQuoteSource) Run(sink QuoteSink) {
+	generator := NewQuoteGenerator(s.cfg)
+	log.Printf("[QuoteProxy] 启动模拟行情 %s（初始价格 %.2f，间隔 %v）", s.cfg.Name, generator.price, generator.Interval())
+	sink.OnConnected()
+
+	for {
+		message, pause := generator.Next()
+		if pause > 0 {
+			log.Printf("[QuoteProxy] 模拟行情 %s 断流 %v", s.cfg.Name, pause)
+			time.Sleep(pause)
+		}
+		sink.OnMessage(message)
+		time.Sleep(generator.Interval())
+	}
+}
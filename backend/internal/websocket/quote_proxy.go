@@ -2,10 +2,10 @@
  * 行情WebSocket代理服务
  * 
  * 用途：
- * - 封装外部行情数据源 wss://push143.jtd9999.vip/ws
- * - 提供统一的WebSocket接口给前端
- * - 管理连接池和数据广播
- * - 支持自动重连和心跳保活
+ * - 封装外部行情数据源（按配置接入多个行情源，见 quote_source.go）
+ * - 提供统一的WebSocket接口给前端（标准化报价协议，见 quote_protocol.go）
+ * - 管理连接池、品种订阅和合并限频推送
+ * - 主行情源静默时按优先级和健康分自动切换备用源
  * 
  * 作者：速金盈技术团队
  * 日期：2025-11
@@ -14,13 +14,15 @@
 package websocket
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/config"
 )
 
 /**
@@ -32,14 +34,32 @@ type QuoteProxyHub struct {
 	broadcast       chan []byte       // 广播消息通道
 	register        chan *Client      // 注册新客户端
 	unregister      chan *Client      // 注销客户端
-	upstreamConn    *websocket.Conn   // 上游数据源连接
 	mu              sync.RWMutex      // 读写锁
-	isUpstreamAlive bool              // 上游连接状态
+	
+	// 行情源（按优先级排序）
+	sources         []*quoteSourceState
+	activeSource    *quoteSourceState // 当前使用的行情源
+	sourceMu        sync.RWMutex      // 行情源切换锁
+	silence         time.Duration     // 静默判定阈值
+	recorder        *quoteRecorder    // 行情录制（可选）
+	tickHandlers    []func([]*model.QuoteTick) // Tick监听
+	filter          *quoteFilter      // 异常Tick校验
+	quarantineHandlers []func([]*model.QuoteQuarantine) // 被拒绝Tick监听（隔离日志）
+	
+	// 标准化报价（按品种）
+	latestQuotes    map[string]*model.QuoteUpdate      // 各品种最新报价（订阅时补发）
+	spreadProvider  func() (float64, float64)    // 平台点差（买入、卖出）
+	quotesMu        sync.Mutex                   // 报价锁
+	flushInterval   time.Duration                // 客户端推送合并间隔
 	
 	// 最新价格缓存（用于风控系统）
 	latestPrice     float64           // 最新Au9999价格（元/克）
 	lastUpdate      time.Time         // 最后更新时间
+	latestSource    string            // 价格来源行情源名称
 	priceMutex      sync.RWMutex      // 价格锁
+	
+	// 平台行情状态（降级横幅），新客户端连接时补发
+	statusMessage   []byte            // 最近一次状态消息
 }
 
 /**
@@ -49,16 +69,16 @@ type Client struct {
 	hub  *QuoteProxyHub
 	conn *websocket.Conn
 	send chan []byte
+	
+	mu      sync.Mutex
+	subs    map[string]bool         // 已订阅品种（"*" 表示全部）
+	pending map[string]*model.QuoteUpdate // 待推送报价（按品种合并）
 }
 
 /**
- * 上游WebSocket配置
+ * 客户端WebSocket配置
  */
 const (
-	upstreamURL  = "wss://push143.jtd9999.vip/ws"
-	dempCode     = "e2571ebfeb4c217b4f6adac7a1ef3d4d"
-	secret       = "ceb1b5791048bb9ca438582b534d005b"
-	
 	// 客户端配置
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
@@ -66,34 +86,67 @@ const (
 	maxMessageSize = 512 * 1024
 )
 
+// riskPriceSymbols 风控价格品种（按优先级）
+var riskPriceSymbols = []string{"AU", "AU9999", "XAU"}
+
 /**
  * NewQuoteProxyHub 创建行情代理中心实例
  * 
- * @return *QuoteProxyHub
+ * @param cfg *config.Config - 应用配置（行情源列表）
+ * @return (*QuoteProxyHub, error)
  */
-func NewQuoteProxyHub() *QuoteProxyHub {
-	return &QuoteProxyHub{
+func NewQuoteProxyHub(cfg *config.Config) (*QuoteProxyHub, error) {
+	sources, err := NewQuoteSources(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("未配置可用的行情源（quote.sources）")
+	}
+	
+	h := &QuoteProxyHub{
 		clients:    make(map[*Client]bool),
 		broadcast:  make(chan []byte, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		silence:    getSilenceThreshold(cfg),
+		latestQuotes: make(map[string]*model.QuoteUpdate),
+		filter:     newQuoteFilter(cfg.Quote.Filter),
+	}
+	maxRate := cfg.Quote.ClientMaxRate
+	if maxRate <= 0 {
+		maxRate = defaultClientMaxRate
 	}
+	h.flushInterval = time.Second / time.Duration(maxRate)
+	for _, source := range sources {
+		h.sources = append(h.sources, &quoteSourceState{hub: h, source: source})
+	}
+	if cfg.Quote.RecordPath != "" {
+		if h.recorder, err = newQuoteRecorder(cfg.Quote.RecordPath); err != nil {
+			return nil, err
+		}
+		log.Printf("[QuoteProxy] 行情录制已开启: %s", cfg.Quote.RecordPath)
+	}
+	return h, nil
 }
 
 /**
  * Run 启动代理中心
  * 
  * 功能：
- * 1. 连接上游数据源
+ * 1. 启动所有行情源（备用源热备，随时可切换）
  * 2. 处理客户端注册/注销
  * 3. 广播消息到所有客户端
- * 4. 维护上游连接
+ * 4. 定时检查行情源健康，主源静默时自动切换
  * 
  * @return void
  */
 func (h *QuoteProxyHub) Run() {
-	// 连接上游数据源
-	go h.connectUpstream()
+	// 启动行情源
+	for _, state := range h.sources {
+		go state.source.Run(state)
+	}
+	go h.monitorSources()
 	
 	// 主事件循环
 	for {
@@ -102,7 +155,15 @@ func (h *QuoteProxyHub) Run() {
 			// 注册新客户端
 			h.mu.Lock()
 			h.clients[client] = true
+			status := h.statusMessage
 			h.mu.Unlock()
+			// 补发当前平台状态，保证新连接也能看到降级横幅
+			if status != nil {
+				select {
+				case client.send <- status:
+				default:
+				}
+			}
 			log.Printf("[QuoteProxy] 新客户端连接，当前连接数: %d", len(h.clients))
 			
 		case client := <-h.unregister:
@@ -117,256 +178,302 @@ func (h *QuoteProxyHub) Run() {
 			
 		case message := <-h.broadcast:
 			// 广播消息到所有客户端
+			// 读锁下只收集发送失败的客户端，删除与关闭必须持写锁，
+			// 否则会与 publishQuotes 等读锁遍历并发写 map
+			var slow []*Client
 			h.mu.RLock()
 			for client := range h.clients {
 				select {
 				case client.send <- message:
 				default:
-					// 发送失败，关闭客户端
-					close(client.send)
-					delete(h.clients, client)
+					slow = append(slow, client)
 				}
 			}
 			h.mu.RUnlock()
+			
+			if len(slow) > 0 {
+				h.mu.Lock()
+				for _, client := range slow {
+					if _, ok := h.clients[client]; ok {
+						delete(h.clients, client)
+						close(client.send)
+					}
+				}
+				h.mu.Unlock()
+			}
 		}
 	}
 }
 
 /**
- * connectUpstream 连接上游数据源
+ * monitorSources 定时检查行情源健康
  * 
- * 业务流程：
- * 1. 建立WebSocket连接
- * 2. 发送订阅消息
- * 3. 接收上游数据并广播
- * 4. 断线自动重连
+ * 主源静默时不会再有消息触发切换，需要定时重新选择
  * 
  * @return void
  */
-func (h *QuoteProxyHub) connectUpstream() {
-	for {
-		log.Printf("[QuoteProxy] 正在连接上游数据源: %s", upstreamURL)
-		
-		// 创建WebSocket连接
-		conn, _, err := websocket.DefaultDialer.Dial(upstreamURL, nil)
-		if err != nil {
-			log.Printf("[QuoteProxy] 连接上游失败: %v，5秒后重试", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
-		
-		h.mu.Lock()
-		h.upstreamConn = conn
-		h.isUpstreamAlive = true
-		h.mu.Unlock()
-		
-		log.Println("[QuoteProxy] ✅ 上游连接成功")
-		
-		// 发送订阅消息
-		h.sendUpstreamSubscribe()
-		
-		// 接收上游数据
-		go h.readUpstream()
-		
-		// 等待连接断开
-		<-time.After(time.Hour * 24)
+func (h *QuoteProxyHub) monitorSources() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	
+	for range ticker.C {
+		h.selectActiveSource()
 	}
 }
 
 /**
- * sendUpstreamSubscribe 发送订阅消息到上游
+ * selectActiveSource 选择当前使用的行情源
  * 
- * @return void
+ * 规则：
+ * 1. 健康分达到最低要求的行情源中，优先级最高者胜出
+ * 2. 优先级相同时取健康分更高者
+ * 3. 均不可用时保持当前行情源不变（等待恢复，由行情保护进入降级）
+ * 
+ * @return *quoteSourceState - 当前行情源
  */
-func (h *QuoteProxyHub) sendUpstreamSubscribe() {
-	subscribeMsg := map[string]interface{}{
-		"userid":           0,
-		"dempCode":         dempCode,
-		"channel":          "channel",
-		"clientIp":         "127.0.0.1",
-		"secret":           secret,
-		"sessionId":        generateSessionID(),
-		"subscriptionType": "all",
-		"time":             time.Now().Format("2006-01-02 15:04:05"),
+func (h *QuoteProxyHub) selectActiveSource() *quoteSourceState {
+	now := time.Now()
+	var best *quoteSourceState
+	bestScore := 0
+	for _, state := range h.sources {
+		score := state.score(now, h.silence)
+		if score < minHealthyScore {
+			continue
+		}
+		if best == nil || state.source.Priority() < best.source.Priority() ||
+			(state.source.Priority() == best.source.Priority() && score > bestScore) {
+			best = state
+			bestScore = score
+		}
 	}
 	
-	data, err := json.Marshal(subscribeMsg)
-	if err != nil {
-		log.Printf("[QuoteProxy] 序列化订阅消息失败: %v", err)
-		return
-	}
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
 	
-	h.mu.RLock()
-	conn := h.upstreamConn
-	h.mu.RUnlock()
+	if best == nil || best == h.activeSource {
+		return h.activeSource
+	}
 	
-	if conn != nil {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-			log.Printf("[QuoteProxy] 发送订阅消息失败: %v", err)
-		} else {
-			log.Println("[QuoteProxy] ✅ 订阅消息已发送")
-		}
+	if h.activeSource == nil {
+		log.Printf("[QuoteProxy] ✅ 使用行情源: %s", best.source.Name())
+	} else {
+		log.Printf("[QuoteProxy] ⚠️ 行情源切换: %s → %s（健康分 %d）",
+			h.activeSource.source.Name(), best.source.Name(), bestScore)
 	}
+	h.activeSource = best
+	return best
 }
 
 /**
- * readUpstream 读取上游数据
+ * handleSourceMessage 处理行情源消息
  * 
+ * 只有当前使用的行情源会更新价格并推送给客户端，备用源仅记录健康状况（并作为交叉校验参考）。
+ * 报价须先通过异常Tick校验，被拒绝的Tick交给隔离日志监听
+ * 
+ * @param state *quoteSourceState - 消息来源
+ * @param message []byte - 原始消息
+ * @param quotes []vendorQuote - 解析出的报价（为空表示非行情消息）
  * @return void
  */
-func (h *QuoteProxyHub) readUpstream() {
-	h.mu.RLock()
-	conn := h.upstreamConn
-	h.mu.RUnlock()
+func (h *QuoteProxyHub) handleSourceMessage(state *quoteSourceState, message []byte, quotes []vendorQuote) {
+	if h.selectActiveSource() != state {
+		return
+	}
+	
+	if h.recorder != nil {
+		h.recorder.Record(state.source.Name(), message)
+	}
 	
-	if conn == nil {
+	if len(quotes) == 0 {
 		return
 	}
+	now := time.Now()
 	
-	defer func() {
-		h.mu.Lock()
-		h.isUpstreamAlive = false
-		h.mu.Unlock()
-		conn.Close()
-	}()
+	quotes, rejected := h.filter.Check(state.source.Name(), quotes, h.crossSourceReferences(state, quotes, now), now)
+	if len(rejected) > 0 {
+		for _, r := range rejected {
+			log.Printf("[QuoteProxy] ⚠️ 异常Tick已隔离: %s %.2f（%s，%s）", r.Symbol, r.Sell, state.source.Name(), r.Detail)
+		}
+		h.sourceMu.RLock()
+		handlers := h.quarantineHandlers
+		h.sourceMu.RUnlock()
+		for _, handler := range handlers {
+			handler(rejected)
+		}
+	}
+	if len(quotes) == 0 {
+		return
+	}
 	
-	for {
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			log.Printf("[QuoteProxy] 读取上游数据失败: %v", err)
-			break
+	if price := riskQuotePrice(quotes); price > 0 {
+		h.priceMutex.Lock()
+		h.latestPrice = price
+		h.lastUpdate = time.Now()
+		h.latestSource = state.source.Name()
+		h.priceMutex.Unlock()
+		log.Printf("[QuoteProxy] 价格更新: Au9999 = %.2f 元/克（%s）", price, state.source.Name())
+	}
+	
+	h.sourceMu.RLock()
+	handlers := h.tickHandlers
+	h.sourceMu.RUnlock()
+	if len(handlers) > 0 {
+		ticks := make([]*model.QuoteTick, 0, len(quotes))
+		for _, q := range quotes {
+			ticks = append(ticks, q.tick(state.source.Name(), now))
+		}
+		for _, handler := range handlers {
+			handler(ticks)
 		}
-		
-		// 解析消息并提取Au9999价格
-		h.extractPrice(message)
-		
-		// 广播到所有客户端
-		h.broadcast <- message
 	}
+	
+	// 标准化后推送给订阅的客户端（不再透传上游原始消息）
+	h.publishQuotes(state.source.Name(), quotes, now)
 }
 
 /**
- * extractPrice 从WebSocket消息中提取Au9999价格
+ * crossSourceReferences 收集其他行情源的最新报价（多源交叉校验参考）
  * 
- * 消息格式示例:
- * {
- *   "data": {
- *     "au9999": {
- *       "currentPrice": "500.12",
- *       ...
- *     }
- *   }
- * }
+ * 只取已连接且未静默的行情源；只配置一个行情源时为空
  * 
- * @param message []byte - WebSocket消息
- * @return void
+ * @param state *quoteSourceState - 当前行情源
+ * @param quotes []vendorQuote - 待校验报价
+ * @param now time.Time - 当前时间
+ * @return map[string][]float64 - 各品种参考销售价
  */
-func (h *QuoteProxyHub) extractPrice(message []byte) {
-	var data map[string]interface{}
-	if err := json.Unmarshal(message, &data); err != nil {
-		return
+func (h *QuoteProxyHub) crossSourceReferences(state *quoteSourceState, quotes []vendorQuote, now time.Time) map[string][]float64 {
+	if len(h.sources) < 2 {
+		return nil
 	}
-
-	parsePrice := func(v interface{}) float64 {
-		switch t := v.(type) {
-		case string:
-			var p float64
-			if _, err := fmt.Sscanf(t, "%f", &p); err == nil && p > 0 {
-				return p
-			}
-		case float64:
-			if t > 0 {
-				return t
-			}
+	references := make(map[string][]float64)
+	for _, other := range h.sources {
+		if other == state {
+			continue
 		}
-		return 0
-	}
-
-	// 尝试提取Au9999价格
-	// 根据实际数据格式调整解析逻辑
-	if dataObj, ok := data["data"].(map[string]interface{}); ok {
-		if au9999, ok := dataObj["au9999"].(map[string]interface{}); ok {
-			if price := parsePrice(au9999["currentPrice"]); price > 0 {
-				h.priceMutex.Lock()
-				h.latestPrice = price
-				h.lastUpdate = time.Now()
-				h.priceMutex.Unlock()
-				log.Printf("[QuoteProxy] 价格更新: Au9999 = %.2f 元/克", price)
-				return
+		for _, q := range quotes {
+			if sell := other.latestQuote(q.Symbol, now, h.silence); sell > 0 {
+				references[q.Symbol] = append(references[q.Symbol], sell)
 			}
 		}
 	}
+	return references
+}
 
-	raw := data
-	if content, ok := raw["content"].(string); ok {
-		var inner map[string]interface{}
-		if err := json.Unmarshal([]byte(content), &inner); err == nil {
-			raw = inner
-		}
-	}
-
-	if items, ok := raw["items"].(map[string]interface{}); ok {
-		raw = items
-	}
-
-	var price float64
-	if au, ok := raw["AU"].(map[string]interface{}); ok {
-		if p := parsePrice(au["Sell"]); p > 0 {
-			price = p
-		}
+/**
+ * GetSourceStatuses 获取所有行情源状态
+ * 
+ * @return []model.QuoteSourceStatus
+ */
+func (h *QuoteProxyHub) GetSourceStatuses() []model.QuoteSourceStatus {
+	h.sourceMu.RLock()
+	active := h.activeSource
+	h.sourceMu.RUnlock()
+	
+	now := time.Now()
+	statuses := make([]model.QuoteSourceStatus, 0, len(h.sources))
+	for _, state := range h.sources {
+		statuses = append(statuses, state.status(now, h.silence, state == active))
 	}
+	return statuses
+}
 
-	if price == 0 {
-		if au9999, ok := raw["AU9999"].(map[string]interface{}); ok {
-			if p := parsePrice(au9999["Sell"]); p > 0 {
-				price = p
+/**
+ * riskQuotePrice 从报价中选取风控使用的Au9999价格
+ * 
+ * 按 AU → AU9999 → XAU 的优先级取销售价；不再退而取任意品种的价格，
+ * 避免其他品种的报价被误当作金价进入风控
+ * 
+ * @param quotes []vendorQuote - 报价（已通过校验）
+ * @return float64 - 价格（无对应品种时为0）
+ */
+func riskQuotePrice(quotes []vendorQuote) float64 {
+	for _, symbol := range riskPriceSymbols {
+		for _, q := range quotes {
+			if q.Symbol == symbol {
+				return q.Sell
 			}
 		}
 	}
+	return 0
+}
 
-	if price == 0 {
-		if xau, ok := raw["XAU"].(map[string]interface{}); ok {
-			if p := parsePrice(xau["Sell"]); p > 0 {
-				price = p
-			}
+/**
+ * parseQuoteNumber 解析价格字段（字符串或数字，非正数返回0）
+ */
+func parseQuoteNumber(v interface{}) float64 {
+	switch t := v.(type) {
+	case string:
+		var p float64
+		if _, err := fmt.Sscanf(t, "%f", &p); err == nil && p > 0 {
+			return p
 		}
-	}
-
-	if price == 0 {
-		for _, v := range raw {
-			if m, ok := v.(map[string]interface{}); ok {
-				if p := parsePrice(m["Sell"]); p > 0 {
-					price = p
-					break
-				}
-			}
+	case float64:
+		if t > 0 {
+			return t
 		}
 	}
+	return 0
+}
 
-	if price > 0 {
-		h.priceMutex.Lock()
-		h.latestPrice = price
-		h.lastUpdate = time.Now()
-		h.priceMutex.Unlock()
-		log.Printf("[QuoteProxy] 价格更新: Sell = %.2f 元/克", price)
-	}
+/**
+ * OnTicks 注册Tick监听（K线聚合、归档等）
+ * 
+ * 监听函数在行情接收协程中同步调用，不应阻塞
+ * 
+ * @param handler func([]*model.QuoteTick) - 监听函数
+ * @return void
+ */
+func (h *QuoteProxyHub) OnTicks(handler func([]*model.QuoteTick)) {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+	h.tickHandlers = append(h.tickHandlers, handler)
+}
+
+/**
+ * OnQuarantine 注册被拒绝Tick的监听（隔离日志）
+ * 
+ * 监听函数在行情接收协程中同步调用，不应阻塞
+ * 
+ * @param handler func([]*model.QuoteQuarantine) - 监听函数
+ * @return void
+ */
+func (h *QuoteProxyHub) OnQuarantine(handler func([]*model.QuoteQuarantine)) {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+	h.quarantineHandlers = append(h.quarantineHandlers, handler)
 }
 
 /**
  * GetLatestPrice 获取最新Au9999价格
  * 
- * @return (float64, time.Time, bool) - 价格、更新时间、是否有效
+ * @return (float64, time.Time, string, bool) - 价格、更新时间、行情源名称、是否有效
  */
-func (h *QuoteProxyHub) GetLatestPrice() (float64, time.Time, bool) {
+func (h *QuoteProxyHub) GetLatestPrice() (float64, time.Time, string, bool) {
 	h.priceMutex.RLock()
 	defer h.priceMutex.RUnlock()
 	
 	// 如果超过5分钟没更新，认为数据无效
 	if time.Since(h.lastUpdate) > 5*time.Minute {
-		return 0, h.lastUpdate, false
+		return 0, h.lastUpdate, h.latestSource, false
 	}
 	
-	return h.latestPrice, h.lastUpdate, h.latestPrice > 0
+	return h.latestPrice, h.lastUpdate, h.latestSource, h.latestPrice > 0
+}
+
+/**
+ * BroadcastStatus 广播平台行情状态（降级/恢复横幅）
+ * 
+ * 状态消息会被缓存，新连接的客户端注册时自动补发
+ * 
+ * @param message []byte - 状态消息JSON
+ * @return void
+ */
+func (h *QuoteProxyHub) BroadcastStatus(message []byte) {
+	h.mu.Lock()
+	h.statusMessage = message
+	h.mu.Unlock()
+	
+	h.broadcast <- message
 }
 
 /**
@@ -377,9 +484,11 @@ func (h *QuoteProxyHub) GetLatestPrice() (float64, time.Time, bool) {
  */
 func (h *QuoteProxyHub) ServeWs(conn *websocket.Conn) {
 	client := &Client{
-		hub:  h,
-		conn: conn,
-		send: make(chan []byte, 256),
+		hub:     h,
+		conn:    conn,
+		send:    make(chan []byte, 256),
+		subs:    make(map[string]bool),
+		pending: make(map[string]*model.QuoteUpdate),
 	}
 	
 	client.hub.register <- client
@@ -390,7 +499,7 @@ func (h *QuoteProxyHub) ServeWs(conn *websocket.Conn) {
 }
 
 /**
- * readPump 读取客户端消息
+ * readPump 读取客户端消息（订阅/退订）
  * 
  * @return void
  */
@@ -408,22 +517,27 @@ func (c *Client) readPump() {
 	})
 	
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		c.handleMessage(message)
 	}
 }
 
 /**
  * writePump 向客户端发送消息
  * 
+ * 报价按合并间隔批量推送（同一品种只推最新一条），状态消息立即推送
+ * 
  * @return void
  */
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
+	flushTicker := time.NewTicker(c.hub.flushInterval)
 	defer func() {
 		ticker.Stop()
+		flushTicker.Stop()
 		c.conn.Close()
 	}()
 	
@@ -440,6 +554,16 @@ func (c *Client) writePump() {
 				return
 			}
 			
+		case <-flushTicker.C:
+			message := c.flushPending()
+			if message == nil {
+				continue
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+			
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
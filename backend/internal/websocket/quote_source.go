@@ -0,0 +1,391 @@
+/**
+ * 行情源抽象
+ *
+ * 用途：
+ * - 定义统一的行情源接口（WebSocket推送 / HTTP轮询 / 回放 / 模拟）
+ * - 根据配置创建行情源实例
+ * - 记录各行情源健康状况，计算健康分用于自动切换
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package websocket
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"suxin/internal/model"
+	"suxin/internal/pkg/config"
+)
+
+/**
+ * 行情源类型
+ */
+const (
+	QuoteSourceTypeJTD       = "jtd"       // 现有上游推送协议（dempCode/secret订阅）
+	QuoteSourceTypeWebSocket = "websocket" // 通用WebSocket推送
+	QuoteSourceTypeHTTP      = "http"      // HTTP轮询
+	QuoteSourceTypeReplay    = "replay"    // 回放录制文件（开发/测试）
+	QuoteSourceTypeSimulate  = "simulate"  // 脚本化模拟行情（开发/测试）
+
+	defaultSilenceSeconds = 15               // 默认静默判定阈值（秒）
+	failureWindow         = 10 * time.Minute // 统计失败次数的时间窗口
+	minHealthyScore       = 30               // 可用行情源的最低健康分
+	messageRateWindow     = 10 * time.Second // 统计消息速率的时间窗口
+)
+
+/**
+ * QuoteSink 行情源回调
+ *
+ * 行情源通过该接口上报连接状态和原始消息，由代理中心统一处理；
+ * LastQuoteAt 供连接监管的看门狗判断行情是否停滞
+ */
+type QuoteSink interface {
+	OnConnecting()
+	OnConnected()
+	OnDisconnected(err error)
+	OnRetry(wait time.Duration)
+	OnMessage(message []byte)
+	LastQuoteAt() time.Time
+}
+
+/**
+ * QuoteSource 行情源接口
+ *
+ * Run 阻塞运行，内部自行负责重连/轮询（回放源回放结束后返回）
+ */
+type QuoteSource interface {
+	Name() string
+	Type() string
+	Priority() int
+	Run(sink QuoteSink)
+}
+
+/**
+ * quoteSourceState 行情源运行状态
+ */
+type quoteSourceState struct {
+	hub    *QuoteProxyHub
+	source QuoteSource
+
+	mu             sync.RWMutex
+	state          string
+	connected      bool
+	everConnected  bool
+	connectedAt    time.Time
+	nextRetryAt    time.Time
+	reconnects     int
+	messageCount   int64
+	recentMessages []time.Time // 速率窗口内的消息时间
+	lastPrice      float64
+	lastPriceAt    time.Time
+	lastMessageAt  time.Time
+	failures       []time.Time // 窗口内的失败时间
+	lastError      string
+	quotes         map[string]sourceQuote // 各品种最新报价（多源交叉校验用）
+}
+
+/**
+ * sourceQuote 行情源某品种的最新报价
+ */
+type sourceQuote struct {
+	sell float64
+	at   time.Time
+}
+
+/**
+ * OnConnecting 行情源开始连接
+ */
+func (s *quoteSourceState) OnConnecting() {
+	s.mu.Lock()
+	s.state = model.QuoteSourceStateConnecting
+	s.nextRetryAt = time.Time{}
+	s.mu.Unlock()
+}
+
+/**
+ * OnConnected 行情源连接成功
+ */
+func (s *quoteSourceState) OnConnected() {
+	s.mu.Lock()
+	wasConnected := s.connected
+	s.connected = true
+	s.state = model.QuoteSourceStateConnected
+	s.nextRetryAt = time.Time{}
+	if !wasConnected {
+		s.connectedAt = time.Now()
+		if s.everConnected {
+			s.reconnects++
+		}
+		s.everConnected = true
+	}
+	reconnects := s.reconnects
+	s.mu.Unlock()
+
+	if !wasConnected {
+		log.Printf("[QuoteProxy] ✅ 行情源 %s 已连接（累计重连 %d 次）", s.source.Name(), reconnects)
+	}
+}
+
+/**
+ * OnRetry 行情源进入退避等待
+ */
+func (s *quoteSourceState) OnRetry(wait time.Duration) {
+	s.mu.Lock()
+	s.state = model.QuoteSourceStateRetrying
+	s.nextRetryAt = time.Now().Add(wait)
+	s.mu.Unlock()
+
+	log.Printf("[QuoteProxy] 行情源 %s 将在 %v 后重连", s.source.Name(), wait.Round(time.Millisecond))
+}
+
+/**
+ * LastQuoteAt 最近一次收到行情的时间
+ */
+func (s *quoteSourceState) LastQuoteAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastPriceAt
+}
+
+/**
+ * OnDisconnected 行情源断开或请求失败
+ */
+func (s *quoteSourceState) OnDisconnected(err error) {
+	now := time.Now()
+	s.mu.Lock()
+	s.connected = false
+	s.connectedAt = time.Time{}
+	s.failures = append(s.failures, now)
+	if err != nil {
+		s.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	log.Printf("[QuoteProxy] 行情源 %s 异常: %v", s.source.Name(), err)
+	s.hub.selectActiveSource()
+}
+
+/**
+ * OnMessage 行情源收到消息
+ */
+func (s *quoteSourceState) OnMessage(message []byte) {
+	quotes := parseVendorQuotes(message)
+	now := time.Now()
+
+	s.mu.Lock()
+	s.lastMessageAt = now
+	s.messageCount++
+	s.recentMessages = append(s.recentMessages, now)
+	if len(quotes) > 0 {
+		if price := riskQuotePrice(quotes); price > 0 {
+			s.lastPrice = price
+		}
+		s.lastPriceAt = now
+		if s.quotes == nil {
+			s.quotes = make(map[string]sourceQuote)
+		}
+		for _, q := range quotes {
+			s.quotes[q.Symbol] = sourceQuote{sell: q.Sell, at: now}
+		}
+	}
+	s.mu.Unlock()
+
+	s.hub.handleSourceMessage(s, message, quotes)
+}
+
+/**
+ * latestQuote 获取某品种未过期的最新报价（未连接或已过期返回0）
+ */
+func (s *quoteSourceState) latestQuote(symbol string, now time.Time, maxAge time.Duration) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, ok := s.quotes[symbol]
+	if !s.connected || !ok || now.Sub(q.at) > maxAge {
+		return 0
+	}
+	return q.sell
+}
+
+/**
+ * score 计算健康分
+ *
+ * 规则：
+ * - 未连接或静默超过阈值：0分
+ * - 满分100，按行情延迟扣分（最多50），按近期失败次数扣分（每次10，最多40）
+ *
+ * @param now time.Time - 当前时间
+ * @param silence time.Duration - 静默判定阈值
+ * @return int
+ */
+func (s *quoteSourceState) score(now time.Time, silence time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 清理窗口外的失败记录
+	kept := s.failures[:0]
+	for _, t := range s.failures {
+		if now.Sub(t) <= failureWindow {
+			kept = append(kept, t)
+		}
+	}
+	s.failures = kept
+
+	// 清理速率窗口外的消息记录
+	recent := s.recentMessages[:0]
+	for _, t := range s.recentMessages {
+		if now.Sub(t) <= messageRateWindow {
+			recent = append(recent, t)
+		}
+	}
+	s.recentMessages = recent
+
+	if !s.connected || s.lastPriceAt.IsZero() {
+		return 0
+	}
+	age := now.Sub(s.lastPriceAt)
+	if age > silence {
+		return 0
+	}
+
+	score := 100 - int(age*50/silence)
+	penalty := len(s.failures) * 10
+	if penalty > 40 {
+		penalty = 40
+	}
+	return score - penalty
+}
+
+/**
+ * status 生成状态快照
+ */
+func (s *quoteSourceState) status(now time.Time, silence time.Duration, active bool) model.QuoteSourceStatus {
+	score := s.score(now, silence)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := model.QuoteSourceStatus{
+		Name:              s.source.Name(),
+		Type:              s.source.Type(),
+		Priority:          s.source.Priority(),
+		Active:            active,
+		State:             s.state,
+		Connected:         s.connected,
+		Score:             score,
+		Reconnects:        s.reconnects,
+		MessageCount:      s.messageCount,
+		MessagesPerSecond: float64(len(s.recentMessages)) / messageRateWindow.Seconds(),
+		LastPrice:         s.lastPrice,
+		RecentFailures:    len(s.failures),
+		LastError:         s.lastError,
+	}
+	if !s.connectedAt.IsZero() {
+		t := s.connectedAt
+		st.ConnectedAt = &t
+	}
+	if !s.nextRetryAt.IsZero() {
+		t := s.nextRetryAt
+		st.NextRetryAt = &t
+	}
+	if !s.lastPriceAt.IsZero() {
+		t := s.lastPriceAt
+		st.LastPriceAt = &t
+	}
+	if !s.lastMessageAt.IsZero() {
+		t := s.lastMessageAt
+		st.LastMessageAt = &t
+	}
+	return st
+}
+
+/**
+ * NewQuoteSources 根据配置创建行情源（按优先级排序）
+ *
+ * 开启 quote.simulate_fallback 时，缺少凭据的 jtd 源跳过而不是报错，没有可用行情源时使用模拟行情
+ *
+ * @param cfg *config.Config - 应用配置
+ * @return ([]QuoteSource, error)
+ */
+func NewQuoteSources(cfg *config.Config) ([]QuoteSource, error) {
+	var sources []QuoteSource
+	names := make(map[string]bool)
+
+	for i, sc := range cfg.Quote.Sources {
+		if sc.Disabled {
+			continue
+		}
+		if sc.Name == "" {
+			sc.Name = fmt.Sprintf("%s-%d", sc.Type, i+1)
+		}
+		if names[sc.Name] {
+			return nil, fmt.Errorf("行情源名称重复: %s", sc.Name)
+		}
+		names[sc.Name] = true
+
+		switch sc.Type {
+		case QuoteSourceTypeJTD, QuoteSourceTypeWebSocket, QuoteSourceTypeHTTP:
+			if sc.URL == "" {
+				return nil, fmt.Errorf("行情源 %s 未配置url", sc.Name)
+			}
+		}
+
+		switch sc.Type {
+		case QuoteSourceTypeJTD:
+			if sc.DempCode == "" || sc.Secret == "" {
+				if cfg.Quote.SimulateFallback {
+					log.Printf("[QuoteProxy] ⚠️ 行情源 %s 缺少demp_code/secret（环境变量 %sDEMP_CODE / %sSECRET），已跳过",
+						sc.Name, config.QuoteSourceEnvPrefix(sc.Name), config.QuoteSourceEnvPrefix(sc.Name))
+					continue
+				}
+				return nil, fmt.Errorf("行情源 %s 缺少demp_code/secret（请设置环境变量 %sDEMP_CODE / %sSECRET）",
+					sc.Name, config.QuoteSourceEnvPrefix(sc.Name), config.QuoteSourceEnvPrefix(sc.Name))
+			}
+			sources = append(sources, newWSQuoteSource(sc))
+		case QuoteSourceTypeWebSocket:
+			sources = append(sources, newWSQuoteSource(sc))
+		case QuoteSourceTypeHTTP:
+			sources = append(sources, newHTTPQuoteSource(sc))
+		case QuoteSourceTypeReplay:
+			if sc.Path == "" {
+				return nil, fmt.Errorf("行情源 %s 未配置回放文件path", sc.Name)
+			}
+			sources = append(sources, newReplayQuoteSource(sc))
+		case QuoteSourceTypeSimulate:
+			sources = append(sources, newSimulateQuoteSource(sc))
+		default:
+			return nil, fmt.Errorf("行情源 %s 类型不支持: %s", sc.Name, sc.Type)
+		}
+	}
+
+	if len(sources) == 0 && cfg.Quote.SimulateFallback {
+		log.Printf("[QuoteProxy] ⚠️ 没有可用行情源，使用模拟行情（quote.simulate_fallback）")
+		sources = append(sources, newSimulateQuoteSource(config.QuoteSourceConfig{
+			Name:     "simulate-fallback",
+			Type:     QuoteSourceTypeSimulate,
+			Priority: 1,
+		}))
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority() < sources[j].Priority()
+	})
+	return sources, nil
+}
+
+/**
+ * getSilenceThreshold 读取静默判定阈值
+ */
+func getSilenceThreshold(cfg *config.Config) time.Duration {
+	seconds := cfg.Quote.SilenceSeconds
+	if seconds <= 0 {
+		seconds = defaultSilenceSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
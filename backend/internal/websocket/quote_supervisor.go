@@ -0,0 +1,211 @@
+/**
+ * 行情源连接监管
+ *
+ * 用途：
+ * - 重连退避：指数退避 + 随机抖动，连接稳定（收到行情）后重置
+ * - 连接看门狗：定时发送心跳（ping帧 + 应用层心跳消息），
+ *   连接建立后长时间收不到行情（连接假活）时主动断开触发重连
+ *
+ * 作者：速金盈技术团队
+ * 日期：2025-11
+ */
+
+package websocket
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"suxin/internal/pkg/config"
+)
+
+const (
+	retryBaseDelay          = time.Second      // 首次退避时长
+	retryMaxDelay           = 60 * time.Second // 最大退避时长
+	retryJitter             = 0.2              // 抖动比例（±20%）
+	defaultHeartbeatSeconds = 20               // 默认心跳间隔（秒）
+	defaultStaleSeconds     = 30               // 默认看门狗阈值（秒）
+	jtdHeartbeatMessage     = `{"type":"p"}`   // jtd协议心跳消息
+)
+
+/**
+ * reconnectBackoff 重连退避
+ *
+ * 第一次失败立即重连，之后按 1s、2s、4s … 递增（上限60秒），每次叠加 ±20% 抖动，
+ * 避免多个实例同时重连上游
+ */
+type reconnectBackoff struct {
+	attempt int
+}
+
+/**
+ * Next 返回下次重连前的等待时长
+ *
+ * @return time.Duration
+ */
+func (b *reconnectBackoff) Next() time.Duration {
+	attempt := b.attempt
+	b.attempt++
+	if attempt == 0 {
+		return 0
+	}
+
+	delay := retryMaxDelay
+	if attempt <= 6 {
+		delay = retryBaseDelay << uint(attempt-1)
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+	jitter := (rand.Float64()*2 - 1) * retryJitter
+	return time.Duration(float64(delay) * (1 + jitter))
+}
+
+/**
+ * Reset 连接恢复正常后重置退避
+ */
+func (b *reconnectBackoff) Reset() {
+	b.attempt = 0
+}
+
+/**
+ * waitRetry 按退避等待（需要等待时上报状态）
+ */
+func waitRetry(sink QuoteSink, backoff *reconnectBackoff) {
+	if wait := backoff.Next(); wait > 0 {
+		sink.OnRetry(wait)
+		time.Sleep(wait)
+	}
+}
+
+/**
+ * connectionWatchdog 推送连接看门狗
+ */
+type connectionWatchdog struct {
+	conn        *websocket.Conn
+	sink        QuoteSink
+	heartbeat   time.Duration
+	message     []byte // 应用层心跳消息（为空不发送）
+	stale       time.Duration
+	connectedAt time.Time
+	stopped     chan error // 看门狗主动断开的原因
+	done        chan struct{}
+}
+
+/**
+ * newConnectionWatchdog 按行情源配置创建看门狗
+ *
+ * @param cfg config.QuoteSourceConfig - 行情源配置
+ * @param conn *websocket.Conn - 上游连接
+ * @param sink QuoteSink - 行情回调（用于读取最近行情时间）
+ * @return *connectionWatchdog
+ */
+func newConnectionWatchdog(cfg config.QuoteSourceConfig, conn *websocket.Conn, sink QuoteSink) *connectionWatchdog {
+	heartbeat := cfg.HeartbeatSeconds
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeatSeconds
+	}
+	stale := cfg.StaleSeconds
+	if stale <= 0 {
+		stale = defaultStaleSeconds
+	}
+	message := cfg.HeartbeatMessage
+	if message == "" && cfg.Type == QuoteSourceTypeJTD {
+		message = jtdHeartbeatMessage
+	}
+
+	w := &connectionWatchdog{
+		conn:        conn,
+		sink:        sink,
+		heartbeat:   time.Duration(heartbeat) * time.Second,
+		stale:       time.Duration(stale) * time.Second,
+		connectedAt: time.Now(),
+		stopped:     make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+	if message != "" {
+		w.message = []byte(message)
+	}
+	return w
+}
+
+/**
+ * Start 启动看门狗（连接读取结束后调用 Stop）
+ */
+func (w *connectionWatchdog) Start() {
+	go w.run()
+}
+
+/**
+ * Stop 停止看门狗，返回看门狗主动断开的原因（没有则为nil）
+ *
+ * @return error
+ */
+func (w *connectionWatchdog) Stop() error {
+	close(w.done)
+	select {
+	case err := <-w.stopped:
+		return err
+	default:
+		return nil
+	}
+}
+
+/**
+ * Healthy 本次连接是否已收到行情
+ *
+ * @return bool
+ */
+func (w *connectionWatchdog) Healthy() bool {
+	return w.sink.LastQuoteAt().After(w.connectedAt)
+}
+
+func (w *connectionWatchdog) run() {
+	check := time.NewTicker(time.Second)
+	heartbeat := time.NewTicker(w.heartbeat)
+	defer check.Stop()
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case <-heartbeat.C:
+			deadline := time.Now().Add(writeWait)
+			err := w.conn.WriteControl(websocket.PingMessage, nil, deadline)
+			if err == nil && w.message != nil {
+				w.conn.SetWriteDeadline(deadline)
+				err = w.conn.WriteMessage(websocket.TextMessage, w.message)
+			}
+			if err != nil {
+				w.abort(fmt.Errorf("发送心跳失败: %v", err))
+				return
+			}
+
+		case now := <-check.C:
+			last := w.sink.LastQuoteAt()
+			if last.Before(w.connectedAt) {
+				last = w.connectedAt
+			}
+			if idle := now.Sub(last); idle > w.stale {
+				w.abort(fmt.Errorf("看门狗：%v 未收到行情，主动重连", idle.Round(time.Second)))
+				return
+			}
+		}
+	}
+}
+
+/**
+ * abort 记录原因并关闭连接（读取协程随即返回错误）
+ */
+func (w *connectionWatchdog) abort(err error) {
+	select {
+	case w.stopped <- err:
+	default:
+	}
+	w.conn.Close()
+}
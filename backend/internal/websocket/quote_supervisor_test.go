@@ -0,0 +1,54 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectBackoffNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int           // 已失败次数
+		base    time.Duration // 抖动前的等待时长
+	}{
+		{name: "first failure retries immediately", attempt: 0, base: 0},
+		{name: "second failure waits base delay", attempt: 1, base: time.Second},
+		{name: "doubles each attempt", attempt: 2, base: 2 * time.Second},
+		{name: "keeps doubling", attempt: 5, base: 16 * time.Second},
+		{name: "last doubling before cap", attempt: 6, base: 32 * time.Second},
+		{name: "capped at max delay", attempt: 7, base: retryMaxDelay},
+		{name: "stays capped without overflow", attempt: 100, base: retryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo := time.Duration(float64(tt.base) * (1 - retryJitter))
+			hi := time.Duration(float64(tt.base) * (1 + retryJitter))
+			// 抖动随机，多次取样确认都落在 ±20% 范围内
+			for i := 0; i < 50; i++ {
+				b := &reconnectBackoff{attempt: tt.attempt}
+				got := b.Next()
+				if got < lo || got > hi {
+					t.Fatalf("Next() = %v, want within [%v, %v]", got, lo, hi)
+				}
+				if b.attempt != tt.attempt+1 {
+					t.Fatalf("attempt = %d, want %d", b.attempt, tt.attempt+1)
+				}
+			}
+		})
+	}
+}
+
+func TestReconnectBackoffReset(t *testing.T) {
+	b := &reconnectBackoff{}
+	for i := 0; i < 5; i++ {
+		b.Next()
+	}
+	b.Reset()
+	if got := b.Next(); got != 0 {
+		t.Errorf("Next() after Reset = %v, want 0", got)
+	}
+	if got := b.Next(); got < time.Duration(float64(retryBaseDelay)*(1-retryJitter)) || got > time.Duration(float64(retryBaseDelay)*(1+retryJitter)) {
+		t.Errorf("second Next() after Reset = %v, want about %v", got, retryBaseDelay)
+	}
+}
@@ -79,9 +79,18 @@ export const API_ENDPOINTS = {
   // 资金相关
   DEPOSITS: '/api/v1/deposits',
   DEPOSIT_CREATE: '/api/v1/deposits',
+  DEPOSIT_REFERENCE_CODE: '/api/v1/deposits/reference-code',
   SUPPLEMENTS: '/api/v1/supplements',
   WITHDRAWS: '/api/v1/withdraws',
   WITHDRAW_CREATE: '/api/v1/withdraws',
+  WITHDRAW_CANCEL: '/api/v1/withdraws/:id/cancel',
+  WITHDRAW_QUOTE: '/api/v1/withdraws/quote',
+  WITHDRAW_POLICY: '/api/v1/withdraw-policy',
+  PAYMENT_PROVIDERS: '/api/v1/payments/providers',
+  PAYMENTS: '/api/v1/payments',
+  PAYMENT_DETAIL: '/api/v1/payments/:order_no',
+  PAYMENT_QUERY: '/api/v1/payments/:order_no/query',
+  PAYMENT_MOCK_PAY: '/api/v1/payments/:order_no/mock-pay',
   FUND_FLOW: '/api/v1/fund-logs',
   
   // 银行卡相关
@@ -95,6 +104,10 @@ export const API_ENDPOINTS = {
   NOTIFICATIONS_COUNT: '/api/v1/notifications/count',
   NOTIFICATION_READ: '/api/v1/notifications/read',
   NOTIFICATION_READ_ALL: '/api/v1/notifications/read-all',
+
+  // 价格提醒
+  PRICE_ALERTS: '/api/v1/price-alerts',
+  PRICE_ALERT_DETAIL: '/api/v1/price-alerts/:id',
   
   // 销售相关
   SALES_INVITE_CODES: '/api/v1/sales/invite-codes',
@@ -116,9 +129,45 @@ export const API_ENDPOINTS = {
   ADMIN_USER_VERIFICATION_REJECT: '/api/v1/users/:id/verification/reject',
   ADMIN_DEPOSITS_PENDING: '/api/v1/deposits/pending',
   ADMIN_DEPOSIT_REVIEW: '/api/v1/deposits/:id/review',
+  ADMIN_DEPOSIT_APPROVALS: '/api/v1/deposits/:id/approvals',
   ADMIN_WITHDRAWS_PENDING: '/api/v1/withdraws/pending',
   ADMIN_WITHDRAW_REVIEW: '/api/v1/withdraws/:id/review',
   ADMIN_WITHDRAW_PAY: '/api/v1/withdraws/:id/pay',
+  ADMIN_WITHDRAW_APPROVALS: '/api/v1/withdraws/:id/approvals',
+  ADMIN_PAYMENTS: '/api/v1/payments/admin/orders',
+  ADMIN_PAYMENT_QUERY: '/api/v1/payments/admin/orders/:order_no/query',
+  ADMIN_PAYMENT_RESOLVE: '/api/v1/payments/admin/orders/:order_no/resolve',
+  ADMIN_PAYMENT_MOCK_PAY: '/api/v1/payments/admin/orders/:order_no/mock-pay',
+  ADMIN_STATEMENT_MAPPINGS: '/api/v1/bank-statements/mappings',
+  ADMIN_STATEMENT_MAPPING: '/api/v1/bank-statements/mappings/:id',
+  ADMIN_STATEMENT_IMPORTS: '/api/v1/bank-statements/imports',
+  ADMIN_STATEMENT_LINES: '/api/v1/bank-statements/lines',
+  ADMIN_STATEMENT_LINE_CANDIDATES: '/api/v1/bank-statements/lines/:id/candidates',
+  ADMIN_STATEMENT_LINE_CONFIRM: '/api/v1/bank-statements/lines/:id/confirm',
+  ADMIN_STATEMENT_LINE_REJECT: '/api/v1/bank-statements/lines/:id/reject',
+  ADMIN_STATEMENT_LINE_IGNORE: '/api/v1/bank-statements/lines/:id/ignore',
+  FILES_UPLOAD: '/api/v1/files',
+  ACCOUNT_STATEMENTS: '/api/v1/statements',
+  ACCOUNT_STATEMENT_DOWNLOAD: '/api/v1/statements/:id/download',
+  ADMIN_ACCOUNT_STATEMENTS: '/api/v1/statements/admin',
+  ADMIN_ACCOUNT_STATEMENT_DOWNLOAD: '/api/v1/statements/admin/:id/download',
+  ADMIN_EXPORT: '/api/v1/admin/exports/:dataset',
+  ADMIN_EXPORT_COUNT: '/api/v1/admin/exports/:dataset/count',
+  ADMIN_EXPORT_JOBS: '/api/v1/admin/exports/jobs',
+  ADMIN_EXPORT_JOB_DOWNLOAD: '/api/v1/admin/exports/jobs/:id/download',
+  ADMIN_ADJUSTMENTS: '/api/v1/admin/adjustments',
+  ADMIN_ADJUSTMENT_REASONS: '/api/v1/admin/adjustments/reasons',
+  ADMIN_ADJUSTMENT_REVIEW: '/api/v1/admin/adjustments/:id/review',
+  ADMIN_ADJUSTMENT_APPROVALS: '/api/v1/admin/adjustments/:id/approvals',
+  ADMIN_WITHDRAW_POLICY_OVERRIDES: '/api/v1/withdraw-policy/overrides',
+  ADMIN_WITHDRAW_POLICY_OVERRIDE: '/api/v1/withdraw-policy/overrides/:user_id',
+  ADMIN_MARGIN_CALLS: '/api/v1/margin-calls',
+  ADMIN_QUOTE_QUARANTINE: '/api/v1/quotes/quarantine',
+  ADMIN_QUOTE_QUARANTINE_REVIEW: '/api/v1/quotes/quarantine/:id/review',
+  ADMIN_RECONCILIATION_RUNS: '/api/v1/reconciliation/runs',
+  ADMIN_RECONCILIATION_RUN: '/api/v1/reconciliation/run',
+  ADMIN_RECONCILIATION_MISMATCHES: '/api/v1/reconciliation/mismatches',
+  ADMIN_RECONCILIATION_RESOLVE: '/api/v1/reconciliation/mismatches/:id/resolve',
   ADMIN_ANNOUNCEMENTS: '/api/v1/admin/announcements',
   ADMIN_SALESPERSONS: '/api/v1/admin/salespersons',
   
@@ -21,6 +21,8 @@ import BankCards from '../pages/BankCards.vue'
 import Notifications from '../pages/Notifications.vue'
 import About from '../pages/About.vue'
 import InviteCodes from '../pages/InviteCodes.vue'
+import PriceAlerts from '../pages/PriceAlerts.vue'
+import Statements from '../pages/Statements.vue'
 
 // 管理员页面
 import AdminUsers from '../pages/admin/Users.vue'
@@ -31,6 +33,15 @@ import PaymentSettings from '../pages/admin/PaymentSettings.vue'
 import PlatformAddresses from '../pages/admin/PlatformAddresses.vue'
 import AdminAnnouncements from '../pages/admin/Announcements.vue'
 import AdminSales from '../pages/admin/Sales.vue'
+import AdminMarginCalls from '../pages/admin/MarginCalls.vue'
+import AdminQuoteQuarantine from '../pages/admin/QuoteQuarantine.vue'
+import AdminReconciliation from '../pages/admin/Reconciliation.vue'
+import AdminPayments from '../pages/admin/Payments.vue'
+import AdminBankStatements from '../pages/admin/BankStatements.vue'
+import AdminWithdrawPolicy from '../pages/admin/WithdrawPolicy.vue'
+import AdminAccountStatements from '../pages/admin/AccountStatements.vue'
+import AdminExports from '../pages/admin/Exports.vue'
+import AdminAdjustments from '../pages/admin/Adjustments.vue'
 
 const router = createRouter({
   history: createWebHistory(),
@@ -91,6 +102,16 @@ const router = createRouter({
       component: About, 
       meta: { requiresAuth: true } 
     },
+    {
+      path: '/price-alerts',
+      component: PriceAlerts,
+      meta: { requiresAuth: true }
+    },
+    {
+      path: '/statements',
+      component: Statements,
+      meta: { requiresAuth: true }
+    },
     {
       path: '/invite-codes',
       component: InviteCodes,
@@ -123,6 +144,51 @@ const router = createRouter({
       component: AdminWithdraws, 
       meta: { requiresAuth: true, requiresAdmin: true } 
     },
+    {
+      path: '/admin/margin-calls',
+      component: AdminMarginCalls,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/quote-quarantine',
+      component: AdminQuoteQuarantine,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/reconciliation',
+      component: AdminReconciliation,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/account-statements',
+      component: AdminAccountStatements,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/exports',
+      component: AdminExports,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/adjustments',
+      component: AdminAdjustments,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/payments',
+      component: AdminPayments,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/bank-statements',
+      component: AdminBankStatements,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
+    {
+      path: '/admin/withdraw-policy',
+      component: AdminWithdrawPolicy,
+      meta: { requiresAuth: true, requiresAdmin: true }
+    },
     { 
       path: '/admin/config', 
       component: AdminConfig, 
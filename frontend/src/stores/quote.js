@@ -1,5 +1,9 @@
 import { defineStore } from 'pinia'
 import { WS_CONFIG } from '../config/websocket'
+import { toDisplayQuote } from '../utils/quoteWebSocket'
+
+// 首页/交易页使用的品种（按优先级）
+const PRICE_SYMBOLS = ['AU', 'AU9999', 'XAU']
 
 export const useQuoteStore = defineStore('quote', {
   state: () => ({
@@ -45,25 +49,24 @@ export const useQuoteStore = defineStore('quote', {
       this.ws.onopen = () => {
         console.log('行情WebSocket已连接')
         this.isConnected = true
+        this.ws.send(JSON.stringify({ action: 'subscribe', symbols: PRICE_SYMBOLS }))
       }
       
       this.ws.onmessage = (event) => {
         try {
-          let data = JSON.parse(event.data)
+          const data = JSON.parse(event.data)
           
-          // 检查是否有content字段（需要二次解析）
-          if (data.content && typeof data.content === 'string') {
-            console.log('检测到嵌套JSON，进行二次解析...')
-            data = JSON.parse(data.content)
+          // 只处理标准化报价（仅包含有变化的品种，需与已有数据合并）
+          if (data.type !== 'quote' || !Array.isArray(data.data)) {
+            return
           }
           
-          // 检查是否有items字段
-          if (data.items) {
-            console.log('从items中提取行情数据')
-            data = data.items
-          }
+          const items = { ...this.quoteData }
+          data.data.forEach(quote => {
+            items[quote.symbol] = toDisplayQuote(quote)
+          })
           
-          this.updateQuote(data)
+          this.updateQuote(items)
         } catch (error) {
           console.error('解析行情数据失败:', error)
         }
@@ -1,4 +1,5 @@
 import dayjs from 'dayjs'
+import { API_BASE_URL } from '../config/api'
 
 /**
  * 格式化金额
@@ -90,6 +91,7 @@ export const POSITION_STATUS = {
  */
 export const REVIEW_STATUS = {
   pending: '待审核',
+  reviewing: '待复核',
   approved: '已通过',
   rejected: '已拒绝'
 }
@@ -110,7 +112,39 @@ export const ROLE_TEXT = {
   customer: '客户',
   sales: '销售',
   support: '客服',
-  super_admin: '超级管理员'
+  super_admin: '超级管理员',
+  system: '系统'
+}
+
+const APPROVAL_DECISION_TEXT = {
+  approve: '通过',
+  reject: '驳回',
+  submit: '发起'
+}
+
+/**
+ * 审批记录转为可读文本（每步一行）
+ * @param {Array} steps - 审批步骤
+ * @returns {string}
+ */
+export function formatApprovalSteps(steps) {
+  if (!steps || steps.length === 0) return '暂无审批记录'
+  return steps.map((step) => {
+    const decision = APPROVAL_DECISION_TEXT[step.decision] || step.decision
+    const role = ROLE_TEXT[step.approver_role] || step.approver_role
+    const note = step.note ? `：${step.note}` : ''
+    return `第${step.step}级 ${step.approver_name}（${role}）${decision}${note}\n${formatDateTime(step.created_at)}`
+  }).join('\n\n')
+}
+
+/**
+ * 文件下载地址补全（后端返回的 /api/... 签名链接需拼接后端地址）
+ * @param {string} url - 图片/文件地址
+ * @returns {string}
+ */
+export function resolveFileUrl(url) {
+  if (!url) return ''
+  return url.startsWith('/api/') ? `${API_BASE_URL}${url}` : url
 }
 
 /**
@@ -187,3 +221,19 @@ export function throttle(fn, delay = 300) {
     }
   }
 }
+
+/**
+ * 保存下载的文件（配合 responseType: 'blob' 的请求）
+ * @param {Blob} blob - 文件内容
+ * @param {string} fileName - 文件名
+ */
+export function saveBlob(blob, fileName) {
+  const url = URL.createObjectURL(blob)
+  const link = document.createElement('a')
+  link.href = url
+  link.download = fileName
+  document.body.appendChild(link)
+  link.click()
+  document.body.removeChild(link)
+  setTimeout(() => URL.revokeObjectURL(url), 1000)
+}
@@ -2,9 +2,9 @@
  * 行情WebSocket客户端
  * 
  * 用途：
- * - 连接到真实行情数据源 wss://push143.jtd9999.vip/ws
+ * - 连接后端行情代理 /ws/quote，按品种订阅
  * - 实现自动重连机制（指数退避策略）
- * - 解析行情数据并触发回调
+ * - 合并标准化报价（按品种增量推送）并触发回调
  * - 处理心跳包和异常情况
  * 
  * 作者：速金盈技术团队
@@ -23,6 +23,7 @@ class QuoteWebSocket {
     this.reconnectAttempts = 0        // 当前重连次数
     this.messageHandlers = []         // 消息处理器列表
     this.isManualClose = false        // 是否手动关闭
+    this.items = {}                   // 已合并的行情数据（按品种）
   }
 
 
@@ -49,6 +50,7 @@ class QuoteWebSocket {
         console.log('[QuoteWS] ✅ 连接成功到后端代理')
         this.reconnectAttempts = 0
         this.isManualClose = false
+        this.subscribe(getAllProductCodes())
       }
       
       // 接收消息处理
@@ -78,12 +80,24 @@ class QuoteWebSocket {
   }
 
 
+  /**
+   * 订阅品种
+   * 
+   * @param {string[]} symbols - 品种代码列表（'*' 表示全部）
+   * @returns {void}
+   */
+  subscribe(symbols) {
+    if (this.isConnected()) {
+      this.ws.send(JSON.stringify({ action: 'subscribe', symbols }))
+    }
+  }
+
   /**
    * 处理接收到的消息
    * 
    * 消息类型：
-   * - type: 'p' - 心跳包，直接忽略
-   * - type: 'messageevent' - 行情数据，解析后触发回调
+   * - type: 'quote' - 标准化报价（仅包含有变化的品种），合并后触发回调
+   * - type: 'subscribed' / 'market_status' - 订阅确认、平台状态，忽略
    * 
    * @param {MessageEvent} event - WebSocket消息事件
    * @returns {void}
@@ -92,26 +106,25 @@ class QuoteWebSocket {
     try {
       const data = JSON.parse(event.data)
       
-      // 处理心跳包
-      if (data.type === 'p') {
+      if (data.type !== 'quote' || !Array.isArray(data.data)) {
         return
       }
       
-      // 处理行情数据
-      if (data.type === 'messageevent' && data.content) {
-        const content = JSON.parse(data.content)
-        
-        if (content.items) {
-          // 触发所有注册的消息处理器
-          this.messageHandlers.forEach(handler => {
-            try {
-              handler(content.items)
-            } catch (err) {
-              console.error('[QuoteWS] 消息处理器执行错误:', err)
-            }
-          })
+      // 合并为 { 品种: { Buy, Sell, H, L, Gap } } 结构，保持页面展示字段不变
+      const items = { ...this.items }
+      data.data.forEach(quote => {
+        items[quote.symbol] = toDisplayQuote(quote)
+      })
+      this.items = items
+      
+      // 触发所有注册的消息处理器
+      this.messageHandlers.forEach(handler => {
+        try {
+          handler(items)
+        } catch (err) {
+          console.error('[QuoteWS] 消息处理器执行错误:', err)
         }
-      }
+      })
     } catch (error) {
       console.error('[QuoteWS] 消息解析错误:', error)
     }
@@ -200,5 +213,32 @@ class QuoteWebSocket {
   }
 }
 
+/**
+ * 获取行情页展示的全部品种代码
+ * 
+ * @returns {string[]}
+ */
+function getAllProductCodes() {
+  return Object.values(WS_CONFIG.PRODUCT_ORDER)
+    .flat()
+    .map(product => product.code)
+}
+
+/**
+ * 将标准化报价转换为页面展示字段
+ * 
+ * @param {Object} quote - { symbol, bid, ask, last, high, low, change, ts }
+ * @returns {Object} { Buy, Sell, H, L, Gap }
+ */
+export function toDisplayQuote(quote) {
+  return {
+    Buy: quote.bid,
+    Sell: quote.ask,
+    H: quote.high,
+    L: quote.low,
+    Gap: quote.change
+  }
+}
+
 // 导出单例实例
 export const quoteWS = new QuoteWebSocket()